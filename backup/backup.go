@@ -0,0 +1,288 @@
+// Package backup reads and writes backup archives containing all data of the
+// ToDo app, independent of the storage backend. An archive is a gzip-compressed
+// JSON Lines file: The first line is a header identifying the format and its
+// version, each following line is a record holding a single entity, and the
+// last line is a trailer with the number of records per type.
+//
+// Each record carries the SHA-256 checksum of its data, and the trailer carries
+// a checksum over all records, so that corrupted, reordered and missing records
+// are detected when reading an archive. A missing trailer indicates that the
+// archive has been truncated.
+package backup
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+)
+
+const (
+	// Format identifies backup archives in their header.
+	Format = "todo-backup"
+
+	// Version is the version of the archive format written by Writer. Reader
+	// accepts archives up to this version.
+	Version = 1
+)
+
+// The types of the records in an archive.
+const (
+	TypeToDo         = "todo"
+	TypeComment      = "comment"
+	TypeAttachment   = "attachment"
+	TypeView         = "view"
+	TypeWebhook      = "webhook"
+	TypeDelivery     = "delivery"
+	TypeCalendarFeed = "calendar_feed"
+)
+
+// trailerType is the type of the trailer, which is the last line of an archive.
+const trailerType = "end"
+
+var (
+	// ErrInvalidArchive indicates that the data is not a backup archive.
+	ErrInvalidArchive = errors.New("invalid backup archive")
+
+	// ErrUnsupportedVersion indicates that an archive has been written by a
+	// newer version of the archive format.
+	ErrUnsupportedVersion = errors.New("unsupported backup archive version")
+
+	// ErrChecksumMismatch indicates that the content of an archive doesn't match
+	// its checksums, i.e. the archive is corrupted.
+	ErrChecksumMismatch = errors.New("backup archive checksum mismatch")
+
+	// ErrTruncated indicates that an archive ends before its trailer.
+	ErrTruncated = errors.New("backup archive is truncated")
+)
+
+// Header is the first line of an archive.
+type Header struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Record is an entity stored in an archive. Data is the JSON encoding of the
+// entity, whose type is identified by Type.
+type Record struct {
+	Type string
+	Data json.RawMessage
+}
+
+// Decode decodes the data of the record into v.
+func (r Record) Decode(v interface{}) error {
+	return json.Unmarshal(r.Data, v)
+}
+
+// line is a record or the trailer as written to an archive.
+type line struct {
+	Type     string          `json:"type"`
+	Data     json.RawMessage `json:"data,omitempty"`
+	Counts   map[string]int  `json:"counts,omitempty"`
+	Checksum string          `json:"checksum"`
+}
+
+// Writer writes an archive. Records are written in the order of the Write
+// calls, and the archive is completed by Close.
+type Writer struct {
+	gzip    *gzip.Writer
+	encoder *json.Encoder
+	digest  *digest
+}
+
+// NewWriter writes the header of an archive created at the given time to w and
+// returns a Writer for its records.
+func NewWriter(w io.Writer, createdAt time.Time) (*Writer, error) {
+	compressed := gzip.NewWriter(w)
+	encoder := json.NewEncoder(compressed)
+
+	// The data of records is written as it is, so that it matches its checksum.
+	encoder.SetEscapeHTML(false)
+
+	header := Header{
+		Format:    Format,
+		Version:   Version,
+		CreatedAt: createdAt.UTC(),
+	}
+
+	if err := encoder.Encode(header); err != nil {
+		return nil, err
+	}
+
+	return &Writer{
+		gzip:    compressed,
+		encoder: encoder,
+		digest:  newDigest(),
+	}, nil
+}
+
+// Write writes v as a record of the given type.
+func (w *Writer) Write(recordType string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return w.encoder.Encode(line{
+		Type:     recordType,
+		Data:     data,
+		Checksum: w.digest.add(recordType, data),
+	})
+}
+
+// Close writes the trailer and flushes the archive. It doesn't close the
+// underlying writer.
+func (w *Writer) Close() error {
+	trailer := line{
+		Type:     trailerType,
+		Counts:   w.digest.counts,
+		Checksum: w.digest.sum(),
+	}
+
+	if err := w.encoder.Encode(trailer); err != nil {
+		return err
+	}
+
+	return w.gzip.Close()
+}
+
+// Reader reads the records of an archive and verifies their checksums.
+type Reader struct {
+	decoder *json.Decoder
+	header  Header
+	digest  *digest
+}
+
+// NewReader reads the header of the archive read from r and returns a Reader
+// for its records.
+func NewReader(r io.Reader) (*Reader, error) {
+	compressed, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err.Error())
+	}
+
+	decoder := json.NewDecoder(compressed)
+
+	var header Header
+
+	if err := decoder.Decode(&header); err != nil || header.Format != Format {
+		return nil, ErrInvalidArchive
+	}
+
+	if header.Version < 1 || header.Version > Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, header.Version)
+	}
+
+	return &Reader{
+		decoder: decoder,
+		header:  header,
+		digest:  newDigest(),
+	}, nil
+}
+
+// Header returns the header of the archive.
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// Counts returns the number of records read so far per record type.
+func (r *Reader) Counts() map[string]int {
+	return r.digest.counts
+}
+
+// Next returns the next record of the archive. After the last record, Next
+// verifies the trailer and returns io.EOF if the archive is complete and
+// intact.
+func (r *Reader) Next() (Record, error) {
+	var next line
+
+	if err := r.decoder.Decode(&next); err != nil {
+		return Record{}, readError(err)
+	}
+
+	if next.Type == trailerType {
+		return Record{}, r.verifyTrailer(next)
+	}
+
+	if next.Type == "" || len(next.Data) == 0 {
+		return Record{}, fmt.Errorf("%w: record without type or data", ErrInvalidArchive)
+	}
+
+	if checksum := r.digest.add(next.Type, next.Data); checksum != next.Checksum {
+		return Record{}, fmt.Errorf("%w: %s record %d", ErrChecksumMismatch, next.Type, r.digest.counts[next.Type])
+	}
+
+	return Record{Type: next.Type, Data: next.Data}, nil
+}
+
+// verifyTrailer compares the trailer with the records that have been read and
+// makes sure that the archive ends after the trailer.
+func (r *Reader) verifyTrailer(trailer line) error {
+	if trailer.Checksum != r.digest.sum() || len(trailer.Counts) != len(r.digest.counts) {
+		return ErrChecksumMismatch
+	}
+
+	for recordType, count := range trailer.Counts {
+		if r.digest.counts[recordType] != count {
+			return ErrChecksumMismatch
+		}
+	}
+
+	var extra json.RawMessage
+
+	if err := r.decoder.Decode(&extra); err != io.EOF {
+		if err == nil {
+			return fmt.Errorf("%w: data after trailer", ErrInvalidArchive)
+		}
+		return readError(err)
+	}
+
+	return io.EOF
+}
+
+// readError converts an error returned while decoding an archive.
+func readError(err error) error {
+	switch {
+	case err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrTruncated
+	case errors.Is(err, gzip.ErrChecksum):
+		return ErrChecksumMismatch
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidArchive, err.Error())
+	}
+}
+
+// digest computes the checksums of the records and counts them.
+type digest struct {
+	hash   hash.Hash
+	counts map[string]int
+}
+
+func newDigest() *digest {
+	return &digest{
+		hash:   sha256.New(),
+		counts: make(map[string]int),
+	}
+}
+
+// add adds a record to the archive checksum and returns the record checksum.
+func (d *digest) add(recordType string, data []byte) string {
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+
+	_, _ = fmt.Fprintf(d.hash, "%s:%s\n", recordType, checksum)
+	d.counts[recordType]++
+
+	return checksum
+}
+
+// sum returns the archive checksum of the records added so far.
+func (d *digest) sum() string {
+	return hex.EncodeToString(d.hash.Sum(nil))
+}
@@ -0,0 +1,214 @@
+// Package backup reads and writes backup archives containing all data of the
+// ToDo app, independent of the storage backend. An archive is a gzip-compressed
+// JSON Lines file: The first line is a header identifying the format and its
+// version, each following line is a record holding a single entity, and the
+// last line is a trailer with the number of records per type.
+//
+// Each record carries the SHA-256 checksum of its data, and the trailer carries
+// a checksum over all records, so that corrupted, reordered and missing records
+// are detected when reading an archive. A missing trailer indicates that the
+// archive has been truncated.
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// writeArchive writes an archive with two ToDo items and a view.
+func writeArchive(t *testing.T) []byte {
+	var buffer bytes.Buffer
+
+	writer, err := NewWriter(&buffer, time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := []struct {
+		recordType string
+		value      interface{}
+	}{
+		{TypeToDo, model.ToDo{ID: 1, Name: "Release <1.0> & more", Version: 3}},
+		{TypeToDo, model.ToDo{ID: 4, Name: "Buy milk", Tasks: []model.Task{{ID: 2, Name: "Oat milk"}}}},
+		{TypeView, model.View{ID: 1, Owner: "alice", Name: "Open", Filter: "NOT done"}},
+	}
+
+	for _, record := range records {
+		if err := writer.Write(record.recordType, record.value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buffer.Bytes()
+}
+
+// readAll reads all records of an archive.
+func readAll(archive []byte) ([]Record, error) {
+	reader, err := NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
+
+// rewrite decompresses an archive, applies edit to its lines and compresses
+// the result again.
+func rewrite(t *testing.T, archive []byte, edit func(lines []string) []string) []byte {
+	reader, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := edit(strings.SplitAfter(string(content), "\n"))
+
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	_, _ = writer.Write([]byte(strings.Join(lines, "")))
+	_ = writer.Close()
+
+	return buffer.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	archive := writeArchive(t)
+
+	reader, err := NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if header := reader.Header(); header.Version != Version || header.CreatedAt.Year() != 2026 {
+		t.Errorf("unexpected header %v", header)
+	}
+
+	records, err := readAll(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 3 || records[0].Type != TypeToDo || records[2].Type != TypeView {
+		t.Fatalf("expected 2 ToDo records and a view record, got %v", records)
+	}
+
+	var toDo model.ToDo
+
+	if err := records[1].Decode(&toDo); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := model.ToDo{ID: 4, Name: "Buy milk", Tasks: []model.Task{{ID: 2, Name: "Oat milk"}}}
+
+	if !cmp.Equal(toDo, expected) {
+		t.Errorf("expected ToDo %v, got %v", expected, toDo)
+	}
+}
+
+func TestReader_Errors(t *testing.T) {
+	archive := writeArchive(t)
+
+	tests := []struct {
+		name     string
+		archive  []byte
+		expected error
+	}{
+		{
+			name:     "no gzip",
+			archive:  []byte(`{"format":"todo-backup","version":1}`),
+			expected: ErrInvalidArchive,
+		},
+		{
+			name: "other format",
+			archive: rewrite(t, archive, func(lines []string) []string {
+				return []string{`{"format":"something","version":1}` + "\n"}
+			}),
+			expected: ErrInvalidArchive,
+		},
+		{
+			name: "newer version",
+			archive: rewrite(t, archive, func(lines []string) []string {
+				lines[0] = strings.Replace(lines[0], `"version":1`, `"version":2`, 1)
+				return lines
+			}),
+			expected: ErrUnsupportedVersion,
+		},
+		{
+			name: "modified record",
+			archive: rewrite(t, archive, func(lines []string) []string {
+				lines[2] = strings.Replace(lines[2], "Buy milk", "Buy beer", 1)
+				return lines
+			}),
+			expected: ErrChecksumMismatch,
+		},
+		{
+			name: "removed record",
+			archive: rewrite(t, archive, func(lines []string) []string {
+				return append(lines[:2], lines[3:]...)
+			}),
+			expected: ErrChecksumMismatch,
+		},
+		{
+			name: "swapped records",
+			archive: rewrite(t, archive, func(lines []string) []string {
+				lines[1], lines[2] = lines[2], lines[1]
+				return lines
+			}),
+			expected: ErrChecksumMismatch,
+		},
+		{
+			name: "missing trailer",
+			archive: rewrite(t, archive, func(lines []string) []string {
+				return lines[:4]
+			}),
+			expected: ErrTruncated,
+		},
+		{
+			name:     "truncated gzip stream",
+			archive:  archive[:len(archive)-10],
+			expected: ErrTruncated,
+		},
+		{
+			name: "data after trailer",
+			archive: rewrite(t, archive, func(lines []string) []string {
+				return append(lines, lines[1])
+			}),
+			expected: ErrInvalidArchive,
+		},
+	}
+
+	for _, test := range tests {
+		if _, err := readAll(test.archive); !errors.Is(err, test.expected) {
+			t.Errorf("%s: expected error %v, got %v", test.name, test.expected, err)
+		}
+	}
+}
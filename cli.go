@@ -0,0 +1,444 @@
+// Package main provides the application entrypoint as well as functions for
+// parsing CLI flags and environment variables.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dominikbraun/todo/client"
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+	"github.com/dominikbraun/todo/tui"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// requestTimeout is the maximum duration of a single CLI command.
+const requestTimeout = 30 * time.Second
+
+// transferTimeout is the maximum duration of a backup or restore, which may
+// transfer large archives.
+const transferTimeout = 30 * time.Minute
+
+var (
+	// errInvalidArguments indicates that a command has been called with the
+	// wrong number of arguments.
+	errInvalidArguments = errors.New("invalid number of arguments")
+
+	// errInvalidID indicates that an ID argument is not a valid ID.
+	errInvalidID = errors.New("id must be a number like 12 or, for tasks, 12/3")
+
+	// errInvalidDue indicates that a due date is neither a date nor a timestamp.
+	errInvalidDue = errors.New("due must be a date like 2026-11-01 or an RFC 3339 timestamp")
+)
+
+// clientFlags creates the flag set of a CLI command, containing the flags that
+// all commands talking to the server have in common.
+func clientFlags(command string) *pflag.FlagSet {
+	flags := pflag.NewFlagSet(command, pflag.ContinueOnError)
+
+	flags.String("server", "http://localhost:8000", "The URL of the ToDo server")
+	flags.String("user", "", "The user sent as X-User header")
+	flags.StringP("output", "o", outputTable, "The output format: table, json or plain")
+
+	return flags
+}
+
+// newClient creates a client for the configured server.
+func newClient(config *viper.Viper) (*client.Client, error) {
+	return client.New(client.Config{
+		URL:        config.GetString("server"),
+		User:       config.GetString("user"),
+		AdminToken: config.GetString("admin-token"),
+	})
+}
+
+// parseCommand parses the flags of a client command, checks the number of
+// positional arguments and creates a client and a printer for the command.
+func parseCommand(flags *pflag.FlagSet, args []string, expectedArgs int, stdout io.Writer) (*viper.Viper, *client.Client, *printer, error) {
+	config, err := loadConfig(flags, args)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if flags.NArg() != expectedArgs {
+		return nil, nil, nil, errInvalidArguments
+	}
+
+	printer, err := newPrinter(stdout, config.GetString("output"))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	c, err := newClient(config)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return config, c, printer, nil
+}
+
+// add creates a ToDo item, e.g. `todo add "Release 1.0" --task "Write notes"`.
+func add(args []string, stdout io.Writer) error {
+	flags := clientFlags("add")
+
+	flags.StringP("description", "d", "", "The description of the ToDo item")
+	flags.StringArrayP("task", "t", nil, "A task of the ToDo item, can be repeated")
+	flags.StringArray("tag", nil, "A tag of the ToDo item, can be repeated")
+	flags.StringP("project", "p", "", "The project of the ToDo item")
+	flags.String("priority", "", "The priority of the ToDo item from A to Z")
+	flags.String("due", "", "The due date as date like 2026-11-01 or RFC 3339 timestamp")
+	flags.String("recurrence", "", "A recurrence rule like FREQ=WEEKLY;BYDAY=MO")
+
+	config, c, printer, err := parseCommand(flags, args, 1, stdout)
+	if err != nil {
+		return err
+	}
+
+	toDo := model.ToDo{
+		Name:        flags.Arg(0),
+		Description: config.GetString("description"),
+		Project:     config.GetString("project"),
+		Priority:    config.GetString("priority"),
+		Recurrence:  config.GetString("recurrence"),
+	}
+
+	toDo.Tags, _ = flags.GetStringArray("tag")
+	tasks, _ := flags.GetStringArray("task")
+
+	for _, task := range tasks {
+		toDo.Tasks = append(toDo.Tasks, model.Task{Name: task})
+	}
+
+	if due := config.GetString("due"); due != "" {
+		if toDo.Due, err = parseDue(due); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	createdToDo, err := c.CreateToDo(ctx, toDo)
+	if err != nil {
+		return err
+	}
+
+	return printer.printToDo(createdToDo)
+}
+
+// list lists all ToDo items, or the items matching a filter query or view.
+func list(args []string, stdout io.Writer) error {
+	flags := clientFlags("ls")
+
+	flags.StringP("filter", "f", "", "Only list ToDo items matching the filter query")
+	flags.String("view", "", "Only list ToDo items matching the view with the given key or ID")
+
+	config, c, printer, err := parseCommand(flags, args, 0, stdout)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	var toDos []model.ToDo
+
+	switch {
+	case config.GetString("view") != "":
+		toDos, err = c.GetViewToDos(ctx, model.View{Key: config.GetString("view")})
+	case config.GetString("filter") != "":
+		toDos, err = c.GetToDosByFilter(ctx, config.GetString("filter"))
+	default:
+		toDos, err = c.GetToDos(ctx)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return printer.printToDos(toDos)
+}
+
+// show shows a single ToDo item along with its tasks.
+func show(args []string, stdout io.Writer) error {
+	flags := clientFlags("show")
+
+	_, c, printer, err := parseCommand(flags, args, 1, stdout)
+	if err != nil {
+		return err
+	}
+
+	id, taskID, err := parseID(flags.Arg(0))
+	if err != nil || taskID != 0 {
+		return errInvalidID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	toDo, err := c.GetToDo(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return printer.printToDo(toDo)
+}
+
+// done marks a ToDo item as done, or a task if the ID has the form `12/3`.
+func done(args []string, stdout io.Writer) error {
+	flags := clientFlags("done")
+
+	flags.Bool("undo", false, "Mark the ToDo item or task as not done instead")
+
+	config, c, printer, err := parseCommand(flags, args, 1, stdout)
+	if err != nil {
+		return err
+	}
+
+	id, taskID, err := parseID(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	toDo, err := c.GetToDo(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	isDone := !config.GetBool("undo")
+
+	if taskID == 0 {
+		toDo.Done = isDone
+
+		if err := c.UpdateToDo(ctx, id, toDo); err != nil {
+			return err
+		}
+
+		if toDo, err = c.GetToDo(ctx, id); err != nil {
+			return err
+		}
+	} else {
+		task, err := findTask(toDo, taskID)
+		if err != nil {
+			return err
+		}
+
+		task.Done = isDone
+
+		if toDo, err = c.UpdateTask(ctx, id, toDo.Version, task); err != nil {
+			return err
+		}
+	}
+
+	return printer.printToDo(toDo)
+}
+
+// remove deletes a ToDo item along with its tasks.
+func remove(args []string, stdout io.Writer) error {
+	flags := clientFlags("rm")
+
+	_, c, printer, err := parseCommand(flags, args, 1, stdout)
+	if err != nil {
+		return err
+	}
+
+	id, taskID, err := parseID(flags.Arg(0))
+	if err != nil || taskID != 0 {
+		return errInvalidID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	if err := c.DeleteToDo(ctx, id); err != nil {
+		return err
+	}
+
+	return printer.printMessage(fmt.Sprintf("deleted ToDo %d", id))
+}
+
+// interactive runs the interactive terminal UI until the user quits.
+func interactive(args []string, stdout io.Writer) error {
+	flags := clientFlags("tui")
+
+	_, c, _, err := parseCommand(flags, args, 0, stdout)
+	if err != nil {
+		return err
+	}
+
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return err
+	}
+
+	if err := screen.Init(); err != nil {
+		return err
+	}
+	defer screen.Fini()
+
+	return tui.New(c, screen).Run(context.Background())
+}
+
+// backupData writes a backup archive of all data to the given file, or to
+// stdout if no file is given, e.g. `todo backup todo.jsonl.gz`.
+func backupData(args []string, stdout io.Writer) error {
+	flags := clientFlags("backup")
+	flags.String("admin-token", "", "The admin token of the server")
+
+	config, err := loadConfig(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() > 1 {
+		return errInvalidArguments
+	}
+
+	c, err := newClient(config)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), transferTimeout)
+	defer cancel()
+
+	if flags.NArg() == 0 {
+		return c.Backup(ctx, stdout)
+	}
+
+	file, err := os.Create(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if err := c.Backup(ctx, file); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	printer, err := newPrinter(stdout, config.GetString("output"))
+	if err != nil {
+		return err
+	}
+
+	return printer.printMessage(fmt.Sprintf("wrote backup to %s", file.Name()))
+}
+
+// restoreData restores a backup archive, e.g. `todo restore todo.jsonl.gz`.
+// Existing data is only overwritten with --force.
+func restoreData(args []string, stdout io.Writer) error {
+	flags := clientFlags("restore")
+	flags.String("admin-token", "", "The admin token of the server")
+	flags.Bool("force", false, "Overwrite the existing data of the server")
+
+	config, c, printer, err := parseCommand(flags, args, 1, stdout)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), transferTimeout)
+	defer cancel()
+
+	result, err := c.Restore(ctx, file, config.GetBool("force"))
+	if err != nil {
+		return err
+	}
+
+	if printer.format == outputJSON {
+		return printer.printJSON(result)
+	}
+
+	recordTypes := make([]string, 0, len(result.Counts))
+
+	for recordType := range result.Counts {
+		recordTypes = append(recordTypes, recordType)
+	}
+
+	sort.Strings(recordTypes)
+
+	counts := make([]string, len(recordTypes))
+
+	for i, recordType := range recordTypes {
+		counts[i] = fmt.Sprintf("%d %s", result.Counts[recordType], recordType)
+	}
+
+	message := fmt.Sprintf("restored backup of %s", result.CreatedAt.Local().Format("2006-01-02 15:04"))
+	if len(counts) > 0 {
+		message += ": " + strings.Join(counts, ", ")
+	}
+
+	return printer.printMessage(message)
+}
+
+// parseID parses an ID argument, which is either a ToDo ID like `12` or a ToDo
+// ID and a task ID like `12/3`. The task ID is 0 if there is none.
+func parseID(arg string) (int64, int64, error) {
+	parts := strings.SplitN(arg, "/", 2)
+
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || id <= 0 {
+		return 0, 0, errInvalidID
+	}
+
+	if len(parts) == 1 {
+		return id, 0, nil
+	}
+
+	taskID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || taskID <= 0 {
+		return 0, 0, errInvalidID
+	}
+
+	return id, taskID, nil
+}
+
+// parseDue parses a due date, which is either a date or an RFC 3339 timestamp.
+// Dates are interpreted as midnight in UTC.
+func parseDue(value string) (*time.Time, error) {
+	due, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		if due, err = time.Parse(time.RFC3339, value); err != nil {
+			return nil, errInvalidDue
+		}
+	}
+
+	due = due.UTC()
+
+	return &due, nil
+}
+
+// findTask returns the task of the ToDo item with the given ID.
+func findTask(toDo model.ToDo, taskID int64) (model.Task, error) {
+	for _, task := range toDo.Tasks {
+		if task.ID == taskID {
+			return task, nil
+		}
+	}
+
+	return model.Task{}, storage.ErrTaskNotFound
+}
@@ -0,0 +1,324 @@
+// Package main provides the application entrypoint as well as functions for
+// parsing CLI flags and environment variables.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/server"
+	"github.com/dominikbraun/todo/storage"
+)
+
+// testAdminToken is the admin token of the test server.
+const testAdminToken = "secret"
+
+// newTestServer starts an httptest.Server serving the real router backed by an
+// in-memory storage and returns its URL.
+func newTestServer(t *testing.T) string {
+	fileSystem, err := storage.NewFileSystem(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create blob store: %s", err.Error())
+	}
+
+	app := core.NewApp(storage.NewMemory(), fileSystem)
+	testServer := httptest.NewServer(server.New(0, 0, app, testAdminToken).Handler())
+	t.Cleanup(testServer.Close)
+
+	return testServer.URL
+}
+
+// runCommand runs a CLI command against the given server and returns its output.
+func runCommand(t *testing.T, serverURL string, args ...string) (string, error) {
+	var stdout bytes.Buffer
+
+	err := run(append(args, "--server", serverURL), &stdout)
+
+	return stdout.String(), err
+}
+
+func TestCommands(t *testing.T) {
+	serverURL := newTestServer(t)
+
+	output, err := runCommand(t, serverURL, "add", "Release 1.0", "--task", "Write notes", "-t", "Tag commit", "--tag", "release", "--due", "2026-11-01", "-o", "plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "1 [ ] Release 1.0\n1/1 [ ] Write notes\n1/2 [ ] Tag commit\n"
+
+	if output != expected {
+		t.Fatalf("expected output %q, got %q", expected, output)
+	}
+
+	_, _ = runCommand(t, serverURL, "add", "Other ToDo")
+
+	output, err = runCommand(t, serverURL, "ls", "--filter", "tag:release")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "ID") || !strings.Contains(lines[1], "0/2") || !strings.Contains(lines[1], "2026-11-01") {
+		t.Fatalf("expected header and Release 1.0 with 0/2 tasks, got %q", output)
+	}
+
+	if _, err := runCommand(t, serverURL, "done", "1/2"); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err = runCommand(t, serverURL, "done", "1", "-o", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var toDo model.ToDo
+
+	if err := json.Unmarshal([]byte(output), &toDo); err != nil {
+		t.Fatalf("could not parse output: %s", err.Error())
+	}
+
+	if !toDo.Done || toDo.Tasks[0].Done || !toDo.Tasks[1].Done {
+		t.Errorf("expected ToDo and second task to be done, got %v", toDo)
+	}
+
+	output, _ = runCommand(t, serverURL, "show", "1")
+
+	if !strings.Contains(output, "Release 1.0") || !strings.HasSuffix(output, "1/2   [x]   Tag commit\n") {
+		t.Errorf("expected ToDo details with done task, got %q", output)
+	}
+
+	if _, err := runCommand(t, serverURL, "rm", "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := runCommand(t, serverURL, "show", "1"); !errors.Is(err, storage.ErrToDoNotFound) {
+		t.Errorf("expected error %v, got %v", storage.ErrToDoNotFound, err)
+	}
+
+	output, _ = runCommand(t, serverURL, "ls", "-o", "json")
+
+	var toDos []model.ToDo
+
+	if err := json.Unmarshal([]byte(output), &toDos); err != nil || len(toDos) != 1 {
+		t.Errorf("expected only the other ToDo, got %q", output)
+	}
+}
+
+func TestCommands_Errors(t *testing.T) {
+	serverURL := newTestServer(t)
+
+	tests := []struct {
+		args     []string
+		expected error
+	}{
+		{[]string{"add"}, errInvalidArguments},
+		{[]string{"add", ""}, core.ErrNameMustNotBeEmpty},
+		{[]string{"add", "ToDo", "--due", "tomorrow"}, errInvalidDue},
+		{[]string{"show", "abc"}, errInvalidID},
+		{[]string{"rm", "1/2"}, errInvalidID},
+		{[]string{"done", "42"}, storage.ErrToDoNotFound},
+		{[]string{"ls", "-o", "xml"}, errUnknownOutput},
+		{[]string{"unknown"}, errUnknownCommand},
+		{[]string{"storage", "move"}, errUnknownSubcommand},
+	}
+
+	for _, test := range tests {
+		if _, err := runCommand(t, serverURL, test.args...); !errors.Is(err, test.expected) {
+			t.Errorf("%v: expected error %v, got %v", test.args, test.expected, err)
+		}
+	}
+}
+
+func TestCommands_BackupAndRestore(t *testing.T) {
+	serverURL := newTestServer(t)
+	archive := filepath.Join(t.TempDir(), "todo.jsonl.gz")
+
+	_, _ = runCommand(t, serverURL, "add", "Release 1.0", "--task", "Write notes")
+
+	if _, err := runCommand(t, serverURL, "backup", archive); err == nil {
+		t.Fatal("expected backup without admin token to fail")
+	}
+
+	if _, err := os.Stat(archive); !os.IsNotExist(err) {
+		t.Errorf("expected no archive after failed backup, got %v", err)
+	}
+
+	output, err := runCommand(t, serverURL, "backup", archive, "--admin-token", testAdminToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if output != "wrote backup to "+archive+"\n" {
+		t.Errorf("unexpected output %q", output)
+	}
+
+	if _, err := runCommand(t, serverURL, "restore", archive, "--admin-token", testAdminToken); !errors.Is(err, core.ErrStorageNotEmpty) {
+		t.Errorf("expected error %v, got %v", core.ErrStorageNotEmpty, err)
+	}
+
+	output, err = runCommand(t, newTestServer(t), "restore", archive, "--admin-token", testAdminToken, "-o", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result model.RestoreResult
+
+	if err := json.Unmarshal([]byte(output), &result); err != nil || result.Counts["todo"] != 1 {
+		t.Errorf("expected 1 restored ToDo item, got %q", output)
+	}
+
+	output, err = runCommand(t, serverURL, "restore", archive, "--admin-token", testAdminToken, "--force")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasSuffix(output, ": 1 todo\n") {
+		t.Errorf("unexpected output %q", output)
+	}
+}
+
+func TestCommands_StorageCopy(t *testing.T) {
+	directory := t.TempDir()
+	attachmentsDir := filepath.Join(directory, "attachments")
+
+	fileSystem, err := storage.NewFileSystem(attachmentsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := core.NewApp(storage.NewMemory(), fileSystem)
+
+	toDo, _ := app.CreateToDo(model.ToDo{Name: "Release 1.0", Tasks: []model.Task{{Name: "Write notes"}}})
+	_, _ = app.CreateAttachment(toDo.ID, 0, "notes.txt", strings.NewReader("My notes"))
+
+	source := filepath.Join(directory, "source.jsonl.gz")
+
+	var archive bytes.Buffer
+
+	if err := app.Backup(&archive); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(source, archive.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	target := filepath.Join(directory, "target.jsonl.gz")
+	args := []string{"storage", "copy", "--from", "file:" + source, "--to", "file:" + target, "--attachments-dir", attachmentsDir}
+
+	var stdout bytes.Buffer
+
+	if err := run(args, &stdout); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasSuffix(stdout.String(), "copied and verified 1 todo, 0 comment, 1 attachment, 0 view, 0 webhook, 0 delivery, 0 calendar_feed\n") {
+		t.Errorf("unexpected output %q", stdout.String())
+	}
+
+	// The target archive must contain the data of the source archive.
+	copied, err := openStorage("file:"+target, attachmentsDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if toDos, _ := copied.FindToDos(); len(toDos) != 1 || toDos[0].Name != "Release 1.0" || len(toDos[0].Tasks) != 1 {
+		t.Errorf("unexpected ToDo items %v", toDos)
+	}
+
+	if err := run(args, &stdout); !errors.Is(err, errArchiveExists) {
+		t.Errorf("expected error %v, got %v", errArchiveExists, err)
+	}
+
+	if err := run([]string{"storage", "copy", "--from", "file:" + source, "--to", "memory:", "--attachments-dir", attachmentsDir}, &stdout); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run([]string{"storage", "copy", "--from", "memory:", "--to", "memory:"}, &stdout); !errors.Is(err, errMemorySource) {
+		t.Errorf("expected error %v, got %v", errMemorySource, err)
+	}
+
+	invalidURLs := [][]string{
+		{"--from", "sqlite:todo.db", "--to", "memory:"},
+		{"--from", "file:" + source, "--to", "mariadb://localhost:3306"},
+	}
+
+	for _, args := range invalidURLs {
+		if err := run(append([]string{"storage", "copy", "--attachments-dir", attachmentsDir}, args...), &stdout); !errors.Is(err, errInvalidStorageURL) {
+			t.Errorf("%v: expected error %v, got %v", args, errInvalidStorageURL, err)
+		}
+	}
+}
+
+func TestCommands_CSV(t *testing.T) {
+	serverURL := newTestServer(t)
+	directory := t.TempDir()
+
+	input := filepath.Join(directory, "tasks.csv")
+	content := "Title,Subtask\nRelease 1.0,Write notes\n,Orphan\nCall mom,\n"
+
+	if err := ioutil.WriteFile(input, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := runCommand(t, serverURL, "csv", "import", input, "--all-or-nothing")
+	if !errors.Is(err, core.ErrInvalidRows) || !strings.Contains(err.Error(), "row 3, name: name must not be empty") {
+		t.Fatalf("expected error %v listing row 3, got %v", core.ErrInvalidRows, err)
+	}
+
+	output, err := runCommand(t, serverURL, "csv", "import", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "imported 2 ToDo items, skipped invalid rows:\n  row 3, name: name must not be empty\n"; output != expected {
+		t.Errorf("expected output %q, got %q", expected, output)
+	}
+
+	output, err = runCommand(t, serverURL, "csv", "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := strings.Split(strings.TrimSpace(output), "\n"); len(lines) != 3 || !strings.Contains(lines[1], "Write notes") {
+		t.Errorf("unexpected CSV file %q", output)
+	}
+
+	if err := run([]string{"csv", "convert"}, &bytes.Buffer{}); !errors.Is(err, errUnknownSubcommand) {
+		t.Errorf("expected error %v, got %v", errUnknownSubcommand, err)
+	}
+}
+
+func TestParseServerConfig(t *testing.T) {
+	_ = os.Setenv("TODO_MARIADB_USER", "root")
+	defer os.Unsetenv("TODO_MARIADB_USER")
+
+	config, err := parseServerConfig([]string{"--port", "8080", "--idempotency-window", "1h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.idempotencyWindow != time.Hour {
+		t.Errorf("expected idempotency window %v, got %v", time.Hour, config.idempotencyWindow)
+	}
+
+	if config.serverPort != 8080 || config.grpcPort != 9000 {
+		t.Errorf("expected ports %d and %d, got %d and %d", 8080, 9000, config.serverPort, config.grpcPort)
+	}
+
+	if config.mariaDB.User != "root" {
+		t.Errorf("expected MariaDB user %s from environment, got %s", "root", config.mariaDB.User)
+	}
+}
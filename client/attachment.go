@@ -0,0 +1,106 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+// CreateAttachment uploads the content of r as an attachment of the ToDo item
+// with the given ID. If taskID is not 0, the attachment will belong to the task
+// with that ID. The content is streamed and not buffered in memory.
+//
+// Since r can only be read once, failed uploads are not retried.
+func (c *Client) CreateAttachment(ctx context.Context, toDoID, taskID int64, filename string, r io.Reader) (model.Attachment, error) {
+	var query url.Values
+
+	if taskID != 0 {
+		query = url.Values{"task_id": {strconv.FormatInt(taskID, 10)}}
+	}
+
+	reader, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+
+	go func() {
+		part, err := form.CreateFormFile("file", filename)
+		if err == nil {
+			_, err = io.Copy(part, r)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		_ = writer.CloseWithError(err)
+	}()
+
+	withoutRetries := *c
+	withoutRetries.maxRetries = 0
+
+	response, err := withoutRetries.send(ctx, http.MethodPost, fmt.Sprintf("/todos/%d/attachments", toDoID), query, form.FormDataContentType(), func() io.Reader {
+		return reader
+	})
+	if err != nil {
+		_ = reader.Close()
+		return model.Attachment{}, err
+	}
+	defer response.Body.Close()
+
+	var attachment model.Attachment
+
+	if err := json.NewDecoder(response.Body).Decode(&attachment); err != nil {
+		return model.Attachment{}, err
+	}
+
+	return attachment, nil
+}
+
+// GetAttachments returns the metadata of all attachments of the ToDo item with
+// the given ID, including the attachments of its tasks.
+func (c *Client) GetAttachments(ctx context.Context, toDoID int64) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/todos/%d/attachments", toDoID), nil, nil, &attachments); err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// OpenAttachment downloads an attachment of the given ToDo item. It returns the
+// metadata sent along with the content and a reader for the content, which has
+// to be closed by the caller.
+func (c *Client) OpenAttachment(ctx context.Context, toDoID, id int64) (model.Attachment, io.ReadCloser, error) {
+	response, err := c.send(ctx, http.MethodGet, fmt.Sprintf("/todos/%d/attachments/%d", toDoID, id), nil, "", func() io.Reader {
+		return nil
+	})
+	if err != nil {
+		return model.Attachment{}, nil, err
+	}
+
+	attachment := model.Attachment{
+		ID:          id,
+		ToDoID:      toDoID,
+		ContentType: response.Header.Get("Content-Type"),
+		Size:        response.ContentLength,
+	}
+
+	if _, params, err := mime.ParseMediaType(response.Header.Get("Content-Disposition")); err == nil {
+		attachment.Filename = params["filename"]
+	}
+
+	return attachment, response.Body, nil
+}
+
+// DeleteAttachment deletes an attachment of the given ToDo item.
+func (c *Client) DeleteAttachment(ctx context.Context, toDoID, id int64) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/todos/%d/attachments/%d", toDoID, id), nil, nil, nil)
+}
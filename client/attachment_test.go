@@ -0,0 +1,55 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+func TestClient_Attachments(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	toDo, _ := client.CreateToDo(ctx, model.ToDo{Name: "ToDo 1", Tasks: []model.Task{{Name: "Task 1"}}})
+
+	attachment, err := client.CreateAttachment(ctx, toDo.ID, toDo.Tasks[0].ID, "notes.txt", strings.NewReader("Some notes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attachment.TaskID != toDo.Tasks[0].ID || attachment.Size != 10 {
+		t.Fatalf("expected attachment of task %d with size %d, got %v", toDo.Tasks[0].ID, 10, attachment)
+	}
+
+	attachments, err := client.GetAttachments(ctx, toDo.ID)
+	if err != nil || len(attachments) != 1 {
+		t.Fatalf("expected %d attachment, got %v (%v)", 1, attachments, err)
+	}
+
+	downloaded, content, err := client.OpenAttachment(ctx, toDo.ID, attachment.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := ioutil.ReadAll(content)
+	_ = content.Close()
+
+	if string(data) != "Some notes" || downloaded.Filename != "notes.txt" || downloaded.Size != 10 {
+		t.Errorf("expected notes.txt with its content, got %v with %q", downloaded, data)
+	}
+
+	if err := client.DeleteAttachment(ctx, toDo.ID, attachment.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := client.OpenAttachment(ctx, toDo.ID, attachment.ID); !errors.Is(err, storage.ErrAttachmentNotFound) {
+		t.Errorf("expected error %v, got %v", storage.ErrAttachmentNotFound, err)
+	}
+}
@@ -0,0 +1,61 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+// Backup downloads a backup archive of all data and writes it to w. It requires
+// the admin token.
+func (c *Client) Backup(ctx context.Context, w io.Writer) error {
+	response, err := c.send(ctx, http.MethodGet, "/admin/backup", nil, "", func() io.Reader {
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	_, err = io.Copy(w, response.Body)
+	return err
+}
+
+// Restore uploads the backup archive read from r and restores it. If force is
+// true, existing data will be overwritten. Otherwise, the restore will fail
+// with core.ErrStorageNotEmpty if the server already has data. It requires the
+// admin token.
+//
+// Since r can only be read once, failed uploads are not retried.
+func (c *Client) Restore(ctx context.Context, r io.Reader, force bool) (model.RestoreResult, error) {
+	var query url.Values
+
+	if force {
+		query = url.Values{"force": {"true"}}
+	}
+
+	withoutRetries := *c
+	withoutRetries.maxRetries = 0
+
+	response, err := withoutRetries.send(ctx, http.MethodPost, "/admin/restore", query, "application/gzip", func() io.Reader {
+		return r
+	})
+	if err != nil {
+		return model.RestoreResult{}, err
+	}
+	defer response.Body.Close()
+
+	var result model.RestoreResult
+
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return model.RestoreResult{}, err
+	}
+
+	return result, nil
+}
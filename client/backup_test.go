@@ -0,0 +1,49 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/model"
+)
+
+func TestClient_BackupAndRestore(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	_, _ = client.CreateToDo(ctx, model.ToDo{Name: "ToDo 1"})
+
+	var archive bytes.Buffer
+
+	if err := client.Backup(ctx, &archive); err != nil {
+		t.Fatal(err)
+	}
+
+	withoutToken := *client
+	withoutToken.adminToken = ""
+
+	var apiError *Error
+
+	if err := withoutToken.Backup(ctx, &bytes.Buffer{}); !errors.As(err, &apiError) || apiError.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %v", http.StatusUnauthorized, err)
+	}
+
+	if _, err := client.Restore(ctx, bytes.NewReader(archive.Bytes()), false); !errors.Is(err, core.ErrStorageNotEmpty) {
+		t.Errorf("expected error %v, got %v", core.ErrStorageNotEmpty, err)
+	}
+
+	result, err := client.Restore(ctx, bytes.NewReader(archive.Bytes()), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Counts["todo"] != 1 {
+		t.Errorf("expected 1 restored ToDo item, got %v", result.Counts)
+	}
+}
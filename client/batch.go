@@ -0,0 +1,37 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+// Batch executes a list of create, update, delete and complete operations and
+// returns their results in the same order. Failed operations have a result
+// with an error status and problem details.
+//
+// If atomic is true and one of the operations fails, none of them is applied,
+// and an *Error wrapping the error of the failed operation is returned, whose
+// Results list the results of all operations.
+//
+// Since the operations are not idempotent, failed requests are not retried.
+func (c *Client) Batch(ctx context.Context, operations []model.BatchOperation, atomic bool) ([]model.BatchResult, error) {
+	withoutRetries := *c
+	withoutRetries.maxRetries = 0
+
+	var response model.BatchResponse
+
+	batch := model.BatchRequest{
+		Atomic:     atomic,
+		Operations: operations,
+	}
+
+	if err := withoutRetries.do(ctx, http.MethodPost, "/todos/batch", nil, batch, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Results, nil
+}
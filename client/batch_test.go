@@ -0,0 +1,53 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+func TestClient_Batch(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	toDo, err := client.CreateToDo(ctx, model.ToDo{Name: "ToDo 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var apiError *Error
+
+	_, err = client.Batch(ctx, []model.BatchOperation{
+		{Action: model.BatchComplete, ID: toDo.ID},
+		{Action: model.BatchDelete, ID: 42},
+	}, true)
+	if !errors.Is(err, storage.ErrToDoNotFound) || !errors.As(err, &apiError) || len(apiError.Results) != 2 {
+		t.Fatalf("expected error %v with 2 results, got %v", storage.ErrToDoNotFound, err)
+	}
+
+	if apiError.Results[0].Status != http.StatusFailedDependency {
+		t.Errorf("expected status %d, got %d", http.StatusFailedDependency, apiError.Results[0].Status)
+	}
+
+	results, err := client.Batch(ctx, []model.BatchOperation{
+		{Action: model.BatchComplete, ID: toDo.ID},
+		{Action: model.BatchDelete, ID: 42},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if results[0].Status != http.StatusOK || results[0].ToDo == nil || !results[0].ToDo.Done {
+		t.Errorf("unexpected result %v", results[0])
+	}
+
+	if results[1].Status != http.StatusNotFound || results[1].Error == nil {
+		t.Errorf("unexpected result %v", results[1])
+	}
+}
@@ -0,0 +1,383 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dominikbraun/todo/backup"
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+const (
+	// DefaultMaxRetries is the number of retries for failed requests if
+	// Config.MaxRetries is 0.
+	DefaultMaxRetries = 3
+
+	// DefaultRetryWait is the delay before the first retry if Config.RetryWait
+	// is 0.
+	DefaultRetryWait = 500 * time.Millisecond
+
+	// maxRetryWait is the maximum delay between two retries.
+	maxRetryWait = 30 * time.Second
+
+	// userHeader is the request header identifying the user a request is made
+	// for, which is required for user-specific resources like views.
+	userHeader = "X-User"
+)
+
+var (
+	// ErrInvalidURL indicates that the configured server URL is not an absolute
+	// HTTP or HTTPS URL.
+	ErrInvalidURL = errors.New("url must be an absolute http or https URL")
+)
+
+// knownErrors are the sentinel errors the API may respond with. An error
+// response whose message matches one of these errors is decoded to that error.
+var knownErrors = []error{
+	storage.ErrToDoNotFound,
+	storage.ErrTaskNotFound,
+	storage.ErrCommentNotFound,
+	storage.ErrAttachmentNotFound,
+	storage.ErrBlobNotFound,
+	storage.ErrViewNotFound,
+	storage.ErrCalendarFeedNotFound,
+	storage.ErrVersionConflict,
+	storage.ErrWebhookNotFound,
+	storage.ErrDeliveryNotFound,
+	core.ErrNameMustNotBeEmpty,
+	core.ErrInvalidPriority,
+	core.ErrNameTooLong,
+	core.ErrDescriptionTooLong,
+	core.ErrProjectTooLong,
+	core.ErrTagTooLong,
+	core.ErrRecurrenceTooLong,
+	core.ErrUIDTooLong,
+	core.ErrTooManyTasks,
+	core.ErrDuplicateTaskID,
+	core.ErrInvalidRows,
+	core.ErrAuthorMustNotBeEmpty,
+	core.ErrBodyMustNotBeEmpty,
+	core.ErrAuthorTooLong,
+	core.ErrFilenameMustNotBeEmpty,
+	core.ErrFilenameTooLong,
+	core.ErrAttachmentTooLarge,
+	core.ErrAttachmentTypeNotAllowed,
+	core.ErrQueryMustNotBeEmpty,
+	core.ErrOwnerMustNotBeEmpty,
+	core.ErrOwnerTooLong,
+	core.ErrFilterTooLong,
+	core.ErrViewIsReadOnly,
+	core.ErrInvalidSortOrder,
+	core.ErrInvalidWebhookURL,
+	core.ErrUnknownEventType,
+	core.ErrWebhookURLTooLong,
+	core.ErrSecretTooLong,
+	core.ErrStorageNotEmpty,
+	core.ErrBatchEmpty,
+	core.ErrBatchTooLarge,
+	core.ErrUnknownBatchAction,
+	core.ErrBatchIDMissing,
+	core.ErrBatchToDoMissing,
+	core.ErrDuplicateBatchID,
+	core.ErrOperationNotApplied,
+	core.ErrIdempotencyKeyTooLong,
+	core.ErrIdempotencyKeyReused,
+	core.ErrIdempotentRequestInProgress,
+	backup.ErrInvalidArchive,
+	backup.ErrUnsupportedVersion,
+	backup.ErrChecksumMismatch,
+	backup.ErrTruncated,
+}
+
+// Config stores the configuration of a Client.
+type Config struct {
+	// URL is the base URL of the server, e.g. `http://localhost:8000`.
+	URL string
+
+	// User is sent as `X-User` header and identifies the user for user-specific
+	// resources like views.
+	User string
+
+	// AdminToken is sent as `Authorization: Bearer` header and is required by
+	// the admin endpoints for backups and restores.
+	AdminToken string
+
+	// HTTPClient is used for sending requests. If nil, a client without timeout
+	// is used, and requests are only bounded by their context.
+	HTTPClient *http.Client
+
+	// MaxRetries is the number of retries for requests that failed due to a
+	// network error or a temporary server error. If 0, DefaultMaxRetries is
+	// used. A negative value disables retries.
+	MaxRetries int
+
+	// RetryWait is the delay before the first retry, which is doubled for each
+	// subsequent retry. If 0, DefaultRetryWait is used.
+	RetryWait time.Duration
+}
+
+// Client is a client for the REST API of the ToDo app. It is safe for concurrent
+// use.
+type Client struct {
+	baseURL    string
+	user       string
+	adminToken string
+	httpClient *http.Client
+	maxRetries int
+	retryWait  time.Duration
+}
+
+// Error is returned for error responses of the API. If the error message, or
+// the message of an invalid field, is a known sentinel error like
+// storage.ErrToDoNotFound, the Error wraps it so that it can be checked using
+// errors.Is. Fields lists the invalid fields of a request body, Rows lists the
+// invalid rows of a refused CSV import, and Results lists the outcome of all
+// operations of a failed atomic batch.
+type Error struct {
+	StatusCode int
+	Message    string
+	Fields     []model.FieldError
+	Rows       []model.RowError
+	Results    []model.BatchResult
+	err        error
+}
+
+// New creates a new client for the server at the configured URL.
+func New(config Config) (*Client, error) {
+	target, err := url.Parse(config.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, ErrInvalidURL
+	}
+
+	client := &Client{
+		baseURL:    strings.TrimSuffix(config.URL, "/"),
+		user:       config.User,
+		adminToken: config.AdminToken,
+		httpClient: config.HTTPClient,
+		maxRetries: config.MaxRetries,
+		retryWait:  config.RetryWait,
+	}
+
+	if client.httpClient == nil {
+		client.httpClient = &http.Client{}
+	}
+
+	if client.maxRetries == 0 {
+		client.maxRetries = DefaultMaxRetries
+	} else if client.maxRetries < 0 {
+		client.maxRetries = 0
+	}
+
+	if client.retryWait == 0 {
+		client.retryWait = DefaultRetryWait
+	}
+
+	return client, nil
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// do sends a request with an optional JSON body and decodes the JSON response
+// into result, unless result is nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, result interface{}) error {
+	var payload []byte
+
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	newBody := func() io.Reader {
+		if payload == nil {
+			return nil
+		}
+		return bytes.NewReader(payload)
+	}
+
+	response, err := c.send(ctx, method, path, query, "application/json", newBody)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if result == nil {
+		_, _ = io.Copy(ioutil.Discard, response.Body)
+		return nil
+	}
+
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+// send sends a request and returns the response if it has a 2xx status code.
+// Other status codes are converted to an *Error.
+//
+// Requests that failed due to a network error or a temporary server error are
+// retried with exponential backoff. Since POST requests are not idempotent,
+// they are only retried if the server rejected them with 429 Too Many Requests.
+// newBody is called for each attempt and may return nil if there's no body.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, contentType string, newBody func() io.Reader) (*http.Response, error) {
+	target := c.baseURL + path
+
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		request, err := http.NewRequestWithContext(ctx, method, target, newBody())
+		if err != nil {
+			return nil, err
+		}
+
+		request.Header.Set("Accept", "application/json")
+
+		if request.Body != nil {
+			request.Header.Set("Content-Type", contentType)
+		}
+
+		if c.user != "" {
+			request.Header.Set(userHeader, c.user)
+		}
+
+		if c.adminToken != "" {
+			request.Header.Set("Authorization", "Bearer "+c.adminToken)
+		}
+
+		response, err := c.httpClient.Do(request)
+
+		if attempt >= c.maxRetries || !isRetryable(method, response, err) || ctx.Err() != nil {
+			if err != nil {
+				return nil, err
+			}
+			if response.StatusCode < 200 || response.StatusCode > 299 {
+				defer response.Body.Close()
+				return nil, decodeError(response)
+			}
+			return response, nil
+		}
+
+		wait := c.backoff(attempt, response)
+
+		if response != nil {
+			_, _ = io.Copy(ioutil.Discard, response.Body)
+			_ = response.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before the next attempt. A `Retry-After` header in
+// seconds takes precedence over the exponential backoff.
+func (c *Client) backoff(attempt int, response *http.Response) time.Duration {
+	if response != nil {
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			wait := time.Duration(seconds) * time.Second
+			if wait > maxRetryWait {
+				return maxRetryWait
+			}
+			return wait
+		}
+	}
+
+	wait := c.retryWait
+
+	for i := 0; i < attempt && wait < maxRetryWait; i++ {
+		wait *= 2
+	}
+
+	if wait > maxRetryWait {
+		return maxRetryWait
+	}
+
+	return wait
+}
+
+// isRetryable reports whether a failed request may be sent again.
+func isRetryable(method string, response *http.Response, err error) bool {
+	if err != nil {
+		return method != http.MethodPost
+	}
+
+	switch response.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return method != http.MethodPost
+	default:
+		return false
+	}
+}
+
+// decodeError converts an error response containing problem details to an
+// *Error.
+func decodeError(response *http.Response) error {
+	var body model.Problem
+
+	data, _ := ioutil.ReadAll(response.Body)
+
+	if err := json.Unmarshal(data, &body); err != nil || body.Detail == "" {
+		body.Detail = strings.TrimSpace(string(data))
+	}
+
+	if body.Detail == "" {
+		body.Detail = http.StatusText(response.StatusCode)
+	}
+
+	apiError := &Error{
+		StatusCode: response.StatusCode,
+		Message:    body.Detail,
+		Fields:     body.Errors,
+		Rows:       body.Rows,
+		Results:    body.Results,
+		err:        knownError(body.Detail),
+	}
+
+	for _, field := range body.Errors {
+		if apiError.err != nil {
+			break
+		}
+		apiError.err = knownError(field.Detail)
+	}
+
+	return apiError
+}
+
+// knownError returns the known error with the given message, or nil if there
+// is none.
+func knownError(message string) error {
+	for _, err := range knownErrors {
+		if err.Error() == message {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/server"
+	"github.com/dominikbraun/todo/storage"
+)
+
+// testAdminToken is the admin token of the test server.
+const testAdminToken = "secret"
+
+// newTestClient starts an httptest.Server serving the real router backed by an
+// in-memory storage and returns a client for it.
+func newTestClient(t *testing.T) *Client {
+	fileSystem, err := storage.NewFileSystem(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create blob store: %s", err.Error())
+	}
+
+	app := core.NewApp(storage.NewMemory(), fileSystem)
+	testServer := httptest.NewServer(server.New(0, 0, app, testAdminToken).Handler())
+	t.Cleanup(testServer.Close)
+
+	client, err := New(Config{
+		URL:        testServer.URL,
+		User:       "alice",
+		AdminToken: testAdminToken,
+		RetryWait:  time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("failed to create client: %s", err.Error())
+	}
+
+	return client
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected error
+	}{
+		{"http://localhost:8000", nil},
+		{"https://todo.example.com/", nil},
+		{"localhost:8000", ErrInvalidURL},
+		{"ftp://localhost", ErrInvalidURL},
+		{"", ErrInvalidURL},
+	}
+
+	for _, test := range tests {
+		if _, err := New(Config{URL: test.url}); !errors.Is(err, test.expected) {
+			t.Errorf("%s: expected error %v, got %v", test.url, test.expected, err)
+		}
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	_, err := client.GetToDo(ctx, 42)
+	if !errors.Is(err, storage.ErrToDoNotFound) {
+		t.Errorf("expected error %v, got %v", storage.ErrToDoNotFound, err)
+	}
+
+	var apiError *Error
+
+	if !errors.As(err, &apiError) || apiError.StatusCode != http.StatusNotFound {
+		t.Errorf("expected *Error with status %d, got %v", http.StatusNotFound, err)
+	}
+
+	_, err = client.GetToDosByFilter(ctx, "tag:")
+	if !errors.As(err, &apiError) || apiError.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected *Error with status %d, got %v", http.StatusBadRequest, err)
+	}
+
+	if errors.Unwrap(err) != nil {
+		t.Errorf("expected no sentinel error for filter syntax errors, got %v", errors.Unwrap(err))
+	}
+}
+
+func TestClient_Retries(t *testing.T) {
+	var requests int32
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = writer.Write([]byte(`[{"id":1,"name":"ToDo 1"}]`))
+	}))
+	defer testServer.Close()
+
+	client, _ := New(Config{URL: testServer.URL, RetryWait: time.Millisecond})
+
+	toDos, err := client.GetToDos(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(toDos) != 1 || requests != 3 {
+		t.Errorf("expected 1 ToDo after %d requests, got %d ToDos after %d requests", 3, len(toDos), requests)
+	}
+
+	// POST requests must not be retried on server errors.
+	atomic.StoreInt32(&requests, 0)
+
+	_, err = client.CreateWebhook(context.Background(), model.Webhook{URL: "http://localhost"})
+
+	var apiError *Error
+
+	if !errors.As(err, &apiError) || apiError.StatusCode != http.StatusServiceUnavailable || requests != 1 {
+		t.Errorf("expected a single request failing with status %d, got %v after %d requests", http.StatusServiceUnavailable, err, requests)
+	}
+
+	// Disabled retries must return the first error.
+	atomic.StoreInt32(&requests, 0)
+	client, _ = New(Config{URL: testServer.URL, MaxRetries: -1})
+
+	if _, err := client.GetToDos(context.Background()); err == nil || requests != 1 {
+		t.Errorf("expected a single failed request, got %v after %d requests", err, requests)
+	}
+}
+
+func TestClient_Context(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer testServer.Close()
+
+	client, _ := New(Config{URL: testServer.URL, MaxRetries: 10, RetryWait: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.GetToDos(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error %v, got %v", context.DeadlineExceeded, err)
+	}
+}
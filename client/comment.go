@@ -0,0 +1,55 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+// CreateComment adds a comment to the thread of the ToDo item with the given
+// ID. If taskID is not 0, the comment will be added to the thread of the task
+// with that ID instead.
+func (c *Client) CreateComment(ctx context.Context, toDoID, taskID int64, comment model.Comment) (model.Comment, error) {
+	var createdComment model.Comment
+
+	if err := c.do(ctx, http.MethodPost, threadPath(toDoID, taskID), nil, comment, &createdComment); err != nil {
+		return model.Comment{}, err
+	}
+
+	return createdComment, nil
+}
+
+// GetComments returns the thread of the given ToDo item or task.
+func (c *Client) GetComments(ctx context.Context, toDoID, taskID int64) ([]model.Comment, error) {
+	var comments []model.Comment
+
+	if err := c.do(ctx, http.MethodGet, threadPath(toDoID, taskID), nil, nil, &comments); err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// UpdateComment updates the author and body of a comment in the thread of the
+// given ToDo item or task.
+func (c *Client) UpdateComment(ctx context.Context, toDoID, taskID, id int64, comment model.Comment) error {
+	return c.do(ctx, http.MethodPut, fmt.Sprintf("%s/%d", threadPath(toDoID, taskID), id), nil, comment, nil)
+}
+
+// DeleteComment deletes a comment from the thread of the given ToDo item or task.
+func (c *Client) DeleteComment(ctx context.Context, toDoID, taskID, id int64) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("%s/%d", threadPath(toDoID, taskID), id), nil, nil, nil)
+}
+
+// threadPath returns the path of the comment thread of a ToDo item or task.
+func threadPath(toDoID, taskID int64) string {
+	if taskID != 0 {
+		return fmt.Sprintf("/todos/%d/tasks/%d/comments", toDoID, taskID)
+	}
+
+	return fmt.Sprintf("/todos/%d/comments", toDoID)
+}
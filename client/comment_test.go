@@ -0,0 +1,55 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+func TestClient_Comments(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	toDo, _ := client.CreateToDo(ctx, model.ToDo{Name: "ToDo 1", Tasks: []model.Task{{Name: "Task 1"}}})
+	taskID := toDo.Tasks[0].ID
+
+	if _, err := client.CreateComment(ctx, toDo.ID, taskID, model.Comment{Author: "Alice"}); !errors.Is(err, core.ErrBodyMustNotBeEmpty) {
+		t.Fatalf("expected error %v, got %v", core.ErrBodyMustNotBeEmpty, err)
+	}
+
+	comment, err := client.CreateComment(ctx, toDo.ID, taskID, model.Comment{Author: "Alice", Body: "Comment 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	comment.Body = "Edited comment"
+
+	if err := client.UpdateComment(ctx, toDo.ID, taskID, comment.ID, comment); err != nil {
+		t.Fatal(err)
+	}
+
+	comments, err := client.GetComments(ctx, toDo.ID, taskID)
+	if err != nil || len(comments) != 1 || comments[0].Body != "Edited comment" {
+		t.Fatalf("expected the edited comment, got %v (%v)", comments, err)
+	}
+
+	comments, _ = client.GetComments(ctx, toDo.ID, 0)
+
+	if len(comments) != 0 {
+		t.Errorf("expected no comments on the ToDo, got %v", comments)
+	}
+
+	if err := client.DeleteComment(ctx, toDo.ID, taskID, comment.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.DeleteComment(ctx, toDo.ID, taskID, comment.ID); !errors.Is(err, storage.ErrCommentNotFound) {
+		t.Errorf("expected error %v, got %v", storage.ErrCommentNotFound, err)
+	}
+}
@@ -0,0 +1,103 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+// CSVOptions configures a CSV import. The values are passed as query parameters
+// of the same name, see the REST API documentation.
+type CSVOptions struct {
+	// Columns maps fields to columns like `name:Title,due:4`.
+	Columns string
+
+	// Header is one of auto, present and absent. If empty, auto is used.
+	Header string
+
+	// Delimiter is a single character or tab. If empty, the delimiter is
+	// detected from the first line.
+	Delimiter string
+
+	// AllOrNothing refuses the whole import if a row is invalid.
+	AllOrNothing bool
+
+	// DryRun only validates the rows.
+	DryRun bool
+}
+
+// ExportCSV downloads all ToDo items matching the given filter query, or all
+// items if the query is empty, as CSV file with one row per task and writes it
+// to w.
+func (c *Client) ExportCSV(ctx context.Context, w io.Writer, filter string) error {
+	var query url.Values
+
+	if filter != "" {
+		query = url.Values{"filter": {filter}}
+	}
+
+	response, err := c.send(ctx, http.MethodGet, "/export/csv", query, "", func() io.Reader {
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	_, err = io.Copy(w, response.Body)
+	return err
+}
+
+// ImportCSV uploads the CSV file read from r and creates its ToDo items. Invalid
+// rows are listed as errors of the result, or, if options.AllOrNothing is true,
+// make the import fail with an *Error wrapping core.ErrInvalidRows whose Rows
+// list the errors.
+//
+// Since r can only be read once, failed uploads are not retried.
+func (c *Client) ImportCSV(ctx context.Context, r io.Reader, options CSVOptions) (model.ImportResult, error) {
+	query := url.Values{}
+
+	for key, value := range map[string]string{
+		"columns":   options.Columns,
+		"header":    options.Header,
+		"delimiter": options.Delimiter,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	if options.AllOrNothing {
+		query.Set("all_or_nothing", strconv.FormatBool(true))
+	}
+
+	if options.DryRun {
+		query.Set("dry_run", strconv.FormatBool(true))
+	}
+
+	withoutRetries := *c
+	withoutRetries.maxRetries = 0
+
+	response, err := withoutRetries.send(ctx, http.MethodPost, "/import/csv", query, "text/csv", func() io.Reader {
+		return r
+	})
+	if err != nil {
+		return model.ImportResult{}, err
+	}
+	defer response.Body.Close()
+
+	var result model.ImportResult
+
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return model.ImportResult{}, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,49 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/core"
+)
+
+func TestClient_CSV(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	file := "Was;Teilaufgabe\nRelease 1.0;Write notes\nRelease 1.0;Tag commit\n;Orphan\n"
+	options := CSVOptions{Columns: "name:Was,task:Teilaufgabe", AllOrNothing: true}
+
+	var apiError *Error
+
+	_, err := client.ImportCSV(ctx, strings.NewReader(file), options)
+	if !errors.Is(err, core.ErrInvalidRows) || !errors.As(err, &apiError) || len(apiError.Rows) != 1 || apiError.Rows[0].Row != 4 {
+		t.Fatalf("expected error %v for row 4, got %v", core.ErrInvalidRows, err)
+	}
+
+	options.AllOrNothing = false
+
+	result, err := client.ImportCSV(ctx, strings.NewReader(file), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.ToDos) != 1 || len(result.ToDos[0].Tasks) != 2 || len(result.Errors) != 1 {
+		t.Errorf("expected 1 ToDo item with 2 tasks and 1 error, got %v", result)
+	}
+
+	var buffer bytes.Buffer
+
+	if err := client.ExportCSV(ctx, &buffer, "name:release"); err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := strings.Split(strings.TrimSpace(buffer.String()), "\n"); len(lines) != 3 || !strings.Contains(lines[2], "Tag commit") {
+		t.Errorf("unexpected CSV file %q", buffer.String())
+	}
+}
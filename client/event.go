@@ -0,0 +1,151 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+// WatchOptions restrict the events received by WatchEvents.
+type WatchOptions struct {
+	// LastEventID is the ID of the last event the caller has received. If set,
+	// all recent events after that ID are received first.
+	LastEventID int64
+
+	// ToDoID restricts the events to those of a single ToDo item.
+	ToDoID int64
+
+	// Project restricts the events to those of ToDo items in a project.
+	Project string
+}
+
+// EventStream is a stream of change events received from the server. It is not
+// safe for concurrent use.
+type EventStream struct {
+	body        io.ReadCloser
+	reader      *bufio.Reader
+	lastEventID int64
+}
+
+// WatchEvents subscribes to the change feed of the server. The returned stream
+// must be closed by the caller, and cancelling the context closes it as well.
+//
+// The stream is not re-established automatically if the connection breaks. To
+// resume without missing events, call WatchEvents again with the LastEventID
+// of the previous stream.
+func (c *Client) WatchEvents(ctx context.Context, options WatchOptions) (*EventStream, error) {
+	query := url.Values{}
+
+	if options.ToDoID != 0 {
+		query.Set("todo_id", strconv.FormatInt(options.ToDoID, 10))
+	}
+
+	if options.Project != "" {
+		query.Set("project", options.Project)
+	}
+
+	target := c.baseURL + "/events"
+
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Accept", "text/event-stream")
+
+	if options.LastEventID != 0 {
+		request.Header.Set("Last-Event-ID", strconv.FormatInt(options.LastEventID, 10))
+	}
+
+	if c.user != "" {
+		request.Header.Set(userHeader, c.user)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		defer response.Body.Close()
+		return nil, decodeError(response)
+	}
+
+	return &EventStream{
+		body:        response.Body,
+		reader:      bufio.NewReader(response.Body),
+		lastEventID: options.LastEventID,
+	}, nil
+}
+
+// Next blocks until the next event has been received. It returns io.EOF once
+// the server has closed the stream.
+func (s *EventStream) Next() (model.Event, error) {
+	var data []string
+
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" {
+				return model.Event{}, io.EOF
+			}
+			if err != io.EOF {
+				return model.Event{}, err
+			}
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+
+		// An empty line terminates an event. Comments like heartbeats and
+		// events without data are skipped.
+		if line == "" {
+			if len(data) == 0 {
+				continue
+			}
+
+			var event model.Event
+
+			if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &event); err != nil {
+				return model.Event{}, err
+			}
+
+			s.lastEventID = event.ID
+
+			return event, nil
+		}
+
+		field, value := line, ""
+
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+
+		if field == "data" {
+			data = append(data, value)
+		}
+	}
+}
+
+// LastEventID returns the ID of the last event received from the stream, which
+// can be used for resuming the stream.
+func (s *EventStream) LastEventID() int64 {
+	return s.lastEventID
+}
+
+// Close closes the stream.
+func (s *EventStream) Close() error {
+	return s.body.Close()
+}
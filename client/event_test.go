@@ -0,0 +1,82 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+func TestClient_WatchEvents(t *testing.T) {
+	client := newTestClient(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.WatchEvents(ctx, WatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	toDo, _ := client.CreateToDo(ctx, model.ToDo{Name: "ToDo 1"})
+	toDo.Done = true
+	_ = client.UpdateToDo(ctx, toDo.ID, toDo)
+
+	for _, expected := range []model.EventType{model.EventCreated, model.EventCompleted} {
+		event, err := stream.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.Type != expected || event.ToDoID != toDo.ID {
+			t.Errorf("expected %s event for ToDo %d, got %v", expected, toDo.ID, event)
+		}
+	}
+
+	if stream.LastEventID() != 2 {
+		t.Errorf("expected last event ID %d, got %d", 2, stream.LastEventID())
+	}
+
+	// A resumed stream must first receive the events after the last event ID.
+	resumed, err := client.WatchEvents(ctx, WatchOptions{LastEventID: 1, ToDoID: toDo.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resumed.Close()
+
+	event, err := resumed.Next()
+	if err != nil || event.ID != 2 || event.Type != model.EventCompleted {
+		t.Errorf("expected missed %s event with ID %d, got %v (%v)", model.EventCompleted, 2, event, err)
+	}
+}
+
+func TestEventStream_Next(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(writer, ": heartbeat\n\n"+
+			"id: 7\nevent: deleted\ndata: {\"id\":7,\"type\":\"deleted\",\r\ndata: \"todo_id\":3}\r\n\r\n")
+	}))
+	defer testServer.Close()
+
+	client, _ := New(Config{URL: testServer.URL})
+
+	stream, err := client.WatchEvents(context.Background(), WatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	event, err := stream.Next()
+	if err != nil || event.ID != 7 || event.Type != model.EventDeleted || event.ToDoID != 3 {
+		t.Errorf("expected %s event for ToDo %d, got %v (%v)", model.EventDeleted, 3, event, err)
+	}
+
+	if _, err := stream.Next(); err != io.EOF {
+		t.Errorf("expected error %v, got %v", io.EOF, err)
+	}
+}
@@ -0,0 +1,23 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+// Search runs a full-text search across ToDo items, tasks and comments and
+// returns the results ordered by relevance.
+func (c *Client) Search(ctx context.Context, query string) ([]model.SearchResult, error) {
+	var results []model.SearchResult
+
+	if err := c.do(ctx, http.MethodGet, "/search", url.Values{"q": {query}}, nil, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,28 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/model"
+)
+
+func TestClient_Search(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	_, _ = client.CreateToDo(ctx, model.ToDo{Name: "Write release notes"})
+
+	if _, err := client.Search(ctx, ""); !errors.Is(err, core.ErrQueryMustNotBeEmpty) {
+		t.Fatalf("expected error %v, got %v", core.ErrQueryMustNotBeEmpty, err)
+	}
+
+	results, err := client.Search(ctx, "release")
+	if err != nil || len(results) != 1 || results[0].Type != model.SearchResultToDo {
+		t.Errorf("expected the ToDo as only result, got %v (%v)", results, err)
+	}
+}
@@ -0,0 +1,133 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+// CreateToDo creates a new ToDo item. The provided item should not have an ID.
+func (c *Client) CreateToDo(ctx context.Context, toDo model.ToDo) (model.ToDo, error) {
+	var createdToDo model.ToDo
+
+	if err := c.do(ctx, http.MethodPost, "/todos", nil, toDo, &createdToDo); err != nil {
+		return model.ToDo{}, err
+	}
+
+	return createdToDo, nil
+}
+
+// GetToDos returns a list of all ToDo items.
+func (c *Client) GetToDos(ctx context.Context) ([]model.ToDo, error) {
+	var toDos []model.ToDo
+
+	if err := c.do(ctx, http.MethodGet, "/todos", nil, nil, &toDos); err != nil {
+		return nil, err
+	}
+
+	return toDos, nil
+}
+
+// GetToDosByFilter returns all ToDo items matching the given filter query. If
+// the query is invalid, an *Error with status 400 will be returned.
+func (c *Client) GetToDosByFilter(ctx context.Context, query string) ([]model.ToDo, error) {
+	var toDos []model.ToDo
+
+	if err := c.do(ctx, http.MethodGet, "/todos", url.Values{"filter": {query}}, nil, &toDos); err != nil {
+		return nil, err
+	}
+
+	return toDos, nil
+}
+
+// GetToDo returns the ToDo item with the given ID.
+func (c *Client) GetToDo(ctx context.Context, id int64) (model.ToDo, error) {
+	var toDo model.ToDo
+
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/todos/%d", id), nil, nil, &toDo); err != nil {
+		return model.ToDo{}, err
+	}
+
+	return toDo, nil
+}
+
+// UpdateToDo overwrites the ToDo item with the given ID. If the item has a
+// version and has been modified in the meantime, storage.ErrVersionConflict
+// will be returned.
+func (c *Client) UpdateToDo(ctx context.Context, id int64, toDo model.ToDo) error {
+	return c.do(ctx, http.MethodPut, fmt.Sprintf("/todos/%d", id), nil, toDo, nil)
+}
+
+// DeleteToDo deletes the ToDo item with the given ID along with its tasks.
+func (c *Client) DeleteToDo(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/todos/%d", id), nil, nil, nil)
+}
+
+// AddTask adds a task to the ToDo item with the given ID and returns the updated
+// item. If version is not 0 and doesn't match the current version of the item,
+// storage.ErrVersionConflict will be returned.
+func (c *Client) AddTask(ctx context.Context, toDoID, version int64, task model.Task) (model.ToDo, error) {
+	return c.editTasks(ctx, toDoID, version, func(toDo *model.ToDo) error {
+		task.ID = 0
+		toDo.Tasks = append(toDo.Tasks, task)
+		return nil
+	})
+}
+
+// UpdateTask overwrites the task of the ToDo item that has the same ID as the
+// given task and returns the updated item.
+func (c *Client) UpdateTask(ctx context.Context, toDoID, version int64, task model.Task) (model.ToDo, error) {
+	return c.editTasks(ctx, toDoID, version, func(toDo *model.ToDo) error {
+		for i := range toDo.Tasks {
+			if toDo.Tasks[i].ID == task.ID {
+				toDo.Tasks[i] = task
+				return nil
+			}
+		}
+		return storage.ErrTaskNotFound
+	})
+}
+
+// DeleteTask removes the task with the given ID from the ToDo item and returns
+// the updated item.
+func (c *Client) DeleteTask(ctx context.Context, toDoID, version, taskID int64) (model.ToDo, error) {
+	return c.editTasks(ctx, toDoID, version, func(toDo *model.ToDo) error {
+		for i := range toDo.Tasks {
+			if toDo.Tasks[i].ID == taskID {
+				toDo.Tasks = append(toDo.Tasks[:i], toDo.Tasks[i+1:]...)
+				return nil
+			}
+		}
+		return storage.ErrTaskNotFound
+	})
+}
+
+// editTasks fetches the ToDo item, applies the edit and writes the item back.
+// The write is based on the fetched version, so concurrent modifications are
+// detected by the server and reported as storage.ErrVersionConflict.
+func (c *Client) editTasks(ctx context.Context, toDoID, version int64, edit func(toDo *model.ToDo) error) (model.ToDo, error) {
+	toDo, err := c.GetToDo(ctx, toDoID)
+	if err != nil {
+		return model.ToDo{}, err
+	}
+
+	if version != 0 && version != toDo.Version {
+		return model.ToDo{}, storage.ErrVersionConflict
+	}
+
+	if err := edit(&toDo); err != nil {
+		return model.ToDo{}, err
+	}
+
+	if err := c.UpdateToDo(ctx, toDoID, toDo); err != nil {
+		return model.ToDo{}, err
+	}
+
+	return c.GetToDo(ctx, toDoID)
+}
@@ -0,0 +1,113 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+func TestClient_ToDos(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	if _, err := client.CreateToDo(ctx, model.ToDo{}); !errors.Is(err, core.ErrNameMustNotBeEmpty) {
+		t.Fatalf("expected error %v, got %v", core.ErrNameMustNotBeEmpty, err)
+	}
+
+	// With several invalid fields, the first known error is wrapped.
+	_, err := client.CreateToDo(ctx, model.ToDo{Priority: "high", Tasks: []model.Task{{}}})
+
+	var apiError *Error
+
+	if !errors.As(err, &apiError) || !errors.Is(err, core.ErrNameMustNotBeEmpty) || len(apiError.Fields) != 3 || apiError.Fields[1].Pointer != "/tasks/0/name" {
+		t.Fatalf("expected error with 3 invalid fields, got %v", err)
+	}
+
+	createdToDo, err := client.CreateToDo(ctx, model.ToDo{
+		Name:  "ToDo 1",
+		Tags:  []string{"release"},
+		Tasks: []model.Task{{Name: "Task 1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if createdToDo.ID != 1 || createdToDo.Version != 1 || len(createdToDo.Tasks) != 1 {
+		t.Fatalf("expected ToDo 1 with version 1 and one task, got %v", createdToDo)
+	}
+
+	_, _ = client.CreateToDo(ctx, model.ToDo{Name: "ToDo 2"})
+
+	toDos, err := client.GetToDos(ctx)
+	if err != nil || len(toDos) != 2 {
+		t.Fatalf("expected %d ToDos, got %d (%v)", 2, len(toDos), err)
+	}
+
+	toDos, err = client.GetToDosByFilter(ctx, "tag:release")
+	if err != nil || len(toDos) != 1 || toDos[0].Name != "ToDo 1" {
+		t.Fatalf("expected only ToDo 1, got %v (%v)", toDos, err)
+	}
+
+	createdToDo.Name = "Updated ToDo"
+
+	if err := client.UpdateToDo(ctx, createdToDo.ID, createdToDo); err != nil {
+		t.Fatal(err)
+	}
+
+	// The version of createdToDo is outdated now.
+	if err := client.UpdateToDo(ctx, createdToDo.ID, createdToDo); !errors.Is(err, storage.ErrVersionConflict) {
+		t.Fatalf("expected error %v, got %v", storage.ErrVersionConflict, err)
+	}
+
+	toDo, err := client.GetToDo(ctx, createdToDo.ID)
+	if err != nil || toDo.Name != "Updated ToDo" || toDo.Version != 2 {
+		t.Fatalf("expected updated ToDo with version 2, got %v (%v)", toDo, err)
+	}
+
+	if err := client.DeleteToDo(ctx, createdToDo.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.DeleteToDo(ctx, createdToDo.ID); !errors.Is(err, storage.ErrToDoNotFound) {
+		t.Errorf("expected error %v, got %v", storage.ErrToDoNotFound, err)
+	}
+}
+
+func TestClient_Tasks(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	toDo, _ := client.CreateToDo(ctx, model.ToDo{Name: "ToDo 1"})
+
+	toDo, err := client.AddTask(ctx, toDo.ID, toDo.Version, model.Task{Name: "Task 1"})
+	if err != nil || len(toDo.Tasks) != 1 {
+		t.Fatalf("expected one task, got %v (%v)", toDo.Tasks, err)
+	}
+
+	if _, err := client.AddTask(ctx, toDo.ID, 1, model.Task{Name: "Task 2"}); !errors.Is(err, storage.ErrVersionConflict) {
+		t.Fatalf("expected error %v, got %v", storage.ErrVersionConflict, err)
+	}
+
+	task := toDo.Tasks[0]
+	task.Name = "Renamed Task"
+
+	toDo, err = client.UpdateTask(ctx, toDo.ID, 0, task)
+	if err != nil || toDo.Tasks[0].Name != "Renamed Task" {
+		t.Fatalf("expected renamed task, got %v (%v)", toDo.Tasks, err)
+	}
+
+	if _, err := client.DeleteTask(ctx, toDo.ID, 0, 42); !errors.Is(err, storage.ErrTaskNotFound) {
+		t.Fatalf("expected error %v, got %v", storage.ErrTaskNotFound, err)
+	}
+
+	toDo, err = client.DeleteTask(ctx, toDo.ID, toDo.Version, task.ID)
+	if err != nil || len(toDo.Tasks) != 0 {
+		t.Errorf("expected no tasks, got %v (%v)", toDo.Tasks, err)
+	}
+}
@@ -0,0 +1,74 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+// CreateView stores a new view for the configured user.
+func (c *Client) CreateView(ctx context.Context, view model.View) (model.View, error) {
+	var createdView model.View
+
+	if err := c.do(ctx, http.MethodPost, "/views", nil, view, &createdView); err != nil {
+		return model.View{}, err
+	}
+
+	return createdView, nil
+}
+
+// GetViews returns the built-in views followed by all views of the configured
+// user.
+func (c *Client) GetViews(ctx context.Context) ([]model.View, error) {
+	var views []model.View
+
+	if err := c.do(ctx, http.MethodGet, "/views", nil, nil, &views); err != nil {
+		return nil, err
+	}
+
+	return views, nil
+}
+
+// GetView returns the saved view with the given ID.
+func (c *Client) GetView(ctx context.Context, id int64) (model.View, error) {
+	var view model.View
+
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/views/%d", id), nil, nil, &view); err != nil {
+		return model.View{}, err
+	}
+
+	return view, nil
+}
+
+// UpdateView overwrites the saved view with the given ID.
+func (c *Client) UpdateView(ctx context.Context, id int64, view model.View) error {
+	return c.do(ctx, http.MethodPut, fmt.Sprintf("/views/%d", id), nil, view, nil)
+}
+
+// DeleteView deletes the saved view with the given ID.
+func (c *Client) DeleteView(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/views/%d", id), nil, nil, nil)
+}
+
+// GetViewToDos returns all ToDo items matching the given view, which is either
+// a built-in view identified by its key or a saved view identified by its ID.
+func (c *Client) GetViewToDos(ctx context.Context, view model.View) ([]model.ToDo, error) {
+	key := view.Key
+
+	if key == "" {
+		key = strconv.FormatInt(view.ID, 10)
+	}
+
+	var toDos []model.ToDo
+
+	if err := c.do(ctx, http.MethodGet, "/views/"+key+"/todos", nil, nil, &toDos); err != nil {
+		return nil, err
+	}
+
+	return toDos, nil
+}
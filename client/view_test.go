@@ -0,0 +1,58 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/model"
+)
+
+func TestClient_Views(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	_, _ = client.CreateToDo(ctx, model.ToDo{Name: "ToDo 1", Tags: []string{"release"}})
+	_, _ = client.CreateToDo(ctx, model.ToDo{Name: "ToDo 2", Done: true})
+
+	view, err := client.CreateView(ctx, model.View{Name: "Release", Filter: "tag:release"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	toDos, err := client.GetViewToDos(ctx, view)
+	if err != nil || len(toDos) != 1 || toDos[0].Name != "ToDo 1" {
+		t.Fatalf("expected only ToDo 1, got %v (%v)", toDos, err)
+	}
+
+	unfinished, _ := core.BuiltInView("unfinished")
+
+	toDos, err = client.GetViewToDos(ctx, unfinished)
+	if err != nil || len(toDos) != 1 {
+		t.Fatalf("expected %d unfinished ToDo, got %v (%v)", 1, toDos, err)
+	}
+
+	view.Sort = "unknown"
+
+	if err := client.UpdateView(ctx, view.ID, view); !errors.Is(err, core.ErrInvalidSortOrder) {
+		t.Fatalf("expected error %v, got %v", core.ErrInvalidSortOrder, err)
+	}
+
+	views, err := client.GetViews(ctx)
+	if err != nil || len(views) != 4 {
+		t.Fatalf("expected %d views, got %v (%v)", 4, views, err)
+	}
+
+	if err := client.DeleteView(ctx, view.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	anonymous, _ := New(Config{URL: client.baseURL})
+
+	if _, err := anonymous.GetViews(ctx); !errors.Is(err, core.ErrOwnerMustNotBeEmpty) {
+		t.Errorf("expected error %v, got %v", core.ErrOwnerMustNotBeEmpty, err)
+	}
+}
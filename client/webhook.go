@@ -0,0 +1,61 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+// CreateWebhook registers a new webhook. If the webhook has no secret, the
+// server generates one, which is contained in the returned webhook.
+func (c *Client) CreateWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error) {
+	var createdWebhook model.Webhook
+
+	if err := c.do(ctx, http.MethodPost, "/webhooks", nil, webhook, &createdWebhook); err != nil {
+		return model.Webhook{}, err
+	}
+
+	return createdWebhook, nil
+}
+
+// GetWebhooks returns a list of all webhooks without their secrets.
+func (c *Client) GetWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	var webhooks []model.Webhook
+
+	if err := c.do(ctx, http.MethodGet, "/webhooks", nil, nil, &webhooks); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// GetWebhook returns the webhook with the given ID without its secret.
+func (c *Client) GetWebhook(ctx context.Context, id int64) (model.Webhook, error) {
+	var webhook model.Webhook
+
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/webhooks/%d", id), nil, nil, &webhook); err != nil {
+		return model.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+// DeleteWebhook deletes the webhook with the given ID along with its deliveries.
+func (c *Client) DeleteWebhook(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/webhooks/%d", id), nil, nil, nil)
+}
+
+// GetDeliveries returns all deliveries of the webhook with the given ID.
+func (c *Client) GetDeliveries(ctx context.Context, webhookID int64) ([]model.Delivery, error) {
+	var deliveries []model.Delivery
+
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/webhooks/%d/deliveries", webhookID), nil, nil, &deliveries); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,51 @@
+// Package client provides a Go client for the REST API of the ToDo app. Its
+// methods mirror the methods of core.App and return the same sentinel errors.
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+func TestClient_Webhooks(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	if _, err := client.CreateWebhook(ctx, model.Webhook{URL: "localhost"}); !errors.Is(err, core.ErrInvalidWebhookURL) {
+		t.Fatalf("expected error %v, got %v", core.ErrInvalidWebhookURL, err)
+	}
+
+	webhook, err := client.CreateWebhook(ctx, model.Webhook{URL: "http://localhost:9999/hook"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if webhook.Secret == "" {
+		t.Errorf("expected a generated secret")
+	}
+
+	_, _ = client.CreateToDo(ctx, model.ToDo{Name: "ToDo 1"})
+
+	deliveries, err := client.GetDeliveries(ctx, webhook.ID)
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("expected %d delivery, got %v (%v)", 1, deliveries, err)
+	}
+
+	webhooks, err := client.GetWebhooks(ctx)
+	if err != nil || len(webhooks) != 1 {
+		t.Fatalf("expected %d webhook, got %v (%v)", 1, webhooks, err)
+	}
+
+	if err := client.DeleteWebhook(ctx, webhook.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.GetWebhook(ctx, webhook.ID); !errors.Is(err, storage.ErrWebhookNotFound) {
+		t.Errorf("expected error %v, got %v", storage.ErrWebhookNotFound, err)
+	}
+}
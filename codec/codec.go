@@ -0,0 +1,341 @@
+// Package codec provides the encodings the REST API reads and writes, selected
+// by the Content-Type and Accept headers of a request. JSON, YAML, XML,
+// MessagePack and CBOR are registered by default, and further codecs can be
+// added with Register.
+//
+// All codecs use the `json` struct tags of the encoded values, so that fields
+// have the same names in every encoding.
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	// ErrUnsupportedMediaType indicates that no codec is registered for the
+	// content type of a request body. It is wrapped by an error naming the type.
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+	// ErrNotAcceptable indicates that no codec is registered for any of the
+	// media types accepted by a client. It is wrapped by an error naming the
+	// accepted types.
+	ErrNotAcceptable = errors.New("none of the accepted media types is supported")
+)
+
+// Codec encodes and decodes values in a particular format.
+type Codec interface {
+	// MediaTypes returns the media types of the format. The first one is
+	// used as Content-Type of encoded values and may have parameters.
+	MediaTypes() []string
+
+	// Encode writes the encoding of v to w.
+	Encode(w io.Writer, v interface{}) error
+
+	// Decode reads the encoding of a value from r and stores it in v.
+	Decode(r io.Reader, v interface{}) error
+}
+
+// ContentType returns the Content-Type of values encoded by the codec.
+func ContentType(c Codec) string {
+	return c.MediaTypes()[0]
+}
+
+// Registry maps media types to codecs. The first registered codec is the
+// default used for requests without Content-Type or Accept header. A Registry
+// is safe for concurrent use.
+type Registry struct {
+	mutex  sync.RWMutex
+	codecs []Codec
+}
+
+// NewRegistry creates a registry containing the given codecs.
+func NewRegistry(codecs ...Codec) *Registry {
+	return &Registry{
+		codecs: codecs,
+	}
+}
+
+// Register adds a codec to the registry. It takes precedence over previously
+// registered codecs for the same media types, except for being the default.
+func (r *Registry) Register(c Codec) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.codecs = append(r.codecs, c)
+}
+
+// Lookup returns the codec for a media type without parameters. The returned
+// bool is false if no codec is registered for the type.
+func (r *Registry) Lookup(mediaType string) (Codec, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for i := len(r.codecs) - 1; i >= 0; i-- {
+		for _, codecType := range r.codecs[i].MediaTypes() {
+			if baseType(codecType) == mediaType {
+				return r.codecs[i], true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// ForContentType returns the codec for the value of a Content-Type header. If
+// the header is empty, the default codec is returned.
+func (r *Registry) ForContentType(header string) (Codec, error) {
+	if header == "" {
+		return r.defaultCodec(), nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMediaType, header)
+	}
+
+	if c, exists := r.Lookup(mediaType); exists {
+		return c, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedMediaType, mediaType)
+}
+
+// ForAccept returns the codec for the most preferred media type of an Accept
+// header that has a codec. Wildcards like `*/*` match the default codec, and
+// wildcards like `text/*` match the first codec with a media type of that
+// kind. If the header is empty, the default codec is returned.
+func (r *Registry) ForAccept(header string) (Codec, error) {
+	if strings.TrimSpace(header) == "" {
+		return r.defaultCodec(), nil
+	}
+
+	for _, mediaRange := range ParseAccept(header) {
+		if c, exists := r.match(mediaRange); exists {
+			return c, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrNotAcceptable, header)
+}
+
+// match returns the codec for a media range, which may contain wildcards.
+func (r *Registry) match(mediaRange string) (Codec, bool) {
+	if mediaRange == "*/*" {
+		return r.defaultCodec(), true
+	}
+
+	if !strings.HasSuffix(mediaRange, "/*") {
+		return r.Lookup(mediaRange)
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	prefix := strings.TrimSuffix(mediaRange, "*")
+
+	for _, c := range r.codecs {
+		for _, codecType := range c.MediaTypes() {
+			if strings.HasPrefix(baseType(codecType), prefix) {
+				return c, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// defaultCodec returns the first registered codec.
+func (r *Registry) defaultCodec() Codec {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.codecs[0]
+}
+
+// ParseAccept returns the media ranges of an Accept header without parameters,
+// ordered by their quality from high to low. Ranges with the same quality keep
+// their order, and ranges with a quality of 0 are omitted.
+func ParseAccept(header string) []string {
+	type mediaRange struct {
+		mediaType string
+		quality   float64
+	}
+
+	var ranges []mediaRange
+
+	for _, value := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+
+		if q, exists := params["q"]; exists {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+
+		if quality > 0 {
+			ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	mediaTypes := make([]string, len(ranges))
+
+	for i, r := range ranges {
+		mediaTypes[i] = r.mediaType
+	}
+
+	return mediaTypes
+}
+
+// baseType returns a media type without parameters.
+func baseType(mediaType string) string {
+	if i := strings.IndexByte(mediaType, ';'); i >= 0 {
+		mediaType = mediaType[:i]
+	}
+
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// defaultRegistry is the registry used by the package-level functions.
+var defaultRegistry = NewRegistry(JSON, YAML, XML, MessagePack, CBOR)
+
+// Register adds a codec to the default registry.
+func Register(c Codec) {
+	defaultRegistry.Register(c)
+}
+
+// Lookup returns the codec of the default registry for a media type.
+func Lookup(mediaType string) (Codec, bool) {
+	return defaultRegistry.Lookup(mediaType)
+}
+
+// ForContentType returns the codec of the default registry for the value of a
+// Content-Type header.
+func ForContentType(header string) (Codec, error) {
+	return defaultRegistry.ForContentType(header)
+}
+
+// ForAccept returns the codec of the default registry for the value of an
+// Accept header.
+func ForAccept(header string) (Codec, error) {
+	return defaultRegistry.ForAccept(header)
+}
+
+var (
+	// JSON encodes values as JSON.
+	JSON Codec = jsonCodec{}
+
+	// YAML encodes values as YAML by converting them from and to JSON.
+	YAML Codec = yamlCodec{}
+
+	// MessagePack encodes values as MessagePack, with times as timestamp
+	// extension.
+	MessagePack Codec = messagePackCodec{}
+
+	// CBOR encodes values as CBOR as specified by RFC 8949, with times as
+	// RFC 3339 strings.
+	CBOR Codec = cborCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) MediaTypes() []string {
+	return []string{"application/json; charset=utf-8"}
+}
+
+func (jsonCodec) Encode(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func (jsonCodec) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+type yamlCodec struct{}
+
+func (yamlCodec) MediaTypes() []string {
+	return []string{"application/yaml; charset=utf-8", "application/x-yaml", "text/yaml"}
+}
+
+func (yamlCodec) Encode(w io.Writer, v interface{}) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+func (yamlCodec) Decode(r io.Reader, v interface{}) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return io.EOF
+	}
+
+	return yaml.Unmarshal(data, v)
+}
+
+type messagePackCodec struct{}
+
+func (messagePackCodec) MediaTypes() []string {
+	return []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}
+}
+
+func (messagePackCodec) Encode(w io.Writer, v interface{}) error {
+	encoder := msgpack.NewEncoder(w)
+	encoder.SetCustomStructTag("json")
+
+	return encoder.Encode(v)
+}
+
+func (messagePackCodec) Decode(r io.Reader, v interface{}) error {
+	decoder := msgpack.NewDecoder(r)
+	decoder.SetCustomStructTag("json")
+
+	return decoder.Decode(v)
+}
+
+type cborCodec struct{}
+
+// cborEncMode encodes times as RFC 3339 strings, which can be decoded by all
+// CBOR implementations.
+var cborEncMode, _ = cbor.EncOptions{Time: cbor.TimeRFC3339}.EncMode()
+
+func (cborCodec) MediaTypes() []string {
+	return []string{"application/cbor"}
+}
+
+func (cborCodec) Encode(w io.Writer, v interface{}) error {
+	return cborEncMode.NewEncoder(w).Encode(v)
+}
+
+func (cborCodec) Decode(r io.Reader, v interface{}) error {
+	return cbor.NewDecoder(r).Decode(v)
+}
@@ -0,0 +1,193 @@
+// Package codec provides the encodings the REST API reads and writes, selected
+// by the Content-Type and Accept headers of a request. JSON, YAML, XML,
+// MessagePack and CBOR are registered by default, and further codecs can be
+// added with Register.
+//
+// All codecs use the `json` struct tags of the encoded values, so that fields
+// have the same names in every encoding.
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	due := time.Date(2026, 11, 1, 12, 30, 0, 0, time.UTC)
+
+	toDo := model.ToDo{
+		ID:          1,
+		Name:        " Release <1.0> & more ",
+		Description: "Line 1\nLine 2",
+		Due:         &due,
+		Priority:    "A",
+		Tags:        []string{"release", "team"},
+		Tasks:       []model.Task{{ID: 1, Name: "Write notes"}, {ID: 2, Name: "Tag commit", Done: true}},
+		Version:     3,
+	}
+
+	problem := model.Problem{
+		Type:   "about:blank",
+		Title:  "Unprocessable Entity",
+		Status: 422,
+		Detail: "/name: name must not be empty; /tasks/0/name: name must not be empty",
+		Errors: []model.FieldError{{Pointer: "/name", Detail: "name must not be empty"}, {Pointer: "/tasks/0/name", Detail: "name must not be empty"}},
+		Rows:   []model.RowError{{Row: 3, Column: "name", Message: "name must not be empty"}},
+	}
+
+	for _, c := range []Codec{JSON, YAML, XML, MessagePack, CBOR} {
+		name := ContentType(c)
+
+		var buffer bytes.Buffer
+
+		if err := c.Encode(&buffer, []model.ToDo{toDo, {ID: 2, Name: "Call mom"}}); err != nil {
+			t.Fatalf("%s: %s", name, err.Error())
+		}
+
+		var toDos []model.ToDo
+
+		if err := c.Decode(&buffer, &toDos); err != nil {
+			t.Fatalf("%s: %s", name, err.Error())
+		}
+
+		if diff := cmp.Diff([]model.ToDo{toDo, {ID: 2, Name: "Call mom"}}, toDos); diff != "" {
+			t.Errorf("%s: unexpected ToDo items (-expected +got):\n%s", name, diff)
+		}
+
+		buffer.Reset()
+
+		if err := c.Encode(&buffer, problem); err != nil {
+			t.Fatalf("%s: %s", name, err.Error())
+		}
+
+		var decoded model.Problem
+
+		if err := c.Decode(&buffer, &decoded); err != nil {
+			t.Fatalf("%s: %s", name, err.Error())
+		}
+
+		if diff := cmp.Diff(problem, decoded); diff != "" {
+			t.Errorf("%s: unexpected problem (-expected +got):\n%s", name, diff)
+		}
+
+		if err := c.Decode(strings.NewReader(""), &decoded); !errors.Is(err, io.EOF) {
+			t.Errorf("%s: expected %v for an empty body, got %v", name, io.EOF, err)
+		}
+	}
+}
+
+func TestXML(t *testing.T) {
+	var buffer bytes.Buffer
+
+	value := map[string]interface{}{
+		"counts": map[string]int{"calendar feed": 1},
+		"due":    nil,
+		"tags":   []string{"release"},
+	}
+
+	if err := XML.Encode(&buffer, value); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<data><counts><item key="calendar feed">1</item></counts><tags><item>release</item></tags></data>` + "\n"
+
+	if diff := cmp.Diff(expected, buffer.String()); diff != "" {
+		t.Errorf("unexpected document (-expected +got):\n%s", diff)
+	}
+
+	var decoded map[string]interface{}
+
+	if err := XML.Decode(&buffer, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedValue := map[string]interface{}{
+		"counts": map[string]interface{}{"calendar feed": "1"},
+		"tags":   []interface{}{"release"},
+	}
+
+	if diff := cmp.Diff(expectedValue, decoded); diff != "" {
+		t.Errorf("unexpected value (-expected +got):\n%s", diff)
+	}
+
+	var toDo model.ToDo
+
+	if err := XML.Decode(strings.NewReader("<todo><id>one</id></todo>"), &toDo); err == nil {
+		t.Error("expected an error for a non-numeric ID")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry(JSON, YAML, XML, MessagePack, CBOR)
+
+	accepts := map[string]Codec{
+		"":                                      JSON,
+		"*/*":                                   JSON,
+		"application/yaml":                      YAML,
+		"text/html, application/x-msgpack":      MessagePack,
+		"application/json;q=0.5, text/xml":      XML,
+		"application/cbor;q=0, application/*":   JSON,
+		"text/*;q=0.9, application/cbor;q=0.8":  YAML,
+		"application/xml; charset=utf-8; q=0.1": XML,
+	}
+
+	for header, expected := range accepts {
+		c, err := registry.ForAccept(header)
+		if err != nil || c != expected {
+			t.Errorf("Accept %q: expected %s, got %v (%v)", header, ContentType(expected), c, err)
+		}
+	}
+
+	for _, header := range []string{"text/html", "application/cbor;q=0", "text/markdown, image/*"} {
+		if _, err := registry.ForAccept(header); !errors.Is(err, ErrNotAcceptable) {
+			t.Errorf("Accept %q: expected error %v, got %v", header, ErrNotAcceptable, err)
+		}
+	}
+
+	contentTypes := map[string]Codec{
+		"":                        JSON,
+		"application/json":        JSON,
+		"Application/YAML":        YAML,
+		"text/xml; charset=utf-8": XML,
+		"application/vnd.msgpack": MessagePack,
+		"application/cbor":        CBOR,
+	}
+
+	for header, expected := range contentTypes {
+		c, err := registry.ForContentType(header)
+		if err != nil || c != expected {
+			t.Errorf("Content-Type %q: expected %s, got %v (%v)", header, ContentType(expected), c, err)
+		}
+	}
+
+	for _, header := range []string{"text/plain", "multipart/form-data; boundary=x", "not a type;"} {
+		if _, err := registry.ForContentType(header); !errors.Is(err, ErrUnsupportedMediaType) {
+			t.Errorf("Content-Type %q: expected error %v, got %v", header, ErrUnsupportedMediaType, err)
+		}
+	}
+
+	// A registered codec takes precedence over existing codecs for its types.
+	registry.Register(prettyJSON{})
+
+	if c, _ := registry.ForContentType("application/json"); c != (prettyJSON{}) {
+		t.Errorf("expected registered codec, got %v", c)
+	}
+}
+
+// prettyJSON is a codec that writes indented JSON.
+type prettyJSON struct {
+	jsonCodec
+}
+
+func (prettyJSON) MediaTypes() []string {
+	return []string{"application/json"}
+}
@@ -0,0 +1,333 @@
+// Package codec provides the encodings the REST API reads and writes, selected
+// by the Content-Type and Accept headers of a request. JSON, YAML, XML,
+// MessagePack and CBOR are registered by default, and further codecs can be
+// added with Register.
+//
+// All codecs use the `json` struct tags of the encoded values, so that fields
+// have the same names in every encoding.
+package codec
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// XML encodes values as XML documents with a `data` root element. Object fields
+// are elements named like their JSON fields, array items are `item` elements,
+// and null values are omitted:
+//
+//	<data><id>1</id><name>Release 1.0</name><tags><item>release</item></tags></data>
+//
+// Object keys that are no valid element names are written as `item` elements
+// with a `key` attribute.
+var XML Codec = xmlCodec{}
+
+const (
+	// xmlRoot is the name of the root element.
+	xmlRoot = "data"
+
+	// xmlItem is the name of array items and of object fields whose key is
+	// written as attribute.
+	xmlItem = "item"
+
+	// xmlKey is the name of the attribute containing an object key.
+	xmlKey = "key"
+)
+
+// xmlNamePattern matches the keys that can be used as element names.
+var xmlNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+type xmlCodec struct{}
+
+func (xmlCodec) MediaTypes() []string {
+	return []string{"application/xml; charset=utf-8", "text/xml"}
+}
+
+// Encode converts v to JSON and writes the JSON tokens as XML elements, which
+// keeps the order of the fields.
+func (xmlCodec) Encode(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	encoder := xml.NewEncoder(w)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	if err := encodeXMLValue(encoder, decoder, xml.StartElement{Name: xml.Name{Local: xmlRoot}}); err != nil {
+		return err
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// encodeXMLValue reads the next JSON value from the decoder and writes it as
+// the given element. Null values are skipped.
+func encodeXMLValue(encoder *xml.Encoder, decoder *json.Decoder, element xml.StartElement) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if token == nil {
+		return nil
+	}
+
+	if err := encoder.EncodeToken(element); err != nil {
+		return err
+	}
+
+	switch token := token.(type) {
+	case json.Delim:
+		for decoder.More() {
+			child := xml.StartElement{Name: xml.Name{Local: xmlItem}}
+
+			if token == '{' {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+
+				if key := keyToken.(string); xmlNamePattern.MatchString(key) {
+					child.Name.Local = key
+				} else {
+					child.Attr = []xml.Attr{{Name: xml.Name{Local: xmlKey}, Value: key}}
+				}
+			}
+
+			if err := encodeXMLValue(encoder, decoder, child); err != nil {
+				return err
+			}
+		}
+
+		// Consume the closing delimiter.
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+	case string:
+		err = encoder.EncodeToken(xml.CharData(token))
+	case json.Number:
+		err = encoder.EncodeToken(xml.CharData(token.String()))
+	case bool:
+		err = encoder.EncodeToken(xml.CharData(strconv.FormatBool(token)))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return encoder.EncodeToken(element.End())
+}
+
+// xmlNode is a parsed XML element. The key is the value of the key attribute
+// or the element name, and isItem is true for `item` elements without key.
+type xmlNode struct {
+	key      string
+	isItem   bool
+	text     string
+	children []*xmlNode
+}
+
+// Decode parses the XML document into a tree, converts the tree to JSON using
+// the type of v to tell objects, arrays and scalar values apart, and decodes
+// the JSON into v. The name of the root element is ignored.
+func (xmlCodec) Decode(r io.Reader, v interface{}) error {
+	root, err := parseXML(xml.NewDecoder(r))
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(root.toJSON(reflect.TypeOf(v)))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// parseXML parses the first element of a document along with its children.
+func parseXML(decoder *xml.Decoder) (*xmlNode, error) {
+	var stack []*xmlNode
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{key: token.Name.Local, isItem: token.Name.Local == xmlItem}
+
+			for _, attr := range token.Attr {
+				if attr.Name.Local == xmlKey {
+					node.key = attr.Value
+					node.isItem = false
+				}
+			}
+
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			}
+
+			stack = append(stack, node)
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(token)
+			}
+		case xml.EndElement:
+			node := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			if len(stack) == 0 {
+				return node, nil
+			}
+		}
+	}
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// toJSON converts the node to a value that is encoded as the JSON expected for
+// the given type. Values that don't fit the type are returned as strings, so
+// that decoding the JSON reports the mismatch.
+func (n *xmlNode) toJSON(t reflect.Type) interface{} {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// Without a type, elements whose children are all items are arrays.
+	if t == nil || t.Kind() == reflect.Interface {
+		if len(n.children) == 0 {
+			return n.text
+		}
+
+		for _, child := range n.children {
+			if !child.isItem {
+				return n.object(func(string) reflect.Type { return t })
+			}
+		}
+
+		return n.items(t)
+	}
+
+	pointer := reflect.PtrTo(t)
+	if pointer.Implements(jsonUnmarshalerType) || pointer.Implements(textUnmarshalerType) {
+		return strings.TrimSpace(n.text)
+	}
+
+	text := strings.TrimSpace(n.text)
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := jsonFields(t)
+		return n.object(func(key string) reflect.Type { return fields[key] })
+	case reflect.Map:
+		return n.object(func(string) reflect.Type { return t.Elem() })
+	case reflect.Slice, reflect.Array:
+		// Byte slices are encoded as base64 strings.
+		if t.Elem().Kind() == reflect.Uint8 {
+			return text
+		}
+		return n.items(t.Elem())
+	case reflect.Bool:
+		if value, err := strconv.ParseBool(text); err == nil {
+			return value
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if _, err := strconv.ParseFloat(text, 64); err == nil {
+			return json.Number(text)
+		}
+	case reflect.String:
+		return n.text
+	}
+
+	return text
+}
+
+// object converts the children of the node to an object, using the type the
+// given function returns for a key. Keys without type, like unknown struct
+// fields, are converted as if they had an interface type.
+func (n *xmlNode) object(typeFor func(key string) reflect.Type) map[string]interface{} {
+	object := make(map[string]interface{}, len(n.children))
+
+	for _, child := range n.children {
+		object[child.key] = child.toJSON(typeFor(child.key))
+	}
+
+	return object
+}
+
+// items converts the children of the node to an array of the given type.
+func (n *xmlNode) items(t reflect.Type) []interface{} {
+	items := make([]interface{}, len(n.children))
+
+	for i, child := range n.children {
+		items[i] = child.toJSON(t)
+	}
+
+	return items
+}
+
+// jsonFields returns the types of the fields of a struct by their JSON names,
+// including the fields of embedded structs.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+
+		if name == "-" || field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct {
+				for embeddedName, embeddedType := range jsonFields(embedded) {
+					if _, exists := fields[embeddedName]; !exists {
+						fields[embeddedName] = embeddedType
+					}
+				}
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fields[name] = field.Type
+	}
+
+	return fields
+}
@@ -0,0 +1,176 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/dominikbraun/todo/core"
+
+	"github.com/go-chi/chi"
+)
+
+var (
+	// errFileMissing indicates that a multipart upload has no `file` part.
+	errFileMissing = errors.New("multipart form must contain a file part")
+)
+
+// multipartOverhead is the number of bytes a multipart request body may exceed
+// core.MaxAttachmentSize by, accounting for boundaries and part headers.
+const multipartOverhead = 1 << 20
+
+// CreateAttachment processes a multipart POST request for uploading a file as
+// an attachment. The file content is expected in a form part named `file` and
+// is streamed to the blob store without buffering the entire file.
+//
+// Expects the `id` URL parameter. If the `task_id` query parameter is set, the
+// attachment will belong to the task with that ID.
+func (r *RESTController) CreateAttachment() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if err := checkAccept(request); err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		id, err := strconv.Atoi(chi.URLParam(request, "id"))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		var taskID int
+
+		if taskIDParam := request.URL.Query().Get("task_id"); taskIDParam != "" {
+			if taskID, err = strconv.Atoi(taskIDParam); err != nil {
+				respond(writer, request, statusCodeForError(err), err)
+				return
+			}
+		}
+
+		request.Body = http.MaxBytesReader(writer, request.Body, core.MaxAttachmentSize+multipartOverhead)
+
+		reader, err := request.MultipartReader()
+		if err != nil {
+			respond(writer, request, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		for {
+			part, err := reader.NextPart()
+			if errors.Is(err, io.EOF) {
+				respond(writer, request, http.StatusUnprocessableEntity, errFileMissing)
+				return
+			}
+			if err != nil {
+				respond(writer, request, http.StatusUnprocessableEntity, err)
+				return
+			}
+
+			if part.FormName() != "file" {
+				continue
+			}
+
+			attachment, err := r.app.CreateAttachment(int64(id), int64(taskID), part.FileName(), part)
+			if err != nil {
+				respond(writer, request, statusCodeForError(err), err)
+				return
+			}
+
+			respond(writer, request, http.StatusOK, attachment)
+			return
+		}
+	}
+}
+
+// GetAttachments processes a GET request for listing the metadata of all
+// attachments of a ToDo item.
+//
+// Expects the `id` URL parameter.
+func (r *RESTController) GetAttachments() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(request, "id"))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		attachments, err := r.app.GetAttachments(int64(id))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, attachments)
+	}
+}
+
+// DownloadAttachment processes a GET request for downloading the content of an
+// attachment. The content is streamed from the blob store to the client.
+//
+// Expects the `id` and `attachmentID` URL parameters.
+func (r *RESTController) DownloadAttachment() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(request, "id"))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		attachmentID, err := strconv.Atoi(chi.URLParam(request, "attachmentID"))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		attachment, content, err := r.app.OpenAttachment(int64(id), int64(attachmentID))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+		defer content.Close()
+
+		disposition := mime.FormatMediaType("attachment", map[string]string{
+			"filename": attachment.Filename,
+		})
+
+		writer.Header().Set("Content-Type", attachment.ContentType)
+		writer.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+		writer.Header().Set("Content-Disposition", disposition)
+		writer.Header().Set("X-Content-Type-Options", "nosniff")
+		writer.WriteHeader(http.StatusOK)
+
+		_, _ = io.Copy(writer, content)
+	}
+}
+
+// DeleteAttachment processes a DELETE request for deleting an attachment along
+// with its content.
+//
+// Expects the `id` and `attachmentID` URL parameters.
+func (r *RESTController) DeleteAttachment() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(request, "id"))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		attachmentID, err := strconv.Atoi(chi.URLParam(request, "attachmentID"))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		err = r.app.DeleteAttachment(int64(id), int64(attachmentID))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, nil)
+	}
+}
@@ -0,0 +1,96 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+)
+
+func TestRESTController_CreateAttachment(t *testing.T) {
+	restController := newTestRESTController(t)
+	toDo := model.ToDo{
+		Name: "ToDo 1",
+		Tasks: []model.Task{
+			{
+				Name: "Task 1",
+			},
+		},
+	}
+
+	createdToDo, _ := restController.app.CreateToDo(toDo)
+
+	var body bytes.Buffer
+
+	form := multipart.NewWriter(&body)
+	file, _ := form.CreateFormFile("file", "notes.txt")
+	_, _ = file.Write([]byte("My notes"))
+	_ = form.Close()
+
+	target := fmt.Sprintf("/todos/%d/attachments?task_id=%d", createdToDo.ID, createdToDo.Tasks[0].ID)
+	request := httptest.NewRequest("POST", target, &body)
+	request.Header.Set("Content-Type", form.FormDataContentType())
+	recorder := httptest.NewRecorder()
+
+	router := chi.NewRouter()
+	router.Post("/todos/{id}/attachments", restController.CreateAttachment())
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	var response model.Attachment
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal("could not parse response body")
+	}
+
+	if response.TaskID != createdToDo.Tasks[0].ID {
+		t.Errorf("expected task ID %d, got %d", createdToDo.Tasks[0].ID, response.TaskID)
+	}
+}
+
+func TestRESTController_DownloadAttachment(t *testing.T) {
+	restController := newTestRESTController(t)
+	toDo := model.ToDo{
+		Name: "ToDo 1",
+	}
+
+	content := "My notes"
+
+	createdToDo, _ := restController.app.CreateToDo(toDo)
+	attachment, _ := restController.app.CreateAttachment(createdToDo.ID, 0, "notes.txt", strings.NewReader(content))
+
+	target := fmt.Sprintf("/todos/%d/attachments/%d", createdToDo.ID, attachment.ID)
+	request := httptest.NewRequest("GET", target, nil)
+	recorder := httptest.NewRecorder()
+
+	router := chi.NewRouter()
+	router.Get("/todos/{id}/attachments/{attachmentID}", restController.DownloadAttachment())
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	if recorder.Body.String() != content {
+		t.Errorf("expected content %s, got %s", content, recorder.Body.String())
+	}
+
+	if disposition := recorder.Header().Get("Content-Disposition"); disposition != `attachment; filename=notes.txt` {
+		t.Errorf("unexpected content disposition %s", disposition)
+	}
+}
@@ -0,0 +1,112 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// errAdminDisabled indicates that the admin endpoints are disabled because
+	// no admin token has been configured.
+	errAdminDisabled = errors.New("admin endpoints are disabled")
+
+	// errInvalidAdminToken indicates that a request has no or a wrong admin token.
+	errInvalidAdminToken = errors.New("invalid admin token")
+)
+
+// RequireAdminToken returns a middleware that only lets requests pass which
+// send the given admin token as `Authorization: Bearer <token>` header. If the
+// token is empty, all requests are rejected.
+func RequireAdminToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if token == "" {
+				respond(writer, request, http.StatusForbidden, errAdminDisabled)
+				return
+			}
+
+			authorization := request.Header.Get("Authorization")
+			sent := strings.TrimPrefix(authorization, "Bearer ")
+
+			if sent == authorization || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+				writer.Header().Set("WWW-Authenticate", "Bearer")
+				respond(writer, request, http.StatusUnauthorized, errInvalidAdminToken)
+				return
+			}
+
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+// Backup processes a GET request for a backup archive of all data. The archive
+// is streamed as a gzip-compressed JSON Lines file.
+func (r *RESTController) Backup() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		filename := fmt.Sprintf("todo-backup-%s.jsonl.gz", time.Now().UTC().Format("2006-01-02"))
+
+		writer.Header().Set("Content-Type", "application/gzip")
+		writer.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+		tracker := &writeTracker{writer: writer}
+
+		// Once the archive is being streamed, an error can't be reported with
+		// a status code anymore. The archive then lacks its trailer, so that
+		// it will be rejected as truncated on restore.
+		if err := r.app.Backup(tracker); err != nil && !tracker.hasWritten {
+			writer.Header().Del("Content-Disposition")
+			writer.Header().Del("Content-Type")
+			respond(writer, request, statusCodeForError(err), err)
+		}
+	}
+}
+
+// Restore processes a POST request for restoring a backup archive sent as
+// request body. The response lists the number of restored entities per type.
+//
+// If the `force` query parameter is true, existing data will be overwritten.
+// Otherwise, the restore will be refused if the storage is not empty.
+func (r *RESTController) Restore() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if err := checkAccept(request); err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		force := false
+
+		if value := request.URL.Query().Get("force"); value != "" {
+			var err error
+			if force, err = strconv.ParseBool(value); err != nil {
+				respond(writer, request, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		result, err := r.app.Restore(request.Body, force)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, result)
+	}
+}
+
+// writeTracker is an io.Writer that records whether anything has been written.
+type writeTracker struct {
+	writer     http.ResponseWriter
+	hasWritten bool
+}
+
+func (w *writeTracker) Write(p []byte) (int, error) {
+	w.hasWritten = true
+	return w.writer.Write(p)
+}
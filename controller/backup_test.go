@@ -0,0 +1,115 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+)
+
+func TestRequireAdminToken(t *testing.T) {
+	handler := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		token         string
+		authorization string
+		expected      int
+	}{
+		{"", "", http.StatusForbidden},
+		{"", "Bearer ", http.StatusForbidden},
+		{"secret", "", http.StatusUnauthorized},
+		{"secret", "Bearer wrong", http.StatusUnauthorized},
+		{"secret", "secret", http.StatusUnauthorized},
+		{"secret", "Bearer secret", http.StatusNoContent},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest("GET", "/admin/backup", nil)
+		if test.authorization != "" {
+			request.Header.Set("Authorization", test.authorization)
+		}
+
+		recorder := httptest.NewRecorder()
+
+		RequireAdminToken(test.token)(handler).ServeHTTP(recorder, request)
+
+		if recorder.Code != test.expected {
+			t.Errorf("token %q, authorization %q: expected status %d, got %d", test.token, test.authorization, test.expected, recorder.Code)
+		}
+	}
+}
+
+func TestRESTController_BackupAndRestore(t *testing.T) {
+	source := newTestRESTController(t)
+
+	_, _ = source.app.CreateToDo(model.ToDo{Name: "ToDo 1"})
+	_, _ = source.app.CreateToDo(model.ToDo{Name: "ToDo 2"})
+
+	recorder := httptest.NewRecorder()
+	source.Backup().ServeHTTP(recorder, httptest.NewRequest("GET", "/admin/backup", nil))
+
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "application/gzip" {
+		t.Fatalf("expected a gzip archive, got status %d and type %s", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+
+	if disposition := recorder.Header().Get("Content-Disposition"); !strings.Contains(disposition, ".jsonl.gz") {
+		t.Errorf("unexpected content disposition %s", disposition)
+	}
+
+	archive := recorder.Body.Bytes()
+
+	restController := newTestRESTController(t)
+	_, _ = restController.app.CreateToDo(model.ToDo{Name: "Existing"})
+
+	router := chi.NewRouter()
+	router.Post("/admin/restore", restController.Restore())
+
+	tests := []struct {
+		target   string
+		body     []byte
+		expected int
+	}{
+		{"/admin/restore?force=maybe", archive, http.StatusBadRequest},
+		{"/admin/restore", []byte("not an archive"), http.StatusBadRequest},
+		{"/admin/restore", archive[:len(archive)-20], http.StatusBadRequest},
+		{"/admin/restore", archive, http.StatusConflict},
+		{"/admin/restore?force=true", archive, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("POST", test.target, bytes.NewReader(test.body)))
+
+		if recorder.Code != test.expected {
+			t.Fatalf("%s: expected status %d, got %d: %s", test.target, test.expected, recorder.Code, recorder.Body.String())
+		}
+
+		if recorder.Code != http.StatusOK {
+			continue
+		}
+
+		var result model.RestoreResult
+
+		if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+			t.Fatal("could not parse response body")
+		}
+
+		if result.Counts["todo"] != 2 {
+			t.Errorf("expected 2 restored ToDo items, got %v", result.Counts)
+		}
+	}
+
+	if toDos, _ := restController.app.GetToDos(); len(toDos) != 2 {
+		t.Errorf("expected 2 ToDo items after restore, got %v", toDos)
+	}
+}
@@ -0,0 +1,63 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"net/http"
+
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/model"
+)
+
+// Batch processes a POST request for executing a list of create, update, delete
+// and complete operations. The response lists the status of each operation
+// along with the resulting ToDo item or the problem details of its error.
+//
+// If the batch is atomic and one of its operations fails, none of them is
+// applied, and the response is a problem with the status of the failed
+// operation, listing the results of all operations.
+func (r *RESTController) Batch() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		var batch model.BatchRequest
+
+		if err := decodeBody(request, &batch); err != nil {
+			respond(writer, request, statusCodeForBody(err), err)
+			return
+		}
+
+		results, err := r.app.Batch(batch.Operations, batch.Atomic)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, model.BatchResponse{
+			Atomic:  batch.Atomic,
+			Results: batchResults(results),
+		})
+	}
+}
+
+// batchResults converts the results of App.Batch to their API representation.
+func batchResults(results []core.BatchResult) []model.BatchResult {
+	converted := make([]model.BatchResult, len(results))
+
+	for i, result := range results {
+		if result.Err != nil {
+			status := statusCodeForError(result.Err)
+			problem := newProblem(status, result.Err)
+
+			converted[i] = model.BatchResult{Status: status, Error: &problem}
+			continue
+		}
+
+		converted[i].Status = http.StatusOK
+
+		if result.ToDo.ID != 0 {
+			toDo := result.ToDo
+			converted[i].ToDo = &toDo
+		}
+	}
+
+	return converted
+}
@@ -0,0 +1,68 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+func TestRESTController_Batch(t *testing.T) {
+	restController := newTestRESTController(t)
+	handler := restController.Batch()
+
+	body := `{"atomic": true, "operations": [{"op": "create", "todo": {"name": "ToDo 1"}}, {"op": "create", "todo": {"name": ""}}]}`
+
+	request := httptest.NewRequest("POST", "/todos/batch", strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, recorder.Code)
+	}
+
+	var problem model.Problem
+
+	if err := json.NewDecoder(recorder.Body).Decode(&problem); err != nil {
+		t.Fatal(err)
+	}
+
+	if problem.Type != problemTypeBatch || len(problem.Errors) != 1 || problem.Errors[0].Pointer != "/operations/1/todo/name" {
+		t.Errorf("unexpected problem %v", problem)
+	}
+
+	if len(problem.Results) != 2 || problem.Results[0].Status != http.StatusFailedDependency || problem.Results[1].Status != http.StatusUnprocessableEntity {
+		t.Errorf("unexpected results %v", problem.Results)
+	}
+
+	body = `{"operations": [{"op": "create", "todo": {"name": "ToDo 1"}}, {"op": "delete", "id": 42}]}`
+
+	request = httptest.NewRequest("POST", "/todos/batch", strings.NewReader(body))
+	recorder = httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	var response model.BatchResponse
+
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Results[0].Status != http.StatusOK || response.Results[0].ToDo == nil || response.Results[0].ToDo.ID == 0 {
+		t.Errorf("unexpected result %v", response.Results[0])
+	}
+
+	if response.Results[1].Status != http.StatusNotFound || response.Results[1].Error.Detail != "requested ToDo item not found" {
+		t.Errorf("unexpected result %v", response.Results[1])
+	}
+}
@@ -0,0 +1,922 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dominikbraun/todo/ical"
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+const (
+	// calDAVRoot is the path of the CalDAV principal, which is also the home
+	// collection containing the calendars.
+	calDAVRoot = "/caldav/"
+
+	// defaultCalendar is the calendar containing the ToDo items without project.
+	defaultCalendar = "default"
+
+	// calendarObjectContentType is the content type of calendar objects.
+	calendarObjectContentType = "text/calendar; charset=utf-8; component=VTODO"
+
+	// davMethods are the methods supported by the CalDAV handler.
+	davMethods = "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT"
+)
+
+var (
+	errCalendarNotFound       = errors.New("requested calendar not found")
+	errCalendarObjectNotFound = errors.New("requested calendar object not found")
+	errCollectionReadOnly     = errors.New("collections can't be modified")
+	errPreconditionFailed     = errors.New("the resource has been modified")
+)
+
+// The properties served by the CalDAV handler.
+var (
+	propResourceType             = xml.Name{Space: nsDAV, Local: "resourcetype"}
+	propDisplayName              = xml.Name{Space: nsDAV, Local: "displayname"}
+	propCurrentUserPrincipal     = xml.Name{Space: nsDAV, Local: "current-user-principal"}
+	propPrincipalURL             = xml.Name{Space: nsDAV, Local: "principal-URL"}
+	propOwner                    = xml.Name{Space: nsDAV, Local: "owner"}
+	propCurrentUserPrivilegeSet  = xml.Name{Space: nsDAV, Local: "current-user-privilege-set"}
+	propSupportedReportSet       = xml.Name{Space: nsDAV, Local: "supported-report-set"}
+	propGetETag                  = xml.Name{Space: nsDAV, Local: "getetag"}
+	propGetContentType           = xml.Name{Space: nsDAV, Local: "getcontenttype"}
+	propCalendarHomeSet          = xml.Name{Space: nsCalDAV, Local: "calendar-home-set"}
+	propSupportedComponentSet    = xml.Name{Space: nsCalDAV, Local: "supported-calendar-component-set"}
+	propCalendarData             = xml.Name{Space: nsCalDAV, Local: "calendar-data"}
+	propGetCTag                  = xml.Name{Space: nsCalendarServer, Local: "getctag"}
+	reportCalendarMultiget       = xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}
+	reportCalendarQuery          = xml.Name{Space: nsCalDAV, Local: "calendar-query"}
+	conditionSupportedReport     = xml.Name{Space: nsDAV, Local: "supported-report"}
+	conditionValidCalendarData   = xml.Name{Space: nsCalDAV, Local: "valid-calendar-data"}
+	conditionValidCalendarObject = xml.Name{Space: nsCalDAV, Local: "valid-calendar-object-resource"}
+)
+
+// davResource is a resource served by the CalDAV handler.
+type davResource interface {
+	// href returns the path of the resource.
+	href() string
+
+	// property returns the value of a property as inner XML and whether the
+	// resource has that property.
+	property(name xml.Name) (string, bool)
+
+	// allProps returns the properties listed for an allprop request.
+	allProps() []xml.Name
+}
+
+// davPath is a parsed CalDAV request path. It addresses the home collection if
+// calendar is empty, a calendar collection if object is empty, and a calendar
+// object otherwise.
+type davPath struct {
+	calendar string
+	object   string
+}
+
+// parseDAVPath parses an escaped request path. The returned bool is false if
+// the path doesn't address a resource of the CalDAV handler.
+func parseDAVPath(escapedPath string) (davPath, bool) {
+	rest := strings.TrimPrefix(escapedPath, strings.TrimSuffix(calDAVRoot, "/"))
+	rest = strings.Trim(rest, "/")
+
+	if rest == "" {
+		return davPath{}, true
+	}
+
+	segments := strings.Split(rest, "/")
+	if len(segments) > 2 {
+		return davPath{}, false
+	}
+
+	calendar, err := url.PathUnescape(segments[0])
+	if err != nil || calendar == "" {
+		return davPath{}, false
+	}
+
+	if len(segments) == 1 {
+		return davPath{calendar: calendar}, true
+	}
+
+	if !strings.HasSuffix(segments[1], ".ics") {
+		return davPath{}, false
+	}
+
+	uid, err := url.PathUnescape(strings.TrimSuffix(segments[1], ".ics"))
+	if err != nil || uid == "" {
+		return davPath{}, false
+	}
+
+	return davPath{calendar: calendar, object: uid}, true
+}
+
+// calendarOf returns the name of the calendar a project belongs to.
+func calendarOf(project string) string {
+	if project == "" {
+		return defaultCalendar
+	}
+
+	return project
+}
+
+// projectOf returns the project of the ToDo items in a calendar.
+func projectOf(calendar string) string {
+	if calendar == defaultCalendar {
+		return ""
+	}
+
+	return calendar
+}
+
+// calendarHref returns the path of a calendar collection.
+func calendarHref(calendar string) string {
+	return calDAVRoot + url.PathEscape(calendar) + "/"
+}
+
+// davHome is the home collection, which also represents the principal.
+type davHome struct{}
+
+func (davHome) href() string {
+	return calDAVRoot
+}
+
+func (davHome) property(name xml.Name) (string, bool) {
+	switch name {
+	case propResourceType:
+		return "<D:collection/><D:principal/>", true
+	case propDisplayName:
+		return "ToDo", true
+	case propCurrentUserPrincipal, propPrincipalURL, propCalendarHomeSet:
+		return hrefElement(calDAVRoot), true
+	case propCurrentUserPrivilegeSet:
+		return "<D:privilege><D:read/></D:privilege>", true
+	}
+
+	return "", false
+}
+
+func (davHome) allProps() []xml.Name {
+	return []xml.Name{propResourceType, propDisplayName, propCurrentUserPrincipal, propPrincipalURL, propCalendarHomeSet}
+}
+
+// davCalendar is a calendar collection containing the ToDo items of a project.
+type davCalendar struct {
+	name    string
+	objects []calendarObject
+}
+
+func (c davCalendar) href() string {
+	return calendarHref(c.name)
+}
+
+func (c davCalendar) property(name xml.Name) (string, bool) {
+	switch name {
+	case propResourceType:
+		return "<D:collection/><C:calendar/>", true
+	case propDisplayName:
+		if c.name == defaultCalendar {
+			return "ToDos", true
+		}
+		return escapeXML(c.name), true
+	case propOwner:
+		return hrefElement(calDAVRoot), true
+	case propSupportedComponentSet:
+		return `<C:comp name="VTODO"/>`, true
+	case propGetCTag:
+		return c.ctag(), true
+	case propCurrentUserPrivilegeSet:
+		return "<D:privilege><D:read/></D:privilege><D:privilege><D:write/></D:privilege>" +
+			"<D:privilege><D:write-content/></D:privilege><D:privilege><D:bind/></D:privilege>" +
+			"<D:privilege><D:unbind/></D:privilege>", true
+	case propSupportedReportSet:
+		return "<D:supported-report><D:report><C:calendar-multiget/></D:report></D:supported-report>" +
+			"<D:supported-report><D:report><C:calendar-query/></D:report></D:supported-report>", true
+	}
+
+	return "", false
+}
+
+func (c davCalendar) allProps() []xml.Name {
+	return []xml.Name{propResourceType, propDisplayName, propOwner, propSupportedComponentSet, propGetCTag}
+}
+
+// ctag returns a tag that changes whenever an object of the calendar changes,
+// which is derived from the IDs and versions of the calendar's ToDo items.
+func (c davCalendar) ctag() string {
+	hash := fnv.New64a()
+
+	for _, object := range c.objects {
+		if object.task == nil {
+			_, _ = fmt.Fprintf(hash, "%d:%d;", object.toDo.ID, object.toDo.Version)
+		}
+	}
+
+	return fmt.Sprintf("%x", hash.Sum64())
+}
+
+// calendarObject is a ToDo item or one of its tasks served as calendar object.
+type calendarObject struct {
+	toDo model.ToDo
+	task *model.Task
+}
+
+func (o calendarObject) href() string {
+	return calendarHref(o.calendar()) + url.PathEscape(o.uid()) + ".ics"
+}
+
+func (o calendarObject) property(name xml.Name) (string, bool) {
+	switch name {
+	case propResourceType:
+		return "", true
+	case propGetETag:
+		return escapeXML(o.etag()), true
+	case propGetContentType:
+		return calendarObjectContentType, true
+	case propCalendarData:
+		return escapeXML(o.data()), true
+	}
+
+	return "", false
+}
+
+func (o calendarObject) allProps() []xml.Name {
+	return []xml.Name{propResourceType, propGetETag, propGetContentType}
+}
+
+// uid returns the UID of the object's component.
+func (o calendarObject) uid() string {
+	if o.task != nil {
+		return ical.TaskUID(o.toDo, *o.task)
+	}
+
+	return ical.ToDoUID(o.toDo)
+}
+
+// calendar returns the name of the calendar containing the object. Tasks are
+// contained in the calendar of their ToDo item.
+func (o calendarObject) calendar() string {
+	return calendarOf(o.toDo.Project)
+}
+
+// etag returns the entity tag of the object, which is derived from the version
+// of the ToDo item. Tasks share the entity tag of their ToDo item, since each
+// edit of a task is a new version of the ToDo item.
+func (o calendarObject) etag() string {
+	return fmt.Sprintf(`"%d"`, o.toDo.Version)
+}
+
+// data returns the object as iCalendar file.
+func (o calendarObject) data() string {
+	var builder strings.Builder
+	_ = ical.EncodeObject(&builder, o.toDo, o.task, time.Now())
+	return builder.String()
+}
+
+// properties returns the iCalendar properties of the object's component for
+// evaluating calendar-query filters.
+func (o calendarObject) properties() map[string]string {
+	properties := map[string]string{
+		"UID":    o.uid(),
+		"STATUS": "NEEDS-ACTION",
+	}
+
+	name, description, done := o.toDo.Name, o.toDo.Description, o.toDo.Done
+	if o.task != nil {
+		name, description, done = o.task.Name, o.task.Description, o.task.Done
+		properties["RELATED-TO"] = ical.ToDoUID(o.toDo)
+	} else {
+		if o.toDo.Due != nil {
+			properties["DUE"] = o.toDo.Due.UTC().Format(time.RFC3339)
+		}
+		if o.toDo.Priority != "" {
+			properties["PRIORITY"] = o.toDo.Priority
+		}
+		if len(o.toDo.Tags) > 0 {
+			properties["CATEGORIES"] = strings.Join(o.toDo.Tags, ",")
+		}
+		if o.toDo.Recurrence != "" {
+			properties["RRULE"] = o.toDo.Recurrence
+		}
+	}
+
+	properties["SUMMARY"] = name
+
+	if description != "" {
+		properties["DESCRIPTION"] = description
+	}
+
+	if done {
+		properties["STATUS"] = "COMPLETED"
+	}
+
+	return properties
+}
+
+// matches reports whether the object matches the filter of a calendar-query
+// report. Time ranges aren't evaluated and match all objects, which is allowed
+// since clients have to handle the returned calendar data anyway.
+func (f compFilter) matches(object calendarObject) bool {
+	if f.Name != "VCALENDAR" || f.IsNotDefined != nil {
+		return false
+	}
+
+	for _, filter := range f.CompFilters {
+		if !filter.matchesToDo(object) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesToDo evaluates a filter for the VTODO component of an object.
+func (f compFilter) matchesToDo(object calendarObject) bool {
+	if f.Name != "VTODO" {
+		return f.IsNotDefined != nil
+	}
+
+	if f.IsNotDefined != nil {
+		return false
+	}
+
+	properties := object.properties()
+
+	for _, filter := range f.PropFilters {
+		if !filter.matches(properties) {
+			return false
+		}
+	}
+
+	// VTODO components don't contain any other components like VALARM.
+	for _, filter := range f.CompFilters {
+		if filter.IsNotDefined == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matches evaluates the filter for the given properties.
+func (f propFilter) matches(properties map[string]string) bool {
+	value, isDefined := properties[strings.ToUpper(f.Name)]
+
+	switch {
+	case f.IsNotDefined != nil:
+		return !isDefined
+	case !isDefined:
+		return false
+	case f.TextMatch != nil:
+		contains := strings.Contains(strings.ToLower(value), strings.ToLower(f.TextMatch.Text))
+		return contains != (f.TextMatch.Negate == "yes")
+	}
+
+	return true
+}
+
+// CalDAV returns a handler serving ToDo items to calendar clients via CalDAV as
+// specified by RFC 4791. Each project is a calendar collection under /caldav/,
+// and ToDo items without project are in the `default` calendar. ToDo items and
+// their tasks are calendar objects with a VTODO component each.
+//
+// The handler supports PROPFIND, the calendar-multiget and calendar-query
+// reports, and reading, writing and deleting calendar objects. Writes are
+// guarded by ETags derived from the version of the ToDo items.
+func (r *RESTController) CalDAV() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("DAV", "1, 3, calendar-access")
+
+		path, isValid := parseDAVPath(request.URL.EscapedPath())
+		if !isValid {
+			respond(writer, request, http.StatusNotFound, errCalendarObjectNotFound)
+			return
+		}
+
+		switch request.Method {
+		case http.MethodOptions:
+			writer.Header().Set("Allow", davMethods)
+			writer.WriteHeader(http.StatusOK)
+		case "PROPFIND":
+			r.davPropfind(writer, request, path)
+		case "REPORT":
+			r.davReport(writer, request, path)
+		case http.MethodGet, http.MethodHead:
+			r.davGet(writer, request, path)
+		case http.MethodPut:
+			r.davPut(writer, request, path)
+		case http.MethodDelete:
+			r.davDelete(writer, request, path)
+		default:
+			writer.Header().Set("Allow", davMethods)
+			writer.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// davPropfind lists the properties of the requested resource and, unless the
+// `Depth` header is 0, of its members.
+func (r *RESTController) davPropfind(writer http.ResponseWriter, request *http.Request, path davPath) {
+	var propfind propfindRequest
+
+	hasBody, err := decodeDAVRequest(request, &propfind)
+	if err != nil {
+		respond(writer, request, http.StatusBadRequest, err)
+		return
+	}
+
+	objects, err := r.calendarObjects()
+	if err != nil {
+		respond(writer, request, statusCodeForError(err), err)
+		return
+	}
+
+	resource, isFound := findResource(objects, path)
+	if !isFound {
+		respond(writer, request, http.StatusNotFound, errCalendarObjectNotFound)
+		return
+	}
+
+	resources := []davResource{resource}
+
+	if request.Header.Get("Depth") != "0" {
+		switch {
+		case path.calendar == "":
+			for _, calendar := range calendars(objects) {
+				resources = append(resources, calendar)
+			}
+		case path.object == "":
+			for _, object := range resource.(davCalendar).objects {
+				resources = append(resources, object)
+			}
+		}
+	}
+
+	allProp := !hasBody || propfind.AllProp != nil
+	responses := make([]davResponse, len(resources))
+
+	for i, resource := range resources {
+		if propfind.PropName != nil {
+			responses[i] = davResponse{href: resource.href()}
+			for _, name := range resource.allProps() {
+				responses[i].found = append(responses[i].found, renderElement(name, ""))
+			}
+			continue
+		}
+		responses[i] = propResponse(resource, propfind.Prop.names(), allProp)
+	}
+
+	writeMultistatus(writer, responses)
+}
+
+// davReport processes calendar-multiget and calendar-query reports, which
+// return the properties of the requested or matching calendar objects.
+func (r *RESTController) davReport(writer http.ResponseWriter, request *http.Request, path davPath) {
+	var report reportRequest
+
+	if _, err := decodeDAVRequest(request, &report); err != nil {
+		respond(writer, request, http.StatusBadRequest, err)
+		return
+	}
+
+	objects, err := r.calendarObjects()
+	if err != nil {
+		respond(writer, request, statusCodeForError(err), err)
+		return
+	}
+
+	var (
+		names     = report.Prop.names()
+		allProp   = report.AllProp != nil
+		responses []davResponse
+	)
+
+	switch report.XMLName {
+	case reportCalendarMultiget:
+		for _, href := range report.Hrefs {
+			href = strings.TrimSpace(href)
+			object, isFound := findObjectByHref(objects, href)
+
+			if !isFound {
+				responses = append(responses, davResponse{href: href, status: http.StatusNotFound})
+				continue
+			}
+
+			responses = append(responses, propResponse(object, names, allProp))
+		}
+
+	case reportCalendarQuery:
+		resource, isFound := findResource(objects, path)
+		if !isFound || path.calendar == "" {
+			respond(writer, request, http.StatusNotFound, errCalendarNotFound)
+			return
+		}
+
+		candidates := []calendarObject{}
+		if calendar, isCalendar := resource.(davCalendar); isCalendar {
+			candidates = calendar.objects
+		} else {
+			candidates = append(candidates, resource.(calendarObject))
+		}
+
+		for _, object := range candidates {
+			if report.Filter == nil || report.Filter.CompFilter.matches(object) {
+				responses = append(responses, propResponse(object, names, allProp))
+			}
+		}
+
+	default:
+		writeDAVError(writer, http.StatusForbidden, conditionSupportedReport)
+		return
+	}
+
+	writeMultistatus(writer, responses)
+}
+
+// davGet returns a calendar object, or all objects of a calendar collection as
+// a single iCalendar file.
+func (r *RESTController) davGet(writer http.ResponseWriter, request *http.Request, path davPath) {
+	objects, err := r.calendarObjects()
+	if err != nil {
+		respond(writer, request, statusCodeForError(err), err)
+		return
+	}
+
+	resource, isFound := findResource(objects, path)
+	if !isFound {
+		respond(writer, request, http.StatusNotFound, errCalendarObjectNotFound)
+		return
+	}
+
+	switch resource := resource.(type) {
+	case calendarObject:
+		writer.Header().Set("Content-Type", calendarObjectContentType)
+		writer.Header().Set("ETag", resource.etag())
+		_, _ = io.WriteString(writer, resource.data())
+
+	case davCalendar:
+		var toDos []model.ToDo
+		for _, object := range resource.objects {
+			if object.task == nil {
+				toDos = append(toDos, object.toDo)
+			}
+		}
+
+		writer.Header().Set("Content-Type", calendarContentType)
+		_ = ical.Encode(writer, toDos, time.Now())
+
+	default:
+		writer.Header().Set("Allow", "OPTIONS, PROPFIND")
+		writer.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// davPut creates or updates a calendar object. New objects become ToDo items
+// in the calendar's project, or tasks if they are related to a ToDo item.
+// Updating an object in another calendar moves the ToDo item to the project of
+// that calendar.
+func (r *RESTController) davPut(writer http.ResponseWriter, request *http.Request, path davPath) {
+	if path.object == "" {
+		respond(writer, request, http.StatusForbidden, errCollectionReadOnly)
+		return
+	}
+
+	objects, err := r.calendarObjects()
+	if err != nil {
+		respond(writer, request, statusCodeForError(err), err)
+		return
+	}
+
+	if _, isFound := findResource(objects, davPath{calendar: path.calendar}); !isFound {
+		respond(writer, request, http.StatusConflict, errCalendarNotFound)
+		return
+	}
+
+	object, err := ical.DecodeObject(http.MaxBytesReader(writer, request.Body, maxImportSize))
+	if err != nil {
+		var syntaxError *ical.SyntaxError
+		if errors.As(err, &syntaxError) {
+			writeDAVError(writer, http.StatusForbidden, conditionValidCalendarData)
+			return
+		}
+		respond(writer, request, http.StatusBadRequest, err)
+		return
+	}
+
+	if object.UID != path.object {
+		writeDAVError(writer, http.StatusForbidden, conditionValidCalendarObject)
+		return
+	}
+
+	var (
+		existing, exists = findObject(objects, object.UID)
+		etag             string
+		result           calendarObject
+		status           = http.StatusNoContent
+	)
+
+	if exists {
+		etag = existing.etag()
+	}
+
+	if preconditionFailed(request, etag) {
+		respond(writer, request, http.StatusPreconditionFailed, errPreconditionFailed)
+		return
+	}
+
+	if exists {
+		result, err = r.updateCalendarObject(existing, path.calendar, object)
+	} else {
+		result, err = r.createCalendarObject(objects, path.calendar, object)
+		status = http.StatusCreated
+	}
+
+	if errors.Is(err, storage.ErrVersionConflict) {
+		respond(writer, request, http.StatusPreconditionFailed, errPreconditionFailed)
+		return
+	} else if err != nil {
+		respond(writer, request, statusCodeForError(err), err)
+		return
+	}
+
+	writer.Header().Set("ETag", result.etag())
+	writer.WriteHeader(status)
+}
+
+// davDelete deletes the ToDo item or task of a calendar object.
+func (r *RESTController) davDelete(writer http.ResponseWriter, request *http.Request, path davPath) {
+	if path.object == "" {
+		respond(writer, request, http.StatusForbidden, errCollectionReadOnly)
+		return
+	}
+
+	objects, err := r.calendarObjects()
+	if err != nil {
+		respond(writer, request, statusCodeForError(err), err)
+		return
+	}
+
+	resource, isFound := findResource(objects, path)
+	if !isFound {
+		respond(writer, request, http.StatusNotFound, errCalendarObjectNotFound)
+		return
+	}
+
+	object := resource.(calendarObject)
+
+	if preconditionFailed(request, object.etag()) {
+		respond(writer, request, http.StatusPreconditionFailed, errPreconditionFailed)
+		return
+	}
+
+	if object.task != nil {
+		_, err = r.app.DeleteTask(object.toDo.ID, object.toDo.Version, object.task.ID)
+	} else {
+		err = r.app.DeleteToDo(object.toDo.ID)
+	}
+
+	if errors.Is(err, storage.ErrVersionConflict) {
+		respond(writer, request, http.StatusPreconditionFailed, errPreconditionFailed)
+		return
+	} else if err != nil {
+		respond(writer, request, statusCodeForError(err), err)
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// createCalendarObject creates a task if the object is related to a ToDo item,
+// and a ToDo item in the calendar's project otherwise.
+func (r *RESTController) createCalendarObject(objects []calendarObject, calendar string, object ical.Object) (calendarObject, error) {
+	if parent, isFound := findObject(objects, object.Parent); object.Parent != "" && isFound && parent.task == nil {
+		task := model.Task{
+			UID:         object.UID,
+			Name:        object.ToDo.Name,
+			Description: object.ToDo.Description,
+			Done:        object.ToDo.Done,
+		}
+
+		toDo, err := r.app.AddTask(parent.toDo.ID, 0, task)
+		if err != nil {
+			return calendarObject{}, err
+		}
+
+		return calendarObject{toDo: toDo, task: &toDo.Tasks[len(toDo.Tasks)-1]}, nil
+	}
+
+	toDo := object.ToDo
+	toDo.Project = projectOf(calendar)
+
+	createdToDo, err := r.app.CreateToDo(toDo)
+	if err != nil {
+		return calendarObject{}, err
+	}
+
+	return calendarObject{toDo: createdToDo}, nil
+}
+
+// updateCalendarObject overwrites an existing ToDo item or task with the given
+// object. Tasks can't be turned into ToDo items and vice versa, so that the
+// RELATED-TO property of the object is ignored.
+func (r *RESTController) updateCalendarObject(existing calendarObject, calendar string, object ical.Object) (calendarObject, error) {
+	if existing.task != nil {
+		task := *existing.task
+		task.Name = object.ToDo.Name
+		task.Description = object.ToDo.Description
+		task.Done = object.ToDo.Done
+
+		toDo, err := r.app.UpdateTask(existing.toDo.ID, existing.toDo.Version, task)
+		if err != nil {
+			return calendarObject{}, err
+		}
+
+		for i := range toDo.Tasks {
+			if toDo.Tasks[i].ID == task.ID {
+				return calendarObject{toDo: toDo, task: &toDo.Tasks[i]}, nil
+			}
+		}
+
+		return calendarObject{}, storage.ErrTaskNotFound
+	}
+
+	toDo := object.ToDo
+	toDo.Project = projectOf(calendar)
+	toDo.Tasks = existing.toDo.Tasks
+	toDo.Version = existing.toDo.Version
+
+	if err := r.app.UpdateToDo(existing.toDo.ID, toDo); err != nil {
+		return calendarObject{}, err
+	}
+
+	updatedToDo, err := r.app.GetToDo(existing.toDo.ID)
+	if err != nil {
+		return calendarObject{}, err
+	}
+
+	return calendarObject{toDo: updatedToDo}, nil
+}
+
+// calendarObjects returns all ToDo items and their tasks as calendar objects,
+// ordered by the ID of the ToDo items.
+func (r *RESTController) calendarObjects() ([]calendarObject, error) {
+	toDos, err := r.app.ExportToDos("")
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []calendarObject
+
+	for _, toDo := range toDos {
+		objects = append(objects, calendarObject{toDo: toDo})
+
+		for i := range toDo.Tasks {
+			objects = append(objects, calendarObject{toDo: toDo, task: &toDo.Tasks[i]})
+		}
+	}
+
+	return objects, nil
+}
+
+// calendars returns the calendar collections, which are the default calendar
+// and a calendar for each project, ordered by name.
+func calendars(objects []calendarObject) []davCalendar {
+	var (
+		names   = []string{defaultCalendar}
+		members = make(map[string][]calendarObject)
+	)
+
+	for _, object := range objects {
+		name := object.calendar()
+		if _, exists := members[name]; !exists && name != defaultCalendar {
+			names = append(names, name)
+		}
+		members[name] = append(members[name], object)
+	}
+
+	sort.Strings(names)
+
+	result := make([]davCalendar, len(names))
+	for i, name := range names {
+		result[i] = davCalendar{name: name, objects: members[name]}
+	}
+
+	return result
+}
+
+// findResource returns the resource addressed by a path.
+func findResource(objects []calendarObject, path davPath) (davResource, bool) {
+	if path.calendar == "" {
+		return davHome{}, true
+	}
+
+	for _, calendar := range calendars(objects) {
+		if calendar.name != path.calendar {
+			continue
+		}
+
+		if path.object == "" {
+			return calendar, true
+		}
+
+		for _, object := range calendar.objects {
+			if object.uid() == path.object {
+				return object, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// findObjectByHref returns the calendar object addressed by an href, which may
+// be a path or an absolute URL.
+func findObjectByHref(objects []calendarObject, href string) (calendarObject, bool) {
+	parsedURL, err := url.Parse(href)
+	if err != nil {
+		return calendarObject{}, false
+	}
+
+	path, isValid := parseDAVPath(parsedURL.EscapedPath())
+	if !isValid || path.object == "" {
+		return calendarObject{}, false
+	}
+
+	resource, isFound := findResource(objects, path)
+	if !isFound {
+		return calendarObject{}, false
+	}
+
+	return resource.(calendarObject), true
+}
+
+// findObject returns the calendar object with the given UID in any calendar.
+func findObject(objects []calendarObject, uid string) (calendarObject, bool) {
+	for _, object := range objects {
+		if object.uid() == uid {
+			return object, true
+		}
+	}
+
+	return calendarObject{}, false
+}
+
+// propResponse returns a response with the requested properties of a resource,
+// or all of its properties for an allprop request.
+func propResponse(resource davResource, names []xml.Name, allProp bool) davResponse {
+	response := davResponse{href: resource.href()}
+
+	if allProp {
+		names = append(resource.allProps(), names...)
+	}
+
+	for _, name := range names {
+		if value, isFound := resource.property(name); isFound {
+			response.found = append(response.found, renderElement(name, value))
+		} else {
+			response.missing = append(response.missing, renderElement(name, ""))
+		}
+	}
+
+	return response
+}
+
+// preconditionFailed evaluates the `If-Match` and `If-None-Match` headers of a
+// request against the current ETag of a resource, which is empty if it doesn't
+// exist. It reports whether the request must be rejected.
+func preconditionFailed(request *http.Request, etag string) bool {
+	if ifMatch := request.Header.Get("If-Match"); ifMatch != "" {
+		if etag == "" || (ifMatch != "*" && !etagListContains(ifMatch, etag)) {
+			return true
+		}
+	}
+
+	if ifNoneMatch := request.Header.Get("If-None-Match"); ifNoneMatch != "" && etag != "" {
+		if ifNoneMatch == "*" || etagListContains(ifNoneMatch, etag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// etagListContains reports whether a comma-separated list of ETags contains the
+// given ETag, using the weak comparison.
+func etagListContains(list, etag string) bool {
+	for _, item := range strings.Split(list, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(item), "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,173 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files of the CalDAV tests")
+
+// goldenHeaders are the response headers compared with the golden files.
+var goldenHeaders = []string{"Allow", "Content-Type", "DAV", "ETag"}
+
+// dtStampPattern matches the DTSTAMP properties of calendar data, which contain
+// the time of the request.
+var dtStampPattern = regexp.MustCompile(`DTSTAMP:\d{8}T\d{6}Z`)
+
+// TestRESTController_CalDAV replays the requests of calendar clients found in
+// testdata/caldav. Each directory contains the requests of a client in the
+// order it sends them, modelled after the requests of that client. They are
+// run against a fresh set of ToDo items, and each response is compared with
+// the .golden file of the request. Run the tests with -update to rewrite the
+// golden files after changing the responses.
+func TestRESTController_CalDAV(t *testing.T) {
+	clients, err := filepath.Glob(filepath.Join("testdata", "caldav", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(clients) == 0 {
+		t.Fatal("no CalDAV fixtures found")
+	}
+
+	for _, client := range clients {
+		client := client
+
+		t.Run(filepath.Base(client), func(t *testing.T) {
+			router := newCalDAVRouter(t)
+
+			fixtures, err := filepath.Glob(filepath.Join(client, "*.http"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sort.Strings(fixtures)
+
+			for _, fixture := range fixtures {
+				request := readFixture(t, fixture)
+				recorder := httptest.NewRecorder()
+
+				router.ServeHTTP(recorder, request)
+
+				actual := formatResponse(recorder)
+				goldenFile := strings.TrimSuffix(fixture, ".http") + ".golden"
+
+				if *updateGolden {
+					if err := ioutil.WriteFile(goldenFile, []byte(actual), 0644); err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+
+				expected, err := ioutil.ReadFile(goldenFile)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if actual != string(expected) {
+					t.Errorf("%s: unexpected response\n--- expected\n%s\n--- actual\n%s", fixture, expected, actual)
+				}
+			}
+		})
+	}
+}
+
+// newCalDAVRouter returns a router serving CalDAV requests for the following
+// ToDo items: A ToDo item with two tasks in the Work project, and a ToDo item
+// without project in the default calendar.
+func newCalDAVRouter(t *testing.T) chi.Router {
+	restController := newTestRESTController(t)
+
+	toDos := []model.ToDo{
+		{
+			Name:     "Release 1.0",
+			Project:  "Work",
+			Priority: "A",
+			Tags:     []string{"release"},
+			Tasks: []model.Task{
+				{Name: "Write changelog"},
+				{Name: "Tag commit", Done: true},
+			},
+		},
+		{Name: "Buy milk", Description: "Oat milk"},
+	}
+
+	for _, toDo := range toDos {
+		if _, err := restController.app.CreateToDo(toDo); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	chi.RegisterMethod("PROPFIND")
+	chi.RegisterMethod("REPORT")
+
+	router := chi.NewRouter()
+	router.Handle("/caldav", restController.CalDAV())
+	router.Handle("/caldav/*", restController.CalDAV())
+
+	return router
+}
+
+// readFixture reads a request consisting of the request line, the headers, an
+// empty line and the body. Lines may end with LF only, and the body is sent with
+// CRLF line endings like calendar clients do.
+func readFixture(t *testing.T, filename string) *http.Request {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	head, body := text, ""
+
+	if i := strings.Index(text, "\n\n"); i >= 0 {
+		head, body = text[:i], text[i+2:]
+	}
+
+	head = strings.ReplaceAll(head, "\n", "\r\n") + "\r\n\r\n"
+
+	request, err := http.ReadRequest(bufio.NewReader(strings.NewReader(head)))
+	if err != nil {
+		t.Fatalf("%s: %s", filename, err.Error())
+	}
+
+	body = strings.ReplaceAll(body, "\n", "\r\n")
+	request.Body = ioutil.NopCloser(strings.NewReader(body))
+	request.ContentLength = int64(len(body))
+
+	return request
+}
+
+// formatResponse formats the status, the compared headers and the body of a
+// response, replacing the DTSTAMP values that depend on the current time.
+func formatResponse(recorder *httptest.ResponseRecorder) string {
+	var builder strings.Builder
+
+	_, _ = fmt.Fprintf(&builder, "%d %s\n", recorder.Code, http.StatusText(recorder.Code))
+
+	for _, header := range goldenHeaders {
+		if value := recorder.Header().Get(header); value != "" {
+			_, _ = fmt.Fprintf(&builder, "%s: %s\n", header, value)
+		}
+	}
+
+	builder.WriteString("\n")
+	builder.WriteString(dtStampPattern.ReplaceAllString(recorder.Body.String(), "DTSTAMP:19700101T000000Z"))
+
+	return strings.ReplaceAll(builder.String(), "\r\n", "\n")
+}
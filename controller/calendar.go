@@ -0,0 +1,177 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/dominikbraun/todo/ical"
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+)
+
+// calendarContentType is the content type of iCalendar files.
+const calendarContentType = "text/calendar; charset=utf-8"
+
+// calendarFeedResponse is a calendar feed along with its absolute URL.
+type calendarFeedResponse struct {
+	model.CalendarFeed
+	URL string `json:"url"`
+}
+
+// ExportICal processes a GET request for exporting all ToDo items as an
+// iCalendar file with a VTODO component for each ToDo item and task.
+//
+// If the `filter` query parameter is set, only the ToDo items matching the
+// filter query will be exported.
+func (r *RESTController) ExportICal() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		toDos, err := r.app.ExportToDos(request.URL.Query().Get("filter"))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		writer.Header().Set("Content-Type", calendarContentType)
+		writer.Header().Set("Content-Disposition", `attachment; filename="todo.ics"`)
+
+		_ = ical.Encode(writer, toDos, time.Now())
+	}
+}
+
+// ImportICal processes a POST request for importing the VTODO components of an
+// iCalendar file sent as request body. The response lists the created items.
+//
+// If the `dry_run` query parameter is true, the items are only validated and
+// listed the way they would be created, without storing them.
+func (r *RESTController) ImportICal() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if err := checkAccept(request); err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		dryRun, err := parseDryRun(request)
+		if err != nil {
+			respond(writer, request, http.StatusBadRequest, err)
+			return
+		}
+
+		toDos, err := ical.Decode(http.MaxBytesReader(writer, request.Body, maxImportSize))
+		if err != nil {
+			respond(writer, request, http.StatusBadRequest, err)
+			return
+		}
+
+		result, err := r.app.ImportToDos(toDos, dryRun)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		status := http.StatusCreated
+		if dryRun {
+			status = http.StatusOK
+		}
+
+		respond(writer, request, status, result)
+	}
+}
+
+// CreateCalendarFeed processes a POST request for creating a secret calendar
+// feed URL for the requesting user. An existing feed URL of the user stops
+// working. The request body is optional and may contain a filter query like
+// `{"filter": "NOT done"}`.
+//
+// Expects the `X-User` header.
+func (r *RESTController) CreateCalendarFeed() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		var options struct {
+			Filter string `json:"filter"`
+		}
+
+		if err := decodeBody(request, &options); err != nil && err != io.EOF {
+			respond(writer, request, statusCodeForBody(err), err)
+			return
+		}
+
+		feed, err := r.app.CreateCalendarFeed(request.Header.Get(userHeader), options.Filter)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusCreated, calendarFeedResponse{
+			CalendarFeed: feed,
+			URL:          calendarFeedURL(request, feed),
+		})
+	}
+}
+
+// GetCalendarFeed processes a GET request for retrieving the calendar feed of
+// the requesting user.
+//
+// Expects the `X-User` header.
+func (r *RESTController) GetCalendarFeed() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		feed, err := r.app.GetCalendarFeed(request.Header.Get(userHeader))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, calendarFeedResponse{
+			CalendarFeed: feed,
+			URL:          calendarFeedURL(request, feed),
+		})
+	}
+}
+
+// DeleteCalendarFeed processes a DELETE request for deleting the calendar feed
+// of the requesting user, which makes its URL stop working.
+//
+// Expects the `X-User` header.
+func (r *RESTController) DeleteCalendarFeed() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		err := r.app.DeleteCalendarFeed(request.Header.Get(userHeader))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, nil)
+	}
+}
+
+// GetCalendarFeedICal processes a GET request for a calendar feed. Since the
+// token in the URL authorizes the request, no `X-User` header is required.
+//
+// Expects the `token` URL parameter.
+func (r *RESTController) GetCalendarFeedICal() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		toDos, err := r.app.GetCalendarFeedToDos(chi.URLParam(request, "token"))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		writer.Header().Set("Content-Type", calendarContentType)
+		writer.Header().Set("Cache-Control", "private, no-cache")
+
+		_ = ical.Encode(writer, toDos, time.Now())
+	}
+}
+
+// calendarFeedURL returns the absolute URL of a calendar feed, assuming that
+// the server is reachable under the host the request has been sent to.
+func calendarFeedURL(request *http.Request, feed model.CalendarFeed) string {
+	scheme := "http"
+	if request.TLS != nil || request.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	return scheme + "://" + request.Host + "/calendar/" + feed.Token + ".ics"
+}
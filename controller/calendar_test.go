@@ -0,0 +1,172 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/ical"
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+)
+
+// newCalendarRouter returns a router serving the iCalendar routes.
+func newCalendarRouter(restController *RESTController) chi.Router {
+	router := chi.NewRouter()
+	router.Get("/export/ical", restController.ExportICal())
+	router.Post("/import/ical", restController.ImportICal())
+	router.Post("/calendar/feed", restController.CreateCalendarFeed())
+	router.Get("/calendar/feed", restController.GetCalendarFeed())
+	router.Delete("/calendar/feed", restController.DeleteCalendarFeed())
+	router.Get("/calendar/{token}.ics", restController.GetCalendarFeedICal())
+
+	return router
+}
+
+func TestRESTController_ICal(t *testing.T) {
+	restController := newTestRESTController(t)
+	router := newCalendarRouter(restController)
+
+	file := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//test//EN\r\n" +
+		"BEGIN:VTODO\r\nUID:1\r\nDTSTAMP:20261018T120000Z\r\nSUMMARY:Release 1.0\r\n" +
+		"DUE;VALUE=DATE:20261101\r\nRRULE:FREQ=MONTHLY\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:2\r\nDTSTAMP:20261018T120000Z\r\nSUMMARY:Write notes\r\n" +
+		"RELATED-TO:1\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:3\r\nDTSTAMP:20261018T120000Z\r\nSUMMARY:Call mom\r\n" +
+		"STATUS:COMPLETED\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+
+	tests := []struct {
+		target   string
+		body     string
+		expected int
+	}{
+		{"/import/ical?dry_run=maybe", file, http.StatusBadRequest},
+		{"/import/ical", "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n", http.StatusBadRequest},
+		{"/import/ical", strings.Replace(file, "SUMMARY:Call mom", "SUMMARY:", 1), http.StatusUnprocessableEntity},
+		{"/import/ical?dry_run=true", file, http.StatusOK},
+		{"/import/ical", file, http.StatusCreated},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest("POST", test.target, strings.NewReader(test.body))
+		recorder := httptest.NewRecorder()
+
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.expected {
+			t.Fatalf("%s: expected status %d, got %d", test.target, test.expected, recorder.Code)
+		}
+
+		if recorder.Code >= http.StatusBadRequest {
+			continue
+		}
+
+		var result model.ImportResult
+
+		if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+			t.Fatal("could not parse response body")
+		}
+
+		if len(result.ToDos) != 2 || len(result.ToDos[0].Tasks) != 1 || result.ToDos[0].Recurrence != "FREQ=MONTHLY" {
+			t.Errorf("%s: expected 2 ToDo items, the first one recurring with 1 task, got %v", test.target, result.ToDos)
+		}
+
+		// The dry run must not have created any items.
+		if stored, _ := restController.app.GetToDos(); result.DryRun && len(stored) != 0 {
+			t.Errorf("expected no ToDo items after dry run, got %d", len(stored))
+		}
+	}
+
+	request := httptest.NewRequest("GET", "/export/ical?filter=done", nil)
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK || !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/calendar") {
+		t.Fatalf("expected status %d with text/calendar, got %d with %s", http.StatusOK, recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+
+	toDos, err := ical.Decode(recorder.Body)
+	if err != nil {
+		t.Fatalf("exported file is invalid: %s", err.Error())
+	}
+
+	if len(toDos) != 1 || toDos[0].Name != "Call mom" || !toDos[0].Done {
+		t.Errorf("expected only %s, got %v", "Call mom", toDos)
+	}
+}
+
+func TestRESTController_CalendarFeed(t *testing.T) {
+	restController := newTestRESTController(t)
+	router := newCalendarRouter(restController)
+
+	_, _ = restController.app.CreateToDo(model.ToDo{Name: "ToDo 1"})
+	_, _ = restController.app.CreateToDo(model.ToDo{Name: "ToDo 2", Done: true})
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, target, strings.NewReader(body))
+		request.Header.Set(userHeader, "alice")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	if recorder := serve("GET", "/calendar/feed", ""); recorder.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d before creating a feed, got %d", http.StatusNotFound, recorder.Code)
+	}
+
+	if recorder := serve("POST", "/calendar/feed", `{"filter": "due<"}`); recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d for invalid filter, got %d", http.StatusBadRequest, recorder.Code)
+	}
+
+	recorder := serve("POST", "/calendar/feed", `{"filter": "NOT done"}`)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, recorder.Code)
+	}
+
+	var feed calendarFeedResponse
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &feed); err != nil {
+		t.Fatal("could not parse response body")
+	}
+
+	if expected := "http://example.com/calendar/" + feed.Token + ".ics"; feed.URL != expected {
+		t.Errorf("expected URL %s, got %s", expected, feed.URL)
+	}
+
+	// The feed is readable without X-User header.
+	request := httptest.NewRequest("GET", "/calendar/"+feed.Token+".ics", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "SUMMARY:ToDo 1\r\n") ||
+		strings.Contains(recorder.Body.String(), "ToDo 2") {
+		t.Fatalf("expected feed with ToDo 1 only, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	if recorder := serve("DELETE", "/calendar/feed", ""); recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	if recorder := serve("GET", "/calendar/"+feed.Token+".ics", ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for deleted feed, got %d", http.StatusNotFound, recorder.Code)
+	}
+
+	// Feeds can be created without request body.
+	request = httptest.NewRequest("POST", "/calendar/feed", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d without user, got %d", http.StatusBadRequest, recorder.Code)
+	}
+
+	if recorder := serve("POST", "/calendar/feed", ""); recorder.Code != http.StatusCreated {
+		t.Errorf("expected status %d without body, got %d", http.StatusCreated, recorder.Code)
+	}
+}
@@ -0,0 +1,57 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/dominikbraun/todo/codec"
+)
+
+// formType is the content type curl sends for data passed with `-d`. Since the
+// API doesn't accept forms, such bodies are read as JSON.
+const formType = "application/x-www-form-urlencoded"
+
+// decodeBody decodes the request body into v using the codec for the request's
+// Content-Type. An empty body results in io.EOF.
+//
+// The Accept header is checked as well, so that requests whose response can't
+// be encoded in an accepted media type fail before changing any data.
+func decodeBody(request *http.Request, v interface{}) error {
+	if err := checkAccept(request); err != nil {
+		return err
+	}
+
+	contentType := request.Header.Get("Content-Type")
+
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType == formType {
+		contentType = ""
+	}
+
+	c, err := codec.ForContentType(contentType)
+	if err != nil {
+		return err
+	}
+
+	return c.Decode(request.Body, v)
+}
+
+// checkAccept returns codec.ErrNotAcceptable if the response to a request can't
+// be encoded in any media type the request accepts.
+func checkAccept(request *http.Request) error {
+	_, err := codec.ForAccept(request.Header.Get("Accept"))
+	return err
+}
+
+// statusCodeForBody returns the HTTP status code for an error returned by
+// decodeBody. Errors other than unsupported media types indicate an invalid
+// body.
+func statusCodeForBody(err error) int {
+	if errors.Is(err, codec.ErrUnsupportedMediaType) || errors.Is(err, codec.ErrNotAcceptable) {
+		return statusCodeForError(err)
+	}
+
+	return http.StatusUnprocessableEntity
+}
@@ -0,0 +1,126 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/codec"
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+)
+
+func TestRESTController_Codecs(t *testing.T) {
+	restController := newTestRESTController(t)
+
+	router := chi.NewRouter()
+	router.Post("/todos", restController.CreateToDo())
+	router.Get("/todos/{id}", restController.GetToDo())
+
+	for _, c := range []codec.Codec{codec.JSON, codec.YAML, codec.XML, codec.MessagePack, codec.CBOR} {
+		contentType := codec.ContentType(c)
+
+		var body bytes.Buffer
+
+		toDo := model.ToDo{Name: "Release 1.0", Tags: []string{"release"}, Tasks: []model.Task{{Name: "Tag commit"}}}
+
+		if err := c.Encode(&body, toDo); err != nil {
+			t.Fatal(err)
+		}
+
+		request := httptest.NewRequest("POST", "/todos", &body)
+		request.Header.Set("Content-Type", contentType)
+		request.Header.Set("Accept", contentType)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != contentType {
+			t.Fatalf("%s: expected status %d, got %d with type %s: %s", contentType, http.StatusOK, recorder.Code, recorder.Header().Get("Content-Type"), recorder.Body.String())
+		}
+
+		var createdToDo model.ToDo
+
+		if err := c.Decode(recorder.Body, &createdToDo); err != nil {
+			t.Fatalf("%s: %s", contentType, err.Error())
+		}
+
+		if createdToDo.ID == 0 || createdToDo.Name != toDo.Name || len(createdToDo.Tasks) != 1 || createdToDo.Tags[0] != "release" {
+			t.Errorf("%s: unexpected ToDo item %v", contentType, createdToDo)
+		}
+
+		request = httptest.NewRequest("GET", "/todos/1000", nil)
+		request.Header.Set("Accept", contentType)
+
+		recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		var response model.Problem
+
+		if err := c.Decode(recorder.Body, &response); err != nil {
+			t.Fatalf("%s: %s", contentType, err.Error())
+		}
+
+		if recorder.Code != http.StatusNotFound || response.Detail == "" {
+			t.Errorf("%s: expected status %d with error, got %d with %v", contentType, http.StatusNotFound, recorder.Code, response)
+		}
+	}
+}
+
+func TestRESTController_Codecs_Negotiation(t *testing.T) {
+	restController := newTestRESTController(t)
+
+	router := chi.NewRouter()
+	router.Post("/todos", restController.CreateToDo())
+	router.Get("/todos/{id}", restController.GetToDo())
+
+	tests := []struct {
+		method       string
+		path         string
+		contentType  string
+		accept       string
+		body         string
+		expected     int
+		responseType string
+	}{
+		{"POST", "/todos", "text/plain", "", `{"name": "ToDo 1"}`, http.StatusUnsupportedMediaType, problemType},
+		{"POST", "/todos", "application/json", "text/html", `{"name": "ToDo 1"}`, http.StatusNotAcceptable, problemType},
+		{"POST", "/todos", "application/yaml", "", "name: [", http.StatusUnprocessableEntity, problemType},
+		{"POST", "/todos", "application/x-www-form-urlencoded", "text/yaml", `{"name": "ToDo 1"}`, http.StatusOK, "application/yaml; charset=utf-8"},
+		{"GET", "/todos/1", "", "text/html, application/*;q=0.5", "", http.StatusOK, "application/json; charset=utf-8"},
+		{"GET", "/todos/1", "", "text/html", "", http.StatusNotAcceptable, problemType},
+		{"GET", "/todos/2", "", "text/html", "", http.StatusNotFound, problemType},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		request.Header.Set("Content-Type", test.contentType)
+		request.Header.Set("Accept", test.accept)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.expected || recorder.Header().Get("Content-Type") != test.responseType {
+			t.Errorf("%s %s (%s, %s): expected status %d with type %s, got %d with type %s: %s", test.method, test.path, test.contentType,
+				test.accept, test.expected, test.responseType, recorder.Code, recorder.Header().Get("Content-Type"), recorder.Body.String())
+		}
+
+		if recorder.Code >= http.StatusBadRequest {
+			var response model.Problem
+			if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil || response.Detail == "" {
+				t.Errorf("%s %s: expected JSON error, got %s", test.method, test.path, recorder.Body.String())
+			}
+		}
+	}
+
+	// The request accepting no supported type must not create an item.
+	if toDos, _ := restController.app.GetToDos(); len(toDos) != 1 {
+		t.Errorf("expected 1 ToDo item, got %d", len(toDos))
+	}
+}
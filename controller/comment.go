@@ -0,0 +1,150 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+)
+
+// CreateComment processes a POST request for adding a comment to the thread of
+// a ToDo item or task. It expects a comment without ID and returns a comment
+// containing the ID and timestamps.
+//
+// Expects the `id` URL parameter and optionally the `taskID` URL parameter.
+func (r *RESTController) CreateComment() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		toDoID, taskID, err := threadParams(request)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		var comment model.Comment
+
+		if err := decodeBody(request, &comment); err != nil {
+			respond(writer, request, statusCodeForBody(err), err)
+			return
+		}
+
+		createdComment, err := r.app.CreateComment(toDoID, taskID, comment)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, createdComment)
+	}
+}
+
+// GetComments processes a GET request for listing all comments in the thread
+// of a ToDo item or task.
+//
+// Expects the `id` URL parameter and optionally the `taskID` URL parameter.
+func (r *RESTController) GetComments() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		toDoID, taskID, err := threadParams(request)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		comments, err := r.app.GetComments(toDoID, taskID)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, comments)
+	}
+}
+
+// UpdateComment processes a PUT request for editing a comment. The author and
+// body of the comment with the given ID will be overridden.
+//
+// Expects the `id` and `commentID` URL parameters and optionally the `taskID`
+// URL parameter.
+func (r *RESTController) UpdateComment() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		toDoID, taskID, err := threadParams(request)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		id, err := strconv.Atoi(chi.URLParam(request, "commentID"))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		var comment model.Comment
+
+		if err := decodeBody(request, &comment); err != nil {
+			respond(writer, request, statusCodeForBody(err), err)
+			return
+		}
+
+		err = r.app.UpdateComment(toDoID, taskID, int64(id), comment)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, nil)
+	}
+}
+
+// DeleteComment processes a DELETE request for deleting a single comment.
+//
+// Expects the `id` and `commentID` URL parameters and optionally the `taskID`
+// URL parameter.
+func (r *RESTController) DeleteComment() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		toDoID, taskID, err := threadParams(request)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		id, err := strconv.Atoi(chi.URLParam(request, "commentID"))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		err = r.app.DeleteComment(toDoID, taskID, int64(id))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, nil)
+	}
+}
+
+// threadParams parses the `id` and `taskID` URL parameters identifying the
+// thread of a ToDo item or task. If there is no `taskID` parameter, the task
+// ID will be 0 and thus refer to the ToDo item itself.
+func threadParams(request *http.Request) (int64, int64, error) {
+	toDoID, err := strconv.Atoi(chi.URLParam(request, "id"))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	taskIDParam := chi.URLParam(request, "taskID")
+	if taskIDParam == "" {
+		return int64(toDoID), 0, nil
+	}
+
+	taskID, err := strconv.Atoi(taskIDParam)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return int64(toDoID), int64(taskID), nil
+}
@@ -0,0 +1,134 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+)
+
+func TestRESTController_CreateComment(t *testing.T) {
+	restController := newTestRESTController(t)
+	toDo := model.ToDo{
+		Name: "ToDo 1",
+		Tasks: []model.Task{
+			{
+				Name: "Task 1",
+			},
+		},
+	}
+
+	createdToDo, _ := restController.app.CreateToDo(toDo)
+
+	comment := model.Comment{
+		Author: "Alice",
+		Body:   "**Markdown** comment",
+	}
+
+	commentBytes, _ := json.Marshal(&comment)
+	body := bytes.NewReader(commentBytes)
+
+	target := fmt.Sprintf("/todos/%d/tasks/%d/comments", createdToDo.ID, createdToDo.Tasks[0].ID)
+	request := httptest.NewRequest("POST", target, body)
+	recorder := httptest.NewRecorder()
+
+	router := chi.NewRouter()
+	router.Post("/todos/{id}/tasks/{taskID}/comments", restController.CreateComment())
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	var response model.Comment
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal("could not parse response body")
+	}
+
+	if response.TaskID != createdToDo.Tasks[0].ID {
+		t.Errorf("expected task ID %d, got %d", createdToDo.Tasks[0].ID, response.TaskID)
+	}
+}
+
+func TestRESTController_GetComments(t *testing.T) {
+	restController := newTestRESTController(t)
+	toDo := model.ToDo{
+		Name: "ToDo 1",
+	}
+
+	createdToDo, _ := restController.app.CreateToDo(toDo)
+
+	comments := []model.Comment{
+		{
+			Author: "Alice",
+			Body:   "Comment 1",
+		},
+		{
+			Author: "Bob",
+			Body:   "Comment 2",
+		},
+	}
+
+	for _, comment := range comments {
+		_, _ = restController.app.CreateComment(createdToDo.ID, 0, comment)
+	}
+
+	target := fmt.Sprintf("/todos/%d/comments", createdToDo.ID)
+	request := httptest.NewRequest("GET", target, nil)
+	recorder := httptest.NewRecorder()
+
+	router := chi.NewRouter()
+	router.Get("/todos/{id}/comments", restController.GetComments())
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	var response []model.Comment
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal("could not parse response body")
+	}
+
+	if len(response) != len(comments) {
+		t.Fatalf("expected %d comments, got %d", len(comments), len(response))
+	}
+}
+
+func TestRESTController_DeleteComment(t *testing.T) {
+	restController := newTestRESTController(t)
+	toDo := model.ToDo{
+		Name: "ToDo 1",
+	}
+
+	createdToDo, _ := restController.app.CreateToDo(toDo)
+	createdComment, _ := restController.app.CreateComment(createdToDo.ID, 0, model.Comment{
+		Author: "Alice",
+		Body:   "Comment 1",
+	})
+
+	target := fmt.Sprintf("/todos/%d/comments/%d", createdToDo.ID, createdComment.ID)
+	request := httptest.NewRequest("DELETE", target, nil)
+	recorder := httptest.NewRecorder()
+
+	router := chi.NewRouter()
+	router.Delete("/todos/{id}/comments/{commentID}", restController.DeleteComment())
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+}
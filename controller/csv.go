@@ -0,0 +1,141 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/dominikbraun/todo/todocsv"
+)
+
+var (
+	// errInvalidHeader indicates that the `header` query parameter of a CSV
+	// import is not auto, present or absent.
+	errInvalidHeader = errors.New("header must be one of auto, present, absent")
+
+	// errInvalidDelimiter indicates that the `delimiter` query parameter of a
+	// CSV import is not a single character or tab.
+	errInvalidDelimiter = errors.New("delimiter must be a single character or tab")
+)
+
+// headerModes maps the values of the `header` query parameter to header modes.
+var headerModes = map[string]todocsv.Header{
+	"":        todocsv.HeaderAuto,
+	"auto":    todocsv.HeaderAuto,
+	"present": todocsv.HeaderPresent,
+	"absent":  todocsv.HeaderAbsent,
+}
+
+// ExportCSV processes a GET request for exporting all ToDo items as a CSV file
+// with one row per task.
+//
+// If the `filter` query parameter is set, only the ToDo items matching the
+// filter query will be exported.
+func (r *RESTController) ExportCSV() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		toDos, err := r.app.ExportToDos(request.URL.Query().Get("filter"))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		writer.Header().Set("Content-Disposition", `attachment; filename="todos.csv"`)
+
+		_ = todocsv.Encode(writer, toDos)
+	}
+}
+
+// ImportCSV processes a POST request for importing the ToDo items of a CSV file
+// sent as request body. The response lists the created items and the rows that
+// have been skipped because they are invalid.
+//
+// The `columns` query parameter maps fields to columns like `name:Title,due:4`,
+// `header` is one of auto, present and absent, and `delimiter` overrides the
+// detected delimiter. If `all_or_nothing` is true, no item is created if any
+// row is invalid. If `dry_run` is true, the items are only validated and listed
+// the way they would be created, without storing them.
+func (r *RESTController) ImportCSV() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if err := checkAccept(request); err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		options, err := parseCSVOptions(request)
+		if err != nil {
+			respond(writer, request, http.StatusBadRequest, err)
+			return
+		}
+
+		dryRun, err := parseDryRun(request)
+		if err != nil {
+			respond(writer, request, http.StatusBadRequest, err)
+			return
+		}
+
+		allOrNothing := false
+
+		if value := request.URL.Query().Get("all_or_nothing"); value != "" {
+			if allOrNothing, err = strconv.ParseBool(value); err != nil {
+				respond(writer, request, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		entries, err := todocsv.Decode(http.MaxBytesReader(writer, request.Body, maxImportSize), options)
+		if err != nil {
+			respond(writer, request, http.StatusBadRequest, err)
+			return
+		}
+
+		result, err := r.app.ImportCSV(entries, allOrNothing, dryRun)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		status := http.StatusCreated
+		if dryRun {
+			status = http.StatusOK
+		}
+
+		respond(writer, request, status, result)
+	}
+}
+
+// parseCSVOptions parses the `columns`, `header` and `delimiter` query
+// parameters of a CSV import.
+func parseCSVOptions(request *http.Request) (todocsv.Options, error) {
+	var (
+		query   = request.URL.Query()
+		options todocsv.Options
+		err     error
+	)
+
+	if options.Mapping, err = todocsv.ParseMapping(query.Get("columns")); err != nil {
+		return todocsv.Options{}, err
+	}
+
+	header, isValid := headerModes[query.Get("header")]
+	if !isValid {
+		return todocsv.Options{}, errInvalidHeader
+	}
+
+	options.Header = header
+
+	switch delimiter := query.Get("delimiter"); {
+	case delimiter == "":
+	case delimiter == "tab":
+		options.Delimiter = '\t'
+	case utf8.RuneCountInString(delimiter) == 1 && delimiter != "\"" && delimiter != "\n" && delimiter != "\r":
+		options.Delimiter, _ = utf8.DecodeRuneInString(delimiter)
+	default:
+		return todocsv.Options{}, errInvalidDelimiter
+	}
+
+	return options, nil
+}
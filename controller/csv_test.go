@@ -0,0 +1,108 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+)
+
+func TestRESTController_CSV(t *testing.T) {
+	restController := newTestRESTController(t)
+
+	router := chi.NewRouter()
+	router.Get("/export/csv", restController.ExportCSV())
+	router.Post("/import/csv", restController.ImportCSV())
+
+	file := "Title;Subtask;Due\n" +
+		"Release 1.0;Write notes;2026-11-01\n" +
+		"Release 1.0;Tag commit;\n" +
+		";Orphan;\n" +
+		"Call mom;;\n"
+
+	tests := []struct {
+		target   string
+		body     string
+		expected int
+		toDos    int
+		errors   int
+	}{
+		{"/import/csv?dry_run=maybe", file, http.StatusBadRequest, 0, 0},
+		{"/import/csv?all_or_nothing=maybe", file, http.StatusBadRequest, 0, 0},
+		{"/import/csv?header=sometimes", file, http.StatusBadRequest, 0, 0},
+		{"/import/csv?delimiter=%3B%3B", file, http.StatusBadRequest, 0, 0},
+		{"/import/csv?columns=color:2", file, http.StatusBadRequest, 0, 0},
+		{"/import/csv?header=absent&columns=name:Title", file, http.StatusBadRequest, 0, 0},
+		{"/import/csv?all_or_nothing=true", file, http.StatusUnprocessableEntity, 0, 1},
+		{"/import/csv?dry_run=true", file, http.StatusOK, 2, 1},
+		{"/import/csv?columns=task:Subtask,name:1&delimiter=%3B", file, http.StatusCreated, 2, 1},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest("POST", test.target, strings.NewReader(test.body))
+		recorder := httptest.NewRecorder()
+
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.expected {
+			t.Fatalf("%s: expected status %d, got %d: %s", test.target, test.expected, recorder.Code, recorder.Body.String())
+		}
+
+		if recorder.Code == http.StatusBadRequest {
+			continue
+		}
+
+		var result model.ImportResult
+
+		if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+			t.Fatal("could not parse response body")
+		}
+
+		// Refused imports list the invalid rows as problem details.
+		if recorder.Code == http.StatusUnprocessableEntity {
+			var problem model.Problem
+
+			if err := json.Unmarshal(recorder.Body.Bytes(), &problem); err != nil {
+				t.Fatal("could not parse response body")
+			}
+
+			result.Errors = problem.Rows
+		}
+
+		if len(result.ToDos) != test.toDos || len(result.Errors) != test.errors {
+			t.Errorf("%s: expected %d ToDo items and %d errors, got %v", test.target, test.toDos, test.errors, result)
+		}
+
+		if len(result.Errors) > 0 && result.Errors[0].Row != 4 {
+			t.Errorf("%s: expected an error in row 4, got %v", test.target, result.Errors)
+		}
+	}
+
+	request := httptest.NewRequest("GET", "/export/csv?filter=done:false", nil)
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK || !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("expected status %d with text/csv, got %d with %s", http.StatusOK, recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+
+	rows, err := csv.NewReader(recorder.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The header is followed by a row per task of the first ToDo item and a
+	// row for the second one.
+	if len(rows) != 4 || rows[1][10] != "Write notes" || rows[3][1] != "Call mom" {
+		t.Errorf("unexpected exported rows %v", rows)
+	}
+}
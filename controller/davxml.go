@@ -0,0 +1,221 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// maxDAVRequestSize is the maximum size of a PROPFIND or REPORT request body.
+const maxDAVRequestSize = 1 << 20
+
+// The XML namespaces used by WebDAV, CalDAV and the CalendarServer extensions.
+const (
+	nsDAV            = "DAV:"
+	nsCalDAV         = "urn:ietf:params:xml:ns:caldav"
+	nsCalendarServer = "http://calendarserver.org/ns/"
+)
+
+// davPrefixes are the prefixes of the known namespaces in responses.
+var davPrefixes = map[string]string{
+	nsDAV:            "D",
+	nsCalDAV:         "C",
+	nsCalendarServer: "CS",
+}
+
+// davElement is an XML element that is only identified by its name, like the
+// properties requested in a prop element.
+type davElement struct {
+	XMLName xml.Name
+}
+
+// davProp is a prop element listing properties.
+type davProp struct {
+	Properties []davElement `xml:",any"`
+}
+
+// names returns the names of the listed properties.
+func (p *davProp) names() []xml.Name {
+	if p == nil {
+		return nil
+	}
+
+	names := make([]xml.Name, len(p.Properties))
+	for i, property := range p.Properties {
+		names[i] = property.XMLName
+	}
+
+	return names
+}
+
+// propfindRequest is the body of a PROPFIND request. An empty body is treated
+// like an allprop request.
+type propfindRequest struct {
+	XMLName  xml.Name  `xml:"DAV: propfind"`
+	AllProp  *struct{} `xml:"DAV: allprop"`
+	PropName *struct{} `xml:"DAV: propname"`
+	Prop     *davProp  `xml:"DAV: prop"`
+}
+
+// reportRequest is the body of a calendar-multiget or calendar-query REPORT
+// request. The kind of report is identified by XMLName.
+type reportRequest struct {
+	XMLName xml.Name
+	AllProp *struct{}       `xml:"DAV: allprop"`
+	Prop    *davProp        `xml:"DAV: prop"`
+	Hrefs   []string        `xml:"DAV: href"`
+	Filter  *calendarFilter `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+// calendarFilter is the filter of a calendar-query report.
+type calendarFilter struct {
+	CompFilter compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+// compFilter matches components by their name and properties.
+type compFilter struct {
+	Name         string       `xml:"name,attr"`
+	IsNotDefined *struct{}    `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	CompFilters  []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	PropFilters  []propFilter `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+}
+
+// propFilter matches a property by its existence or its text.
+type propFilter struct {
+	Name         string     `xml:"name,attr"`
+	IsNotDefined *struct{}  `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TextMatch    *textMatch `xml:"urn:ietf:params:xml:ns:caldav text-match"`
+}
+
+// textMatch matches property values containing the given text, ignoring the
+// case. If Negate is yes, values not containing the text are matched.
+type textMatch struct {
+	Text   string `xml:",chardata"`
+	Negate string `xml:"negate-condition,attr"`
+}
+
+// davResponse is a response element of a multi-status response. The found and
+// missing properties are rendered XML elements. If status is set, the response
+// has that status instead of properties, e.g. for unknown hrefs.
+type davResponse struct {
+	href    string
+	found   []string
+	missing []string
+	status  int
+}
+
+// decodeDAVRequest decodes the XML body of a request into v. The returned bool
+// is false if the request has no body.
+func decodeDAVRequest(request *http.Request, v interface{}) (bool, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(request.Body, maxDAVRequestSize))
+	if err != nil {
+		return false, err
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return false, nil
+	}
+
+	return true, xml.Unmarshal(body, v)
+}
+
+// renderElement renders an XML element with the given inner XML. Elements of
+// unknown namespaces declare their namespace themselves.
+func renderElement(name xml.Name, inner string) string {
+	tag, start := name.Local, name.Local
+
+	if prefix, isKnown := davPrefixes[name.Space]; isKnown {
+		tag = prefix + ":" + name.Local
+		start = tag
+	} else {
+		start = fmt.Sprintf(`%s xmlns="%s"`, tag, escapeXML(name.Space))
+	}
+
+	if inner == "" {
+		return "<" + start + "/>"
+	}
+
+	return "<" + start + ">" + inner + "</" + tag + ">"
+}
+
+// escapeXML escapes text for use in XML. Carriage returns are escaped as well,
+// since XML parsers would normalize the CRLF line breaks of iCalendar data.
+func escapeXML(text string) string {
+	return strings.NewReplacer(
+		"&", "&amp;",
+		"<", "&lt;",
+		">", "&gt;",
+		`"`, "&quot;",
+		"\r", "&#13;",
+	).Replace(text)
+}
+
+// hrefElement renders an href element.
+func hrefElement(href string) string {
+	return "<D:href>" + escapeXML(href) + "</D:href>"
+}
+
+// statusLine renders the status of a response or propstat element.
+func statusLine(code int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", code, http.StatusText(code))
+}
+
+// writeMultistatus writes a 207 Multi-Status response.
+func writeMultistatus(writer http.ResponseWriter, responses []davResponse) {
+	var builder strings.Builder
+
+	builder.WriteString(xml.Header)
+	builder.WriteString(`<D:multistatus xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav" xmlns:CS="http://calendarserver.org/ns/">` + "\n")
+
+	for _, response := range responses {
+		builder.WriteString("<D:response>\n" + hrefElement(response.href) + "\n")
+
+		if response.status != 0 {
+			builder.WriteString("<D:status>" + statusLine(response.status) + "</D:status>\n")
+		}
+
+		writePropstat(&builder, response.found, http.StatusOK)
+		writePropstat(&builder, response.missing, http.StatusNotFound)
+
+		builder.WriteString("</D:response>\n")
+	}
+
+	builder.WriteString("</D:multistatus>\n")
+
+	writer.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	writer.WriteHeader(http.StatusMultiStatus)
+	_, _ = io.WriteString(writer, builder.String())
+}
+
+// writePropstat writes a propstat element for the given properties, if any.
+func writePropstat(builder *strings.Builder, properties []string, status int) {
+	if len(properties) == 0 {
+		return
+	}
+
+	builder.WriteString("<D:propstat>\n<D:prop>\n")
+
+	for _, property := range properties {
+		builder.WriteString(property + "\n")
+	}
+
+	builder.WriteString("</D:prop>\n<D:status>" + statusLine(status) + "</D:status>\n</D:propstat>\n")
+}
+
+// writeDAVError writes an error response indicating the precondition or
+// postcondition that has been violated by a request.
+func writeDAVError(writer http.ResponseWriter, status int, condition xml.Name) {
+	writer.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	writer.WriteHeader(status)
+
+	_, _ = io.WriteString(writer, xml.Header+
+		`<D:error xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">`+
+		renderElement(condition, "")+
+		"</D:error>\n")
+}
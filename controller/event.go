@@ -0,0 +1,117 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+var (
+	// errStreamingUnsupported indicates that the response writer can't flush
+	// partial responses, which is required for streaming events.
+	errStreamingUnsupported = errors.New("streaming is not supported")
+)
+
+// heartbeatInterval is the interval in which a comment is sent to idle event
+// streams, preventing proxies from closing the connection.
+const heartbeatInterval = 15 * time.Second
+
+// Events processes a GET request for streaming changes of ToDo items as
+// Server-Sent Events. Each event has its ID, its type (created, updated,
+// deleted or completed) and the event as JSON data.
+//
+// The `todo_id` and `project` query parameters restrict the stream to the
+// events of a single ToDo item or project. If the `Last-Event-ID` header is
+// set, all recent events after that ID are sent first.
+func (r *RESTController) Events() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		flusher, ok := writer.(http.Flusher)
+		if !ok {
+			respond(writer, request, http.StatusInternalServerError, errStreamingUnsupported)
+			return
+		}
+
+		var (
+			query       = request.URL.Query()
+			toDoID      int64
+			lastEventID int64
+			err         error
+		)
+
+		if value := query.Get("todo_id"); value != "" {
+			if toDoID, err = strconv.ParseInt(value, 10, 64); err != nil {
+				respond(writer, request, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		if value := request.Header.Get("Last-Event-ID"); value != "" {
+			if lastEventID, err = strconv.ParseInt(value, 10, 64); err != nil {
+				respond(writer, request, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		project := query.Get("project")
+
+		subscription := r.app.Subscribe(lastEventID)
+		defer subscription.Close()
+
+		writer.Header().Set("Content-Type", "text/event-stream")
+		writer.Header().Set("Cache-Control", "no-cache")
+		writer.Header().Set("Connection", "keep-alive")
+		writer.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-request.Context().Done():
+				return
+			case <-heartbeat.C:
+				_, _ = fmt.Fprint(writer, ": heartbeat\n\n")
+				flusher.Flush()
+			case event, ok := <-subscription.Events:
+				// The subscription has been dropped because the client fell
+				// too far behind. It will reconnect using Last-Event-ID.
+				if !ok {
+					return
+				}
+
+				if toDoID != 0 && event.ToDoID != toDoID {
+					continue
+				}
+
+				if project != "" && event.ToDo.Project != project {
+					continue
+				}
+
+				if err := writeEvent(writer, event); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// writeEvent writes an event in the Server-Sent Events format.
+func writeEvent(writer http.ResponseWriter, event model.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+
+	return err
+}
@@ -0,0 +1,80 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+)
+
+func TestRESTController_Events(t *testing.T) {
+	restController := newTestRESTController(t)
+
+	router := chi.NewRouter()
+	router.Get("/events", restController.Events())
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	_, _ = restController.app.CreateToDo(model.ToDo{Name: "ToDo 1", Project: "website"})
+	_, _ = restController.app.CreateToDo(model.ToDo{Name: "ToDo 2", Project: "backend"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	request, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/events?project=website", nil)
+	request.Header.Set("Last-Event-ID", "0")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	if contentType := response.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("expected content type %s, got %s", "text/event-stream", contentType)
+	}
+
+	// The stream has been established, so this event is sent live.
+	toDo, _ := restController.app.CreateToDo(model.ToDo{Name: "ToDo 3", Project: "website"})
+	_ = restController.app.DeleteToDo(toDo.ID)
+
+	reader := bufio.NewReader(response.Body)
+
+	expected := []string{"id: 3", "event: created", "data: ", "", "id: 4", "event: deleted"}
+
+	for _, prefix := range expected {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(line, prefix) {
+			t.Fatalf("expected line starting with %q, got %q", prefix, line)
+		}
+	}
+
+	// Resuming after the first event replays the remaining events of the log.
+	request, _ = http.NewRequestWithContext(ctx, "GET", server.URL+"/events?todo_id=2", nil)
+	request.Header.Set("Last-Event-ID", "1")
+
+	response, err = http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	line, _ := bufio.NewReader(response.Body).ReadString('\n')
+
+	if line != "id: 2\n" {
+		t.Errorf("expected replayed event %d, got %q", 2, line)
+	}
+}
@@ -0,0 +1,1170 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+// graphQLSchema is the schema of the GraphQL API. It exposes the same ToDo items
+// as the REST API along with their tasks, comments and attachments.
+const graphQLSchema = `
+	schema {
+		query: Query
+		mutation: Mutation
+	}
+
+	scalar Time
+
+	type Query {
+		todos(filter: String, first: Int, after: String): ToDoConnection!
+		todo(id: ID!): ToDo!
+	}
+
+	type Mutation {
+		createToDo(input: ToDoInput!): ToDo!
+		updateToDo(id: ID!, input: ToDoInput!): ToDo!
+		deleteToDo(id: ID!): ID!
+		addTask(todoId: ID!, version: Int, input: TaskInput!): ToDo!
+		updateTask(todoId: ID!, version: Int, id: ID!, input: TaskInput!): ToDo!
+		deleteTask(todoId: ID!, version: Int, id: ID!): ToDo!
+		addComment(todoId: ID!, taskId: ID, input: CommentInput!): Comment!
+	}
+
+	type ToDoConnection {
+		edges: [ToDoEdge!]!
+		pageInfo: PageInfo!
+		totalCount: Int!
+	}
+
+	type ToDoEdge {
+		cursor: String!
+		node: ToDo!
+	}
+
+	type PageInfo {
+		hasNextPage: Boolean!
+		endCursor: String
+	}
+
+	type ToDo {
+		id: ID!
+		name: String!
+		description: String!
+		done: Boolean!
+		due: Time
+		project: String!
+		priority: String!
+		recurrence: String!
+		tags: [String!]!
+		version: Int!
+		tasks: [Task!]!
+		comments: [Comment!]!
+		attachments: [Attachment!]!
+	}
+
+	type Task {
+		id: ID!
+		name: String!
+		description: String!
+		done: Boolean!
+		comments: [Comment!]!
+		attachments: [Attachment!]!
+	}
+
+	type Comment {
+		id: ID!
+		author: String!
+		body: String!
+		createdAt: Time!
+		updatedAt: Time!
+	}
+
+	type Attachment {
+		id: ID!
+		filename: String!
+		contentType: String!
+		size: Int!
+		createdAt: Time!
+	}
+
+	input ToDoInput {
+		name: String!
+		description: String
+		done: Boolean
+		due: Time
+		project: String
+		priority: String
+		recurrence: String
+		tags: [String!]
+		tasks: [TaskInput!]
+		version: Int
+	}
+
+	input TaskInput {
+		id: ID
+		name: String!
+		description: String
+		done: Boolean
+	}
+
+	input CommentInput {
+		author: String!
+		body: String!
+	}
+`
+
+const (
+	// defaultPageSize is the number of ToDo items returned by the todos query
+	// if the `first` argument is omitted.
+	defaultPageSize = 20
+
+	// maxPageSize is the maximum number of ToDo items returned by a single
+	// todos query.
+	maxPageSize = 100
+
+	// cursorPrefix is prepended to the ToDo ID before encoding it as cursor.
+	cursorPrefix = "todo:"
+
+	// maxQueryDepth is the maximum nesting depth of the fields of a query, which
+	// is enough for the comments of the tasks of a ToDo connection.
+	maxQueryDepth = 8
+)
+
+var (
+	// errInvalidID indicates that a GraphQL ID is not a valid integer ID.
+	errInvalidID = errors.New("id must be an integer")
+
+	// errInvalidCursor indicates that a pagination cursor is malformed.
+	errInvalidCursor = errors.New("cursor is invalid")
+
+	// errInvalidPageSize indicates that the requested page size is out of range.
+	errInvalidPageSize = errors.New("first must be between 0 and 100")
+
+	// errQueryMissing indicates that a GraphQL request doesn't contain a query.
+	errQueryMissing = errors.New("query must not be empty")
+
+	// errGetMutation indicates that an operation other than a query has been
+	// sent with a GET request.
+	errGetMutation = errors.New("only queries can be sent with GET, use POST for mutations")
+
+	// errGraphQLContentType indicates that a POST request doesn't have a JSON
+	// body.
+	errGraphQLContentType = errors.New("content type must be application/json")
+)
+
+// graphQLStatusCodes are the HTTP status codes for errors that originate from
+// the GraphQL controller itself rather than from the core application.
+var graphQLStatusCodes = map[error]int{
+	errInvalidID:       http.StatusBadRequest,
+	errInvalidCursor:   http.StatusBadRequest,
+	errInvalidPageSize: http.StatusBadRequest,
+}
+
+// graphQLRequest is the body of a GraphQL request.
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// loaderKey is the context key under which the toDoLoader of a request is stored.
+type loaderKey struct{}
+
+// GraphQL processes a GraphQL request. Queries can be sent as POST request with
+// a JSON body containing `query`, `operationName` and `variables`, or as GET
+// request with the same fields as query parameters.
+//
+// GET requests may only run queries, so that mutations can't be triggered by
+// links or images on other sites, and POST requests must have the content type
+// `application/json`, which browsers don't send across origins without asking
+// for permission first.
+//
+// The response always has status 200 once the query has been executed. Errors
+// of single fields are listed in the `errors` array of the response, and their
+// `extensions.status` field contains the HTTP status code the REST API would
+// respond with.
+func (r *RESTController) GraphQL() http.HandlerFunc {
+	resolver := &graphQLResolver{app: r.app}
+	schema := graphql.MustParseSchema(graphQLSchema, resolver, graphql.MaxDepth(maxQueryDepth))
+
+	// GET requests are executed against a schema without mutations, so that
+	// they can't change data even if operationType fails to recognize them.
+	querySchema := graphql.MustParseSchema(strings.Replace(graphQLSchema, "mutation: Mutation", "", 1),
+		resolver, graphql.MaxDepth(maxQueryDepth))
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method == http.MethodPost && !isJSON(request) {
+			respond(writer, request, http.StatusUnsupportedMediaType, errGraphQLContentType)
+			return
+		}
+
+		graphQLRequest, err := parseGraphQLRequest(request)
+		if err != nil {
+			respond(writer, request, http.StatusBadRequest, err)
+			return
+		}
+
+		executor := schema
+
+		if request.Method == http.MethodGet {
+			if operation := operationType(graphQLRequest.Query, graphQLRequest.OperationName); operation != "" && operation != "query" {
+				writer.Header().Set("Allow", http.MethodPost)
+				respond(writer, request, http.StatusMethodNotAllowed, errGetMutation)
+				return
+			}
+			executor = querySchema
+		}
+
+		ctx := context.WithValue(request.Context(), loaderKey{}, newToDoLoader(r.app))
+		response := executor.Exec(ctx, graphQLRequest.Query, graphQLRequest.OperationName, graphQLRequest.Variables)
+
+		respond(writer, request, http.StatusOK, response)
+	}
+}
+
+// parseGraphQLRequest reads a GraphQL request from the request body or, for GET
+// requests, from the URL query parameters.
+func parseGraphQLRequest(request *http.Request) (graphQLRequest, error) {
+	var graphQLRequest graphQLRequest
+
+	if request.Method == http.MethodGet {
+		query := request.URL.Query()
+
+		graphQLRequest.Query = query.Get("query")
+		graphQLRequest.OperationName = query.Get("operationName")
+
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &graphQLRequest.Variables); err != nil {
+				return graphQLRequest, err
+			}
+		}
+	} else if err := json.NewDecoder(request.Body).Decode(&graphQLRequest); err != nil {
+		return graphQLRequest, err
+	}
+
+	if strings.TrimSpace(graphQLRequest.Query) == "" {
+		return graphQLRequest, errQueryMissing
+	}
+
+	return graphQLRequest, nil
+}
+
+// isJSON reports whether the body of a request is a JSON document according to
+// its Content-Type header.
+func isJSON(request *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+
+	return err == nil && mediaType == "application/json"
+}
+
+// operationType returns the type of the operation of a GraphQL document that is
+// run for the given operation name, i.e. `query`, `mutation` or `subscription`.
+// If the operation can't be determined, e.g. because the document is invalid,
+// an empty string will be returned.
+func operationType(document, operationName string) string {
+	type operation struct {
+		operationType string
+		name          string
+	}
+
+	var (
+		operations []operation
+		depth      int
+		// keyword is the keyword of the definition whose name is expected
+		// next, and atDefinition reports whether a new definition may start.
+		keyword      string
+		atDefinition = true
+	)
+
+	for i := 0; i < len(document); {
+		c := document[i]
+
+		switch {
+		case c == '#':
+			for i < len(document) && document[i] != '\n' && document[i] != '\r' {
+				i++
+			}
+			continue
+		case c == '"':
+			i = skipString(document, i)
+			continue
+		case c == '{':
+			if depth == 0 && atDefinition {
+				operations = append(operations, operation{operationType: "query"})
+			} else if depth == 0 && keyword != "" {
+				operations = append(operations, operation{operationType: keyword})
+			}
+			keyword, atDefinition = "", false
+			depth++
+		case c == '}':
+			if depth--; depth == 0 {
+				atDefinition = true
+			}
+		case c == '(' || c == '@':
+			if depth == 0 && keyword != "" {
+				operations = append(operations, operation{operationType: keyword})
+				keyword = ""
+			}
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			start := i
+			for i < len(document) && isNameChar(document[i]) {
+				i++
+			}
+			name := document[start:i]
+
+			if depth == 0 {
+				switch {
+				case atDefinition:
+					keyword, atDefinition = name, false
+					if keyword == "fragment" {
+						keyword = ""
+					}
+				case keyword != "":
+					operations = append(operations, operation{operationType: keyword, name: name})
+					keyword = ""
+				}
+			}
+			continue
+		}
+
+		i++
+	}
+
+	for _, op := range operations {
+		if op.name == operationName || operationName == "" && len(operations) == 1 {
+			return op.operationType
+		}
+	}
+
+	return ""
+}
+
+// skipString returns the index after the GraphQL string or block string that
+// starts at the given index.
+func skipString(document string, start int) int {
+	if strings.HasPrefix(document[start:], `"""`) {
+		for i := start + 3; i < len(document); i++ {
+			switch {
+			case strings.HasPrefix(document[i:], `\"""`):
+				i += 3
+			case strings.HasPrefix(document[i:], `"""`):
+				return i + 3
+			}
+		}
+		return len(document)
+	}
+
+	for i := start + 1; i < len(document); i++ {
+		switch document[i] {
+		case '\\':
+			i++
+		case '"', '\n':
+			return i + 1
+		}
+	}
+
+	return len(document)
+}
+
+// isNameChar reports whether a character may be part of a GraphQL name.
+func isNameChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// graphQLError is returned by resolvers. It adds the HTTP status code for the
+// error to the extensions of the GraphQL error.
+type graphQLError struct {
+	err error
+}
+
+// resolverError wraps an error returned by the core application or storage.
+func resolverError(err error) error {
+	return graphQLError{err: err}
+}
+
+func (g graphQLError) Error() string {
+	return g.err.Error()
+}
+
+func (g graphQLError) Unwrap() error {
+	return g.err
+}
+
+// Extensions returns the extensions of the GraphQL error, which contain the
+// HTTP status code of the error.
+func (g graphQLError) Extensions() map[string]interface{} {
+	status, isRegistered := graphQLStatusCodes[g.err]
+	if !isRegistered {
+		status = statusCodeForError(g.err)
+	}
+
+	return map[string]interface{}{
+		"status": status,
+	}
+}
+
+// toDoLoader loads the comments and attachments of ToDo items in batches. All
+// ToDo items resolved during a request are registered with the loader, and the
+// first time the comments or attachments of one of them are requested, they
+// are loaded for all registered items at once. This way, a query for a list of
+// ToDo items and their comments needs a single storage query for the comments.
+//
+// Since GraphQL fields are resolved concurrently, toDoLoader is safe for
+// concurrent use.
+type toDoLoader struct {
+	app               *core.App
+	mutex             sync.Mutex
+	toDoIDs           []int64
+	comments          map[int64][]model.Comment
+	commentsLoaded    map[int64]bool
+	attachments       map[int64][]model.Attachment
+	attachmentsLoaded map[int64]bool
+}
+
+// newToDoLoader creates a new loader for a single request.
+func newToDoLoader(app *core.App) *toDoLoader {
+	return &toDoLoader{
+		app:               app,
+		comments:          make(map[int64][]model.Comment),
+		commentsLoaded:    make(map[int64]bool),
+		attachments:       make(map[int64][]model.Attachment),
+		attachmentsLoaded: make(map[int64]bool),
+	}
+}
+
+// loaderFromContext returns the toDoLoader of the current request.
+func loaderFromContext(ctx context.Context) *toDoLoader {
+	return ctx.Value(loaderKey{}).(*toDoLoader)
+}
+
+// register adds the given ToDo items to the batch loaded by the next call of
+// commentsOf or attachmentsOf.
+func (l *toDoLoader) register(toDos ...model.ToDo) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for _, toDo := range toDos {
+		l.toDoIDs = append(l.toDoIDs, toDo.ID)
+	}
+}
+
+// commentsOf returns the comments of a ToDo item and its tasks.
+func (l *toDoLoader) commentsOf(toDoID int64) ([]model.Comment, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if !l.commentsLoaded[toDoID] {
+		batch := l.batch(l.commentsLoaded, toDoID)
+
+		comments, err := l.app.GetCommentsOfToDos(batch)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, comment := range comments {
+			l.comments[comment.ToDoID] = append(l.comments[comment.ToDoID], comment)
+		}
+
+		for _, id := range batch {
+			l.commentsLoaded[id] = true
+		}
+	}
+
+	return l.comments[toDoID], nil
+}
+
+// attachmentsOf returns the attachments of a ToDo item and its tasks.
+func (l *toDoLoader) attachmentsOf(toDoID int64) ([]model.Attachment, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if !l.attachmentsLoaded[toDoID] {
+		batch := l.batch(l.attachmentsLoaded, toDoID)
+
+		attachments, err := l.app.GetAttachmentsOfToDos(batch)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, attachment := range attachments {
+			l.attachments[attachment.ToDoID] = append(l.attachments[attachment.ToDoID], attachment)
+		}
+
+		for _, id := range batch {
+			l.attachmentsLoaded[id] = true
+		}
+	}
+
+	return l.attachments[toDoID], nil
+}
+
+// batch returns the requested ToDo ID along with all registered IDs that have
+// not been loaded yet.
+func (l *toDoLoader) batch(loaded map[int64]bool, toDoID int64) []int64 {
+	batch := []int64{toDoID}
+
+	for _, id := range l.toDoIDs {
+		if !loaded[id] && id != toDoID {
+			batch = append(batch, id)
+		}
+	}
+
+	return batch
+}
+
+// graphQLResolver is the root resolver of the GraphQL schema, providing the
+// queries and mutations.
+type graphQLResolver struct {
+	app *core.App
+}
+
+// Todos resolves a page of ToDo items ordered by their ID. If a filter query is
+// set, only the ToDo items matching the query are returned.
+func (g *graphQLResolver) Todos(ctx context.Context, args struct {
+	Filter *string
+	First  *int32
+	After  *string
+}) (*toDoConnectionResolver, error) {
+	first := defaultPageSize
+
+	if args.First != nil {
+		if *args.First < 0 || *args.First > maxPageSize {
+			return nil, resolverError(errInvalidPageSize)
+		}
+		first = int(*args.First)
+	}
+
+	var (
+		query string
+		after int64
+	)
+
+	if args.Filter != nil {
+		query = *args.Filter
+	}
+
+	if args.After != nil {
+		cursor, err := decodeCursor(*args.After)
+		if err != nil {
+			return nil, resolverError(err)
+		}
+		after = cursor
+	}
+
+	// Load one more item than requested in order to detect a next page.
+	toDos, totalCount, err := g.app.GetToDoPage(query, after, first+1)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+
+	hasNextPage := len(toDos) > first
+	if hasNextPage {
+		toDos = toDos[:first]
+	}
+
+	loaderFromContext(ctx).register(toDos...)
+
+	return &toDoConnectionResolver{
+		toDos:       toDos,
+		hasNextPage: hasNextPage,
+		totalCount:  totalCount,
+		loader:      loaderFromContext(ctx),
+	}, nil
+}
+
+// Todo resolves a single ToDo item.
+func (g *graphQLResolver) Todo(ctx context.Context, args struct{ ID graphql.ID }) (*toDoResolver, error) {
+	id, err := parseGraphQLID(args.ID)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+
+	toDo, err := g.app.GetToDo(id)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+
+	return newToDoResolver(ctx, toDo), nil
+}
+
+// toDoInput is the input of the createToDo and updateToDo mutations.
+type toDoInput struct {
+	Name        string
+	Description *string
+	Done        *bool
+	Due         *graphql.Time
+	Project     *string
+	Priority    *string
+	Recurrence  *string
+	Tags        *[]string
+	Tasks       *[]taskInput
+	Version     *int32
+}
+
+// taskInput is the input of the task mutations.
+type taskInput struct {
+	ID          *graphql.ID
+	Name        string
+	Description *string
+	Done        *bool
+}
+
+// commentInput is the input of the addComment mutation.
+type commentInput struct {
+	Author string
+	Body   string
+}
+
+// CreateToDo creates a ToDo item.
+func (g *graphQLResolver) CreateToDo(ctx context.Context, args struct{ Input toDoInput }) (*toDoResolver, error) {
+	toDo, err := args.Input.toDo()
+	if err != nil {
+		return nil, resolverError(err)
+	}
+
+	createdToDo, err := g.app.CreateToDo(toDo)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+
+	return newToDoResolver(ctx, createdToDo), nil
+}
+
+// UpdateToDo overrides the ToDo item with the given ID and returns the updated
+// item.
+func (g *graphQLResolver) UpdateToDo(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input toDoInput
+}) (*toDoResolver, error) {
+	id, err := parseGraphQLID(args.ID)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+
+	toDo, err := args.Input.toDo()
+	if err != nil {
+		return nil, resolverError(err)
+	}
+
+	if err := g.app.UpdateToDo(id, toDo); err != nil {
+		return nil, resolverError(err)
+	}
+
+	updatedToDo, err := g.app.GetToDo(id)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+
+	return newToDoResolver(ctx, updatedToDo), nil
+}
+
+// DeleteToDo deletes the ToDo item with the given ID and returns its ID.
+func (g *graphQLResolver) DeleteToDo(args struct{ ID graphql.ID }) (graphql.ID, error) {
+	id, err := parseGraphQLID(args.ID)
+	if err != nil {
+		return "", resolverError(err)
+	}
+
+	if err := g.app.DeleteToDo(id); err != nil {
+		return "", resolverError(err)
+	}
+
+	return args.ID, nil
+}
+
+// AddTask adds a task to a ToDo item and returns the updated item.
+func (g *graphQLResolver) AddTask(ctx context.Context, args struct {
+	TodoID  graphql.ID
+	Version *int32
+	Input   taskInput
+}) (*toDoResolver, error) {
+	return g.editTask(ctx, args.TodoID, args.Version, func(toDoID, version int64) (model.ToDo, error) {
+		task, err := args.Input.task()
+		if err != nil {
+			return model.ToDo{}, err
+		}
+		task.ID = 0
+		return g.app.AddTask(toDoID, version, task)
+	})
+}
+
+// UpdateTask overrides a task of a ToDo item and returns the updated item.
+func (g *graphQLResolver) UpdateTask(ctx context.Context, args struct {
+	TodoID  graphql.ID
+	Version *int32
+	ID      graphql.ID
+	Input   taskInput
+}) (*toDoResolver, error) {
+	return g.editTask(ctx, args.TodoID, args.Version, func(toDoID, version int64) (model.ToDo, error) {
+		task, err := args.Input.task()
+		if err != nil {
+			return model.ToDo{}, err
+		}
+		if task.ID, err = parseGraphQLID(args.ID); err != nil {
+			return model.ToDo{}, err
+		}
+		return g.app.UpdateTask(toDoID, version, task)
+	})
+}
+
+// DeleteTask deletes a task of a ToDo item and returns the updated item.
+func (g *graphQLResolver) DeleteTask(ctx context.Context, args struct {
+	TodoID  graphql.ID
+	Version *int32
+	ID      graphql.ID
+}) (*toDoResolver, error) {
+	return g.editTask(ctx, args.TodoID, args.Version, func(toDoID, version int64) (model.ToDo, error) {
+		taskID, err := parseGraphQLID(args.ID)
+		if err != nil {
+			return model.ToDo{}, err
+		}
+		return g.app.DeleteTask(toDoID, version, taskID)
+	})
+}
+
+// AddComment adds a comment to the thread of a ToDo item or, if the task ID is
+// set, to the thread of the task.
+func (g *graphQLResolver) AddComment(args struct {
+	TodoID graphql.ID
+	TaskID *graphql.ID
+	Input  commentInput
+}) (*commentResolver, error) {
+	toDoID, err := parseGraphQLID(args.TodoID)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+
+	var taskID int64
+
+	if args.TaskID != nil {
+		if taskID, err = parseGraphQLID(*args.TaskID); err != nil {
+			return nil, resolverError(err)
+		}
+	}
+
+	comment, err := g.app.CreateComment(toDoID, taskID, model.Comment{
+		Author: args.Input.Author,
+		Body:   args.Input.Body,
+	})
+	if err != nil {
+		return nil, resolverError(err)
+	}
+
+	return &commentResolver{comment: comment}, nil
+}
+
+// editTask parses the ToDo ID and version of a task mutation and runs the edit.
+func (g *graphQLResolver) editTask(ctx context.Context, toDoID graphql.ID, version *int32, edit func(toDoID, version int64) (model.ToDo, error)) (*toDoResolver, error) {
+	id, err := parseGraphQLID(toDoID)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+
+	var expectedVersion int64
+
+	if version != nil {
+		expectedVersion = int64(*version)
+	}
+
+	toDo, err := edit(id, expectedVersion)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+
+	return newToDoResolver(ctx, toDo), nil
+}
+
+// toDo converts the input to a ToDo item.
+func (t toDoInput) toDo() (model.ToDo, error) {
+	toDo := model.ToDo{
+		Name: t.Name,
+	}
+
+	if t.Description != nil {
+		toDo.Description = *t.Description
+	}
+
+	if t.Done != nil {
+		toDo.Done = *t.Done
+	}
+
+	if t.Due != nil {
+		due := t.Due.UTC().Truncate(time.Second)
+		toDo.Due = &due
+	}
+
+	if t.Project != nil {
+		toDo.Project = *t.Project
+	}
+
+	if t.Priority != nil {
+		toDo.Priority = *t.Priority
+	}
+
+	if t.Recurrence != nil {
+		toDo.Recurrence = *t.Recurrence
+	}
+
+	if t.Tags != nil {
+		toDo.Tags = *t.Tags
+	}
+
+	if t.Version != nil {
+		toDo.Version = int64(*t.Version)
+	}
+
+	if t.Tasks != nil {
+		for _, input := range *t.Tasks {
+			task, err := input.task()
+			if err != nil {
+				return model.ToDo{}, err
+			}
+			toDo.Tasks = append(toDo.Tasks, task)
+		}
+	}
+
+	return toDo, nil
+}
+
+// task converts the input to a task.
+func (t taskInput) task() (model.Task, error) {
+	task := model.Task{
+		Name: t.Name,
+	}
+
+	if t.Description != nil {
+		task.Description = *t.Description
+	}
+
+	if t.Done != nil {
+		task.Done = *t.Done
+	}
+
+	if t.ID != nil {
+		id, err := parseGraphQLID(*t.ID)
+		if err != nil {
+			return model.Task{}, err
+		}
+		task.ID = id
+	}
+
+	return task, nil
+}
+
+// toDoConnectionResolver resolves a page of ToDo items.
+type toDoConnectionResolver struct {
+	toDos       []model.ToDo
+	hasNextPage bool
+	totalCount  int
+	loader      *toDoLoader
+}
+
+func (t *toDoConnectionResolver) Edges() []*toDoEdgeResolver {
+	edges := make([]*toDoEdgeResolver, len(t.toDos))
+
+	for i, toDo := range t.toDos {
+		edges[i] = &toDoEdgeResolver{
+			node: &toDoResolver{toDo: toDo, loader: t.loader},
+		}
+	}
+
+	return edges
+}
+
+func (t *toDoConnectionResolver) PageInfo() *pageInfoResolver {
+	pageInfo := &pageInfoResolver{
+		hasNextPage: t.hasNextPage,
+	}
+
+	if len(t.toDos) > 0 {
+		endCursor := encodeCursor(t.toDos[len(t.toDos)-1].ID)
+		pageInfo.endCursor = &endCursor
+	}
+
+	return pageInfo
+}
+
+func (t *toDoConnectionResolver) TotalCount() int32 {
+	return int32(t.totalCount)
+}
+
+// toDoEdgeResolver resolves a ToDo item within a page along with its cursor.
+type toDoEdgeResolver struct {
+	node *toDoResolver
+}
+
+func (t *toDoEdgeResolver) Cursor() string {
+	return encodeCursor(t.node.toDo.ID)
+}
+
+func (t *toDoEdgeResolver) Node() *toDoResolver {
+	return t.node
+}
+
+// pageInfoResolver resolves the pagination info of a page.
+type pageInfoResolver struct {
+	hasNextPage bool
+	endCursor   *string
+}
+
+func (p *pageInfoResolver) HasNextPage() bool {
+	return p.hasNextPage
+}
+
+func (p *pageInfoResolver) EndCursor() *string {
+	return p.endCursor
+}
+
+// toDoResolver resolves a ToDo item. Its comments and attachments are loaded
+// using the toDoLoader of the request.
+type toDoResolver struct {
+	toDo   model.ToDo
+	loader *toDoLoader
+}
+
+// newToDoResolver creates a resolver for a ToDo item and registers the item
+// with the toDoLoader of the request.
+func newToDoResolver(ctx context.Context, toDo model.ToDo) *toDoResolver {
+	loader := loaderFromContext(ctx)
+	loader.register(toDo)
+
+	return &toDoResolver{
+		toDo:   toDo,
+		loader: loader,
+	}
+}
+
+func (t *toDoResolver) ID() graphql.ID {
+	return formatGraphQLID(t.toDo.ID)
+}
+
+func (t *toDoResolver) Name() string {
+	return t.toDo.Name
+}
+
+func (t *toDoResolver) Description() string {
+	return t.toDo.Description
+}
+
+func (t *toDoResolver) Done() bool {
+	return t.toDo.Done
+}
+
+func (t *toDoResolver) Due() *graphql.Time {
+	if t.toDo.Due == nil {
+		return nil
+	}
+
+	return &graphql.Time{Time: *t.toDo.Due}
+}
+
+func (t *toDoResolver) Project() string {
+	return t.toDo.Project
+}
+
+func (t *toDoResolver) Priority() string {
+	return t.toDo.Priority
+}
+
+func (t *toDoResolver) Recurrence() string {
+	return t.toDo.Recurrence
+}
+
+func (t *toDoResolver) Tags() []string {
+	if t.toDo.Tags == nil {
+		return []string{}
+	}
+
+	return t.toDo.Tags
+}
+
+func (t *toDoResolver) Version() int32 {
+	return int32(t.toDo.Version)
+}
+
+func (t *toDoResolver) Tasks() []*taskResolver {
+	tasks := make([]*taskResolver, len(t.toDo.Tasks))
+
+	for i, task := range t.toDo.Tasks {
+		tasks[i] = &taskResolver{task: task, toDoID: t.toDo.ID, loader: t.loader}
+	}
+
+	return tasks
+}
+
+func (t *toDoResolver) Comments() ([]*commentResolver, error) {
+	return resolveComments(t.loader, t.toDo.ID, 0)
+}
+
+func (t *toDoResolver) Attachments() ([]*attachmentResolver, error) {
+	return resolveAttachments(t.loader, t.toDo.ID, 0)
+}
+
+// taskResolver resolves a task of a ToDo item.
+type taskResolver struct {
+	task   model.Task
+	toDoID int64
+	loader *toDoLoader
+}
+
+func (t *taskResolver) ID() graphql.ID {
+	return formatGraphQLID(t.task.ID)
+}
+
+func (t *taskResolver) Name() string {
+	return t.task.Name
+}
+
+func (t *taskResolver) Description() string {
+	return t.task.Description
+}
+
+func (t *taskResolver) Done() bool {
+	return t.task.Done
+}
+
+func (t *taskResolver) Comments() ([]*commentResolver, error) {
+	return resolveComments(t.loader, t.toDoID, t.task.ID)
+}
+
+func (t *taskResolver) Attachments() ([]*attachmentResolver, error) {
+	return resolveAttachments(t.loader, t.toDoID, t.task.ID)
+}
+
+// resolveComments returns the comment thread of a ToDo item or, if the task ID
+// is not 0, of a task.
+func resolveComments(loader *toDoLoader, toDoID, taskID int64) ([]*commentResolver, error) {
+	comments, err := loader.commentsOf(toDoID)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+
+	resolvers := make([]*commentResolver, 0)
+
+	for _, comment := range comments {
+		if comment.TaskID == taskID {
+			resolvers = append(resolvers, &commentResolver{comment: comment})
+		}
+	}
+
+	return resolvers, nil
+}
+
+// resolveAttachments returns the attachments of a ToDo item or, if the task ID
+// is not 0, of a task.
+func resolveAttachments(loader *toDoLoader, toDoID, taskID int64) ([]*attachmentResolver, error) {
+	attachments, err := loader.attachmentsOf(toDoID)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+
+	resolvers := make([]*attachmentResolver, 0)
+
+	for _, attachment := range attachments {
+		if attachment.TaskID == taskID {
+			resolvers = append(resolvers, &attachmentResolver{attachment: attachment})
+		}
+	}
+
+	return resolvers, nil
+}
+
+// commentResolver resolves a comment.
+type commentResolver struct {
+	comment model.Comment
+}
+
+func (c *commentResolver) ID() graphql.ID {
+	return formatGraphQLID(c.comment.ID)
+}
+
+func (c *commentResolver) Author() string {
+	return c.comment.Author
+}
+
+func (c *commentResolver) Body() string {
+	return c.comment.Body
+}
+
+func (c *commentResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: c.comment.CreatedAt}
+}
+
+func (c *commentResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: c.comment.UpdatedAt}
+}
+
+// attachmentResolver resolves the metadata of an attachment.
+type attachmentResolver struct {
+	attachment model.Attachment
+}
+
+func (a *attachmentResolver) ID() graphql.ID {
+	return formatGraphQLID(a.attachment.ID)
+}
+
+func (a *attachmentResolver) Filename() string {
+	return a.attachment.Filename
+}
+
+func (a *attachmentResolver) ContentType() string {
+	return a.attachment.ContentType
+}
+
+func (a *attachmentResolver) Size() int32 {
+	return int32(a.attachment.Size)
+}
+
+func (a *attachmentResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: a.attachment.CreatedAt}
+}
+
+// parseGraphQLID converts a GraphQL ID to an integer ID.
+func parseGraphQLID(id graphql.ID) (int64, error) {
+	parsed, err := strconv.ParseInt(string(id), 10, 64)
+	if err != nil {
+		return 0, errInvalidID
+	}
+
+	return parsed, nil
+}
+
+// formatGraphQLID converts an integer ID to a GraphQL ID.
+func formatGraphQLID(id int64) graphql.ID {
+	return graphql.ID(strconv.FormatInt(id, 10))
+}
+
+// encodeCursor returns the opaque pagination cursor for a ToDo ID.
+func encodeCursor(id int64) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + strconv.FormatInt(id, 10)))
+}
+
+// decodeCursor returns the ToDo ID encoded in a pagination cursor.
+func decodeCursor(cursor string) (int64, error) {
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(decoded), cursorPrefix) {
+		return 0, errInvalidCursor
+	}
+
+	id, err := strconv.ParseInt(strings.TrimPrefix(string(decoded), cursorPrefix), 10, 64)
+	if err != nil {
+		return 0, errInvalidCursor
+	}
+
+	return id, nil
+}
@@ -0,0 +1,412 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/filter"
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+
+	"github.com/go-chi/chi"
+)
+
+// graphQLResponse is the response body of a GraphQL request.
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string `json:"message"`
+		Extensions struct {
+			Status int `json:"status"`
+		} `json:"extensions"`
+	} `json:"errors"`
+}
+
+// countingStorage is a storage.Storage counting the calls of the functions that
+// load ToDo items or their relations.
+type countingStorage struct {
+	storage.Storage
+	mutex sync.Mutex
+	calls map[string]int
+}
+
+func (c *countingStorage) count(function string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.calls[function]++
+}
+
+func (c *countingStorage) FindToDos() ([]model.ToDo, error) {
+	c.count("FindToDos")
+	return c.Storage.FindToDos()
+}
+
+func (c *countingStorage) FindToDoPage(expression filter.Expression, afterID int64, limit int) ([]model.ToDo, int, error) {
+	c.count("FindToDoPage")
+	return c.Storage.FindToDoPage(expression, afterID, limit)
+}
+
+func (c *countingStorage) FindComments(toDoID, taskID int64) ([]model.Comment, error) {
+	c.count("FindComments")
+	return c.Storage.FindComments(toDoID, taskID)
+}
+
+func (c *countingStorage) FindCommentsByToDoIDs(toDoIDs []int64) ([]model.Comment, error) {
+	c.count("FindCommentsByToDoIDs")
+	return c.Storage.FindCommentsByToDoIDs(toDoIDs)
+}
+
+func (c *countingStorage) FindAttachments(toDoID int64) ([]model.Attachment, error) {
+	c.count("FindAttachments")
+	return c.Storage.FindAttachments(toDoID)
+}
+
+func (c *countingStorage) FindAttachmentsByToDoIDs(toDoIDs []int64) ([]model.Attachment, error) {
+	c.count("FindAttachmentsByToDoIDs")
+	return c.Storage.FindAttachmentsByToDoIDs(toDoIDs)
+}
+
+// postGraphQL sends a GraphQL query to the router and decodes the response.
+func postGraphQL(t *testing.T, router http.Handler, query string, variables map[string]interface{}, data interface{}) graphQLResponse {
+	body, _ := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+
+	request := httptest.NewRequest("POST", "/graphql", strings.NewReader(string(body)))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	var response graphQLResponse
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal("could not parse response body")
+	}
+
+	if data != nil && len(response.Data) > 0 {
+		if err := json.Unmarshal(response.Data, data); err != nil {
+			t.Fatalf("could not parse response data: %s", err.Error())
+		}
+	}
+
+	return response
+}
+
+func TestRESTController_GraphQL_Pagination(t *testing.T) {
+	restController := newTestRESTController(t)
+
+	for _, name := range []string{"ToDo 1", "ToDo 2", "ToDo 3"} {
+		_, _ = restController.app.CreateToDo(model.ToDo{Name: name, Tags: []string{"release"}})
+	}
+	_, _ = restController.app.CreateToDo(model.ToDo{Name: "ToDo 4"})
+
+	router := chi.NewRouter()
+	router.Post("/graphql", restController.GraphQL())
+
+	query := `query($after: String) {
+		todos(filter: "tag:release", first: 2, after: $after) {
+			edges { cursor node { name } }
+			pageInfo { hasNextPage endCursor }
+			totalCount
+		}
+	}`
+
+	var data struct {
+		ToDos struct {
+			Edges []struct {
+				Node struct {
+					Name string `json:"name"`
+				} `json:"node"`
+			} `json:"edges"`
+			PageInfo struct {
+				HasNextPage bool   `json:"hasNextPage"`
+				EndCursor   string `json:"endCursor"`
+			} `json:"pageInfo"`
+			TotalCount int `json:"totalCount"`
+		} `json:"todos"`
+	}
+
+	postGraphQL(t, router, query, nil, &data)
+
+	if len(data.ToDos.Edges) != 2 || data.ToDos.Edges[0].Node.Name != "ToDo 1" {
+		t.Fatalf("expected ToDo 1 and ToDo 2 on the first page, got %v", data.ToDos.Edges)
+	}
+
+	if !data.ToDos.PageInfo.HasNextPage || data.ToDos.TotalCount != 3 {
+		t.Errorf("expected a next page and a total count of %d, got %v", 3, data.ToDos)
+	}
+
+	postGraphQL(t, router, query, map[string]interface{}{"after": data.ToDos.PageInfo.EndCursor}, &data)
+
+	if len(data.ToDos.Edges) != 1 || data.ToDos.Edges[0].Node.Name != "ToDo 3" {
+		t.Fatalf("expected only ToDo 3 on the second page, got %v", data.ToDos.Edges)
+	}
+
+	if data.ToDos.PageInfo.HasNextPage {
+		t.Errorf("expected no next page")
+	}
+}
+
+func TestRESTController_GraphQL_Batching(t *testing.T) {
+	counter := &countingStorage{
+		Storage: storage.NewMemory(),
+		calls:   make(map[string]int),
+	}
+
+	fileSystem, err := storage.NewFileSystem(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create blob store: %s", err.Error())
+	}
+
+	restController := NewRESTController(core.NewApp(counter, fileSystem))
+
+	for _, name := range []string{"ToDo 1", "ToDo 2", "ToDo 3"} {
+		toDo, _ := restController.app.CreateToDo(model.ToDo{Name: name, Tasks: []model.Task{{Name: "Task"}}})
+		_, _ = restController.app.CreateComment(toDo.ID, 0, model.Comment{Author: "Alice", Body: "On the ToDo"})
+		_, _ = restController.app.CreateComment(toDo.ID, toDo.Tasks[0].ID, model.Comment{Author: "Bob", Body: "On the task"})
+	}
+
+	router := chi.NewRouter()
+	router.Post("/graphql", restController.GraphQL())
+
+	query := `{
+		todos {
+			edges { node {
+				comments { author }
+				attachments { filename }
+				tasks { comments { author } attachments { filename } }
+			} }
+		}
+	}`
+
+	var data struct {
+		ToDos struct {
+			Edges []struct {
+				Node struct {
+					Comments []struct {
+						Author string `json:"author"`
+					} `json:"comments"`
+					Tasks []struct {
+						Comments []struct {
+							Author string `json:"author"`
+						} `json:"comments"`
+					} `json:"tasks"`
+				} `json:"node"`
+			} `json:"edges"`
+		} `json:"todos"`
+	}
+
+	response := postGraphQL(t, router, query, nil, &data)
+
+	if len(response.Errors) != 0 {
+		t.Fatalf("expected no errors, got %v", response.Errors)
+	}
+
+	for _, edge := range data.ToDos.Edges {
+		if len(edge.Node.Comments) != 1 || edge.Node.Comments[0].Author != "Alice" {
+			t.Errorf("expected the comment of Alice on the ToDo, got %v", edge.Node.Comments)
+		}
+
+		if len(edge.Node.Tasks) != 1 || len(edge.Node.Tasks[0].Comments) != 1 || edge.Node.Tasks[0].Comments[0].Author != "Bob" {
+			t.Errorf("expected the comment of Bob on the task, got %v", edge.Node.Tasks)
+		}
+	}
+
+	expectedCalls := map[string]int{
+		"FindToDoPage":             1,
+		"FindToDos":                0,
+		"FindCommentsByToDoIDs":    1,
+		"FindAttachmentsByToDoIDs": 1,
+	}
+
+	for function, expected := range expectedCalls {
+		if counter.calls[function] != expected {
+			t.Errorf("expected %d calls of %s, got %d", expected, function, counter.calls[function])
+		}
+	}
+
+	if counter.calls["FindComments"] != 0 || counter.calls["FindAttachments"] != 0 {
+		t.Errorf("expected no per-item queries, got %v", counter.calls)
+	}
+}
+
+func TestRESTController_GraphQL_Mutations(t *testing.T) {
+	restController := newTestRESTController(t)
+
+	router := chi.NewRouter()
+	router.Post("/graphql", restController.GraphQL())
+
+	var created struct {
+		CreateToDo struct {
+			ID      string `json:"id"`
+			Version int    `json:"version"`
+			Tasks   []struct {
+				Name string `json:"name"`
+			} `json:"tasks"`
+		} `json:"createToDo"`
+	}
+
+	postGraphQL(t, router, `mutation {
+		createToDo(input: {name: "ToDo 1", tasks: [{name: "Task 1"}]}) { id version tasks { name } }
+	}`, nil, &created)
+
+	if created.CreateToDo.ID != "1" || created.CreateToDo.Version != 1 || len(created.CreateToDo.Tasks) != 1 {
+		t.Fatalf("expected ToDo 1 with version 1 and one task, got %v", created.CreateToDo)
+	}
+
+	var added struct {
+		AddTask struct {
+			Version int `json:"version"`
+			Tasks   []struct {
+				Name string `json:"name"`
+			} `json:"tasks"`
+		} `json:"addTask"`
+	}
+
+	postGraphQL(t, router, `mutation {
+		addTask(todoId: "1", version: 1, input: {name: "Task 2"}) { version tasks { name } }
+	}`, nil, &added)
+
+	if added.AddTask.Version != 2 || len(added.AddTask.Tasks) != 2 {
+		t.Fatalf("expected version 2 with two tasks, got %v", added.AddTask)
+	}
+
+	tests := []struct {
+		query    string
+		expected int
+	}{
+		{`mutation { updateToDo(id: "1", input: {name: "Outdated", version: 1}) { id } }`, http.StatusConflict},
+		{`mutation { createToDo(input: {name: ""}) { id } }`, http.StatusUnprocessableEntity},
+		{`mutation { deleteToDo(id: "42") }`, http.StatusNotFound},
+		{`mutation { deleteToDo(id: "abc") }`, http.StatusBadRequest},
+		{`{ todos(filter: "tag:") { totalCount } }`, http.StatusBadRequest},
+		{`{ todos(after: "invalid") { totalCount } }`, http.StatusBadRequest},
+		{`{ todo(id: "42") { id } }`, http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		response := postGraphQL(t, router, test.query, nil, nil)
+
+		if len(response.Errors) != 1 {
+			t.Errorf("%s: expected one error, got %d", test.query, len(response.Errors))
+			continue
+		}
+
+		if response.Errors[0].Extensions.Status != test.expected {
+			t.Errorf("%s: expected status %d, got %d", test.query, test.expected, response.Errors[0].Extensions.Status)
+		}
+	}
+
+	var deleted struct {
+		DeleteToDo string `json:"deleteToDo"`
+	}
+
+	postGraphQL(t, router, `mutation { deleteToDo(id: "1") }`, nil, &deleted)
+
+	if deleted.DeleteToDo != "1" {
+		t.Errorf("expected deleted ID %s, got %s", "1", deleted.DeleteToDo)
+	}
+}
+
+func TestRESTController_GraphQL_Methods(t *testing.T) {
+	restController := newTestRESTController(t)
+	_, _ = restController.app.CreateToDo(model.ToDo{Name: "ToDo 1"})
+
+	router := chi.NewRouter()
+	router.Get("/graphql", restController.GraphQL())
+	router.Post("/graphql", restController.GraphQL())
+
+	tests := []struct {
+		method      string
+		contentType string
+		query       string
+		expected    int
+	}{
+		{"GET", "", `{ todo(id: "1") { name } }`, http.StatusOK},
+		{"GET", "", `mutation { deleteToDo(id: "1") }`, http.StatusMethodNotAllowed},
+		{"GET", "", `# query
+			mutation Delete { deleteToDo(id: "1") }`, http.StatusMethodNotAllowed},
+		{"POST", "text/plain", `mutation { deleteToDo(id: "1") }`, http.StatusUnsupportedMediaType},
+		{"POST", "application/x-www-form-urlencoded", `mutation { deleteToDo(id: "1") }`, http.StatusUnsupportedMediaType},
+		{"POST", "application/json; charset=utf-8", `{ todo(id: "1") { name } }`, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		var request *http.Request
+
+		if test.method == "GET" {
+			request = httptest.NewRequest("GET", "/graphql?query="+url.QueryEscape(test.query), nil)
+		} else {
+			body, _ := json.Marshal(graphQLRequest{Query: test.query})
+			request = httptest.NewRequest("POST", "/graphql", strings.NewReader(string(body)))
+			request.Header.Set("Content-Type", test.contentType)
+		}
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.expected {
+			t.Errorf("%s %s: expected status %d, got %d", test.method, test.query, test.expected, recorder.Code)
+		}
+	}
+
+	if _, err := restController.app.GetToDo(1); err != nil {
+		t.Errorf("expected ToDo 1 to still exist, got %v", err)
+	}
+
+	// Queries nested deeper than maxQueryDepth are rejected before their
+	// fields are resolved.
+	response := postGraphQL(t, router, `{ todos { edges { node { tasks { comments { id } } } } } }`, nil, nil)
+	if len(response.Errors) != 0 {
+		t.Errorf("unexpected errors %v", response.Errors)
+	}
+
+	query := "id"
+	for i := 0; i < maxQueryDepth; i++ {
+		query = fmt.Sprintf("field%d { %s }", i, query)
+	}
+
+	response = postGraphQL(t, router, "{ "+query+" }", nil, nil)
+	if len(response.Errors) == 0 || !strings.Contains(response.Errors[0].Message, "exceeds max depth") {
+		t.Errorf("expected max depth error, got %v", response.Errors)
+	}
+}
+
+func TestOperationType(t *testing.T) {
+	tests := []struct {
+		document      string
+		operationName string
+		expected      string
+	}{
+		{`{ todos { totalCount } }`, "", "query"},
+		{`query { todos { totalCount } }`, "", "query"},
+		{`mutation { deleteToDo(id: "1") }`, "", "mutation"},
+		{`mutation($id: ID!) { deleteToDo(id: $id) }`, "", "mutation"},
+		{`subscription Watch { todos }`, "", "subscription"},
+		{`query Get { todo(id: "1") { name } } mutation Delete { deleteToDo(id: "1") }`, "Delete", "mutation"},
+		{`query Get { todo(id: "1") { name } } mutation Delete { deleteToDo(id: "1") }`, "Get", "query"},
+		{`query Get { a } mutation Delete { b }`, "", ""},
+		{`fragment Fields on ToDo { name } mutation { createToDo(input: {name: "{"}) { ...Fields } }`, "", "mutation"},
+		{"# mutation\n{ todo(id: \"1\") { description(text: \"\"\"}\"\"\") } }", "", "query"},
+		{`query {`, "", "query"},
+	}
+
+	for _, test := range tests {
+		if operation := operationType(test.document, test.operationName); operation != test.expected {
+			t.Errorf("%s: expected operation type %q, got %q", test.document, test.expected, operation)
+		}
+	}
+}
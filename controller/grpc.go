@@ -0,0 +1,236 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/todopb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GRPCController represents a controller implementing the gRPC ToDoService. It
+// provides the same operations as the RESTController.
+type GRPCController struct {
+	todopb.UnimplementedToDoServiceServer
+	app *core.App
+}
+
+// NewGRPCController returns a new gRPC controller instance that will use the
+// provided app instance for running business logic.
+func NewGRPCController(app *core.App) *GRPCController {
+	return &GRPCController{
+		app: app,
+	}
+}
+
+// CreateToDo creates a ToDo item. It expects a ToDo item without ID and returns
+// an item containing the ID.
+func (g *GRPCController) CreateToDo(_ context.Context, request *todopb.CreateToDoRequest) (*todopb.ToDo, error) {
+	createdToDo, err := g.app.CreateToDo(toDoFromProto(request.GetTodo()))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return toDoToProto(createdToDo), nil
+}
+
+// ListToDos lists all ToDo items. If a filter query is set, only the ToDo items
+// matching the query will be listed.
+func (g *GRPCController) ListToDos(_ context.Context, request *todopb.ListToDosRequest) (*todopb.ListToDosResponse, error) {
+	var (
+		toDos []model.ToDo
+		err   error
+	)
+
+	if request.GetFilter() != "" {
+		toDos, err = g.app.GetToDosByFilter(request.GetFilter())
+	} else {
+		toDos, err = g.app.GetToDos()
+	}
+
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	response := &todopb.ListToDosResponse{
+		Todos: make([]*todopb.ToDo, len(toDos)),
+	}
+
+	for i, toDo := range toDos {
+		response.Todos[i] = toDoToProto(toDo)
+	}
+
+	return response, nil
+}
+
+// GetToDo returns the ToDo item with the given ID.
+func (g *GRPCController) GetToDo(_ context.Context, request *todopb.GetToDoRequest) (*todopb.ToDo, error) {
+	toDo, err := g.app.GetToDo(request.GetId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return toDoToProto(toDo), nil
+}
+
+// UpdateToDo overrides the ToDo item with the given ID.
+func (g *GRPCController) UpdateToDo(_ context.Context, request *todopb.UpdateToDoRequest) (*emptypb.Empty, error) {
+	if err := g.app.UpdateToDo(request.GetId(), toDoFromProto(request.GetTodo())); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// DeleteToDo deletes the ToDo item with the given ID along with its sub-tasks.
+func (g *GRPCController) DeleteToDo(_ context.Context, request *todopb.DeleteToDoRequest) (*emptypb.Empty, error) {
+	if err := g.app.DeleteToDo(request.GetId()); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// WatchToDos streams the changes of ToDo items until the client cancels the
+// call. The events can be restricted to a ToDo item or project, and recent
+// events after the last event ID are sent first.
+//
+// If the client falls too far behind, the stream is aborted with UNAVAILABLE,
+// and the client should resume using the ID of the last received event.
+func (g *GRPCController) WatchToDos(request *todopb.WatchToDosRequest, stream todopb.ToDoService_WatchToDosServer) error {
+	subscription := g.app.Subscribe(request.GetLastEventId())
+	defer subscription.Close()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return status.Error(codes.Unavailable, "event stream fell behind")
+			}
+
+			if request.GetTodoId() != 0 && event.ToDoID != request.GetTodoId() {
+				continue
+			}
+
+			if request.GetProject() != "" && event.ToDo.Project != request.GetProject() {
+				continue
+			}
+
+			if err := stream.Send(eventToProto(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// grpcError converts an error to a gRPC status error. The status code is derived
+// from the HTTP status code the REST API responds with for the same error.
+func grpcError(err error) error {
+	grpcCodes := map[int]codes.Code{
+		http.StatusBadRequest:            codes.InvalidArgument,
+		http.StatusUnauthorized:          codes.Unauthenticated,
+		http.StatusForbidden:             codes.PermissionDenied,
+		http.StatusNotFound:              codes.NotFound,
+		http.StatusConflict:              codes.Aborted,
+		http.StatusRequestEntityTooLarge: codes.ResourceExhausted,
+		http.StatusUnsupportedMediaType:  codes.InvalidArgument,
+		http.StatusUnprocessableEntity:   codes.InvalidArgument,
+	}
+
+	code, isRegistered := grpcCodes[statusCodeForError(err)]
+	if !isRegistered {
+		code = codes.Internal
+	}
+
+	return status.Error(code, err.Error())
+}
+
+// toDoToProto converts a ToDo item to its protocol buffer message.
+func toDoToProto(toDo model.ToDo) *todopb.ToDo {
+	message := &todopb.ToDo{
+		Id:          toDo.ID,
+		Name:        toDo.Name,
+		Description: toDo.Description,
+		Done:        toDo.Done,
+		Project:     toDo.Project,
+		Priority:    toDo.Priority,
+		Recurrence:  toDo.Recurrence,
+		Tags:        toDo.Tags,
+		Version:     toDo.Version,
+	}
+
+	if toDo.Due != nil {
+		message.Due = timestamppb.New(*toDo.Due)
+	}
+
+	for _, task := range toDo.Tasks {
+		message.Tasks = append(message.Tasks, &todopb.Task{
+			Id:          task.ID,
+			Name:        task.Name,
+			Description: task.Description,
+			Done:        task.Done,
+		})
+	}
+
+	return message
+}
+
+// toDoFromProto converts a protocol buffer message to a ToDo item.
+func toDoFromProto(message *todopb.ToDo) model.ToDo {
+	toDo := model.ToDo{
+		ID:          message.GetId(),
+		Name:        message.GetName(),
+		Description: message.GetDescription(),
+		Done:        message.GetDone(),
+		Project:     message.GetProject(),
+		Priority:    message.GetPriority(),
+		Recurrence:  message.GetRecurrence(),
+		Tags:        message.GetTags(),
+		Version:     message.GetVersion(),
+	}
+
+	if message.GetDue() != nil {
+		due := message.GetDue().AsTime().UTC().Truncate(time.Second)
+		toDo.Due = &due
+	}
+
+	for _, task := range message.GetTasks() {
+		toDo.Tasks = append(toDo.Tasks, model.Task{
+			ID:          task.GetId(),
+			Name:        task.GetName(),
+			Description: task.GetDescription(),
+			Done:        task.GetDone(),
+		})
+	}
+
+	return toDo
+}
+
+// eventToProto converts an event to its protocol buffer message.
+func eventToProto(event model.Event) *todopb.Event {
+	eventTypes := map[model.EventType]todopb.EventType{
+		model.EventCreated:   todopb.EventType_EVENT_TYPE_CREATED,
+		model.EventUpdated:   todopb.EventType_EVENT_TYPE_UPDATED,
+		model.EventDeleted:   todopb.EventType_EVENT_TYPE_DELETED,
+		model.EventCompleted: todopb.EventType_EVENT_TYPE_COMPLETED,
+	}
+
+	return &todopb.Event{
+		Id:     event.ID,
+		Type:   eventTypes[event.Type],
+		TodoId: event.ToDoID,
+		Todo:   toDoToProto(event.ToDo),
+		Time:   timestamppb.New(event.Time),
+	}
+}
@@ -0,0 +1,154 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/dominikbraun/todo/todopb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestGRPCClient serves a gRPC controller backed by the app of the given
+// REST controller on an in-memory connection and returns a client for it.
+func newTestGRPCClient(t *testing.T, restController *RESTController) todopb.ToDoServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+
+	todopb.RegisterToDoServiceServer(server, NewGRPCController(restController.app))
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}
+
+	conn, err := grpc.Dial("bufnet", grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+		server.Stop()
+	})
+
+	return todopb.NewToDoServiceClient(conn)
+}
+
+func TestGRPCController_ToDos(t *testing.T) {
+	client := newTestGRPCClient(t, newTestRESTController(t))
+	ctx := context.Background()
+
+	toDo, err := client.CreateToDo(ctx, &todopb.CreateToDoRequest{
+		Todo: &todopb.ToDo{
+			Name:  "ToDo 1",
+			Tags:  []string{"Release"},
+			Tasks: []*todopb.Task{{Name: "Task 1"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if toDo.Id == 0 || toDo.Tasks[0].Id == 0 || toDo.Tags[0] != "release" {
+		t.Fatalf("unexpected ToDo %v", toDo)
+	}
+
+	toDo.Done = true
+
+	if _, err := client.UpdateToDo(ctx, &todopb.UpdateToDoRequest{Id: toDo.Id, Todo: toDo}); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.ListToDos(ctx, &todopb.ListToDosRequest{Filter: "done"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(response.Todos) != 1 || response.Todos[0].Version != 2 {
+		t.Fatalf("expected the updated ToDo, got %v", response.Todos)
+	}
+
+	tests := map[string]struct {
+		call     func() error
+		expected codes.Code
+	}{
+		"unknown ToDo": {
+			call: func() error {
+				_, err := client.GetToDo(ctx, &todopb.GetToDoRequest{Id: 42})
+				return err
+			},
+			expected: codes.NotFound,
+		},
+		"empty name": {
+			call: func() error {
+				_, err := client.CreateToDo(ctx, &todopb.CreateToDoRequest{Todo: &todopb.ToDo{}})
+				return err
+			},
+			expected: codes.InvalidArgument,
+		},
+		"invalid filter": {
+			call: func() error {
+				_, err := client.ListToDos(ctx, &todopb.ListToDosRequest{Filter: "tag:"})
+				return err
+			},
+			expected: codes.InvalidArgument,
+		},
+		"outdated version": {
+			call: func() error {
+				_, err := client.UpdateToDo(ctx, &todopb.UpdateToDoRequest{Id: toDo.Id, Todo: toDo})
+				return err
+			},
+			expected: codes.Aborted,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if code := status.Code(test.call()); code != test.expected {
+				t.Errorf("expected code %s, got %s", test.expected, code)
+			}
+		})
+	}
+}
+
+func TestGRPCController_WatchToDos(t *testing.T) {
+	restController := newTestRESTController(t)
+	client := newTestGRPCClient(t, restController)
+
+	toDo, _ := client.CreateToDo(context.Background(), &todopb.CreateToDoRequest{
+		Todo: &todopb.ToDo{Name: "ToDo 1"},
+	})
+	_, _ = client.CreateToDo(context.Background(), &todopb.CreateToDoRequest{
+		Todo: &todopb.ToDo{Name: "ToDo 2"},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Replay all events after the first one, but only those of the first ToDo.
+	stream, err := client.WatchToDos(ctx, &todopb.WatchToDosRequest{TodoId: toDo.Id, LastEventId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = client.DeleteToDo(context.Background(), &todopb.DeleteToDoRequest{Id: toDo.Id})
+
+	event, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if event.Type != todopb.EventType_EVENT_TYPE_DELETED || event.TodoId != toDo.Id {
+		t.Errorf("expected delete event of ToDo %d, got %v", toDo.Id, event)
+	}
+}
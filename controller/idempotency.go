@@ -0,0 +1,186 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+const (
+	// idempotencyKeyHeader is the request header containing a client-generated
+	// key that makes retries of a request safe.
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	// idempotentReplayedHeader is set to true for responses that have been
+	// replayed instead of processing the request again.
+	idempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// Idempotency returns a middleware that makes POST requests with an
+// `Idempotency-Key` header safe to retry. The response to the first request
+// with a key is stored, and retries with the same key get the stored response
+// without the request being processed again.
+//
+// Keys are scoped to the user of the `X-User` header. A key that is reused for
+// a request with a different method, URL, content type or body is rejected with
+// ErrIdempotencyKeyReused, and a key whose request is still being processed is
+// rejected with ErrIdempotentRequestInProgress until the lease of its claim
+// expires.
+func (r *RESTController) Idempotency() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			key := request.Header.Get(idempotencyKeyHeader)
+
+			if request.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(writer, request)
+				return
+			}
+
+			owner := request.Header.Get(userHeader)
+
+			record, err := r.app.GetIdempotencyRecord(owner, key)
+
+			switch {
+			case err == nil:
+				replay(writer, request, record)
+				return
+			case !errors.Is(err, storage.ErrIdempotencyRecordNotFound):
+				respond(writer, request, statusCodeForError(err), err)
+				return
+			}
+
+			claim, err := r.app.ClaimIdempotencyKey(owner, key)
+			if err != nil {
+				respond(writer, request, statusCodeForError(err), err)
+				return
+			}
+
+			// The fingerprint is computed while the handler reads the body, so
+			// that large bodies like attachments don't have to be buffered.
+			fingerprint := newFingerprint(request)
+			body := request.Body
+
+			request.Body = ioutil.NopCloser(io.TeeReader(body, fingerprint))
+			recorder := &responseRecorder{ResponseWriter: writer}
+
+			next.ServeHTTP(recorder, request)
+
+			// Include the part of the body the handler hasn't read. If the body
+			// can't be read anymore, e.g. because it exceeded a size limit, the
+			// fingerprint is incomplete and the response isn't stored.
+			_, drainErr := io.Copy(ioutil.Discard, request.Body)
+			_ = body.Close()
+
+			if drainErr != nil || !isReplayable(recorder.statusCode()) {
+				_ = r.app.ReleaseIdempotencyKey(claim)
+				return
+			}
+
+			_ = r.app.CompleteIdempotentRequest(claim, model.IdempotencyRecord{
+				Fingerprint: hex.EncodeToString(fingerprint.Sum(nil)),
+				Status:      recorder.statusCode(),
+				ContentType: recorder.Header().Get("Content-Type"),
+				Body:        recorder.body.Bytes(),
+			})
+		})
+	}
+}
+
+// replay writes a stored response if the request matches the fingerprint of
+// the request the response has been stored for.
+func replay(writer http.ResponseWriter, request *http.Request, record model.IdempotencyRecord) {
+	fingerprint := newFingerprint(request)
+
+	if _, err := io.Copy(fingerprint, request.Body); err != nil {
+		respond(writer, request, http.StatusBadRequest, err)
+		return
+	}
+
+	if hex.EncodeToString(fingerprint.Sum(nil)) != record.Fingerprint {
+		respond(writer, request, statusCodeForError(core.ErrIdempotencyKeyReused), core.ErrIdempotencyKeyReused)
+		return
+	}
+
+	if record.ContentType != "" {
+		writer.Header().Set("Content-Type", record.ContentType)
+	}
+
+	writer.Header().Set(idempotentReplayedHeader, strconv.FormatBool(true))
+	writer.WriteHeader(record.Status)
+
+	_, _ = writer.Write(record.Body)
+}
+
+// newFingerprint returns a hash of the method, URL and content type of a
+// request, to which the body has to be written.
+func newFingerprint(request *http.Request) hash.Hash {
+	fingerprint := sha256.New()
+
+	for _, value := range []string{request.Method, request.URL.RequestURI(), request.Header.Get("Content-Type")} {
+		_, _ = io.WriteString(fingerprint, value+"\n")
+	}
+
+	return fingerprint
+}
+
+// isReplayable reports whether a response with the given status code may be
+// stored and replayed. Server errors, missing credentials and rate limits are
+// not stored, since a retry of the request may succeed.
+func isReplayable(statusCode int) bool {
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+		return false
+	default:
+		return statusCode < 500
+	}
+}
+
+// responseRecorder passes a response through to the client while keeping a
+// copy of its status code and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader records the status code and writes it to the client.
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if r.status == 0 {
+		r.status = statusCode
+	}
+
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records the data and writes it to the client.
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	r.body.Write(data)
+
+	return r.ResponseWriter.Write(data)
+}
+
+// statusCode returns the recorded status code, which is 200 if the handler
+// hasn't written anything.
+func (r *responseRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+
+	return r.status
+}
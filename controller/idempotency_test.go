@@ -0,0 +1,131 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+)
+
+func TestRESTController_Idempotency(t *testing.T) {
+	restController := newTestRESTController(t)
+
+	router := chi.NewRouter()
+	router.Use(restController.Idempotency())
+	router.Post("/todos", restController.CreateToDo())
+
+	send := func(key, user, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest("POST", "/todos", strings.NewReader(body))
+		request.Header.Set(idempotencyKeyHeader, key)
+		request.Header.Set(userHeader, user)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	first := send("key-1", "alice", `{"name": "ToDo 1"}`)
+	retry := send("key-1", "alice", `{"name": "ToDo 1"}`)
+
+	if first.Code != http.StatusOK || retry.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d and %d", http.StatusOK, first.Code, retry.Code)
+	}
+
+	if retry.Body.String() != first.Body.String() || retry.Header().Get(idempotentReplayedHeader) != "true" {
+		t.Errorf("expected replayed response %q, got %q", first.Body.String(), retry.Body.String())
+	}
+
+	if recorder := send("key-1", "alice", `{"name": "ToDo 2"}`); recorder.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status %d for a different body, got %d", http.StatusUnprocessableEntity, recorder.Code)
+	}
+
+	// Keys are scoped to the user, and requests without key are not affected.
+	send("key-1", "bob", `{"name": "ToDo 1"}`)
+	send("", "alice", `{"name": "ToDo 1"}`)
+
+	// Invalid requests get the same error when retried, while the key of a
+	// request rejected by the server can be used again.
+	if recorder := send("key-2", "alice", `{"name": ""}`); recorder.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, recorder.Code)
+	}
+
+	if recorder := send("key-2", "alice", `{"name": ""}`); recorder.Header().Get(idempotentReplayedHeader) != "true" {
+		t.Errorf("expected a replayed error response, got %d", recorder.Code)
+	}
+
+	toDos, err := restController.app.GetToDos()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(toDos) != 3 {
+		t.Errorf("expected %d ToDo items, got %d", 3, len(toDos))
+	}
+
+	var created model.ToDo
+
+	if err := json.NewDecoder(first.Body).Decode(&created); err != nil || created.ID == 0 {
+		t.Errorf("unexpected response %v: %v", created, err)
+	}
+}
+
+func TestRESTController_Idempotency_InProgress(t *testing.T) {
+	restController := newTestRESTController(t)
+
+	if _, err := restController.app.ClaimIdempotencyKey("alice", "key-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := restController.Idempotency()(restController.CreateToDo())
+
+	request := httptest.NewRequest("POST", "/todos", strings.NewReader(`{"name": "ToDo 1"}`))
+	request.Header.Set(idempotencyKeyHeader, "key-1")
+	request.Header.Set(userHeader, "alice")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusConflict {
+		t.Errorf("expected status %d, got %d", http.StatusConflict, recorder.Code)
+	}
+}
+
+func TestRESTController_Idempotency_ExpiredLease(t *testing.T) {
+	restController := newTestRESTController(t)
+	restController.app.SetIdempotencyLease(-time.Second)
+
+	if _, err := restController.app.ClaimIdempotencyKey("alice", "key-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	restController.app.SetIdempotencyLease(core.DefaultIdempotencyLease)
+	handler := restController.Idempotency()(restController.CreateToDo())
+
+	// The claim of a request that has never been completed is taken over.
+	for i := 0; i < 2; i++ {
+		request := httptest.NewRequest("POST", "/todos", strings.NewReader(`{"name": "ToDo 1"}`))
+		request.Header.Set(idempotencyKeyHeader, "key-1")
+		request.Header.Set(userHeader, "alice")
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, recorder.Code)
+		}
+	}
+
+	if toDos, _ := restController.app.GetToDos(); len(toDos) != 1 {
+		t.Errorf("expected %d ToDo item, got %d", 1, len(toDos))
+	}
+}
@@ -0,0 +1,46 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// markdownType is the media type of Markdown documents as registered in RFC 7763.
+const markdownType = "text/markdown"
+
+// acceptsMarkdown reports whether a request prefers a Markdown document over
+// JSON according to its Accept header. Media types are considered in the order
+// they are listed, and types with a quality of 0 are skipped.
+func acceptsMarkdown(request *http.Request) bool {
+	for _, value := range strings.Split(request.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+
+		if quality, err := strconv.ParseFloat(params["q"], 64); err == nil && quality == 0 {
+			continue
+		}
+
+		switch mediaType {
+		case markdownType:
+			return true
+		case "application/json", "application/*", "*/*":
+			return false
+		}
+	}
+
+	return false
+}
+
+// isMarkdown reports whether the body of a request is a Markdown document
+// according to its Content-Type header.
+func isMarkdown(request *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+
+	return err == nil && mediaType == markdownType
+}
@@ -0,0 +1,92 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+)
+
+func TestRESTController_Markdown(t *testing.T) {
+	restController := newTestRESTController(t)
+
+	router := chi.NewRouter()
+	router.Post("/todos", restController.CreateToDo())
+	router.Get("/todos/{id}", restController.GetToDo())
+
+	document := "# Release 1.0\n\nShip it.\n\n- [ ] Write notes\n  Mention the API.\n- [x] Tag commit\n"
+
+	tests := []struct {
+		contentType string
+		body        string
+		expected    int
+	}{
+		{"text/markdown; charset=utf-8", "Some text\n# Release 1.0\n", http.StatusBadRequest},
+		{"text/markdown", "# \n\n- [ ] Task\n", http.StatusUnprocessableEntity},
+		{"text/markdown; charset=utf-8", document, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest("POST", "/todos", strings.NewReader(test.body))
+		request.Header.Set("Content-Type", test.contentType)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.expected {
+			t.Fatalf("%q: expected status %d, got %d: %s", test.body, test.expected, recorder.Code, recorder.Body.String())
+		}
+	}
+
+	var toDo model.ToDo
+
+	if toDos, _ := restController.app.GetToDos(); len(toDos) == 1 {
+		toDo = toDos[0]
+	}
+
+	if toDo.Description != "Ship it." || len(toDo.Tasks) != 2 || !toDo.Tasks[1].Done {
+		t.Fatalf("expected ToDo item with 2 tasks, got %v", toDo)
+	}
+
+	accepts := []struct {
+		accept   string
+		markdown bool
+	}{
+		{"", false},
+		{"application/json", false},
+		{"text/markdown", true},
+		{"text/markdown;q=0, application/json", false},
+		{"application/json, text/markdown", false},
+		{"text/html, text/markdown;q=0.9, */*;q=0.8", true},
+	}
+
+	for _, test := range accepts {
+		request := httptest.NewRequest("GET", "/todos/1", nil)
+		request.Header.Set("Accept", test.accept)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		isMarkdown := strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/markdown")
+
+		if recorder.Code != http.StatusOK || isMarkdown != test.markdown {
+			t.Errorf("%q: expected Markdown to be %t, got status %d and type %s", test.accept, test.markdown, recorder.Code, recorder.Header().Get("Content-Type"))
+			continue
+		}
+
+		if isMarkdown && recorder.Body.String() != document {
+			t.Errorf("expected document %q, got %q", document, recorder.Body.String())
+		}
+
+		if !isMarkdown && json.Unmarshal(recorder.Body.Bytes(), &model.ToDo{}) != nil {
+			t.Errorf("%q: expected a JSON response, got %q", test.accept, recorder.Body.String())
+		}
+	}
+}
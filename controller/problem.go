@@ -0,0 +1,73 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/model"
+)
+
+const (
+	// problemType is the content type of JSON error responses as specified by
+	// RFC 7807.
+	problemType = "application/problem+json"
+
+	// problemTypeValidation identifies problems caused by invalid fields of the
+	// request body, which are listed as errors.
+	problemTypeValidation = "https://github.com/dominikbraun/todo#validation-errors"
+
+	// problemTypeInvalidRows identifies imports refused because of invalid
+	// rows, which are listed as rows.
+	problemTypeInvalidRows = "https://github.com/dominikbraun/todo#csv"
+
+	// problemTypeBatch identifies atomic batches that failed because of one of
+	// their operations, listing the results of all operations.
+	problemTypeBatch = "https://github.com/dominikbraun/todo#batch-operations"
+)
+
+// newProblem returns the problem details for an error response with the given
+// status code. Validation errors, refused imports and failed atomic batches get
+// their own problem types, while other errors have no specific type.
+func newProblem(status int, err error) model.Problem {
+	problem := model.Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: err.Error(),
+	}
+
+	var (
+		validationErr *core.ValidationError
+		invalidRows   *core.InvalidRowsError
+		batchErr      *core.BatchError
+	)
+
+	switch {
+	case errors.As(err, &batchErr):
+		failed := newProblem(status, batchErr.Results[batchErr.Index].Err)
+
+		problem.Type = problemTypeBatch
+		problem.Title = "Batch operation failed"
+		problem.Errors = failed.Errors
+		problem.Results = batchResults(batchErr.Results)
+	case errors.As(err, &validationErr):
+		problem.Type = problemTypeValidation
+		problem.Title = "Invalid request body"
+
+		for _, violation := range validationErr.Violations {
+			problem.Errors = append(problem.Errors, model.FieldError{
+				Pointer: violation.Field,
+				Detail:  violation.Err.Error(),
+			})
+		}
+	case errors.As(err, &invalidRows):
+		problem.Type = problemTypeInvalidRows
+		problem.Title = "Invalid import rows"
+		problem.Rows = invalidRows.Rows
+	}
+
+	return problem
+}
@@ -0,0 +1,95 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRESTController_Problems(t *testing.T) {
+	restController := newTestRESTController(t)
+
+	router := chi.NewRouter()
+	router.Post("/todos", restController.CreateToDo())
+	router.Get("/todos/{id}", restController.GetToDo())
+
+	tests := []struct {
+		method   string
+		target   string
+		body     string
+		expected model.Problem
+	}{
+		{
+			method: "POST",
+			target: "/todos",
+			body:   `{"name": "", "priority": "high", "tasks": [{"name": "Task 1"}, {"name": ""}]}`,
+			expected: model.Problem{
+				Type:   problemTypeValidation,
+				Title:  "Invalid request body",
+				Status: http.StatusUnprocessableEntity,
+				Detail: "/name: name must not be empty; /tasks/1/name: name must not be empty; /priority: priority must be a letter from A to Z",
+				Errors: []model.FieldError{
+					{Pointer: "/name", Detail: "name must not be empty"},
+					{Pointer: "/tasks/1/name", Detail: "name must not be empty"},
+					{Pointer: "/priority", Detail: "priority must be a letter from A to Z"},
+				},
+			},
+		},
+		{
+			method: "POST",
+			target: "/todos",
+			body:   `{"name": " ", "tasks": [{"id": 1, "name": "Task 1"}, {"id": 1, "name": "Task 2"}]}`,
+			expected: model.Problem{
+				Type:   problemTypeValidation,
+				Title:  "Invalid request body",
+				Status: http.StatusUnprocessableEntity,
+				Detail: "/name: name must not be empty; /tasks/1/id: task ID is used by several tasks",
+				Errors: []model.FieldError{
+					{Pointer: "/name", Detail: "name must not be empty"},
+					{Pointer: "/tasks/1/id", Detail: "task ID is used by several tasks"},
+				},
+			},
+		},
+		{
+			method: "GET",
+			target: "/todos/1",
+			expected: model.Problem{
+				Type:   "about:blank",
+				Title:  "Not Found",
+				Status: http.StatusNotFound,
+				Detail: "requested ToDo item not found",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(test.method, test.target, strings.NewReader(test.body))
+		recorder := httptest.NewRecorder()
+
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.expected.Status || recorder.Header().Get("Content-Type") != problemType {
+			t.Errorf("%s %s: expected status %d with type %s, got %d with type %s", test.method, test.target,
+				test.expected.Status, problemType, recorder.Code, recorder.Header().Get("Content-Type"))
+		}
+
+		var problem model.Problem
+
+		if err := json.NewDecoder(recorder.Body).Decode(&problem); err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff(test.expected, problem); diff != "" {
+			t.Errorf("%s %s: unexpected problem (-expected +got):\n%s", test.method, test.target, diff)
+		}
+	}
+}
@@ -3,24 +3,23 @@
 package controller
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/dominikbraun/todo/backup"
+	"github.com/dominikbraun/todo/codec"
 	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/filter"
 	"github.com/dominikbraun/todo/model"
 	"github.com/dominikbraun/todo/storage"
+	"github.com/dominikbraun/todo/todomd"
 
 	"github.com/go-chi/chi"
-	"github.com/go-chi/render"
 )
 
-type errorResponse struct {
-	Error string `json:"error"`
-}
-
 // RESTController represents a controller capable of handling HTTP requests and
-// yielding a corresponding JSON result.
+// yielding a corresponding result in the format the request accepts.
 type RESTController struct {
 	app *core.App
 }
@@ -35,12 +34,26 @@ func NewRESTController(app *core.App) *RESTController {
 
 // CreateToDo processes a POST request for creating a ToDo item. It expects a
 // ToDo item without ID and returns an item containing the ID.
+//
+// If the request has the `text/markdown` content type, the item is read from a
+// Markdown document with a heading and a task list as described in todomd.
 func (r *RESTController) CreateToDo() http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
 		var toDo model.ToDo
 
-		if err := json.NewDecoder(request.Body).Decode(&toDo); err != nil {
-			respond(writer, request, http.StatusUnprocessableEntity, err)
+		if isMarkdown(request) {
+			var err error
+			if err = checkAccept(request); err != nil {
+				respond(writer, request, statusCodeForError(err), err)
+				return
+			}
+
+			if toDo, err = todomd.Decode(http.MaxBytesReader(writer, request.Body, maxImportSize)); err != nil {
+				respond(writer, request, http.StatusBadRequest, err)
+				return
+			}
+		} else if err := decodeBody(request, &toDo); err != nil {
+			respond(writer, request, statusCodeForBody(err), err)
 			return
 		}
 
@@ -55,9 +68,22 @@ func (r *RESTController) CreateToDo() http.HandlerFunc {
 }
 
 // GetToDos processes a GET request for listing all ToDo items.
+//
+// If the `filter` query parameter is set, only the ToDo items matching the
+// filter query will be listed.
 func (r *RESTController) GetToDos() http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
-		toDos, err := r.app.GetToDos()
+		var (
+			toDos []model.ToDo
+			err   error
+		)
+
+		if query := request.URL.Query().Get("filter"); query != "" {
+			toDos, err = r.app.GetToDosByFilter(query)
+		} else {
+			toDos, err = r.app.GetToDos()
+		}
+
 		if err != nil {
 			respond(writer, request, statusCodeForError(err), err)
 			return
@@ -67,7 +93,9 @@ func (r *RESTController) GetToDos() http.HandlerFunc {
 	}
 }
 
-// GetToDo processes a GET request for retrieving a single ToDo item by ID.
+// GetToDo processes a GET request for retrieving a single ToDo item by ID. If
+// the request accepts `text/markdown` rather than JSON, the item is returned as
+// Markdown document.
 //
 // Expects the `id` URL parameter.
 func (r *RESTController) GetToDo() http.HandlerFunc {
@@ -84,6 +112,14 @@ func (r *RESTController) GetToDo() http.HandlerFunc {
 			return
 		}
 
+		writer.Header().Set("Vary", "Accept")
+
+		if acceptsMarkdown(request) {
+			writer.Header().Set("Content-Type", markdownType+"; charset=utf-8")
+			_ = todomd.Encode(writer, toDo)
+			return
+		}
+
 		respond(writer, request, http.StatusOK, toDo)
 	}
 }
@@ -102,8 +138,8 @@ func (r *RESTController) UpdateToDo() http.HandlerFunc {
 
 		var toDo model.ToDo
 
-		if err := json.NewDecoder(request.Body).Decode(&toDo); err != nil {
-			respond(writer, request, http.StatusUnprocessableEntity, err)
+		if err := decodeBody(request, &toDo); err != nil {
+			respond(writer, request, statusCodeForBody(err), err)
 			return
 		}
 
@@ -139,38 +175,132 @@ func (r *RESTController) DeleteToDo() http.HandlerFunc {
 	}
 }
 
-// respond writes the status code as well as the JSON body to an HTTP response.
+// respond writes the status code as well as the body to an HTTP response. The
+// body is encoded by the codec for the request's Accept header.
 //
-// If v is nil, the response body will be empty. In addition, an errorResponse
-// instance will be rendered automatically if v is an error value.
+// If v is nil, the response body will be empty. In addition, error values are
+// converted to RFC 7807 problem details, which have the `application/problem+json`
+// content type when encoded as JSON. Errors are encoded as JSON if the request
+// doesn't accept a supported media type, while other values are replaced with a
+// 406 Not Acceptable error.
 func respond(writer http.ResponseWriter, request *http.Request, status int, v interface{}) {
-	render.Status(request, status)
-
 	response := v
 
-	if err, isError := v.(error); isError {
-		response = errorResponse{Error: err.Error()}
+	err, isError := v.(error)
+	if isError {
+		response = newProblem(status, err)
 	}
 
-	if response != nil {
-		render.JSON(writer, request, response)
+	if response == nil {
+		writer.WriteHeader(status)
+		return
 	}
+
+	c, err := codec.ForAccept(request.Header.Get("Accept"))
+	if err != nil {
+		if !isError {
+			status = statusCodeForError(err)
+			response = newProblem(status, err)
+			isError = true
+		}
+		c = codec.JSON
+	}
+
+	contentType := codec.ContentType(c)
+	if isError && c == codec.JSON {
+		contentType = problemType
+	}
+
+	writer.Header().Set("Content-Type", contentType)
+	writer.Header().Set("Vary", "Accept")
+	writer.WriteHeader(status)
+
+	_ = c.Encode(writer, response)
 }
 
 // statusCodeForError returns an appropriate HTTP status code for a given error.
 func statusCodeForError(err error) int {
-	statusCodes := map[error]int{
-		storage.ErrToDoNotFound:    http.StatusNotFound,
-		core.ErrNameMustNotBeEmpty: http.StatusUnprocessableEntity,
-		nil:                        http.StatusOK,
+	var syntaxError *filter.SyntaxError
+
+	if errors.As(err, &syntaxError) {
+		return http.StatusBadRequest
+	}
+
+	if err == nil {
+		return http.StatusOK
 	}
 
-	statusCode, isRegistered := statusCodes[err]
+	// The registered errors are checked in order, so that an error wrapping
+	// several of them, like a validation error with multiple violations, always
+	// gets the status code of the first one.
+	statusCodes := []struct {
+		err        error
+		statusCode int
+	}{
+		{storage.ErrToDoNotFound, http.StatusNotFound},
+		{storage.ErrTaskNotFound, http.StatusNotFound},
+		{storage.ErrCommentNotFound, http.StatusNotFound},
+		{storage.ErrAttachmentNotFound, http.StatusNotFound},
+		{storage.ErrBlobNotFound, http.StatusNotFound},
+		{storage.ErrViewNotFound, http.StatusNotFound},
+		{storage.ErrCalendarFeedNotFound, http.StatusNotFound},
+		{storage.ErrVersionConflict, http.StatusConflict},
+		{storage.ErrWebhookNotFound, http.StatusNotFound},
+		{storage.ErrDeliveryNotFound, http.StatusNotFound},
+		{core.ErrNameMustNotBeEmpty, http.StatusUnprocessableEntity},
+		{core.ErrInvalidPriority, http.StatusUnprocessableEntity},
+		{core.ErrInvalidRecurrence, http.StatusUnprocessableEntity},
+		{core.ErrNameTooLong, http.StatusUnprocessableEntity},
+		{core.ErrDescriptionTooLong, http.StatusUnprocessableEntity},
+		{core.ErrProjectTooLong, http.StatusUnprocessableEntity},
+		{core.ErrTagTooLong, http.StatusUnprocessableEntity},
+		{core.ErrRecurrenceTooLong, http.StatusUnprocessableEntity},
+		{core.ErrUIDTooLong, http.StatusUnprocessableEntity},
+		{core.ErrTooManyTasks, http.StatusUnprocessableEntity},
+		{core.ErrDuplicateTaskID, http.StatusUnprocessableEntity},
+		{core.ErrInvalidRows, http.StatusUnprocessableEntity},
+		{core.ErrAuthorMustNotBeEmpty, http.StatusUnprocessableEntity},
+		{core.ErrBodyMustNotBeEmpty, http.StatusUnprocessableEntity},
+		{core.ErrAuthorTooLong, http.StatusUnprocessableEntity},
+		{core.ErrFilenameMustNotBeEmpty, http.StatusUnprocessableEntity},
+		{core.ErrFilenameTooLong, http.StatusUnprocessableEntity},
+		{core.ErrAttachmentTooLarge, http.StatusRequestEntityTooLarge},
+		{core.ErrAttachmentTypeNotAllowed, http.StatusUnsupportedMediaType},
+		{core.ErrQueryMustNotBeEmpty, http.StatusBadRequest},
+		{core.ErrOwnerMustNotBeEmpty, http.StatusBadRequest},
+		{core.ErrOwnerTooLong, http.StatusBadRequest},
+		{core.ErrFilterTooLong, http.StatusUnprocessableEntity},
+		{core.ErrViewIsReadOnly, http.StatusForbidden},
+		{core.ErrInvalidSortOrder, http.StatusUnprocessableEntity},
+		{core.ErrInvalidWebhookURL, http.StatusUnprocessableEntity},
+		{core.ErrUnknownEventType, http.StatusUnprocessableEntity},
+		{core.ErrWebhookURLTooLong, http.StatusUnprocessableEntity},
+		{core.ErrSecretTooLong, http.StatusUnprocessableEntity},
+		{core.ErrStorageNotEmpty, http.StatusConflict},
+		{core.ErrBatchEmpty, http.StatusUnprocessableEntity},
+		{core.ErrBatchTooLarge, http.StatusUnprocessableEntity},
+		{core.ErrUnknownBatchAction, http.StatusUnprocessableEntity},
+		{core.ErrBatchIDMissing, http.StatusUnprocessableEntity},
+		{core.ErrBatchToDoMissing, http.StatusUnprocessableEntity},
+		{core.ErrDuplicateBatchID, http.StatusUnprocessableEntity},
+		{core.ErrOperationNotApplied, http.StatusFailedDependency},
+		{core.ErrIdempotencyKeyTooLong, http.StatusBadRequest},
+		{core.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity},
+		{core.ErrIdempotentRequestInProgress, http.StatusConflict},
+		{backup.ErrInvalidArchive, http.StatusBadRequest},
+		{backup.ErrUnsupportedVersion, http.StatusBadRequest},
+		{backup.ErrChecksumMismatch, http.StatusBadRequest},
+		{backup.ErrTruncated, http.StatusBadRequest},
+		{codec.ErrUnsupportedMediaType, http.StatusUnsupportedMediaType},
+		{codec.ErrNotAcceptable, http.StatusNotAcceptable},
+	}
 
-	// Return status 500 for all errors that are not nil and not registered.
-	if err != nil && !isRegistered {
-		return http.StatusInternalServerError
+	for _, registered := range statusCodes {
+		if errors.Is(err, registered.err) {
+			return registered.statusCode
+		}
 	}
 
-	return statusCode
+	// Return status 500 for all errors that are not registered.
+	return http.StatusInternalServerError
 }
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/dominikbraun/todo/core"
@@ -18,9 +19,14 @@ import (
 )
 
 // newTestRESTController creates a new REST controller that uses a core.App
-// instance backend by an in-memory storage.
-func newTestRESTController() *RESTController {
-	app := core.NewApp(storage.NewMemory())
+// instance backend by an in-memory storage and a temporary blob store.
+func newTestRESTController(t *testing.T) *RESTController {
+	fileSystem, err := storage.NewFileSystem(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create blob store: %s", err.Error())
+	}
+
+	app := core.NewApp(storage.NewMemory(), fileSystem)
 
 	return &RESTController{
 		app: app,
@@ -28,7 +34,7 @@ func newTestRESTController() *RESTController {
 }
 
 func TestRESTController_CreateToDo(t *testing.T) {
-	restController := newTestRESTController()
+	restController := newTestRESTController(t)
 	toDo := model.ToDo{
 		Name: "ToDo 1",
 		Tasks: []model.Task{
@@ -58,7 +64,7 @@ func TestRESTController_CreateToDo(t *testing.T) {
 }
 
 func TestRESTController_GetToDos(t *testing.T) {
-	restController := newTestRESTController()
+	restController := newTestRESTController(t)
 	toDos := []model.ToDo{
 		{
 			Name: "ToDo 1",
@@ -111,8 +117,56 @@ func TestRESTController_GetToDos(t *testing.T) {
 	}
 }
 
+func TestRESTController_GetToDos_Filter(t *testing.T) {
+	restController := newTestRESTController(t)
+	toDos := []model.ToDo{
+		{
+			Name: "ToDo 1",
+			Tags: []string{"release"},
+		},
+		{
+			Name: "ToDo 2",
+		},
+	}
+
+	for _, toDo := range toDos {
+		_, _ = restController.app.CreateToDo(toDo)
+	}
+
+	router := chi.NewRouter()
+	router.Get("/todos", restController.GetToDos())
+
+	request := httptest.NewRequest("GET", "/todos?filter="+url.QueryEscape("tag:release AND (done"), nil)
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, recorder.Code)
+	}
+
+	request = httptest.NewRequest("GET", "/todos?filter="+url.QueryEscape("tag:release AND NOT done"), nil)
+	recorder = httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	var response []model.ToDo
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal("could not parse response body")
+	}
+
+	if len(response) != 1 {
+		t.Fatalf("expected %d ToDos, got %d", 1, len(response))
+	}
+}
+
 func TestRESTController_GetToDo(t *testing.T) {
-	restController := newTestRESTController()
+	restController := newTestRESTController(t)
 	toDo := model.ToDo{
 		Name:        "ToDo 1",
 		Description: "My ToDo",
@@ -149,7 +203,7 @@ func TestRESTController_GetToDo(t *testing.T) {
 }
 
 func TestRESTController_UpdateToDo(t *testing.T) {
-	restController := newTestRESTController()
+	restController := newTestRESTController(t)
 	toDo := model.ToDo{
 		Name: "ToDo 1",
 		Tasks: []model.Task{
@@ -200,7 +254,7 @@ func TestRESTController_UpdateToDo(t *testing.T) {
 }
 
 func TestRESTController_DeleteToDo(t *testing.T) {
-	restController := newTestRESTController()
+	restController := newTestRESTController(t)
 	toDo := model.ToDo{
 		Name:        "ToDo 1",
 		Description: "My ToDo",
@@ -229,3 +283,35 @@ func TestRESTController_DeleteToDo(t *testing.T) {
 		t.Errorf("expected status %d, got %d", http.StatusOK, recorder.Code)
 	}
 }
+
+func TestStatusCodeForError(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{nil, http.StatusOK},
+		{storage.ErrToDoNotFound, http.StatusNotFound},
+		{fmt.Errorf("row 2: %w", core.ErrNameTooLong), http.StatusUnprocessableEntity},
+		{core.ErrOwnerMustNotBeEmpty, http.StatusBadRequest},
+		{fmt.Errorf("unknown"), http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		if statusCode := statusCodeForError(test.err); statusCode != test.expected {
+			t.Errorf("%v: expected status %d, got %d", test.err, test.expected, statusCode)
+		}
+	}
+
+	// An error matching several registered errors always gets the status
+	// code of the first one.
+	err := &core.ValidationError{Violations: []core.Violation{
+		{Field: "/sort", Err: core.ErrInvalidSortOrder},
+		{Field: "/owner", Err: core.ErrOwnerTooLong},
+	}}
+
+	for i := 0; i < 20; i++ {
+		if statusCode := statusCodeForError(err); statusCode != http.StatusBadRequest {
+			t.Fatalf("expected status %d, got %d", http.StatusBadRequest, statusCode)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"net/http"
+)
+
+// Search processes a GET request for a full-text search across all ToDo items,
+// tasks and comments.
+//
+// Expects the `q` query parameter.
+func (r *RESTController) Search() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		results, err := r.app.Search(request.URL.Query().Get("q"))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, results)
+	}
+}
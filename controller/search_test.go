@@ -0,0 +1,55 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+)
+
+func TestRESTController_Search(t *testing.T) {
+	restController := newTestRESTController(t)
+	toDo := model.ToDo{
+		Name:        "ToDo 1",
+		Description: "Prepare the release",
+	}
+
+	_, _ = restController.app.CreateToDo(toDo)
+
+	router := chi.NewRouter()
+	router.Get("/search", restController.Search())
+
+	request := httptest.NewRequest("GET", "/search", nil)
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, recorder.Code)
+	}
+
+	request = httptest.NewRequest("GET", "/search?q=release", nil)
+	recorder = httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	var response []model.SearchResult
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal("could not parse response body")
+	}
+
+	if len(response) != 1 {
+		t.Fatalf("expected %d results, got %d", 1, len(response))
+	}
+}
@@ -0,0 +1,82 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dominikbraun/todo/todotxt"
+)
+
+// maxImportSize is the maximum size of an imported file in bytes.
+const maxImportSize = 10 << 20
+
+// ExportToDoTxt processes a GET request for exporting all ToDo items as a
+// todo.txt file.
+//
+// If the `filter` query parameter is set, only the ToDo items matching the
+// filter query will be exported.
+func (r *RESTController) ExportToDoTxt() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		toDos, err := r.app.ExportToDos(request.URL.Query().Get("filter"))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		writer.Header().Set("Content-Disposition", `attachment; filename="todo.txt"`)
+
+		_ = todotxt.Encode(writer, toDos)
+	}
+}
+
+// ImportToDoTxt processes a POST request for importing the ToDo items of a
+// todo.txt file sent as request body. The response lists the created items.
+//
+// If the `dry_run` query parameter is true, the items are only validated and
+// listed the way they would be created, without storing them.
+func (r *RESTController) ImportToDoTxt() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if err := checkAccept(request); err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		dryRun, err := parseDryRun(request)
+		if err != nil {
+			respond(writer, request, http.StatusBadRequest, err)
+			return
+		}
+
+		toDos, err := todotxt.Decode(http.MaxBytesReader(writer, request.Body, maxImportSize))
+		if err != nil {
+			respond(writer, request, http.StatusBadRequest, err)
+			return
+		}
+
+		result, err := r.app.ImportToDos(toDos, dryRun)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		status := http.StatusCreated
+		if dryRun {
+			status = http.StatusOK
+		}
+
+		respond(writer, request, status, result)
+	}
+}
+
+// parseDryRun parses the optional `dry_run` query parameter of an import.
+func parseDryRun(request *http.Request) (bool, error) {
+	value := request.URL.Query().Get("dry_run")
+	if value == "" {
+		return false, nil
+	}
+
+	return strconv.ParseBool(value)
+}
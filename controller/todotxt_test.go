@@ -0,0 +1,91 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+)
+
+func TestRESTController_ToDoTxt(t *testing.T) {
+	restController := newTestRESTController(t)
+
+	router := chi.NewRouter()
+	router.Get("/export/todotxt", restController.ExportToDoTxt())
+	router.Post("/import/todotxt", restController.ImportToDoTxt())
+
+	file := "(A) Release 1.0 +website @release due:2026-11-01 id:1\n" +
+		"Write notes p:1\n" +
+		"x Tag commit p:1\n" +
+		"x Call mom @phone pri:B\n"
+
+	tests := []struct {
+		target   string
+		body     string
+		expected int
+	}{
+		{"/import/todotxt?dry_run=maybe", file, http.StatusBadRequest},
+		{"/import/todotxt", "ToDo 1\nToDo 2 due:tomorrow\n", http.StatusBadRequest},
+		{"/import/todotxt?dry_run=true", file, http.StatusOK},
+		{"/import/todotxt", file, http.StatusCreated},
+	}
+
+	var result model.ImportResult
+
+	for _, test := range tests {
+		request := httptest.NewRequest("POST", test.target, strings.NewReader(test.body))
+		recorder := httptest.NewRecorder()
+
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.expected {
+			t.Fatalf("%s: expected status %d, got %d", test.target, test.expected, recorder.Code)
+		}
+
+		if recorder.Code == http.StatusBadRequest {
+			continue
+		}
+
+		if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+			t.Fatal("could not parse response body")
+		}
+
+		if len(result.ToDos) != 2 || len(result.ToDos[0].Tasks) != 2 {
+			t.Errorf("%s: expected 2 ToDo items, the first one with 2 tasks, got %v", test.target, result.ToDos)
+		}
+
+		// The dry run must not have created any items.
+		if stored, _ := restController.app.GetToDos(); result.DryRun && len(stored) != 0 {
+			t.Errorf("expected no ToDo items after dry run, got %d", len(stored))
+		}
+	}
+
+	request := httptest.NewRequest("GET", "/export/todotxt", nil)
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK || !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("expected status %d with text/plain, got %d with %s", http.StatusOK, recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+
+	if recorder.Body.String() != file {
+		t.Errorf("expected exported file %q, got %q", file, recorder.Body.String())
+	}
+
+	request = httptest.NewRequest("GET", "/export/todotxt?filter=tag:phone", nil)
+	recorder = httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	if expected := "x Call mom @phone pri:B\n"; recorder.Body.String() != expected {
+		t.Errorf("expected exported file %q, got %q", expected, recorder.Body.String())
+	}
+}
@@ -0,0 +1,176 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+)
+
+// userHeader is the request header identifying the user a request is made for.
+// It is used for scoping user-specific resources like views.
+const userHeader = "X-User"
+
+// CreateView processes a POST request for saving a view. It expects a view
+// without ID and returns a view containing the ID.
+//
+// Expects the `X-User` header.
+func (r *RESTController) CreateView() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		var view model.View
+
+		if err := decodeBody(request, &view); err != nil {
+			respond(writer, request, statusCodeForBody(err), err)
+			return
+		}
+
+		createdView, err := r.app.CreateView(request.Header.Get(userHeader), view)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, createdView)
+	}
+}
+
+// GetViews processes a GET request for listing the built-in views as well as
+// all views of the requesting user.
+//
+// Expects the `X-User` header.
+func (r *RESTController) GetViews() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		views, err := r.app.GetViews(request.Header.Get(userHeader))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, views)
+	}
+}
+
+// GetView processes a GET request for retrieving a single view.
+//
+// Expects the `X-User` header and the `id` URL parameter, which is either the
+// ID of a saved view or the key of a built-in view.
+func (r *RESTController) GetView() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		view, err := r.resolveView(request)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, view)
+	}
+}
+
+// UpdateView processes a PUT request for updating a saved view. The view with
+// the given ID will be overridden by the view in the request body.
+//
+// Expects the `X-User` header and the `id` URL parameter.
+func (r *RESTController) UpdateView() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := viewID(request)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		var view model.View
+
+		if err := decodeBody(request, &view); err != nil {
+			respond(writer, request, statusCodeForBody(err), err)
+			return
+		}
+
+		err = r.app.UpdateView(request.Header.Get(userHeader), id, view)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, nil)
+	}
+}
+
+// DeleteView processes a DELETE request for deleting a saved view.
+//
+// Expects the `X-User` header and the `id` URL parameter.
+func (r *RESTController) DeleteView() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := viewID(request)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		err = r.app.DeleteView(request.Header.Get(userHeader), id)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, nil)
+	}
+}
+
+// GetViewToDos processes a GET request for listing all ToDo items matching a
+// view. The view is evaluated at request time, so the result always reflects
+// the current state of the ToDo items.
+//
+// Expects the `X-User` header and the `id` URL parameter, which is either the
+// ID of a saved view or the key of a built-in view.
+func (r *RESTController) GetViewToDos() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		view, err := r.resolveView(request)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		toDos, err := r.app.GetViewToDos(view)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, toDos)
+	}
+}
+
+// resolveView returns the view identified by the `id` URL parameter. Built-in
+// views take precedence over saved views of the requesting user.
+func (r *RESTController) resolveView(request *http.Request) (model.View, error) {
+	if view, exists := core.BuiltInView(chi.URLParam(request, "id")); exists {
+		return view, nil
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(request, "id"))
+	if err != nil {
+		return model.View{}, err
+	}
+
+	return r.app.GetView(request.Header.Get(userHeader), int64(id))
+}
+
+// viewID reads the ID of a saved view from the `id` URL parameter. Built-in
+// views are rejected since they can't be modified.
+func viewID(request *http.Request) (int64, error) {
+	if _, exists := core.BuiltInView(chi.URLParam(request, "id")); exists {
+		return 0, core.ErrViewIsReadOnly
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(request, "id"))
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(id), nil
+}
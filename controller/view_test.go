@@ -0,0 +1,75 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+)
+
+func TestRESTController_Views(t *testing.T) {
+	restController := newTestRESTController(t)
+
+	_, _ = restController.app.CreateToDo(model.ToDo{Name: "ToDo 1", Tags: []string{"release"}})
+	_, _ = restController.app.CreateToDo(model.ToDo{Name: "ToDo 2", Done: true})
+
+	router := chi.NewRouter()
+	router.Post("/views", restController.CreateView())
+	router.Get("/views", restController.GetViews())
+	router.Delete("/views/{id}", restController.DeleteView())
+	router.Get("/views/{id}/todos", restController.GetViewToDos())
+
+	tests := []struct {
+		method   string
+		path     string
+		user     string
+		body     string
+		expected int
+	}{
+		{"POST", "/views", "", `{"name":"Release","filter":"tag:release"}`, http.StatusBadRequest},
+		{"POST", "/views", "alice", `{"name":"Release","filter":"tag:"}`, http.StatusBadRequest},
+		{"POST", "/views", "alice", `{"name":"Release","filter":"tag:release"}`, http.StatusOK},
+		{"GET", "/views/1/todos", "alice", "", http.StatusOK},
+		{"GET", "/views/1/todos", "bob", "", http.StatusNotFound},
+		{"GET", "/views/unfinished/todos", "bob", "", http.StatusOK},
+		{"DELETE", "/views/today", "alice", "", http.StatusForbidden},
+		{"DELETE", "/views/1", "alice", "", http.StatusOK},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		if test.user != "" {
+			request.Header.Set(userHeader, test.user)
+		}
+		recorder := httptest.NewRecorder()
+
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.expected {
+			t.Errorf("%s %s: expected status %d, got %d", test.method, test.path, test.expected, recorder.Code)
+		}
+	}
+
+	request := httptest.NewRequest("GET", "/views/unfinished/todos", nil)
+	request.Header.Set(userHeader, "alice")
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	var response []model.ToDo
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal("could not parse response body")
+	}
+
+	if len(response) != 1 || response[0].Name != "ToDo 1" {
+		t.Errorf("expected only ToDo 1 to be unfinished, got %v", response)
+	}
+}
@@ -0,0 +1,119 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+)
+
+// CreateWebhook processes a POST request for registering a webhook. It expects
+// a webhook without ID and returns a webhook containing the ID and secret.
+func (r *RESTController) CreateWebhook() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		var webhook model.Webhook
+
+		if err := decodeBody(request, &webhook); err != nil {
+			respond(writer, request, statusCodeForBody(err), err)
+			return
+		}
+
+		createdWebhook, err := r.app.CreateWebhook(webhook)
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, createdWebhook)
+	}
+}
+
+// GetWebhooks processes a GET request for listing all webhooks. The secrets of
+// the webhooks are omitted.
+func (r *RESTController) GetWebhooks() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		webhooks, err := r.app.GetWebhooks()
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		for i := range webhooks {
+			webhooks[i].Secret = ""
+		}
+
+		respond(writer, request, http.StatusOK, webhooks)
+	}
+}
+
+// GetWebhook processes a GET request for retrieving a single webhook. The
+// secret of the webhook is omitted.
+//
+// Expects the `id` URL parameter.
+func (r *RESTController) GetWebhook() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(request, "id"))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		webhook, err := r.app.GetWebhook(int64(id))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		webhook.Secret = ""
+
+		respond(writer, request, http.StatusOK, webhook)
+	}
+}
+
+// DeleteWebhook processes a DELETE request for deleting a webhook. Pending
+// deliveries of the webhook won't be sent anymore.
+//
+// Expects the `id` URL parameter.
+func (r *RESTController) DeleteWebhook() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(request, "id"))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		err = r.app.DeleteWebhook(int64(id))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, nil)
+	}
+}
+
+// GetDeliveries processes a GET request for listing the delivery log of a
+// webhook, including pending, succeeded and failed deliveries.
+//
+// Expects the `id` URL parameter.
+func (r *RESTController) GetDeliveries() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(request, "id"))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		deliveries, err := r.app.GetDeliveries(int64(id))
+		if err != nil {
+			respond(writer, request, statusCodeForError(err), err)
+			return
+		}
+
+		respond(writer, request, http.StatusOK, deliveries)
+	}
+}
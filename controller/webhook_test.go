@@ -0,0 +1,89 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+)
+
+func TestRESTController_Webhooks(t *testing.T) {
+	restController := newTestRESTController(t)
+
+	router := chi.NewRouter()
+	router.Post("/webhooks", restController.CreateWebhook())
+	router.Get("/webhooks", restController.GetWebhooks())
+	router.Get("/webhooks/{id}", restController.GetWebhook())
+	router.Delete("/webhooks/{id}", restController.DeleteWebhook())
+	router.Get("/webhooks/{id}/deliveries", restController.GetDeliveries())
+
+	tests := []struct {
+		method   string
+		path     string
+		body     string
+		expected int
+	}{
+		{"POST", "/webhooks", `{"url":"example.com"}`, http.StatusUnprocessableEntity},
+		{"POST", "/webhooks", `{"url":"https://example.com/hook","events":["deleted"]}`, http.StatusOK},
+		{"GET", "/webhooks/1", "", http.StatusOK},
+		{"GET", "/webhooks/2", "", http.StatusNotFound},
+		{"GET", "/webhooks/2/deliveries", "", http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		recorder := httptest.NewRecorder()
+
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.expected {
+			t.Errorf("%s %s: expected status %d, got %d", test.method, test.path, test.expected, recorder.Code)
+		}
+	}
+
+	toDo, _ := restController.app.CreateToDo(model.ToDo{Name: "ToDo 1"})
+	_ = restController.app.DeleteToDo(toDo.ID)
+
+	request := httptest.NewRequest("GET", "/webhooks/1/deliveries", nil)
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	var response []model.Delivery
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal("could not parse response body")
+	}
+
+	if len(response) != 1 || response[0].EventType != model.EventDeleted {
+		t.Errorf("expected a single delivery of the deleted event, got %v", response)
+	}
+
+	// The secret is only returned when the webhook is created.
+	for _, path := range []string{"/webhooks", "/webhooks/1"} {
+		request = httptest.NewRequest("GET", path, nil)
+		recorder = httptest.NewRecorder()
+
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != http.StatusOK || strings.Contains(recorder.Body.String(), "secret") {
+			t.Errorf("GET %s: expected a response without secret, got %d %s", path, recorder.Code, recorder.Body.String())
+		}
+	}
+
+	request = httptest.NewRequest("DELETE", "/webhooks/1", nil)
+	recorder = httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+}
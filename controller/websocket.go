@@ -0,0 +1,276 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/gorilla/websocket"
+)
+
+// The message types of the WebSocket API. Clients send subscribe, unsubscribe
+// and task messages, the server replies with ack or error messages and sends
+// event messages for all subscribed ToDo items.
+const (
+	messageSubscribe   = "subscribe"
+	messageUnsubscribe = "unsubscribe"
+	messageAddTask     = "add_task"
+	messageUpdateTask  = "update_task"
+	messageDeleteTask  = "delete_task"
+	messageAck         = "ack"
+	messageError       = "error"
+	messageEvent       = "event"
+)
+
+const (
+	// socketWriteTimeout is the maximum duration for sending a single message.
+	socketWriteTimeout = 10 * time.Second
+
+	// socketReadTimeout is the maximum duration without any message or pong
+	// from the client, after which the connection is closed.
+	socketReadTimeout = 60 * time.Second
+
+	// socketPingInterval is the interval in which pings are sent to the client.
+	// It has to be shorter than socketReadTimeout, so that the client is able
+	// to respond in time.
+	socketPingInterval = socketReadTimeout * 9 / 10
+
+	// maxSocketMessageSize is the maximum size of a message from the client in
+	// bytes. Larger messages close the connection.
+	maxSocketMessageSize = 64 << 10
+)
+
+var (
+	// errUnknownMessageType indicates that a WebSocket message has an unknown
+	// or missing type.
+	errUnknownMessageType = errors.New("unknown message type")
+
+	// errTaskMissing indicates that a task message doesn't contain a task.
+	errTaskMissing = errors.New("message must contain a task")
+)
+
+var upgrader = websocket.Upgrader{}
+
+// socketMessage is a JSON message sent over a WebSocket connection in either
+// direction. Depending on the type, only some of the fields are set.
+type socketMessage struct {
+	Type      string       `json:"type"`
+	RequestID string       `json:"request_id,omitempty"`
+	ToDoID    int64        `json:"todo_id,omitempty"`
+	Version   int64        `json:"version,omitempty"`
+	Task      *model.Task  `json:"task,omitempty"`
+	ToDo      *model.ToDo  `json:"todo,omitempty"`
+	Event     *model.Event `json:"event,omitempty"`
+	Status    int          `json:"status,omitempty"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// socketSession holds the state of a single WebSocket connection. The mutex
+// guards the subscribed ToDo items as well as writes to the connection.
+type socketSession struct {
+	conn       *websocket.Conn
+	app        *core.App
+	mutex      sync.Mutex
+	subscribed map[int64]bool
+}
+
+// WebSocket processes a GET request for establishing a WebSocket connection.
+// Clients can subscribe to ToDo items to receive their change events and edit
+// their tasks. Each request message is answered with an ack message containing
+// the current ToDo item, or an error message containing the HTTP status code
+// the REST API would respond with.
+//
+// Task edits may contain the version of the ToDo item they're based on. If the
+// item has been modified in the meantime, the edit is rejected with status 409.
+func (r *RESTController) WebSocket() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		// Upgrade already responds with an HTTP error if the handshake fails.
+		conn, err := upgrader.Upgrade(writer, request, nil)
+		if err != nil {
+			return
+		}
+
+		session := &socketSession{
+			conn:       conn,
+			app:        r.app,
+			subscribed: make(map[int64]bool),
+		}
+
+		session.run()
+	}
+}
+
+// run forwards events to the client and handles incoming messages until the
+// connection is closed. Connections of clients that don't respond to pings are
+// closed after socketReadTimeout.
+func (s *socketSession) run() {
+	defer s.conn.Close()
+
+	s.conn.SetReadLimit(maxSocketMessageSize)
+	s.extendReadDeadline()
+
+	s.conn.SetPongHandler(func(string) error {
+		s.extendReadDeadline()
+		return nil
+	})
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go s.ping(done)
+
+	subscription := s.app.Subscribe(0)
+	defer subscription.Close()
+
+	go func() {
+		for event := range subscription.Events {
+			event := event
+			if s.isSubscribed(event.ToDoID) {
+				_ = s.write(socketMessage{Type: messageEvent, ToDoID: event.ToDoID, Event: &event})
+			}
+		}
+		// The subscription has been closed, either because the connection is
+		// closed or because the client fell too far behind. In the latter case,
+		// closing the connection tells the client to reconnect.
+		_ = s.conn.Close()
+	}()
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		s.extendReadDeadline()
+
+		var message socketMessage
+
+		if err := json.Unmarshal(data, &message); err != nil {
+			_ = s.write(errorMessage(message, http.StatusUnprocessableEntity, err))
+			continue
+		}
+
+		if err := s.write(s.handle(message)); err != nil {
+			return
+		}
+	}
+}
+
+// handle processes a single message from the client and returns the reply.
+func (s *socketSession) handle(message socketMessage) socketMessage {
+	var (
+		toDo model.ToDo
+		err  error
+	)
+
+	switch message.Type {
+	case messageSubscribe:
+		if toDo, err = s.app.GetToDo(message.ToDoID); err == nil {
+			s.setSubscribed(message.ToDoID, true)
+		}
+	case messageUnsubscribe:
+		s.setSubscribed(message.ToDoID, false)
+		return socketMessage{Type: messageAck, RequestID: message.RequestID, ToDoID: message.ToDoID}
+	case messageAddTask, messageUpdateTask, messageDeleteTask:
+		if message.Task == nil {
+			return errorMessage(message, http.StatusUnprocessableEntity, errTaskMissing)
+		}
+		toDo, err = s.editTask(message)
+	default:
+		return errorMessage(message, http.StatusBadRequest, errUnknownMessageType)
+	}
+
+	if err != nil {
+		return errorMessage(message, statusCodeForError(err), err)
+	}
+
+	return socketMessage{
+		Type:      messageAck,
+		RequestID: message.RequestID,
+		ToDoID:    toDo.ID,
+		ToDo:      &toDo,
+	}
+}
+
+// editTask runs the task edit requested by a message.
+func (s *socketSession) editTask(message socketMessage) (model.ToDo, error) {
+	switch message.Type {
+	case messageAddTask:
+		return s.app.AddTask(message.ToDoID, message.Version, *message.Task)
+	case messageUpdateTask:
+		return s.app.UpdateTask(message.ToDoID, message.Version, *message.Task)
+	default:
+		return s.app.DeleteTask(message.ToDoID, message.Version, message.Task.ID)
+	}
+}
+
+// isSubscribed reports whether the client has subscribed to the ToDo item.
+func (s *socketSession) isSubscribed(toDoID int64) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.subscribed[toDoID]
+}
+
+// setSubscribed subscribes or unsubscribes the client to or from a ToDo item.
+func (s *socketSession) setSubscribed(toDoID int64, subscribed bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if subscribed {
+		s.subscribed[toDoID] = true
+	} else {
+		delete(s.subscribed, toDoID)
+	}
+}
+
+// ping sends pings to the client in the socketPingInterval until done is closed.
+func (s *socketSession) ping(done <-chan struct{}) {
+	ticker := time.NewTicker(socketPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(socketWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// extendReadDeadline gives the client another socketReadTimeout to send a
+// message or pong.
+func (s *socketSession) extendReadDeadline() {
+	_ = s.conn.SetReadDeadline(time.Now().Add(socketReadTimeout))
+}
+
+// write sends a message to the client. It is safe for concurrent use.
+func (s *socketSession) write(message socketMessage) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_ = s.conn.SetWriteDeadline(time.Now().Add(socketWriteTimeout))
+
+	return s.conn.WriteJSON(message)
+}
+
+// errorMessage creates an error reply to the given message.
+func errorMessage(message socketMessage, status int, err error) socketMessage {
+	return socketMessage{
+		Type:      messageError,
+		RequestID: message.RequestID,
+		ToDoID:    message.ToDoID,
+		Status:    status,
+		Error:     err.Error(),
+	}
+}
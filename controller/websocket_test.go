@@ -0,0 +1,133 @@
+// Package controller provides application controllers that convert incoming
+// requests to domain models, run business logic on them and return the results.
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/go-chi/chi"
+	"github.com/gorilla/websocket"
+)
+
+func TestRESTController_WebSocket(t *testing.T) {
+	restController := newTestRESTController(t)
+
+	toDo, _ := restController.app.CreateToDo(model.ToDo{
+		Name:  "ToDo 1",
+		Tasks: []model.Task{{Name: "Task 1"}},
+	})
+
+	router := chi.NewRouter()
+	router.Get("/ws", restController.WebSocket())
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	exchange := func(message socketMessage) socketMessage {
+		if err := conn.WriteJSON(message); err != nil {
+			t.Fatal(err)
+		}
+
+		var reply socketMessage
+
+		if err := conn.ReadJSON(&reply); err != nil {
+			t.Fatal(err)
+		}
+
+		return reply
+	}
+
+	reply := exchange(socketMessage{Type: messageSubscribe, RequestID: "1", ToDoID: 42})
+
+	if reply.Type != messageError || reply.Status != http.StatusNotFound {
+		t.Errorf("expected error with status %d, got %v", http.StatusNotFound, reply)
+	}
+
+	reply = exchange(socketMessage{Type: messageSubscribe, RequestID: "2", ToDoID: toDo.ID})
+
+	if reply.Type != messageAck || reply.RequestID != "2" || reply.ToDo.Version != 1 {
+		t.Fatalf("expected ack with ToDo, got %v", reply)
+	}
+
+	task := model.Task{ID: toDo.Tasks[0].ID, Name: "Renamed"}
+	message := socketMessage{Type: messageUpdateTask, RequestID: "3", ToDoID: toDo.ID, Version: 1, Task: &task}
+
+	// The event caused by the edit may arrive before or after the ack.
+	if err := conn.WriteJSON(message); err != nil {
+		t.Fatal(err)
+	}
+
+	received := make(map[string]socketMessage)
+
+	for i := 0; i < 2; i++ {
+		var reply socketMessage
+		if err := conn.ReadJSON(&reply); err != nil {
+			t.Fatal(err)
+		}
+		received[reply.Type] = reply
+	}
+
+	if ack := received[messageAck]; ack.ToDo == nil || ack.ToDo.Version != 2 {
+		t.Errorf("expected ack with version %d, got %v", 2, ack)
+	}
+
+	if event := received[messageEvent]; event.Event == nil || event.Event.Type != model.EventUpdated {
+		t.Errorf("expected update event, got %v", event)
+	}
+
+	// Sending the same edit again is based on an outdated version.
+	reply = exchange(message)
+
+	if reply.Type != messageError || reply.Status != http.StatusConflict {
+		t.Errorf("expected error with status %d, got %v", http.StatusConflict, reply)
+	}
+
+	task = model.Task{ID: toDo.Tasks[0].ID}
+	reply = exchange(socketMessage{Type: messageUpdateTask, RequestID: "4", ToDoID: toDo.ID, Task: &task})
+
+	if reply.Type != messageError || reply.Status != http.StatusUnprocessableEntity {
+		t.Errorf("expected error with status %d, got %v", http.StatusUnprocessableEntity, reply)
+	}
+}
+
+func TestRESTController_WebSocket_MessageTooLarge(t *testing.T) {
+	restController := newTestRESTController(t)
+
+	router := chi.NewRouter()
+	router.Get("/ws", restController.WebSocket())
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	message := `{"type":"subscribe","request_id":"` + strings.Repeat("a", maxSocketMessageSize) + `"}`
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseMessageTooBig) {
+		t.Errorf("expected close error %d, got %v", websocket.CloseMessageTooBig, err)
+	}
+}
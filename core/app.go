@@ -3,42 +3,132 @@ package core
 
 import (
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/dominikbraun/todo/filter"
+	"github.com/dominikbraun/todo/ical"
 	"github.com/dominikbraun/todo/model"
 	"github.com/dominikbraun/todo/storage"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 var (
 	// ErrNameMustNotBeEmpty indicates that a ToDo or task name is empty.
 	ErrNameMustNotBeEmpty = errors.New("name must not be empty")
+
+	// ErrInvalidPriority indicates that a priority is not a letter from A to Z.
+	ErrInvalidPriority = errors.New("priority must be a letter from A to Z")
+
+	// ErrInvalidRecurrence indicates that a recurrence is not an RFC 5545
+	// recurrence rule. It is wrapped by an error describing the violation.
+	ErrInvalidRecurrence = errors.New("recurrence must be a recurrence rule like FREQ=WEEKLY;BYDAY=MO")
+
+	// ErrNameTooLong indicates that a ToDo or task name is longer than
+	// MaxNameLength characters.
+	ErrNameTooLong = fmt.Errorf("name must not be longer than %d characters", MaxNameLength)
+
+	// ErrDescriptionTooLong indicates that a ToDo or task description is longer
+	// than MaxDescriptionLength characters.
+	ErrDescriptionTooLong = fmt.Errorf("description must not be longer than %d characters", MaxDescriptionLength)
+
+	// ErrProjectTooLong indicates that a project is longer than MaxProjectLength
+	// characters.
+	ErrProjectTooLong = fmt.Errorf("project must not be longer than %d characters", MaxProjectLength)
+
+	// ErrTagTooLong indicates that a tag is longer than MaxTagLength characters.
+	ErrTagTooLong = fmt.Errorf("tag must not be longer than %d characters", MaxTagLength)
+
+	// ErrRecurrenceTooLong indicates that a recurrence is longer than
+	// MaxRecurrenceLength characters.
+	ErrRecurrenceTooLong = fmt.Errorf("recurrence must not be longer than %d characters", MaxRecurrenceLength)
+
+	// ErrUIDTooLong indicates that the UID of a ToDo item or task is longer than
+	// MaxUIDLength characters.
+	ErrUIDTooLong = fmt.Errorf("uid must not be longer than %d characters", MaxUIDLength)
+
+	// ErrTooManyTasks indicates that a ToDo item has more than MaxTasks tasks.
+	ErrTooManyTasks = fmt.Errorf("a ToDo item must not have more than %d tasks", MaxTasks)
+
+	// ErrDuplicateTaskID indicates that several tasks of a ToDo item have the
+	// same ID.
+	ErrDuplicateTaskID = errors.New("task ID is used by several tasks")
+)
+
+// The maximum lengths match the sizes of the columns they are stored in by the
+// MariaDB storage, so that values are never truncated.
+const (
+	// MaxNameLength is the maximum number of characters of ToDo and task names.
+	MaxNameLength = 100
+
+	// MaxDescriptionLength is the maximum number of characters of ToDo and task
+	// descriptions.
+	MaxDescriptionLength = 500
+
+	// MaxProjectLength is the maximum number of characters of a project.
+	MaxProjectLength = 100
+
+	// MaxTagLength is the maximum number of characters of a tag.
+	MaxTagLength = 100
+
+	// MaxRecurrenceLength is the maximum number of characters of a recurrence.
+	MaxRecurrenceLength = 255
+
+	// MaxUIDLength is the maximum number of characters of a ToDo or task UID.
+	MaxUIDLength = 255
+
+	// MaxTasks is the maximum number of tasks of a ToDo item.
+	MaxTasks = 100
 )
 
-// App represents the core application. At this time, it merely consists of an
-// arbitrary storage.Storage implementation for accessing ToDo items.
+// App represents the core application. At this time, it consists of arbitrary
+// storage.Storage and storage.BlobStore implementations for accessing ToDo items
+// and the content of their attachments, and an EventBus publishing all changes
+// of ToDo items.
 type App struct {
-	storage storage.Storage
+	storage           storage.Storage
+	blobStore         storage.BlobStore
+	events            *EventBus
+	idempotencyWindow time.Duration
+	idempotencyLease  time.Duration
 }
 
-// NewApp creates a new App instance that persists data to the given storage.
-func NewApp(storage storage.Storage) *App {
+// NewApp creates a new App instance that persists data to the given storage and
+// attachment contents to the given blob store.
+func NewApp(storage storage.Storage, blobStore storage.BlobStore) *App {
 	return &App{
-		storage: storage,
+		storage:           storage,
+		blobStore:         blobStore,
+		events:            NewEventBus(EventLogSize),
+		idempotencyWindow: DefaultIdempotencyWindow,
+		idempotencyLease:  DefaultIdempotencyLease,
 	}
 }
 
 // CreateToDo creates a new ToDo item. The provided item should not have an ID.
 func (a *App) CreateToDo(toDo model.ToDo) (model.ToDo, error) {
-	if toDo.Name == "" {
-		return model.ToDo{}, ErrNameMustNotBeEmpty
+	toDo = normalizeToDo(toDo)
+
+	if err := validateToDo(toDo); err != nil {
+		return model.ToDo{}, err
 	}
 
-	for _, task := range toDo.Tasks {
-		if task.Name == "" {
-			return model.ToDo{}, ErrNameMustNotBeEmpty
-		}
+	createdToDo, err := a.storage.CreateToDo(toDo)
+	if err != nil {
+		return model.ToDo{}, err
 	}
 
-	return a.storage.CreateToDo(toDo)
+	if err := a.storage.Reindex(createdToDo.ID); err != nil {
+		return model.ToDo{}, err
+	}
+
+	a.publish(model.EventCreated, createdToDo)
+
+	return createdToDo, nil
 }
 
 // GetToDos returns a list of all stored ToDo items.
@@ -46,28 +136,248 @@ func (a *App) GetToDos() ([]model.ToDo, error) {
 	return a.storage.FindToDos()
 }
 
+// GetToDosByFilter returns a list of all ToDo items matching the given filter
+// query. If the query is invalid, a *filter.SyntaxError will be returned.
+func (a *App) GetToDosByFilter(query string) ([]model.ToDo, error) {
+	expression, err := filter.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.storage.FindToDosByFilter(expression)
+}
+
+// GetToDoPage returns up to limit ToDo items with an ID greater than afterID
+// ordered by their ID, along with the total number of ToDo items. If the filter
+// query is not empty, only the items matching it are considered. If the query
+// is invalid, a *filter.SyntaxError will be returned.
+func (a *App) GetToDoPage(query string, afterID int64, limit int) ([]model.ToDo, int, error) {
+	var expression filter.Expression
+
+	if query != "" {
+		parsed, err := filter.Parse(query)
+		if err != nil {
+			return nil, 0, err
+		}
+		expression = parsed
+	}
+
+	return a.storage.FindToDoPage(expression, afterID, limit)
+}
+
 // GetToDo returns the ToDo with the given ID or an error if it doesn't exist.
 func (a *App) GetToDo(id int64) (model.ToDo, error) {
 	return a.storage.FindToDoByID(id)
 }
 
 // UpdateToDo updates a ToDo item by replacing the stored item with the given ID
-// with the provided item.
+// with the provided item. If the item is marked as done by the update, an
+// EventCompleted will be published instead of an EventUpdated.
 func (a *App) UpdateToDo(id int64, toDo model.ToDo) error {
-	if toDo.Name == "" {
-		return ErrNameMustNotBeEmpty
+	toDo = normalizeToDo(toDo)
+
+	if err := validateToDo(toDo); err != nil {
+		return err
 	}
 
-	for _, task := range toDo.Tasks {
-		if task.Name == "" {
-			return ErrNameMustNotBeEmpty
-		}
+	previous, err := a.storage.FindToDoByID(id)
+	if err != nil {
+		return err
+	}
+
+	keepUIDs(&toDo, previous)
+
+	if err := a.storage.UpdateToDo(id, toDo); err != nil {
+		return err
 	}
 
-	return a.storage.UpdateToDo(id, toDo)
+	if err := a.storage.Reindex(id); err != nil {
+		return err
+	}
+
+	if err := a.deleteAttachmentsOfRemovedTasks(id, toDo.Tasks); err != nil {
+		return err
+	}
+
+	updated, err := a.storage.FindToDoByID(id)
+	if err != nil {
+		return err
+	}
+
+	if updated.Done && !previous.Done {
+		a.publish(model.EventCompleted, updated)
+	} else {
+		a.publish(model.EventUpdated, updated)
+	}
+
+	return nil
 }
 
-// DeleteToDo deletes the ToDo item with the given ID along with its sub-tasks.
+// DeleteToDo deletes the ToDo item with the given ID along with its sub-tasks,
+// comments and attachments.
 func (a *App) DeleteToDo(id int64) error {
-	return a.storage.DeleteToDo(id)
+	toDo, err := a.storage.FindToDoByID(id)
+	if err != nil {
+		return err
+	}
+
+	attachments, err := a.storage.FindAttachments(id)
+	if err != nil {
+		return err
+	}
+
+	if err := a.storage.DeleteToDo(id); err != nil {
+		return err
+	}
+
+	if err := a.storage.Reindex(id); err != nil {
+		return err
+	}
+
+	// The attachment metadata has been deleted along with the ToDo item, so
+	// only the blobs are left to be deleted.
+	for _, attachment := range attachments {
+		if err := a.blobStore.Delete(attachment.BlobKey); err != nil {
+			return err
+		}
+	}
+
+	a.publish(model.EventDeleted, toDo)
+
+	return nil
+}
+
+// validateToDo checks whether a normalized ToDo item and its tasks can be
+// stored. All invalid fields are reported by a *ValidationError.
+func validateToDo(toDo model.ToDo) error {
+	var v validator
+
+	validateText(&v, "", toDo.Name, toDo.Description, toDo.UID)
+
+	if len(toDo.Tasks) > MaxTasks {
+		v.add("/tasks", ErrTooManyTasks)
+	}
+
+	taskIDs := make(map[int64]bool)
+
+	for i, task := range toDo.Tasks {
+		validateText(&v, fmt.Sprintf("/tasks/%d", i), task.Name, task.Description, task.UID)
+
+		if task.ID != 0 && taskIDs[task.ID] {
+			v.add(fmt.Sprintf("/tasks/%d/id", i), ErrDuplicateTaskID)
+		}
+		taskIDs[task.ID] = true
+	}
+
+	if utf8.RuneCountInString(toDo.Project) > MaxProjectLength {
+		v.add("/project", ErrProjectTooLong)
+	}
+
+	if p := toDo.Priority; p != "" && (len(p) != 1 || p[0] < 'A' || p[0] > 'Z') {
+		v.add("/priority", ErrInvalidPriority)
+	}
+
+	for i, tag := range toDo.Tags {
+		if utf8.RuneCountInString(tag) > MaxTagLength {
+			v.add(fmt.Sprintf("/tags/%d", i), ErrTagTooLong)
+		}
+	}
+
+	if utf8.RuneCountInString(toDo.Recurrence) > MaxRecurrenceLength {
+		v.add("/recurrence", ErrRecurrenceTooLong)
+	} else if toDo.Recurrence != "" {
+		if err := ical.ValidateRecurrence(toDo.Recurrence); err != nil {
+			v.add("/recurrence", fmt.Errorf("%w: %s", ErrInvalidRecurrence, err.Error()))
+		}
+	}
+
+	return v.err()
+}
+
+// validateText checks the name, description and UID of a ToDo item or task,
+// whose fields are prefixed with the given JSON pointer.
+func validateText(v *validator, prefix, name, description, uid string) {
+	if name == "" {
+		v.add(prefix+"/name", ErrNameMustNotBeEmpty)
+	} else if utf8.RuneCountInString(name) > MaxNameLength {
+		v.add(prefix+"/name", ErrNameTooLong)
+	}
+
+	if utf8.RuneCountInString(description) > MaxDescriptionLength {
+		v.add(prefix+"/description", ErrDescriptionTooLong)
+	}
+
+	if utf8.RuneCountInString(uid) > MaxUIDLength {
+		v.add(prefix+"/uid", ErrUIDTooLong)
+	}
+}
+
+// normalizeToDo returns a copy of a ToDo item whose texts are converted to the
+// Unicode normalization form NFC, so that equal texts are stored the same way
+// and their lengths are counted the same way. Names and the project are also
+// trimmed, which makes names consisting of whitespace empty, and tags are
+// normalized by normalizeTags.
+func normalizeToDo(toDo model.ToDo) model.ToDo {
+	toDo.Name = strings.TrimSpace(norm.NFC.String(toDo.Name))
+	toDo.Description = norm.NFC.String(toDo.Description)
+	toDo.Project = strings.TrimSpace(norm.NFC.String(toDo.Project))
+	toDo.Tags = normalizeTags(toDo.Tags)
+
+	// Copy the tasks so that the caller's slice isn't modified.
+	toDo.Tasks = append([]model.Task(nil), toDo.Tasks...)
+
+	for i, task := range toDo.Tasks {
+		toDo.Tasks[i].Name = strings.TrimSpace(norm.NFC.String(task.Name))
+		toDo.Tasks[i].Description = norm.NFC.String(task.Description)
+	}
+
+	return toDo
+}
+
+// keepUIDs copies the UIDs of the previous version of a ToDo item and its tasks
+// to the updated item if the update doesn't provide them. This way, updates
+// made by clients that don't know about UIDs don't break calendar sync.
+func keepUIDs(toDo *model.ToDo, previous model.ToDo) {
+	if toDo.UID == "" {
+		toDo.UID = previous.UID
+	}
+
+	// Copy the tasks so that the caller's slice isn't modified.
+	toDo.Tasks = append([]model.Task(nil), toDo.Tasks...)
+
+	for i, task := range toDo.Tasks {
+		for _, previousTask := range previous.Tasks {
+			if task.UID == "" && task.ID != 0 && task.ID == previousTask.ID {
+				toDo.Tasks[i].UID = previousTask.UID
+			}
+		}
+	}
+}
+
+// normalizeTags trims and lower-cases all tags, removes empty and duplicate tags
+// and sorts the remaining tags alphabetically.
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	var normalized []string
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(norm.NFC.String(tag)))
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	sort.Strings(normalized)
+
+	return normalized
+}
+
+// currentTime returns the current time in UTC. Since MariaDB doesn't store any
+// fractional seconds in DATETIME columns, the time is truncated to seconds so
+// that all storage implementations return the same values.
+func currentTime() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
 }
@@ -3,21 +3,33 @@ package core
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
+	"github.com/dominikbraun/todo/filter"
 	"github.com/dominikbraun/todo/model"
 	"github.com/dominikbraun/todo/storage"
 )
 
-// newTestApp creates a new app that is backed by an in-memory storage.
-func newTestApp() *App {
+// newTestApp creates a new app that is backed by an in-memory storage and a
+// temporary blob store.
+func newTestApp(t *testing.T) *App {
+	fileSystem, err := storage.NewFileSystem(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create blob store: %s", err.Error())
+	}
+
 	return &App{
-		storage: storage.NewMemory(),
+		storage:           storage.NewMemory(),
+		blobStore:         fileSystem,
+		events:            NewEventBus(EventLogSize),
+		idempotencyWindow: DefaultIdempotencyWindow,
+		idempotencyLease:  DefaultIdempotencyLease,
 	}
 }
 
 func TestApp_CreateToDo(t *testing.T) {
-	app := newTestApp()
+	app := newTestApp(t)
 	toDo := model.ToDo{
 		Tasks: []model.Task{
 			{
@@ -35,6 +47,31 @@ func TestApp_CreateToDo(t *testing.T) {
 	}
 
 	toDo.Name = "ToDo 1"
+	toDo.Priority = "a"
+
+	_, err = app.CreateToDo(toDo)
+	if !errors.Is(err, ErrInvalidPriority) {
+		t.Errorf("expected error %v, got %v", ErrInvalidPriority, err)
+	}
+
+	toDo.Priority = "A"
+	toDo.Recurrence = "FREQ=WEEKLY;BYDAY=XY"
+
+	_, err = app.CreateToDo(toDo)
+	if !errors.Is(err, ErrInvalidRecurrence) {
+		t.Errorf("expected error %v, got %v", ErrInvalidRecurrence, err)
+	}
+
+	toDo.Recurrence = "FREQ=WEEKLY;BYDAY=MO"
+	toDo.Tasks[1].Description = strings.Repeat("ä", MaxDescriptionLength+1)
+
+	_, err = app.CreateToDo(toDo)
+	if !errors.Is(err, ErrDescriptionTooLong) {
+		t.Errorf("expected error %v, got %v", ErrDescriptionTooLong, err)
+	}
+
+	// Lengths are counted in characters rather than bytes.
+	toDo.Tasks[1].Description = strings.Repeat("ä", MaxDescriptionLength)
 
 	_, err = app.CreateToDo(toDo)
 	if err != nil {
@@ -43,7 +80,7 @@ func TestApp_CreateToDo(t *testing.T) {
 }
 
 func TestApp_UpdateToDo(t *testing.T) {
-	app := newTestApp()
+	app := newTestApp(t)
 	toDo := model.ToDo{
 		Name: "ToDo 1",
 		Tasks: []model.Task{
@@ -73,3 +110,70 @@ func TestApp_UpdateToDo(t *testing.T) {
 		t.Fatalf("error updating ToDo: %s", err.Error())
 	}
 }
+
+func TestApp_UpdateToDo_KeepsUIDs(t *testing.T) {
+	app := newTestApp(t)
+
+	toDo, _ := app.CreateToDo(model.ToDo{
+		Name:  "ToDo 1",
+		UID:   "todo@example.com",
+		Tasks: []model.Task{{Name: "Task 1", UID: "task@example.com"}},
+	})
+
+	// An update made without knowing the UIDs must not remove them.
+	update := model.ToDo{Name: "Renamed", Tasks: []model.Task{{ID: toDo.Tasks[0].ID, Name: "Task 1"}}}
+
+	if err := app.UpdateToDo(toDo.ID, update); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, _ := app.GetToDo(toDo.ID)
+
+	if updated.UID != "todo@example.com" || updated.Tasks[0].UID != "task@example.com" {
+		t.Errorf("expected UIDs to be kept, got %v", updated)
+	}
+
+	if update.Tasks[0].UID != "" {
+		t.Errorf("expected the update not to be modified, got %v", update)
+	}
+}
+
+func TestApp_GetToDosByFilter(t *testing.T) {
+	app := newTestApp(t)
+	toDos := []model.ToDo{
+		{
+			Name: "ToDo 1",
+			Tags: []string{" Release", "release"},
+		},
+		{
+			Name: "ToDo 2",
+			Done: true,
+			Tags: []string{"release"},
+		},
+	}
+
+	for _, toDo := range toDos {
+		_, _ = app.CreateToDo(toDo)
+	}
+
+	_, err := app.GetToDosByFilter("tag:release AND")
+
+	var syntaxError *filter.SyntaxError
+
+	if !errors.As(err, &syntaxError) {
+		t.Errorf("expected a syntax error, got %v", err)
+	}
+
+	filteredToDos, err := app.GetToDosByFilter("tag:release AND NOT done")
+	if err != nil {
+		t.Fatalf("error filtering ToDos: %s", err.Error())
+	}
+
+	if len(filteredToDos) != 1 {
+		t.Fatalf("expected %d ToDos, got %d", 1, len(filteredToDos))
+	}
+
+	if len(filteredToDos[0].Tags) != 1 {
+		t.Errorf("expected tags to be normalized, got %v", filteredToDos[0].Tags)
+	}
+}
@@ -0,0 +1,249 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+const (
+	// MaxAttachmentSize is the maximum size of an attachment's content in bytes.
+	MaxAttachmentSize = 10 << 20
+
+	// MaxFilenameLength is the maximum number of characters of a filename.
+	MaxFilenameLength = 255
+)
+
+var (
+	// ErrFilenameMustNotBeEmpty indicates that an attachment has no filename.
+	ErrFilenameMustNotBeEmpty = errors.New("filename must not be empty")
+
+	// ErrFilenameTooLong indicates that a filename is longer than
+	// MaxFilenameLength characters.
+	ErrFilenameTooLong = fmt.Errorf("filename must not be longer than %d characters", MaxFilenameLength)
+
+	// ErrAttachmentTooLarge indicates that an attachment's content exceeds
+	// MaxAttachmentSize.
+	ErrAttachmentTooLarge = errors.New("attachment exceeds the maximum size")
+
+	// ErrAttachmentTypeNotAllowed indicates that the detected content type of
+	// an attachment is not one of the allowed types.
+	ErrAttachmentTypeNotAllowed = errors.New("attachment type is not allowed")
+)
+
+// allowedAttachmentTypes lists all media types that attachments may have. The
+// type is detected from the content, so that clients can't lie about it.
+// Office documents are detected as ZIP archives.
+var allowedAttachmentTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"application/zip": true,
+	"text/plain":      true,
+}
+
+// CreateAttachment stores the content read from r as an attachment of the ToDo
+// item with the given ID. If taskID is not 0, the attachment will belong to the
+// task with that ID instead.
+//
+// The filename is checked before the content is read. The content type is
+// detected from the first 512 bytes of the content. If the content type is not
+// allowed or the content is larger than MaxAttachmentSize, the attachment will
+// be rejected.
+func (a *App) CreateAttachment(toDoID, taskID int64, filename string, r io.Reader) (model.Attachment, error) {
+	filename = sanitizeFilename(filename)
+	if filename == "" {
+		return model.Attachment{}, ErrFilenameMustNotBeEmpty
+	}
+
+	if utf8.RuneCountInString(filename) > MaxFilenameLength {
+		return model.Attachment{}, ErrFilenameTooLong
+	}
+
+	if err := a.ensureThreadExists(toDoID, taskID); err != nil {
+		return model.Attachment{}, err
+	}
+
+	head := make([]byte, 512)
+
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return model.Attachment{}, err
+	}
+	head = head[:n]
+
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !allowedAttachmentTypes[contentType] {
+		return model.Attachment{}, ErrAttachmentTypeNotAllowed
+	}
+
+	key, err := newBlobKey()
+	if err != nil {
+		return model.Attachment{}, err
+	}
+
+	// Read one byte more than allowed in order to detect oversized content.
+	content := io.LimitReader(io.MultiReader(bytes.NewReader(head), r), MaxAttachmentSize+1)
+
+	size, err := a.blobStore.Put(key, content)
+	if err != nil {
+		return model.Attachment{}, err
+	}
+
+	if size > MaxAttachmentSize {
+		_ = a.blobStore.Delete(key)
+		return model.Attachment{}, ErrAttachmentTooLarge
+	}
+
+	attachment, err := a.storage.CreateAttachment(model.Attachment{
+		ToDoID:      toDoID,
+		TaskID:      taskID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		BlobKey:     key,
+		CreatedAt:   currentTime(),
+	})
+	if err != nil {
+		_ = a.blobStore.Delete(key)
+		return model.Attachment{}, err
+	}
+
+	return attachment, nil
+}
+
+// GetAttachments returns the metadata of all attachments of the ToDo item with
+// the given ID, including the attachments of its tasks.
+func (a *App) GetAttachments(toDoID int64) ([]model.Attachment, error) {
+	if _, err := a.storage.FindToDoByID(toDoID); err != nil {
+		return nil, err
+	}
+
+	return a.storage.FindAttachments(toDoID)
+}
+
+// GetAttachmentsOfToDos returns the metadata of all attachments of the given
+// ToDo items and their tasks with a single storage query. Unknown ToDo IDs are
+// ignored.
+func (a *App) GetAttachmentsOfToDos(toDoIDs []int64) ([]model.Attachment, error) {
+	return a.storage.FindAttachmentsByToDoIDs(toDoIDs)
+}
+
+// OpenAttachment returns the metadata of an attachment of the given ToDo item
+// along with a reader for its content. The reader has to be closed by the
+// caller.
+func (a *App) OpenAttachment(toDoID, id int64) (model.Attachment, io.ReadCloser, error) {
+	attachment, err := a.findAttachmentOfToDo(toDoID, id)
+	if err != nil {
+		return model.Attachment{}, nil, err
+	}
+
+	content, err := a.blobStore.Open(attachment.BlobKey)
+	if err != nil {
+		return model.Attachment{}, nil, err
+	}
+
+	return attachment, content, nil
+}
+
+// DeleteAttachment deletes an attachment of the given ToDo item.
+func (a *App) DeleteAttachment(toDoID, id int64) error {
+	attachment, err := a.findAttachmentOfToDo(toDoID, id)
+	if err != nil {
+		return err
+	}
+
+	return a.deleteAttachment(attachment)
+}
+
+// findAttachmentOfToDo returns the metadata of the attachment with the given
+// ID. If the attachment doesn't belong to the given ToDo item, the error
+// ErrAttachmentNotFound will be returned.
+func (a *App) findAttachmentOfToDo(toDoID, id int64) (model.Attachment, error) {
+	if _, err := a.storage.FindToDoByID(toDoID); err != nil {
+		return model.Attachment{}, err
+	}
+
+	attachment, err := a.storage.FindAttachmentByID(id)
+	if err != nil {
+		return model.Attachment{}, err
+	}
+
+	if attachment.ToDoID != toDoID {
+		return model.Attachment{}, storage.ErrAttachmentNotFound
+	}
+
+	return attachment, nil
+}
+
+// deleteAttachment deletes the metadata and the content of an attachment.
+func (a *App) deleteAttachment(attachment model.Attachment) error {
+	if err := a.storage.DeleteAttachment(attachment.ID); err != nil {
+		return err
+	}
+
+	return a.blobStore.Delete(attachment.BlobKey)
+}
+
+// deleteAttachmentsOfRemovedTasks deletes all attachments of the given ToDo
+// item that belong to a task which is not contained in the given tasks.
+func (a *App) deleteAttachmentsOfRemovedTasks(toDoID int64, tasks []model.Task) error {
+	attachments, err := a.storage.FindAttachments(toDoID)
+	if err != nil {
+		return err
+	}
+
+	taskIDs := make(map[int64]bool)
+
+	for _, task := range tasks {
+		taskIDs[task.ID] = true
+	}
+
+	for _, attachment := range attachments {
+		if attachment.TaskID == 0 || taskIDs[attachment.TaskID] {
+			continue
+		}
+
+		if err := a.deleteAttachment(attachment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sanitizeFilename strips any directories from a client-provided filename.
+func sanitizeFilename(filename string) string {
+	filename = path.Base(strings.ReplaceAll(filename, "\\", "/"))
+
+	if filename == "." || filename == "/" {
+		return ""
+	}
+
+	return filename
+}
+
+// newBlobKey generates a random key for storing a blob in the blob store.
+func newBlobKey() (string, error) {
+	key := make([]byte, 16)
+
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(key), nil
+}
@@ -0,0 +1,82 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+func TestApp_CreateAttachment(t *testing.T) {
+	app := newTestApp(t)
+
+	toDo, _ := app.storage.CreateToDo(model.ToDo{
+		Name: "ToDo 1",
+	})
+
+	_, err := app.CreateAttachment(toDo.ID, 0, "binary.exe", bytes.NewReader([]byte{0x4d, 0x5a, 0x90, 0x00}))
+	if !errors.Is(err, ErrAttachmentTypeNotAllowed) {
+		t.Errorf("expected error %v, got %v", ErrAttachmentTypeNotAllowed, err)
+	}
+
+	tooLarge := strings.NewReader(strings.Repeat("a", MaxAttachmentSize+1))
+
+	_, err = app.CreateAttachment(toDo.ID, 0, "large.txt", tooLarge)
+	if !errors.Is(err, ErrAttachmentTooLarge) {
+		t.Errorf("expected error %v, got %v", ErrAttachmentTooLarge, err)
+	}
+
+	// The filename is rejected without reading the content.
+	longName := strings.Repeat("ä", MaxFilenameLength-3) + ".txt"
+
+	_, err = app.CreateAttachment(toDo.ID, 0, longName, failingReader{})
+	if !errors.Is(err, ErrFilenameTooLong) {
+		t.Errorf("expected error %v, got %v", ErrFilenameTooLong, err)
+	}
+
+	attachment, err := app.CreateAttachment(toDo.ID, 0, "../notes.txt", strings.NewReader("My notes"))
+	if err != nil {
+		t.Fatalf("error creating attachment: %s", err.Error())
+	}
+
+	if attachment.Filename != "notes.txt" {
+		t.Errorf("expected filename %s, got %s", "notes.txt", attachment.Filename)
+	}
+
+	if attachment.ContentType != "text/plain" {
+		t.Errorf("expected content type %s, got %s", "text/plain", attachment.ContentType)
+	}
+}
+
+func TestApp_DeleteToDo(t *testing.T) {
+	app := newTestApp(t)
+
+	toDo, _ := app.storage.CreateToDo(model.ToDo{
+		Name: "ToDo 1",
+	})
+
+	attachment, err := app.CreateAttachment(toDo.ID, 0, "notes.txt", strings.NewReader("My notes"))
+	if err != nil {
+		t.Fatalf("error creating attachment: %s", err.Error())
+	}
+
+	if err := app.DeleteToDo(toDo.ID); err != nil {
+		t.Fatalf("error deleting ToDo: %s", err.Error())
+	}
+
+	if _, err := app.blobStore.Open(attachment.BlobKey); !errors.Is(err, storage.ErrBlobNotFound) {
+		t.Errorf("expected error %v, got %v", storage.ErrBlobNotFound, err)
+	}
+}
+
+// failingReader is a reader whose content can't be read.
+type failingReader struct{}
+
+// Read always returns an error.
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("content must not be read")
+}
@@ -0,0 +1,484 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/dominikbraun/todo/backup"
+	"github.com/dominikbraun/todo/model"
+)
+
+// ErrStorageNotEmpty indicates that a backup can't be restored because the
+// storage already contains data and the restore hasn't been forced.
+var ErrStorageNotEmpty = errors.New("storage is not empty, force the restore to overwrite it")
+
+// attachmentRecord is the record of an attachment in a backup archive, which
+// contains the content of the attachment instead of its blob key.
+type attachmentRecord struct {
+	model.Attachment
+	Content []byte `json:"content"`
+}
+
+// Backup writes a backup archive of all data to w: ToDo items, comments,
+// attachments including their content, views, webhooks with their deliveries
+// and calendar feeds. Entities are written with their IDs, so that they can be
+// restored as they are.
+func (a *App) Backup(w io.Writer) error {
+	writer, err := backup.NewWriter(w, currentTime())
+	if err != nil {
+		return err
+	}
+
+	toDos, err := a.ExportToDos("")
+	if err != nil {
+		return err
+	}
+
+	toDoIDs := make([]int64, len(toDos))
+
+	for i, toDo := range toDos {
+		toDoIDs[i] = toDo.ID
+
+		if err := writer.Write(backup.TypeToDo, toDo); err != nil {
+			return err
+		}
+	}
+
+	comments, err := a.storage.FindCommentsByToDoIDs(toDoIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, comment := range comments {
+		if err := writer.Write(backup.TypeComment, comment); err != nil {
+			return err
+		}
+	}
+
+	attachments, err := a.storage.FindAttachmentsByToDoIDs(toDoIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, attachment := range attachments {
+		content, err := a.readBlob(attachment.BlobKey)
+		if err != nil {
+			return err
+		}
+
+		record := attachmentRecord{
+			Attachment: attachment,
+			Content:    content,
+		}
+
+		if err := writer.Write(backup.TypeAttachment, record); err != nil {
+			return err
+		}
+	}
+
+	views, err := a.storage.FindAllViews()
+	if err != nil {
+		return err
+	}
+
+	for _, view := range views {
+		if err := writer.Write(backup.TypeView, view); err != nil {
+			return err
+		}
+	}
+
+	webhooks, err := a.storage.FindWebhooks()
+	if err != nil {
+		return err
+	}
+
+	for _, webhook := range webhooks {
+		if err := writer.Write(backup.TypeWebhook, webhook); err != nil {
+			return err
+		}
+
+		deliveries, err := a.storage.FindDeliveries(webhook.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, delivery := range deliveries {
+			if err := writer.Write(backup.TypeDelivery, delivery); err != nil {
+				return err
+			}
+		}
+	}
+
+	feeds, err := a.storage.FindCalendarFeeds()
+	if err != nil {
+		return err
+	}
+
+	for _, feed := range feeds {
+		if err := writer.Write(backup.TypeCalendarFeed, feed); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+// Restore restores the backup archive read from r, keeping the IDs of all
+// entities. The archive is verified completely before anything is restored, so
+// that a corrupted or truncated archive doesn't lead to a partial restore.
+//
+// If the storage already contains data, the restore will be refused with the
+// error ErrStorageNotEmpty unless force is true. In that case, all existing data
+// including the attachment contents will be replaced. The existing data is
+// written to a snapshot beforehand, and if the restore fails, the snapshot is
+// restored instead. Should that fail as well, the error contains the path of
+// the snapshot file, which can be restored manually.
+func (a *App) Restore(r io.Reader, force bool) (model.RestoreResult, error) {
+	file, err := ioutil.TempFile("", "todo-restore-*")
+	if err != nil {
+		return model.RestoreResult{}, err
+	}
+
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+
+	if _, err := io.Copy(file, r); err != nil {
+		return model.RestoreResult{}, err
+	}
+
+	result, err := a.readBackup(file, func(record backup.Record) error {
+		return a.restoreRecord(record, true)
+	})
+	if err != nil {
+		return model.RestoreResult{}, err
+	}
+
+	isEmpty, err := a.isEmpty()
+	if err != nil {
+		return model.RestoreResult{}, err
+	}
+
+	if isEmpty {
+		if err := a.restoreArchive(file); err != nil {
+			// Remove the partially restored data, so that the storage is empty
+			// again and the restore can be retried.
+			if clearErr := a.reset(nil); clearErr != nil {
+				return model.RestoreResult{}, fmt.Errorf("%w; removing the restored data failed: %s", err, clearErr.Error())
+			}
+			return model.RestoreResult{}, err
+		}
+		return result, nil
+	}
+
+	if !force {
+		return model.RestoreResult{}, ErrStorageNotEmpty
+	}
+
+	snapshot, err := a.writeSnapshot()
+	if err != nil {
+		return model.RestoreResult{}, err
+	}
+
+	// The contents of the existing attachments are only deleted once they have
+	// been replaced, since the snapshot doesn't contain their blob keys.
+	blobKeys, err := a.attachmentBlobKeys()
+	if err != nil {
+		_ = removeSnapshot(snapshot)
+		return model.RestoreResult{}, err
+	}
+
+	if err := a.replaceData(file, blobKeys); err != nil {
+		if rollbackErr := a.replaceData(snapshot, blobKeys); rollbackErr != nil {
+			_ = snapshot.Close()
+			return model.RestoreResult{}, fmt.Errorf("%w; restoring the previous data failed, it has been kept in %s: %s", err, snapshot.Name(), rollbackErr.Error())
+		}
+		a.deleteBlobs(blobKeys)
+		_ = removeSnapshot(snapshot)
+
+		return model.RestoreResult{}, err
+	}
+
+	a.deleteBlobs(blobKeys)
+	_ = removeSnapshot(snapshot)
+
+	return result, nil
+}
+
+// writeSnapshot writes a backup archive of all data to a temporary file, which
+// has to be removed using removeSnapshot.
+func (a *App) writeSnapshot() (*os.File, error) {
+	snapshot, err := ioutil.TempFile("", "todo-snapshot-*")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.Backup(snapshot); err != nil {
+		_ = removeSnapshot(snapshot)
+		return nil, err
+	}
+
+	if err := snapshot.Sync(); err != nil {
+		_ = removeSnapshot(snapshot)
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// removeSnapshot closes and removes a snapshot file.
+func removeSnapshot(snapshot *os.File) error {
+	_ = snapshot.Close()
+	return os.Remove(snapshot.Name())
+}
+
+// replaceData deletes all data except for the attachment contents with the
+// given blob keys and restores the archive in the given file.
+func (a *App) replaceData(file *os.File, keep map[string]bool) error {
+	if err := a.reset(keep); err != nil {
+		return err
+	}
+
+	return a.restoreArchive(file)
+}
+
+// restoreArchive restores all records of the archive in the given file, which
+// has already been verified, and updates the search index.
+func (a *App) restoreArchive(file *os.File) error {
+	var toDoIDs []int64
+
+	_, err := a.readBackup(file, func(record backup.Record) error {
+		if record.Type == backup.TypeToDo {
+			var toDo model.ToDo
+			if err := record.Decode(&toDo); err != nil {
+				return err
+			}
+			toDoIDs = append(toDoIDs, toDo.ID)
+		}
+		return a.restoreRecord(record, false)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range toDoIDs {
+		if err := a.storage.Reindex(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readBackup reads the archive in the given file from the beginning and calls
+// apply for each record.
+func (a *App) readBackup(file *os.File, apply func(record backup.Record) error) (model.RestoreResult, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return model.RestoreResult{}, err
+	}
+
+	reader, err := backup.NewReader(file)
+	if err != nil {
+		return model.RestoreResult{}, err
+	}
+
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return model.RestoreResult{}, err
+		}
+
+		if err := apply(record); err != nil {
+			return model.RestoreResult{}, err
+		}
+	}
+
+	return model.RestoreResult{
+		CreatedAt: reader.Header().CreatedAt,
+		Counts:    reader.Counts(),
+	}, nil
+}
+
+// restoreRecord decodes a record and restores the entity it contains. If
+// dryRun is true, the record is only decoded.
+func (a *App) restoreRecord(record backup.Record, dryRun bool) error {
+	var (
+		restore func() error
+		err     error
+	)
+
+	switch record.Type {
+	case backup.TypeToDo:
+		var toDo model.ToDo
+		err = record.Decode(&toDo)
+		restore = func() error { return a.storage.RestoreToDo(toDo) }
+	case backup.TypeComment:
+		var comment model.Comment
+		err = record.Decode(&comment)
+		restore = func() error { return a.storage.RestoreComment(comment) }
+	case backup.TypeAttachment:
+		var attachment attachmentRecord
+		err = record.Decode(&attachment)
+		restore = func() error { return a.restoreAttachment(attachment) }
+	case backup.TypeView:
+		var view model.View
+		err = record.Decode(&view)
+		restore = func() error { return a.storage.RestoreView(view) }
+	case backup.TypeWebhook:
+		var webhook model.Webhook
+		err = record.Decode(&webhook)
+		restore = func() error { return a.storage.RestoreWebhook(webhook) }
+	case backup.TypeDelivery:
+		var delivery model.Delivery
+		err = record.Decode(&delivery)
+		restore = func() error { return a.storage.RestoreDelivery(delivery) }
+	case backup.TypeCalendarFeed:
+		var feed model.CalendarFeed
+		err = record.Decode(&feed)
+		restore = func() error { return a.storage.SaveCalendarFeed(feed) }
+	default:
+		return fmt.Errorf("%w: unknown record type %s", backup.ErrInvalidArchive, record.Type)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: invalid %s record: %s", backup.ErrInvalidArchive, record.Type, err.Error())
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	return restore()
+}
+
+// restoreAttachment stores the content of an attachment under a new blob key
+// and restores its metadata.
+func (a *App) restoreAttachment(record attachmentRecord) error {
+	key, err := newBlobKey()
+	if err != nil {
+		return err
+	}
+
+	if _, err := a.blobStore.Put(key, bytes.NewReader(record.Content)); err != nil {
+		return err
+	}
+
+	attachment := record.Attachment
+	attachment.BlobKey = key
+
+	if err := a.storage.RestoreAttachment(attachment); err != nil {
+		_ = a.blobStore.Delete(key)
+		return err
+	}
+
+	return nil
+}
+
+// readBlob reads the entire content of the blob with the given key.
+func (a *App) readBlob(key string) ([]byte, error) {
+	blob, err := a.blobStore.Open(key)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = blob.Close()
+	}()
+
+	return ioutil.ReadAll(blob)
+}
+
+// isEmpty reports whether the storage contains neither ToDo items nor views,
+// webhooks or calendar feeds. Comments and attachments always belong to a ToDo
+// item, and deliveries to a webhook.
+func (a *App) isEmpty() (bool, error) {
+	toDos, err := a.storage.FindToDos()
+	if err != nil || len(toDos) > 0 {
+		return false, err
+	}
+
+	views, err := a.storage.FindAllViews()
+	if err != nil || len(views) > 0 {
+		return false, err
+	}
+
+	webhooks, err := a.storage.FindWebhooks()
+	if err != nil || len(webhooks) > 0 {
+		return false, err
+	}
+
+	feeds, err := a.storage.FindCalendarFeeds()
+	if err != nil || len(feeds) > 0 {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// attachmentBlobKeys returns the blob keys of all attachments.
+func (a *App) attachmentBlobKeys() (map[string]bool, error) {
+	toDos, err := a.storage.FindToDos()
+	if err != nil {
+		return nil, err
+	}
+
+	toDoIDs := make([]int64, len(toDos))
+
+	for i, toDo := range toDos {
+		toDoIDs[i] = toDo.ID
+	}
+
+	attachments, err := a.storage.FindAttachmentsByToDoIDs(toDoIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	blobKeys := make(map[string]bool, len(attachments))
+
+	for _, attachment := range attachments {
+		blobKeys[attachment.BlobKey] = true
+	}
+
+	return blobKeys, nil
+}
+
+// deleteBlobs deletes the attachment contents with the given blob keys. Errors
+// are ignored, since the contents are no longer referenced by any attachment.
+func (a *App) deleteBlobs(blobKeys map[string]bool) {
+	for key := range blobKeys {
+		_ = a.blobStore.Delete(key)
+	}
+}
+
+// reset deletes all data, including the contents of all attachments except for
+// those with the given blob keys.
+func (a *App) reset(keep map[string]bool) error {
+	blobKeys, err := a.attachmentBlobKeys()
+	if err != nil {
+		return err
+	}
+
+	for key := range blobKeys {
+		if keep[key] {
+			continue
+		}
+		if err := a.blobStore.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	if err := a.storage.Remove(); err != nil {
+		return err
+	}
+
+	return a.storage.Initialize()
+}
@@ -0,0 +1,252 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/backup"
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newBackupTestApp returns an App containing an entity of each kind that is
+// included in backups.
+func newBackupTestApp(t *testing.T) *App {
+	app := newTestApp(t)
+
+	// The first ToDo item is deleted, so that the restored IDs have a gap.
+	deleted, _ := app.CreateToDo(model.ToDo{Name: "Deleted"})
+	_ = app.DeleteToDo(deleted.ID)
+
+	webhook, err := app.CreateWebhook(model.Webhook{URL: "https://example.com/hook"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	toDo, err := app.CreateToDo(model.ToDo{
+		Name:  "Release preparation",
+		Tasks: []model.Task{{Name: "Write changelog"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.CreateComment(toDo.ID, toDo.Tasks[0].ID, model.Comment{Author: "alice", Body: "Done soon"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.CreateAttachment(toDo.ID, 0, "notes.txt", strings.NewReader("My notes")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.CreateView("alice", model.View{Name: "Release", Filter: "tag:release"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.CreateCalendarFeed("alice", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if deliveries, _ := app.GetDeliveries(webhook.ID); len(deliveries) == 0 {
+		t.Fatal("expected deliveries for the created ToDo item")
+	}
+
+	return app
+}
+
+func TestApp_Restore(t *testing.T) {
+	source := newBackupTestApp(t)
+
+	var archive bytes.Buffer
+
+	if err := source.Backup(&archive); err != nil {
+		t.Fatal(err)
+	}
+
+	app := newTestApp(t)
+
+	result, err := app.Restore(bytes.NewReader(archive.Bytes()), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Counts[backup.TypeToDo] != 1 || result.Counts[backup.TypeAttachment] != 1 || result.Counts[backup.TypeDelivery] == 0 {
+		t.Errorf("unexpected counts %v", result.Counts)
+	}
+
+	expectedToDos, _ := source.GetToDos()
+	toDos, _ := app.GetToDos()
+
+	if !cmp.Equal(toDos, expectedToDos) {
+		t.Errorf("expected ToDo items %v, got %v", expectedToDos, toDos)
+	}
+
+	toDoID := toDos[0].ID
+
+	expectedComments, _ := source.GetCommentsOfToDos([]int64{toDoID})
+	comments, _ := app.GetCommentsOfToDos([]int64{toDoID})
+
+	if !cmp.Equal(comments, expectedComments) {
+		t.Errorf("expected comments %v, got %v", expectedComments, comments)
+	}
+
+	attachments, _ := app.GetAttachments(toDoID)
+	if len(attachments) != 1 {
+		t.Fatalf("expected 1 attachment, got %d", len(attachments))
+	}
+
+	_, content, err := app.OpenAttachment(toDoID, attachments[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := ioutil.ReadAll(content)
+	_ = content.Close()
+
+	if string(data) != "My notes" {
+		t.Errorf("expected attachment content %q, got %q", "My notes", data)
+	}
+
+	expectedWebhooks, _ := source.GetWebhooks()
+	webhooks, _ := app.GetWebhooks()
+
+	if !cmp.Equal(webhooks, expectedWebhooks) {
+		t.Errorf("expected webhooks %v, got %v", expectedWebhooks, webhooks)
+	}
+
+	expectedDeliveries, _ := source.GetDeliveries(webhooks[0].ID)
+	deliveries, _ := app.GetDeliveries(webhooks[0].ID)
+
+	if !cmp.Equal(deliveries, expectedDeliveries) {
+		t.Errorf("expected deliveries %v, got %v", expectedDeliveries, deliveries)
+	}
+
+	expectedFeed, _ := source.GetCalendarFeed("alice")
+	if feed, _ := app.GetCalendarFeed("alice"); !cmp.Equal(feed, expectedFeed) {
+		t.Errorf("expected calendar feed %v, got %v", expectedFeed, feed)
+	}
+
+	expectedViews, _ := source.GetViews("alice")
+	if views, _ := app.GetViews("alice"); !cmp.Equal(views, expectedViews) {
+		t.Errorf("expected views %v, got %v", expectedViews, views)
+	}
+
+	if results, _ := app.Search("changelog"); len(results) != 1 {
+		t.Errorf("expected restored ToDo items to be searchable, got %v", results)
+	}
+
+	// New items must not reuse the IDs of restored items.
+	created, _ := app.CreateToDo(model.ToDo{Name: "New ToDo"})
+	if created.ID <= toDoID {
+		t.Errorf("expected an ID greater than %d, got %d", toDoID, created.ID)
+	}
+}
+
+func TestApp_Restore_NonEmptyStorage(t *testing.T) {
+	source := newBackupTestApp(t)
+
+	var archive bytes.Buffer
+
+	if err := source.Backup(&archive); err != nil {
+		t.Fatal(err)
+	}
+
+	app := newTestApp(t)
+	existing, _ := app.CreateToDo(model.ToDo{Name: "Existing"})
+	_, _ = app.CreateAttachment(existing.ID, 0, "existing.txt", strings.NewReader("Existing"))
+
+	if _, err := app.Restore(bytes.NewReader(archive.Bytes()), false); !errors.Is(err, ErrStorageNotEmpty) {
+		t.Fatalf("expected error %v, got %v", ErrStorageNotEmpty, err)
+	}
+
+	// A corrupted archive must be rejected before any data is deleted.
+	corrupted := archive.Bytes()[:archive.Len()/2]
+
+	if _, err := app.Restore(bytes.NewReader(corrupted), true); !errors.Is(err, backup.ErrTruncated) {
+		t.Fatalf("expected error %v, got %v", backup.ErrTruncated, err)
+	}
+
+	if toDos, _ := app.GetToDos(); len(toDos) != 1 || toDos[0].Name != "Existing" {
+		t.Fatalf("expected the existing ToDo item to be kept, got %v", toDos)
+	}
+
+	if _, err := app.Restore(bytes.NewReader(archive.Bytes()), true); err != nil {
+		t.Fatal(err)
+	}
+
+	toDos, _ := app.GetToDos()
+	if len(toDos) != 1 || toDos[0].Name != "Release preparation" {
+		t.Errorf("expected only the restored ToDo item, got %v", toDos)
+	}
+}
+
+// failingBlobStore is a blob store that refuses to store a given content.
+type failingBlobStore struct {
+	storage.BlobStore
+	content string
+}
+
+// Put returns an error if the content read from r is the refused content.
+func (f *failingBlobStore) Put(key string, r io.Reader) (int64, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+
+	if string(data) == f.content {
+		return 0, errors.New("blob store is unavailable")
+	}
+
+	return f.BlobStore.Put(key, bytes.NewReader(data))
+}
+
+func TestApp_Restore_Rollback(t *testing.T) {
+	source := newBackupTestApp(t)
+
+	var archive bytes.Buffer
+
+	if err := source.Backup(&archive); err != nil {
+		t.Fatal(err)
+	}
+
+	app := newTestApp(t)
+	existing, _ := app.CreateToDo(model.ToDo{Name: "Existing"})
+	_, _ = app.CreateAttachment(existing.ID, 0, "existing.txt", strings.NewReader("Existing"))
+
+	// The attachment of the archive is restored after its ToDo item, so that
+	// the restore fails halfway.
+	app.blobStore = &failingBlobStore{BlobStore: app.blobStore, content: "My notes"}
+
+	if _, err := app.Restore(bytes.NewReader(archive.Bytes()), true); err == nil {
+		t.Fatal("expected the restore to fail")
+	}
+
+	toDos, _ := app.GetToDos()
+	if len(toDos) != 1 || toDos[0].Name != "Existing" {
+		t.Fatalf("expected the existing ToDo item to be restored, got %v", toDos)
+	}
+
+	attachments, _ := app.GetAttachments(toDos[0].ID)
+	if len(attachments) != 1 {
+		t.Fatalf("expected 1 attachment, got %d", len(attachments))
+	}
+
+	_, content, err := app.OpenAttachment(toDos[0].ID, attachments[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := ioutil.ReadAll(content)
+	_ = content.Close()
+
+	if string(data) != "Existing" {
+		t.Errorf("expected attachment content %q, got %q", "Existing", data)
+	}
+}
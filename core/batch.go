@@ -0,0 +1,309 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+// MaxBatchSize is the maximum number of operations of a batch.
+const MaxBatchSize = 100
+
+var (
+	// ErrBatchEmpty indicates that a batch has no operations.
+	ErrBatchEmpty = errors.New("batch must contain at least one operation")
+
+	// ErrBatchTooLarge indicates that a batch has more than MaxBatchSize
+	// operations.
+	ErrBatchTooLarge = fmt.Errorf("batch must not contain more than %d operations", MaxBatchSize)
+
+	// ErrUnknownBatchAction indicates that the action of a batch operation is
+	// not one of the model.BatchAction constants. It is the same error as
+	// storage.ErrUnknownBatchAction.
+	ErrUnknownBatchAction = storage.ErrUnknownBatchAction
+
+	// ErrBatchIDMissing indicates that an operation other than create has no ID.
+	ErrBatchIDMissing = errors.New("id must be given for update, delete and complete operations")
+
+	// ErrBatchToDoMissing indicates that a create or update operation has no
+	// ToDo item.
+	ErrBatchToDoMissing = errors.New("todo must be given for create and update operations")
+
+	// ErrDuplicateBatchID indicates that several operations of a batch refer to
+	// the same ToDo item.
+	ErrDuplicateBatchID = errors.New("a batch must not contain several operations for the same ToDo item")
+
+	// ErrOperationNotApplied is the error of all other operations of an atomic
+	// batch if one of its operations fails.
+	ErrOperationNotApplied = errors.New("operation was not applied because another operation of the atomic batch failed")
+)
+
+// BatchResult is the outcome of an operation of App.Batch. ToDo is the created
+// or changed ToDo item, which is empty for delete operations, and Err is the
+// error of the operation if it failed.
+type BatchResult struct {
+	ToDo model.ToDo
+	Err  error
+}
+
+// BatchError is returned if an operation of an atomic batch fails. Index is the
+// index of the failed operation, and Results lists the outcome of all
+// operations, none of which has been applied.
+type BatchError struct {
+	Index   int
+	Results []BatchResult
+}
+
+// Error returns the message of the failed operation, so that clients can
+// recognize it.
+func (e *BatchError) Error() string {
+	return e.Results[e.Index].Err.Error()
+}
+
+// Unwrap returns the error of the failed operation.
+func (e *BatchError) Unwrap() error {
+	return e.Results[e.Index].Err
+}
+
+// batchOperation is a validated operation along with the state of its ToDo
+// item before the batch, which is needed for publishing events and deleting
+// attachments.
+type batchOperation struct {
+	index       int
+	action      model.BatchAction
+	operation   storage.BatchOperation
+	previous    model.ToDo
+	attachments []model.Attachment
+}
+
+// Batch executes a list of create, update, delete and complete operations and
+// returns their results in the same order. Invalid fields of an operation are
+// reported by a *ValidationError with JSON pointers like
+// `/operations/2/todo/name`, referring to the operation within the batch.
+//
+// If atomic is true, the operations are applied in a single transaction. If one
+// of them fails, none is applied, and a *BatchError is returned. Otherwise,
+// each operation succeeds or fails on its own.
+func (a *App) Batch(operations []model.BatchOperation, atomic bool) ([]BatchResult, error) {
+	if len(operations) == 0 {
+		return nil, ErrBatchEmpty
+	}
+
+	if len(operations) > MaxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	var (
+		results  = make([]BatchResult, len(operations))
+		prepared = make([]batchOperation, 0, len(operations))
+		ids      = make(map[int64]bool)
+	)
+
+	for i, operation := range operations {
+		op, err := a.prepareBatchOperation(i, operation, ids)
+		if err != nil {
+			results[i].Err = err
+
+			if atomic {
+				return nil, abortBatch(results, i)
+			}
+			continue
+		}
+
+		prepared = append(prepared, op)
+	}
+
+	storageOperations := make([]storage.BatchOperation, len(prepared))
+
+	for i, op := range prepared {
+		storageOperations[i] = op.operation
+	}
+
+	storageResults, err := a.storage.Batch(storageOperations, atomic)
+
+	if err != nil {
+		// An atomic batch returns the results up to the failed operation.
+		if n := len(storageResults); n > 0 && storageResults[n-1].Err != nil {
+			failed := prepared[n-1].index
+			results[failed].Err = storageResults[n-1].Err
+
+			return nil, abortBatch(results, failed)
+		}
+		return nil, err
+	}
+
+	for i, op := range prepared {
+		if storageResults[i].Err != nil {
+			results[op.index].Err = storageResults[i].Err
+			continue
+		}
+
+		// The operation has been stored, so its result is returned even if
+		// updating the search index or deleting attachments fails.
+		toDo, err := a.completeBatchOperation(op, storageResults[i].ID)
+		if err != nil {
+			log.Printf("failed to complete batch operation %d: %s", op.index, err.Error())
+		}
+
+		results[op.index].ToDo = toDo
+	}
+
+	return results, nil
+}
+
+// prepareBatchOperation validates an operation and converts it to a storage
+// operation. ids contains the IDs of the ToDo items changed by the previous
+// operations of the batch.
+func (a *App) prepareBatchOperation(index int, operation model.BatchOperation, ids map[int64]bool) (batchOperation, error) {
+	var (
+		v      validator
+		prefix = fmt.Sprintf("/operations/%d", index)
+		op     = batchOperation{index: index, action: operation.Action}
+	)
+
+	switch operation.Action {
+	case model.BatchCreate:
+		op.operation.Action = storage.BatchCreate
+	case model.BatchUpdate, model.BatchComplete:
+		op.operation.Action = storage.BatchUpdate
+	case model.BatchDelete:
+		op.operation.Action = storage.BatchDelete
+	default:
+		v.add(prefix+"/op", ErrUnknownBatchAction)
+	}
+
+	if operation.Action != model.BatchCreate {
+		switch {
+		case operation.ID == 0:
+			v.add(prefix+"/id", ErrBatchIDMissing)
+		case ids[operation.ID]:
+			v.add(prefix+"/id", ErrDuplicateBatchID)
+		}
+	}
+
+	needsToDo := operation.Action == model.BatchCreate || operation.Action == model.BatchUpdate
+
+	if needsToDo && operation.ToDo == nil {
+		v.add(prefix+"/todo", ErrBatchToDoMissing)
+	}
+
+	if err := v.err(); err != nil {
+		return batchOperation{}, err
+	}
+
+	op.operation.ID = operation.ID
+
+	if operation.Action != model.BatchCreate {
+		ids[operation.ID] = true
+
+		previous, err := a.storage.FindToDoByID(operation.ID)
+		if err != nil {
+			return batchOperation{}, err
+		}
+		op.previous = previous
+	}
+
+	switch operation.Action {
+	case model.BatchCreate, model.BatchUpdate:
+		toDo := normalizeToDo(*operation.ToDo)
+
+		if err := validateToDo(toDo); err != nil {
+			return batchOperation{}, prefixViolations(err, prefix+"/todo")
+		}
+
+		if operation.Action == model.BatchUpdate {
+			keepUIDs(&toDo, op.previous)
+		}
+
+		op.operation.ToDo = toDo
+	case model.BatchComplete:
+		toDo := op.previous
+		toDo.Done = true
+
+		op.operation.ToDo = toDo
+	case model.BatchDelete:
+		attachments, err := a.storage.FindAttachments(operation.ID)
+		if err != nil {
+			return batchOperation{}, err
+		}
+		op.attachments = attachments
+	}
+
+	return op, nil
+}
+
+// completeBatchOperation updates the search index, deletes attachments that
+// are no longer needed and publishes the event of an applied operation, just
+// like the single-item methods of App. It returns the resulting ToDo item, or
+// an empty item for delete operations.
+//
+// Since the operation has already been applied, all steps are run even if one
+// of them fails, and the first error is returned along with the ToDo item.
+func (a *App) completeBatchOperation(op batchOperation, id int64) (model.ToDo, error) {
+	var firstErr error
+
+	fail := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if err := a.storage.Reindex(id); err != nil {
+		fail(err)
+	}
+
+	if op.action == model.BatchDelete {
+		for _, attachment := range op.attachments {
+			if err := a.blobStore.Delete(attachment.BlobKey); err != nil {
+				fail(err)
+			}
+		}
+
+		a.publish(model.EventDeleted, op.previous)
+
+		return model.ToDo{}, firstErr
+	}
+
+	if op.action != model.BatchCreate {
+		if err := a.deleteAttachmentsOfRemovedTasks(id, op.operation.ToDo.Tasks); err != nil {
+			fail(err)
+		}
+	}
+
+	toDo, err := a.storage.FindToDoByID(id)
+	if err != nil {
+		fail(err)
+
+		toDo = op.operation.ToDo
+		toDo.ID = id
+	}
+
+	eventType := model.EventUpdated
+
+	switch {
+	case op.action == model.BatchCreate:
+		eventType = model.EventCreated
+	case toDo.Done && !op.previous.Done:
+		eventType = model.EventCompleted
+	}
+
+	a.publish(eventType, toDo)
+
+	return toDo, firstErr
+}
+
+// abortBatch marks all operations except for the failed one as not applied and
+// returns a *BatchError.
+func abortBatch(results []BatchResult, failed int) error {
+	for i := range results {
+		if i != failed {
+			results[i] = BatchResult{Err: ErrOperationNotApplied}
+		}
+	}
+
+	return &BatchError{Index: failed, Results: results}
+}
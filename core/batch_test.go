@@ -0,0 +1,161 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestApp_Batch(t *testing.T) {
+	app := newTestApp(t)
+
+	first, _ := app.CreateToDo(model.ToDo{Name: "ToDo 1"})
+	second, _ := app.CreateToDo(model.ToDo{Name: "ToDo 2"})
+
+	results, err := app.Batch([]model.BatchOperation{
+		{Action: model.BatchCreate, ToDo: &model.ToDo{Name: " ToDo 3 "}},
+		{Action: model.BatchComplete, ID: first.ID},
+		{Action: model.BatchUpdate, ID: second.ID, ToDo: &model.ToDo{}},
+		{Action: model.BatchDelete, ID: 42},
+		{Action: model.BatchDelete, ID: second.ID},
+	}, false)
+	if err != nil {
+		t.Fatalf("error executing batch: %s", err.Error())
+	}
+
+	if results[0].Err != nil || results[0].ToDo.Name != "ToDo 3" {
+		t.Errorf("unexpected result %v", results[0])
+	}
+
+	if results[1].Err != nil || !results[1].ToDo.Done {
+		t.Errorf("unexpected result %v", results[1])
+	}
+
+	var validationErr *ValidationError
+
+	if !errors.As(results[2].Err, &validationErr) || validationErr.Violations[0].Field != "/operations/2/todo/name" {
+		t.Errorf("expected violation of /operations/2/todo/name, got %v", results[2].Err)
+	}
+
+	if !errors.Is(results[3].Err, storage.ErrToDoNotFound) {
+		t.Errorf("expected error %v, got %v", storage.ErrToDoNotFound, results[3].Err)
+	}
+
+	if !errors.Is(results[4].Err, ErrDuplicateBatchID) {
+		t.Errorf("expected error %v, got %v", ErrDuplicateBatchID, results[4].Err)
+	}
+
+	toDos, _ := app.GetToDos()
+
+	if len(toDos) != 3 {
+		t.Errorf("expected %d ToDo items, got %d", 3, len(toDos))
+	}
+}
+
+func TestApp_Batch_Atomic(t *testing.T) {
+	app := newTestApp(t)
+
+	toDo, _ := app.CreateToDo(model.ToDo{Name: "ToDo 1"})
+
+	_, err := app.Batch([]model.BatchOperation{
+		{Action: model.BatchCreate, ToDo: &model.ToDo{Name: "ToDo 2"}},
+		{Action: model.BatchDelete, ID: toDo.ID},
+		{Action: model.BatchComplete, ID: 42},
+	}, true)
+
+	var batchErr *BatchError
+
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("expected a batch error, got %v", err)
+	}
+
+	if batchErr.Index != 2 || !errors.Is(err, storage.ErrToDoNotFound) {
+		t.Errorf("unexpected batch error %v at index %d", err, batchErr.Index)
+	}
+
+	for _, i := range []int{0, 1} {
+		if !errors.Is(batchErr.Results[i].Err, ErrOperationNotApplied) {
+			t.Errorf("expected error %v for operation %d, got %v", ErrOperationNotApplied, i, batchErr.Results[i].Err)
+		}
+	}
+
+	toDos, _ := app.GetToDos()
+
+	if diff := cmp.Diff([]model.ToDo{toDo}, toDos); diff != "" {
+		t.Errorf("unexpected ToDo items (-expected +got):\n%s", diff)
+	}
+
+	results, err := app.Batch([]model.BatchOperation{
+		{Action: model.BatchCreate, ToDo: &model.ToDo{Name: "ToDo 2"}},
+		{Action: model.BatchDelete, ID: toDo.ID},
+	}, true)
+	if err != nil {
+		t.Fatalf("error executing batch: %s", err.Error())
+	}
+
+	toDos, _ = app.GetToDos()
+
+	if len(toDos) != 1 || toDos[0].ID != results[0].ToDo.ID {
+		t.Errorf("unexpected ToDo items %v", toDos)
+	}
+}
+
+func TestApp_Batch_Limits(t *testing.T) {
+	app := newTestApp(t)
+
+	if _, err := app.Batch(nil, false); !errors.Is(err, ErrBatchEmpty) {
+		t.Errorf("expected error %v, got %v", ErrBatchEmpty, err)
+	}
+
+	operations := make([]model.BatchOperation, MaxBatchSize+1)
+
+	if _, err := app.Batch(operations, false); !errors.Is(err, ErrBatchTooLarge) {
+		t.Errorf("expected error %v, got %v", ErrBatchTooLarge, err)
+	}
+
+	results, _ := app.Batch([]model.BatchOperation{{Action: "archive"}}, false)
+
+	if !errors.Is(results[0].Err, ErrUnknownBatchAction) || !errors.Is(results[0].Err, ErrBatchIDMissing) {
+		t.Errorf("expected errors %v and %v, got %v", ErrUnknownBatchAction, ErrBatchIDMissing, results[0].Err)
+	}
+}
+
+// unindexedStorage is a storage whose search index can't be updated.
+type unindexedStorage struct {
+	storage.Storage
+}
+
+// Reindex always returns an error.
+func (u unindexedStorage) Reindex(toDoID int64) error {
+	return errors.New("search index is unavailable")
+}
+
+func TestApp_Batch_CompletionError(t *testing.T) {
+	app := newTestApp(t)
+
+	existing, _ := app.CreateToDo(model.ToDo{Name: "ToDo 1"})
+	app.storage = unindexedStorage{Storage: app.storage}
+
+	// The operations have been stored, so their results must be returned even
+	// though the search index can't be updated.
+	results, err := app.Batch([]model.BatchOperation{
+		{Action: model.BatchCreate, ToDo: &model.ToDo{Name: "ToDo 2"}},
+		{Action: model.BatchComplete, ID: existing.ID},
+		{Action: model.BatchDelete, ID: 42},
+	}, false)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if results[0].Err != nil || results[0].ToDo.Name != "ToDo 2" || results[1].Err != nil || !results[1].ToDo.Done {
+		t.Errorf("expected applied operations, got %v", results)
+	}
+
+	if !errors.Is(results[2].Err, storage.ErrToDoNotFound) {
+		t.Errorf("expected error %v, got %v", storage.ErrToDoNotFound, results[2].Err)
+	}
+}
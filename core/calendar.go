@@ -0,0 +1,74 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"strings"
+
+	"github.com/dominikbraun/todo/filter"
+	"github.com/dominikbraun/todo/model"
+)
+
+// CreateCalendarFeed creates a calendar feed with a new random token for the
+// given owner. An existing feed of the owner is replaced, so that its token
+// becomes invalid. If the filter query isn't empty, it has to be valid and
+// the feed will only contain the matching ToDo items.
+func (a *App) CreateCalendarFeed(owner, query string) (model.CalendarFeed, error) {
+	if owner == "" {
+		return model.CalendarFeed{}, ErrOwnerMustNotBeEmpty
+	}
+
+	if strings.TrimSpace(query) != "" {
+		if _, err := filter.Parse(query); err != nil {
+			return model.CalendarFeed{}, err
+		}
+	}
+
+	token, err := newSecret()
+	if err != nil {
+		return model.CalendarFeed{}, err
+	}
+
+	feed := model.CalendarFeed{
+		Owner:     owner,
+		Token:     token,
+		Filter:    query,
+		CreatedAt: currentTime(),
+	}
+
+	if err := a.storage.SaveCalendarFeed(feed); err != nil {
+		return model.CalendarFeed{}, err
+	}
+
+	return feed, nil
+}
+
+// GetCalendarFeed returns the calendar feed of the given owner.
+func (a *App) GetCalendarFeed(owner string) (model.CalendarFeed, error) {
+	if owner == "" {
+		return model.CalendarFeed{}, ErrOwnerMustNotBeEmpty
+	}
+
+	return a.storage.FindCalendarFeed(owner)
+}
+
+// DeleteCalendarFeed deletes the calendar feed of the given owner, so that its
+// token becomes invalid.
+func (a *App) DeleteCalendarFeed(owner string) error {
+	if owner == "" {
+		return ErrOwnerMustNotBeEmpty
+	}
+
+	return a.storage.DeleteCalendarFeed(owner)
+}
+
+// GetCalendarFeedToDos returns the ToDo items of the calendar feed with the
+// given token, ordered by their ID. An unknown token results in
+// storage.ErrCalendarFeedNotFound.
+func (a *App) GetCalendarFeedToDos(token string) ([]model.ToDo, error) {
+	feed, err := a.storage.FindCalendarFeedByToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.ExportToDos(feed.Filter)
+}
@@ -0,0 +1,78 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dominikbraun/todo/filter"
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+func TestApp_CreateCalendarFeed(t *testing.T) {
+	app := newTestApp(t)
+
+	if _, err := app.CreateCalendarFeed("", ""); !errors.Is(err, ErrOwnerMustNotBeEmpty) {
+		t.Errorf("expected error %v, got %v", ErrOwnerMustNotBeEmpty, err)
+	}
+
+	var syntaxError *filter.SyntaxError
+
+	if _, err := app.CreateCalendarFeed("alice", "due<"); !errors.As(err, &syntaxError) {
+		t.Errorf("expected syntax error, got %v", err)
+	}
+
+	feed, err := app.CreateCalendarFeed("alice", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(feed.Token) != 64 || feed.CreatedAt.IsZero() {
+		t.Errorf("expected feed with token and creation time, got %v", feed)
+	}
+
+	// Creating another feed invalidates the old token.
+	newFeed, err := app.CreateCalendarFeed("alice", "NOT done")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if newFeed.Token == feed.Token {
+		t.Errorf("expected new token")
+	}
+
+	if _, err := app.GetCalendarFeedToDos(feed.Token); !errors.Is(err, storage.ErrCalendarFeedNotFound) {
+		t.Errorf("expected error %v, got %v", storage.ErrCalendarFeedNotFound, err)
+	}
+
+	if found, err := app.GetCalendarFeed("alice"); err != nil || found != newFeed {
+		t.Errorf("expected feed %v, got %v (%v)", newFeed, found, err)
+	}
+}
+
+func TestApp_GetCalendarFeedToDos(t *testing.T) {
+	app := newTestApp(t)
+
+	_, _ = app.CreateToDo(model.ToDo{Name: "ToDo 1"})
+	_, _ = app.CreateToDo(model.ToDo{Name: "ToDo 2", Done: true})
+
+	feed, _ := app.CreateCalendarFeed("alice", "NOT done")
+
+	toDos, err := app.GetCalendarFeedToDos(feed.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(toDos) != 1 || toDos[0].Name != "ToDo 1" {
+		t.Errorf("expected only %s, got %v", "ToDo 1", toDos)
+	}
+
+	if err := app.DeleteCalendarFeed("alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.GetCalendarFeedToDos(feed.Token); !errors.Is(err, storage.ErrCalendarFeedNotFound) {
+		t.Errorf("expected error %v, got %v", storage.ErrCalendarFeedNotFound, err)
+	}
+}
@@ -0,0 +1,168 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+var (
+	// ErrAuthorMustNotBeEmpty indicates that a comment author is empty.
+	ErrAuthorMustNotBeEmpty = errors.New("author must not be empty")
+
+	// ErrBodyMustNotBeEmpty indicates that a comment body is empty.
+	ErrBodyMustNotBeEmpty = errors.New("body must not be empty")
+
+	// ErrAuthorTooLong indicates that a comment author is longer than
+	// MaxAuthorLength characters.
+	ErrAuthorTooLong = fmt.Errorf("author must not be longer than %d characters", MaxAuthorLength)
+)
+
+// MaxAuthorLength is the maximum number of characters of a comment author.
+const MaxAuthorLength = 100
+
+// CreateComment adds a comment to the thread of the ToDo item with the given
+// ID. If taskID is not 0, the comment will be added to the thread of the task
+// with that ID instead. The provided comment should not have an ID.
+func (a *App) CreateComment(toDoID, taskID int64, comment model.Comment) (model.Comment, error) {
+	if err := validateComment(comment); err != nil {
+		return model.Comment{}, err
+	}
+
+	if err := a.ensureThreadExists(toDoID, taskID); err != nil {
+		return model.Comment{}, err
+	}
+
+	now := currentTime()
+
+	comment.ToDoID = toDoID
+	comment.TaskID = taskID
+	comment.CreatedAt = now
+	comment.UpdatedAt = now
+
+	createdComment, err := a.storage.CreateComment(comment)
+	if err != nil {
+		return model.Comment{}, err
+	}
+
+	if err := a.storage.Reindex(toDoID); err != nil {
+		return model.Comment{}, err
+	}
+
+	return createdComment, nil
+}
+
+// GetComments returns the thread of the given ToDo item or task, i.e. all of
+// its comments in the order they were created.
+func (a *App) GetComments(toDoID, taskID int64) ([]model.Comment, error) {
+	if err := a.ensureThreadExists(toDoID, taskID); err != nil {
+		return nil, err
+	}
+
+	return a.storage.FindComments(toDoID, taskID)
+}
+
+// GetCommentsOfToDos returns the comments of the given ToDo items and their
+// tasks with a single storage query. Unknown ToDo IDs are ignored.
+func (a *App) GetCommentsOfToDos(toDoIDs []int64) ([]model.Comment, error) {
+	return a.storage.FindCommentsByToDoIDs(toDoIDs)
+}
+
+// UpdateComment updates the author and body of a comment in the thread of the
+// given ToDo item or task. The creation timestamp of the comment is retained.
+func (a *App) UpdateComment(toDoID, taskID, id int64, comment model.Comment) error {
+	if err := validateComment(comment); err != nil {
+		return err
+	}
+
+	storedComment, err := a.findCommentInThread(toDoID, taskID, id)
+	if err != nil {
+		return err
+	}
+
+	storedComment.Author = comment.Author
+	storedComment.Body = comment.Body
+	storedComment.UpdatedAt = currentTime()
+
+	if err := a.storage.UpdateComment(id, storedComment); err != nil {
+		return err
+	}
+
+	return a.storage.Reindex(toDoID)
+}
+
+// DeleteComment deletes a comment from the thread of the given ToDo or task.
+func (a *App) DeleteComment(toDoID, taskID, id int64) error {
+	if _, err := a.findCommentInThread(toDoID, taskID, id); err != nil {
+		return err
+	}
+
+	if err := a.storage.DeleteComment(id); err != nil {
+		return err
+	}
+
+	return a.storage.Reindex(toDoID)
+}
+
+// ensureThreadExists checks whether the ToDo item with the given ID exists and,
+// if taskID is not 0, whether that ToDo item has a task with the given ID.
+func (a *App) ensureThreadExists(toDoID, taskID int64) error {
+	toDo, err := a.storage.FindToDoByID(toDoID)
+	if err != nil {
+		return err
+	}
+
+	if taskID == 0 {
+		return nil
+	}
+
+	for _, task := range toDo.Tasks {
+		if task.ID == taskID {
+			return nil
+		}
+	}
+
+	return storage.ErrTaskNotFound
+}
+
+// findCommentInThread returns the comment with the given ID. If the comment
+// doesn't belong to the thread of the given ToDo or task, ErrCommentNotFound
+// will be returned.
+func (a *App) findCommentInThread(toDoID, taskID, id int64) (model.Comment, error) {
+	if err := a.ensureThreadExists(toDoID, taskID); err != nil {
+		return model.Comment{}, err
+	}
+
+	comment, err := a.storage.FindCommentByID(id)
+	if err != nil {
+		return model.Comment{}, err
+	}
+
+	if comment.ToDoID != toDoID || comment.TaskID != taskID {
+		return model.Comment{}, storage.ErrCommentNotFound
+	}
+
+	return comment, nil
+}
+
+// validateComment checks whether all required comment fields are set and the
+// author isn't too long. All invalid fields are reported by a *ValidationError.
+func validateComment(comment model.Comment) error {
+	var v validator
+
+	if comment.Author == "" {
+		v.add("/author", ErrAuthorMustNotBeEmpty)
+	} else if utf8.RuneCountInString(comment.Author) > MaxAuthorLength {
+		v.add("/author", ErrAuthorTooLong)
+	}
+
+	if comment.Body == "" {
+		v.add("/body", ErrBodyMustNotBeEmpty)
+	}
+
+	return v.err()
+}
@@ -0,0 +1,79 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+func TestApp_CreateComment(t *testing.T) {
+	app := newTestApp(t)
+
+	toDo, _ := app.storage.CreateToDo(model.ToDo{
+		Name: "ToDo 1",
+		Tasks: []model.Task{
+			{
+				Name: "Task 1",
+			},
+		},
+	})
+
+	comment := model.Comment{
+		Author: "Alice",
+	}
+
+	_, err := app.CreateComment(toDo.ID, 0, comment)
+	if !errors.Is(err, ErrBodyMustNotBeEmpty) {
+		t.Errorf("expected error %v, got %v", ErrBodyMustNotBeEmpty, err)
+	}
+
+	comment.Body = "Comment 1"
+
+	_, err = app.CreateComment(toDo.ID, 42, comment)
+	if !errors.Is(err, storage.ErrTaskNotFound) {
+		t.Errorf("expected error %v, got %v", storage.ErrTaskNotFound, err)
+	}
+
+	createdComment, err := app.CreateComment(toDo.ID, toDo.Tasks[0].ID, comment)
+	if err != nil {
+		t.Fatalf("error creating comment: %s", err.Error())
+	}
+
+	if createdComment.CreatedAt.IsZero() {
+		t.Errorf("expected creation timestamp to be set")
+	}
+}
+
+func TestApp_UpdateComment(t *testing.T) {
+	app := newTestApp(t)
+
+	toDo, _ := app.storage.CreateToDo(model.ToDo{
+		Name: "ToDo 1",
+	})
+
+	createdComment, _ := app.CreateComment(toDo.ID, 0, model.Comment{
+		Author: "Alice",
+		Body:   "Comment 1",
+	})
+
+	createdComment.Body = "My Comment 1"
+
+	err := app.UpdateComment(toDo.ID, 1, createdComment.ID, createdComment)
+	if !errors.Is(err, storage.ErrTaskNotFound) {
+		t.Errorf("expected error %v, got %v", storage.ErrTaskNotFound, err)
+	}
+
+	err = app.UpdateComment(toDo.ID, 0, createdComment.ID, createdComment)
+	if err != nil {
+		t.Fatalf("error updating comment: %s", err.Error())
+	}
+
+	comments, _ := app.GetComments(toDo.ID, 0)
+
+	if comments[0].Body != createdComment.Body {
+		t.Errorf("expected body %s, got %s", createdComment.Body, comments[0].Body)
+	}
+}
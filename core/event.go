@@ -0,0 +1,147 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"log"
+	"sync"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+// EventLogSize is the number of recent events kept for resuming subscriptions.
+const EventLogSize = 1000
+
+// subscriptionBufferSize is the number of events a subscriber may fall behind
+// before it is dropped.
+const subscriptionBufferSize = 64
+
+// EventBus distributes domain events to all subscribers. It keeps a bounded log
+// of recent events, which allows subscribers to resume after the last event
+// they've received, e.g. after a reconnect.
+type EventBus struct {
+	mutex       sync.Mutex
+	log         []model.Event
+	capacity    int
+	lastID      int64
+	subscribers map[*Subscription]bool
+}
+
+// Subscription receives the events published on an EventBus. The Events channel
+// is closed when the subscription is closed or the subscriber fell too far
+// behind, in which case it should resubscribe using the last received event ID.
+type Subscription struct {
+	Events <-chan model.Event
+	events chan model.Event
+	bus    *EventBus
+}
+
+// NewEventBus creates a new EventBus that keeps the given number of events.
+func NewEventBus(capacity int) *EventBus {
+	return &EventBus{
+		log:         make([]model.Event, 0, capacity),
+		capacity:    capacity,
+		subscribers: make(map[*Subscription]bool),
+	}
+}
+
+// Publish assigns the next event ID and the current time to the given event,
+// appends it to the log and sends it to all subscribers.
+func (b *EventBus) Publish(event model.Event) model.Event {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.lastID++
+	event.ID = b.lastID
+	event.Time = currentTime()
+
+	if len(b.log) == b.capacity {
+		b.log = append(b.log[:0], b.log[1:]...)
+	}
+	b.log = append(b.log, event)
+
+	for subscription := range b.subscribers {
+		select {
+		case subscription.events <- event:
+		default:
+			b.drop(subscription)
+		}
+	}
+
+	return event
+}
+
+// Subscribe registers a new subscription. All logged events with an ID greater
+// than lastEventID are delivered first, so passing the ID of the last received
+// event resumes a previous subscription. A lastEventID of 0 only delivers new
+// events. Events that already have been evicted from the log are skipped.
+func (b *EventBus) Subscribe(lastEventID int64) *Subscription {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var missed []model.Event
+
+	if lastEventID > 0 {
+		for _, event := range b.log {
+			if event.ID > lastEventID {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	events := make(chan model.Event, len(missed)+subscriptionBufferSize)
+
+	for _, event := range missed {
+		events <- event
+	}
+
+	subscription := &Subscription{
+		Events: events,
+		events: events,
+		bus:    b,
+	}
+	b.subscribers[subscription] = true
+
+	return subscription
+}
+
+// Close unsubscribes from the event bus and closes the Events channel.
+func (s *Subscription) Close() {
+	s.bus.mutex.Lock()
+	defer s.bus.mutex.Unlock()
+
+	s.bus.drop(s)
+}
+
+// drop removes a subscription and closes its channel. The caller has to hold
+// the mutex of the event bus.
+func (b *EventBus) drop(subscription *Subscription) {
+	if !b.subscribers[subscription] {
+		return
+	}
+
+	delete(b.subscribers, subscription)
+	close(subscription.events)
+}
+
+// Subscribe subscribes to all changes of ToDo items. See EventBus.Subscribe.
+func (a *App) Subscribe(lastEventID int64) *Subscription {
+	return a.events.Subscribe(lastEventID)
+}
+
+// publish emits an event of the given type for a ToDo item and enqueues its
+// deliveries to all subscribed webhooks.
+//
+// Since the change of the ToDo item has already been stored, a failure to
+// enqueue the deliveries is only logged instead of failing the request, which
+// would make clients retry a change that has been applied.
+func (a *App) publish(eventType model.EventType, toDo model.ToDo) {
+	event := a.events.Publish(model.Event{
+		Type:   eventType,
+		ToDoID: toDo.ID,
+		ToDo:   toDo,
+	})
+
+	if err := a.enqueueDeliveries(event); err != nil {
+		log.Printf("failed to enqueue webhook deliveries of event %d: %s", event.ID, err.Error())
+	}
+}
@@ -0,0 +1,98 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+func TestEventBus_Subscribe(t *testing.T) {
+	bus := NewEventBus(2)
+
+	for i := int64(1); i <= 3; i++ {
+		bus.Publish(model.Event{Type: model.EventCreated, ToDoID: i})
+	}
+
+	// The first event has been evicted from the log, so only the events with
+	// the IDs 2 and 3 can be replayed.
+	subscription := bus.Subscribe(1)
+
+	bus.Publish(model.Event{Type: model.EventDeleted, ToDoID: 1})
+	subscription.Close()
+
+	var ids []int64
+
+	for event := range subscription.Events {
+		ids = append(ids, event.ID)
+	}
+
+	expected := []int64{2, 3, 4}
+
+	if len(ids) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, ids)
+	}
+
+	for i := range ids {
+		if ids[i] != expected[i] {
+			t.Fatalf("expected events %v, got %v", expected, ids)
+		}
+	}
+}
+
+func TestEventBus_Publish(t *testing.T) {
+	bus := NewEventBus(EventLogSize)
+	subscription := bus.Subscribe(0)
+
+	for i := 0; i <= subscriptionBufferSize; i++ {
+		bus.Publish(model.Event{Type: model.EventUpdated, ToDoID: 1})
+	}
+
+	received := 0
+
+	for range subscription.Events {
+		received++
+	}
+
+	if received != subscriptionBufferSize {
+		t.Errorf("expected %d events before being dropped, got %d", subscriptionBufferSize, received)
+	}
+
+	// Closing a dropped subscription must not panic.
+	subscription.Close()
+}
+
+func TestApp_Subscribe(t *testing.T) {
+	app := newTestApp(t)
+	subscription := app.Subscribe(0)
+
+	toDo, _ := app.CreateToDo(model.ToDo{Name: "ToDo 1", Project: "website"})
+
+	toDo.Description = "Updated"
+	_ = app.UpdateToDo(toDo.ID, toDo)
+
+	toDo, _ = app.GetToDo(toDo.ID)
+	toDo.Done = true
+	_ = app.UpdateToDo(toDo.ID, toDo)
+
+	_ = app.DeleteToDo(toDo.ID)
+
+	expected := []model.EventType{
+		model.EventCreated,
+		model.EventUpdated,
+		model.EventCompleted,
+		model.EventDeleted,
+	}
+
+	for _, eventType := range expected {
+		event := <-subscription.Events
+
+		if event.Type != eventType {
+			t.Fatalf("expected event %s, got %s", eventType, event.Type)
+		}
+
+		if event.ToDoID != toDo.ID || event.ToDo.Project != "website" {
+			t.Errorf("unexpected event %v", event)
+		}
+	}
+}
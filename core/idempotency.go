@@ -0,0 +1,179 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+const (
+	// DefaultIdempotencyWindow is the time idempotency keys and their responses
+	// are stored for if no other window has been set.
+	DefaultIdempotencyWindow = 24 * time.Hour
+
+	// DefaultIdempotencyLease is the time a claimed idempotency key is reserved
+	// for its request if no other lease has been set. If the request hasn't been
+	// completed by then, e.g. because the server crashed, the key can be
+	// claimed by a retry.
+	DefaultIdempotencyLease = time.Minute
+
+	// MaxIdempotencyKeyLength is the maximum number of characters of an
+	// idempotency key.
+	MaxIdempotencyKeyLength = 255
+)
+
+var (
+	// ErrIdempotencyKeyTooLong indicates that an idempotency key is longer than
+	// MaxIdempotencyKeyLength characters.
+	ErrIdempotencyKeyTooLong = fmt.Errorf("idempotency key must not be longer than %d characters", MaxIdempotencyKeyLength)
+
+	// ErrIdempotencyKeyReused indicates that an idempotency key has already been
+	// used for a request with a different method, URL or body.
+	ErrIdempotencyKeyReused = errors.New("idempotency key has already been used for a different request")
+
+	// ErrIdempotentRequestInProgress indicates that a request with the same
+	// idempotency key is still being processed.
+	ErrIdempotentRequestInProgress = errors.New("a request with the same idempotency key is still being processed")
+
+	// ErrIdempotencyLeaseExpired indicates that a request took longer than the
+	// idempotency lease, so that its key may have been claimed by a retry.
+	ErrIdempotencyLeaseExpired = errors.New("idempotency key lease has expired")
+)
+
+// SetIdempotencyWindow sets the time idempotency keys and their responses are
+// stored for. Afterwards, a key can be used for a new request.
+func (a *App) SetIdempotencyWindow(window time.Duration) {
+	a.idempotencyWindow = window
+}
+
+// SetIdempotencyLease sets the time a claimed key is reserved for its request.
+// Afterwards, the key can be claimed by a retry of the request.
+func (a *App) SetIdempotencyLease(lease time.Duration) {
+	a.idempotencyLease = lease
+}
+
+// GetIdempotencyRecord returns the unexpired idempotency record of the given
+// user and key. If there is none, storage.ErrIdempotencyRecordNotFound will be
+// returned, and if the request the key has been claimed for is still being
+// processed, ErrIdempotentRequestInProgress will be returned.
+func (a *App) GetIdempotencyRecord(owner, key string) (model.IdempotencyRecord, error) {
+	record, err := a.storage.FindIdempotencyRecord(owner, key)
+	if err != nil {
+		return model.IdempotencyRecord{}, err
+	}
+
+	if record.ExpiresAt.Before(currentTime()) {
+		return model.IdempotencyRecord{}, storage.ErrIdempotencyRecordNotFound
+	}
+
+	if record.Status == 0 {
+		return model.IdempotencyRecord{}, ErrIdempotentRequestInProgress
+	}
+
+	return record, nil
+}
+
+// ClaimIdempotencyKey reserves an idempotency key of the given user for a new
+// request for the idempotency lease and returns the claim, whose random
+// Fingerprint identifies it until the request has been completed. If the key
+// has already been claimed by a concurrent request whose lease hasn't expired,
+// ErrIdempotentRequestInProgress will be returned. Expired records, including
+// claims whose lease has expired, are deleted beforehand.
+func (a *App) ClaimIdempotencyKey(owner, key string) (model.IdempotencyRecord, error) {
+	if utf8.RuneCountInString(owner) > MaxOwnerLength {
+		return model.IdempotencyRecord{}, ErrOwnerTooLong
+	}
+
+	if utf8.RuneCountInString(key) > MaxIdempotencyKeyLength {
+		return model.IdempotencyRecord{}, ErrIdempotencyKeyTooLong
+	}
+
+	now := currentTime()
+
+	if err := a.storage.DeleteExpiredIdempotencyRecords(now); err != nil {
+		return model.IdempotencyRecord{}, err
+	}
+
+	token, err := newSecret()
+	if err != nil {
+		return model.IdempotencyRecord{}, err
+	}
+
+	claim := model.IdempotencyRecord{
+		Owner:       owner,
+		Key:         key,
+		Fingerprint: token,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(a.idempotencyLease),
+	}
+
+	err = a.storage.CreateIdempotencyRecord(claim)
+
+	if errors.Is(err, storage.ErrIdempotencyKeyExists) {
+		return model.IdempotencyRecord{}, ErrIdempotentRequestInProgress
+	}
+
+	if err != nil {
+		return model.IdempotencyRecord{}, err
+	}
+
+	return claim, nil
+}
+
+// CompleteIdempotentRequest stores the response to the request a key has been
+// claimed for. The fingerprint of the response record identifies the request,
+// and its owner and key are the ones of the claim. The window of the key
+// starts when the key has been claimed.
+//
+// If the lease of the claim has expired, ErrIdempotencyLeaseExpired will be
+// returned and the response won't be stored.
+func (a *App) CompleteIdempotentRequest(claim, response model.IdempotencyRecord) error {
+	claimed, err := a.findClaim(claim)
+	if err != nil {
+		return err
+	}
+
+	response.Owner = claimed.Owner
+	response.Key = claimed.Key
+	response.CreatedAt = claimed.CreatedAt
+	response.ExpiresAt = claimed.CreatedAt.Add(a.idempotencyWindow)
+
+	return a.storage.UpdateIdempotencyRecord(response)
+}
+
+// ReleaseIdempotencyKey deletes a claimed key without storing a response, so
+// that the request can be retried with the same key. If the lease of the claim
+// has expired, ErrIdempotencyLeaseExpired will be returned and the key won't
+// be deleted, since it may have been claimed by a retry.
+func (a *App) ReleaseIdempotencyKey(claim model.IdempotencyRecord) error {
+	if _, err := a.findClaim(claim); err != nil {
+		return err
+	}
+
+	return a.storage.DeleteIdempotencyRecord(claim.Owner, claim.Key)
+}
+
+// findClaim returns the stored record of the given claim. If the record is no
+// longer in progress, belongs to another claim or its lease has expired,
+// ErrIdempotencyLeaseExpired will be returned.
+func (a *App) findClaim(claim model.IdempotencyRecord) (model.IdempotencyRecord, error) {
+	claimed, err := a.storage.FindIdempotencyRecord(claim.Owner, claim.Key)
+
+	switch {
+	case errors.Is(err, storage.ErrIdempotencyRecordNotFound):
+		return model.IdempotencyRecord{}, ErrIdempotencyLeaseExpired
+	case err != nil:
+		return model.IdempotencyRecord{}, err
+	}
+
+	if claimed.Status != 0 || claimed.Fingerprint != claim.Fingerprint || claimed.ExpiresAt.Before(currentTime()) {
+		return model.IdempotencyRecord{}, ErrIdempotencyLeaseExpired
+	}
+
+	return claimed, nil
+}
@@ -0,0 +1,135 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+func TestApp_IdempotencyKeys(t *testing.T) {
+	app := newTestApp(t)
+
+	if _, err := app.GetIdempotencyRecord("alice", "key-1"); !errors.Is(err, storage.ErrIdempotencyRecordNotFound) {
+		t.Errorf("expected error %v, got %v", storage.ErrIdempotencyRecordNotFound, err)
+	}
+
+	claim, err := app.ClaimIdempotencyKey("alice", "key-1")
+	if err != nil {
+		t.Fatalf("error claiming key: %s", err.Error())
+	}
+
+	if _, err := app.ClaimIdempotencyKey("alice", "key-1"); !errors.Is(err, ErrIdempotentRequestInProgress) {
+		t.Errorf("expected error %v, got %v", ErrIdempotentRequestInProgress, err)
+	}
+
+	if _, err := app.GetIdempotencyRecord("alice", "key-1"); !errors.Is(err, ErrIdempotentRequestInProgress) {
+		t.Errorf("expected error %v, got %v", ErrIdempotentRequestInProgress, err)
+	}
+
+	err = app.CompleteIdempotentRequest(claim, model.IdempotencyRecord{
+		Fingerprint: "fingerprint",
+		Status:      200,
+		Body:        []byte("{}"),
+	})
+	if err != nil {
+		t.Fatalf("error completing request: %s", err.Error())
+	}
+
+	record, err := app.GetIdempotencyRecord("alice", "key-1")
+	if err != nil {
+		t.Fatalf("error getting record: %s", err.Error())
+	}
+
+	if record.Status != 200 || record.Fingerprint != "fingerprint" || !record.ExpiresAt.Equal(record.CreatedAt.Add(DefaultIdempotencyWindow)) {
+		t.Errorf("unexpected record %v", record)
+	}
+
+	// A completed request can't be released anymore.
+	if err := app.ReleaseIdempotencyKey(claim); !errors.Is(err, ErrIdempotencyLeaseExpired) {
+		t.Errorf("expected error %v, got %v", ErrIdempotencyLeaseExpired, err)
+	}
+
+	claim, err = app.ClaimIdempotencyKey("alice", "key-2")
+	if err != nil {
+		t.Fatalf("error claiming key: %s", err.Error())
+	}
+
+	if err := app.ReleaseIdempotencyKey(claim); err != nil {
+		t.Fatalf("error releasing key: %s", err.Error())
+	}
+
+	if _, err := app.ClaimIdempotencyKey("alice", "key-2"); err != nil {
+		t.Errorf("error claiming released key: %s", err.Error())
+	}
+
+	if _, err := app.ClaimIdempotencyKey("alice", strings.Repeat("k", MaxIdempotencyKeyLength+1)); !errors.Is(err, ErrIdempotencyKeyTooLong) {
+		t.Errorf("expected error %v, got %v", ErrIdempotencyKeyTooLong, err)
+	}
+
+	if _, err := app.ClaimIdempotencyKey(strings.Repeat("a", MaxOwnerLength+1), "key-1"); !errors.Is(err, ErrOwnerTooLong) {
+		t.Errorf("expected error %v, got %v", ErrOwnerTooLong, err)
+	}
+}
+
+func TestApp_IdempotencyWindow(t *testing.T) {
+	app := newTestApp(t)
+	app.SetIdempotencyWindow(-time.Second)
+
+	claim, err := app.ClaimIdempotencyKey("alice", "key-1")
+	if err != nil {
+		t.Fatalf("error claiming key: %s", err.Error())
+	}
+
+	if err := app.CompleteIdempotentRequest(claim, model.IdempotencyRecord{Status: 200}); err != nil {
+		t.Fatalf("error completing request: %s", err.Error())
+	}
+
+	// The record has expired immediately, so that the key can be claimed again.
+	if _, err := app.GetIdempotencyRecord("alice", "key-1"); !errors.Is(err, storage.ErrIdempotencyRecordNotFound) {
+		t.Errorf("expected error %v, got %v", storage.ErrIdempotencyRecordNotFound, err)
+	}
+
+	if _, err := app.ClaimIdempotencyKey("alice", "key-1"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestApp_IdempotencyLease(t *testing.T) {
+	app := newTestApp(t)
+	app.SetIdempotencyLease(-time.Second)
+
+	claim, err := app.ClaimIdempotencyKey("alice", "key-1")
+	if err != nil {
+		t.Fatalf("error claiming key: %s", err.Error())
+	}
+
+	// The lease has expired immediately, so that a retry can take over the key.
+	if _, err := app.GetIdempotencyRecord("alice", "key-1"); !errors.Is(err, storage.ErrIdempotencyRecordNotFound) {
+		t.Errorf("expected error %v, got %v", storage.ErrIdempotencyRecordNotFound, err)
+	}
+
+	app.SetIdempotencyLease(DefaultIdempotencyLease)
+
+	if _, err := app.ClaimIdempotencyKey("alice", "key-1"); err != nil {
+		t.Fatalf("error taking over key: %s", err.Error())
+	}
+
+	// The first request must neither store its response nor release the key
+	// of the retry.
+	if err := app.CompleteIdempotentRequest(claim, model.IdempotencyRecord{Status: 200}); !errors.Is(err, ErrIdempotencyLeaseExpired) {
+		t.Errorf("expected error %v, got %v", ErrIdempotencyLeaseExpired, err)
+	}
+
+	if err := app.ReleaseIdempotencyKey(claim); !errors.Is(err, ErrIdempotencyLeaseExpired) {
+		t.Errorf("expected error %v, got %v", ErrIdempotencyLeaseExpired, err)
+	}
+
+	if _, err := app.GetIdempotencyRecord("alice", "key-1"); !errors.Is(err, ErrIdempotentRequestInProgress) {
+		t.Errorf("expected error %v, got %v", ErrIdempotentRequestInProgress, err)
+	}
+}
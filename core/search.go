@@ -0,0 +1,39 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/search"
+)
+
+// MaxSearchResults is the maximum number of results returned by Search.
+const MaxSearchResults = 50
+
+var (
+	// ErrQueryMustNotBeEmpty indicates that a search query is empty.
+	ErrQueryMustNotBeEmpty = errors.New("query must not be empty")
+)
+
+// Search runs a full-text search across all ToDo items, tasks and comments and
+// returns the best matches ordered by relevance. Each result has a snippet of
+// the matched text with all query terms highlighted.
+func (a *App) Search(query string) ([]model.SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ErrQueryMustNotBeEmpty
+	}
+
+	results, err := a.storage.Search(query, MaxSearchResults)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, result := range results {
+		results[i].Snippet = search.Snippet(result.Text, query)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,53 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+func TestApp_Search(t *testing.T) {
+	app := newTestApp(t)
+
+	if _, err := app.Search("  "); !errors.Is(err, ErrQueryMustNotBeEmpty) {
+		t.Errorf("expected error %v, got %v", ErrQueryMustNotBeEmpty, err)
+	}
+
+	toDo, _ := app.CreateToDo(model.ToDo{
+		Name: "Release preparation",
+		Tasks: []model.Task{
+			{
+				Name: "Write changelog",
+			},
+		},
+	})
+
+	results, err := app.Search("changelog")
+	if err != nil {
+		t.Fatalf("error searching: %s", err.Error())
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("expected %d results, got %d", 1, len(results))
+	}
+
+	if results[0].Snippet != "Write <mark>changelog</mark>" {
+		t.Errorf("unexpected snippet %s", results[0].Snippet)
+	}
+
+	_, _ = app.CreateComment(toDo.ID, 0, model.Comment{
+		Author: "Alice",
+		Body:   "The changelog needs a review",
+	})
+
+	toDo.Tasks = nil
+	_ = app.UpdateToDo(toDo.ID, toDo)
+
+	results, _ = app.Search("changelog")
+
+	if len(results) != 1 || results[0].Type != model.SearchResultComment {
+		t.Fatalf("expected the comment to be the only result, got %v", results)
+	}
+}
@@ -0,0 +1,77 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+// AddTask adds a new task to the ToDo item with the given ID and returns the
+// updated item. The task should not have an ID.
+//
+// If version is not 0, it has to match the version of the stored item, which
+// guarantees that the edit is based on the latest state of the item. Otherwise,
+// storage.ErrVersionConflict will be returned.
+func (a *App) AddTask(toDoID, version int64, task model.Task) (model.ToDo, error) {
+	return a.editTasks(toDoID, version, func(toDo *model.ToDo) error {
+		task.ID = 0
+		toDo.Tasks = append(toDo.Tasks, task)
+		return nil
+	})
+}
+
+// UpdateTask overwrites a task of the ToDo item with the given ID and returns
+// the updated item. The version is treated like in AddTask.
+func (a *App) UpdateTask(toDoID, version int64, task model.Task) (model.ToDo, error) {
+	return a.editTasks(toDoID, version, func(toDo *model.ToDo) error {
+		for i := range toDo.Tasks {
+			if toDo.Tasks[i].ID == task.ID {
+				toDo.Tasks[i] = task
+				return nil
+			}
+		}
+		return storage.ErrTaskNotFound
+	})
+}
+
+// DeleteTask removes a task from the ToDo item with the given ID and returns
+// the updated item. The version is treated like in AddTask.
+func (a *App) DeleteTask(toDoID, version, taskID int64) (model.ToDo, error) {
+	return a.editTasks(toDoID, version, func(toDo *model.ToDo) error {
+		for i := range toDo.Tasks {
+			if toDo.Tasks[i].ID == taskID {
+				toDo.Tasks = append(toDo.Tasks[:i], toDo.Tasks[i+1:]...)
+				return nil
+			}
+		}
+		return storage.ErrTaskNotFound
+	})
+}
+
+// editTasks loads the ToDo item with the given ID, applies the edit function to
+// it and stores the result using UpdateToDo, so that the same validation rules
+// apply as for updating the entire item.
+func (a *App) editTasks(toDoID, version int64, edit func(toDo *model.ToDo) error) (model.ToDo, error) {
+	toDo, err := a.storage.FindToDoByID(toDoID)
+	if err != nil {
+		return model.ToDo{}, err
+	}
+
+	if version != 0 && version != toDo.Version {
+		return model.ToDo{}, storage.ErrVersionConflict
+	}
+
+	// Copy the tasks, since storage implementations like the in-memory storage
+	// may return the stored slice.
+	toDo.Tasks = append([]model.Task(nil), toDo.Tasks...)
+
+	if err := edit(&toDo); err != nil {
+		return model.ToDo{}, err
+	}
+
+	if err := a.UpdateToDo(toDoID, toDo); err != nil {
+		return model.ToDo{}, err
+	}
+
+	return a.storage.FindToDoByID(toDoID)
+}
@@ -0,0 +1,87 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+func TestApp_UpdateTask(t *testing.T) {
+	app := newTestApp(t)
+
+	toDo, _ := app.CreateToDo(model.ToDo{
+		Name:  "ToDo 1",
+		Tasks: []model.Task{{Name: "Task 1"}},
+	})
+
+	updatedToDo, err := app.UpdateTask(toDo.ID, toDo.Version, model.Task{ID: toDo.Tasks[0].ID, Name: "Renamed"})
+	if err != nil {
+		t.Fatalf("error updating task: %s", err.Error())
+	}
+
+	if updatedToDo.Version != toDo.Version+1 || updatedToDo.Tasks[0].Name != "Renamed" {
+		t.Errorf("unexpected ToDo %v", updatedToDo)
+	}
+
+	tests := map[string]struct {
+		version  int64
+		task     model.Task
+		expected error
+	}{
+		"outdated version": {
+			version:  toDo.Version,
+			task:     model.Task{ID: toDo.Tasks[0].ID, Name: "Outdated"},
+			expected: storage.ErrVersionConflict,
+		},
+		"empty name": {
+			task:     model.Task{ID: toDo.Tasks[0].ID},
+			expected: ErrNameMustNotBeEmpty,
+		},
+		"unknown task": {
+			task:     model.Task{ID: 42, Name: "Task 42"},
+			expected: storage.ErrTaskNotFound,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := app.UpdateTask(toDo.ID, test.version, test.task)
+			if !errors.Is(err, test.expected) {
+				t.Errorf("expected error %v, got %v", test.expected, err)
+			}
+		})
+	}
+
+	storedToDo, _ := app.GetToDo(toDo.ID)
+
+	if storedToDo.Tasks[0].Name != "Renamed" {
+		t.Errorf("expected rejected edits to leave the task unchanged, got %v", storedToDo.Tasks[0])
+	}
+}
+
+func TestApp_AddAndDeleteTask(t *testing.T) {
+	app := newTestApp(t)
+
+	toDo, _ := app.CreateToDo(model.ToDo{Name: "ToDo 1"})
+
+	toDo, err := app.AddTask(toDo.ID, 0, model.Task{Name: "Task 1"})
+	if err != nil {
+		t.Fatalf("error adding task: %s", err.Error())
+	}
+
+	if len(toDo.Tasks) != 1 || toDo.Tasks[0].ID == 0 {
+		t.Fatalf("expected a task with ID, got %v", toDo.Tasks)
+	}
+
+	toDo, err = app.DeleteTask(toDo.ID, toDo.Version, toDo.Tasks[0].ID)
+	if err != nil {
+		t.Fatalf("error deleting task: %s", err.Error())
+	}
+
+	if len(toDo.Tasks) != 0 {
+		t.Errorf("expected %d tasks, got %d", 0, len(toDo.Tasks))
+	}
+}
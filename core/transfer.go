@@ -0,0 +1,237 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+	"github.com/dominikbraun/todo/todocsv"
+)
+
+// ErrInvalidRows indicates that an all-or-nothing import has been refused
+// because some of its rows are invalid. It is wrapped by *InvalidRowsError.
+var ErrInvalidRows = errors.New("import contains invalid rows")
+
+// InvalidRowsError lists the invalid rows of a refused import.
+type InvalidRowsError struct {
+	Rows []model.RowError
+}
+
+// Error returns the message of ErrInvalidRows, so that clients can recognize it.
+func (e *InvalidRowsError) Error() string {
+	return ErrInvalidRows.Error()
+}
+
+// Unwrap returns ErrInvalidRows.
+func (e *InvalidRowsError) Unwrap() error {
+	return ErrInvalidRows
+}
+
+// toDoColumns and taskColumns map the fields of ToDo items and tasks, as named
+// by violations, to the CSV fields they are read from.
+var (
+	toDoColumns = map[string]string{
+		"/name":        todocsv.FieldName,
+		"/description": todocsv.FieldDescription,
+		"/project":     todocsv.FieldProject,
+		"/priority":    todocsv.FieldPriority,
+		"/tags":        todocsv.FieldTags,
+		"/recurrence":  todocsv.FieldRecurrence,
+		"/tasks":       todocsv.FieldTask,
+	}
+	taskColumns = map[string]string{
+		"/name":        todocsv.FieldTask,
+		"/description": todocsv.FieldTaskDescription,
+	}
+)
+
+// ImportToDos creates all given ToDo items, which should not have IDs. All items
+// are validated before the first one is created, so that an invalid item does
+// not lead to a partial import. Validation errors are prefixed with the 1-based
+// position of the item and wrap the original error.
+//
+// If dryRun is true, the items are only validated and returned the way they
+// would be created, but without being stored.
+func (a *App) ImportToDos(toDos []model.ToDo, dryRun bool) (model.ImportResult, error) {
+	for i, toDo := range toDos {
+		if err := validateToDo(normalizeToDo(toDo)); err != nil {
+			return model.ImportResult{}, fmt.Errorf("todo %d: %w", i+1, err)
+		}
+	}
+
+	result := model.ImportResult{
+		DryRun: dryRun,
+		ToDos:  make([]model.ToDo, 0, len(toDos)),
+	}
+
+	for _, toDo := range toDos {
+		if dryRun {
+			result.ToDos = append(result.ToDos, normalizeToDo(toDo))
+			continue
+		}
+
+		createdToDo, err := a.CreateToDo(toDo)
+		if err != nil {
+			return model.ImportResult{}, err
+		}
+
+		result.ToDos = append(result.ToDos, createdToDo)
+	}
+
+	return result, nil
+}
+
+// ImportCSV creates the ToDo items read from a CSV file. Each entry is validated
+// along with its tasks, and the errors are reported with the row and the field
+// they refer to.
+//
+// If allOrNothing is true, an *InvalidRowsError listing all errors is returned
+// if any entry is invalid, and the ToDo items are created in a single
+// transaction, so that none is created if creating one of them fails.
+// Otherwise, invalid entries are skipped and listed as errors of the result.
+//
+// If dryRun is true, the items are only validated and returned the way they
+// would be created, but without being stored.
+func (a *App) ImportCSV(entries []todocsv.Entry, allOrNothing, dryRun bool) (model.ImportResult, error) {
+	result := model.ImportResult{
+		DryRun: dryRun,
+		ToDos:  make([]model.ToDo, 0, len(entries)),
+	}
+
+	valid := make([]model.ToDo, 0, len(entries))
+
+	for _, entry := range entries {
+		if rowErrors := validateEntry(entry); len(rowErrors) > 0 {
+			result.Errors = append(result.Errors, rowErrors...)
+			continue
+		}
+		valid = append(valid, entry.ToDo)
+	}
+
+	sort.SliceStable(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Row < result.Errors[j].Row
+	})
+
+	if allOrNothing && len(result.Errors) > 0 {
+		return model.ImportResult{}, &InvalidRowsError{Rows: result.Errors}
+	}
+
+	if dryRun {
+		for _, toDo := range valid {
+			result.ToDos = append(result.ToDos, normalizeToDo(toDo))
+		}
+		return result, nil
+	}
+
+	var (
+		operations        = make([]batchOperation, len(valid))
+		storageOperations = make([]storage.BatchOperation, len(valid))
+	)
+
+	for i, toDo := range valid {
+		storageOperations[i] = storage.BatchOperation{Action: storage.BatchCreate, ToDo: normalizeToDo(toDo)}
+		operations[i] = batchOperation{index: i, action: model.BatchCreate, operation: storageOperations[i]}
+	}
+
+	// An all-or-nothing import is stored in a single transaction, and events
+	// are only published for ToDo items that have been stored.
+	storageResults, err := a.storage.Batch(storageOperations, allOrNothing)
+	if err != nil {
+		return model.ImportResult{}, err
+	}
+
+	var firstErr error
+
+	for i, op := range operations {
+		if storageResults[i].Err != nil {
+			if firstErr == nil {
+				firstErr = storageResults[i].Err
+			}
+			continue
+		}
+
+		createdToDo, err := a.completeBatchOperation(op, storageResults[i].ID)
+		if err != nil {
+			log.Printf("failed to complete import of ToDo %d: %s", createdToDo.ID, err.Error())
+		}
+
+		result.ToDos = append(result.ToDos, createdToDo)
+	}
+
+	if firstErr != nil {
+		return model.ImportResult{}, firstErr
+	}
+
+	return result, nil
+}
+
+// validateEntry returns the errors of an entry read from a CSV file, including
+// the values that couldn't be parsed.
+func validateEntry(entry todocsv.Entry) []model.RowError {
+	rowErrors := append([]model.RowError(nil), entry.Errors...)
+
+	var validationErr *ValidationError
+
+	if errors.As(validateToDo(normalizeToDo(entry.ToDo)), &validationErr) {
+		for _, violation := range validationErr.Violations {
+			rowErrors = append(rowErrors, rowError(entry, violation))
+		}
+	}
+
+	return rowErrors
+}
+
+// rowError returns a RowError for a violation of an entry. Violations of task
+// fields refer to the row of the task.
+func rowError(entry todocsv.Entry, violation Violation) model.RowError {
+	row, field, columns := entry.Row, violation.Field, toDoColumns
+
+	// Task fields are named like `/tasks/2/name`.
+	if parts := strings.SplitN(field, "/", 4); len(parts) == 4 && parts[1] == "tasks" {
+		if i, err := strconv.Atoi(parts[2]); err == nil && i < len(entry.TaskRows) {
+			row, field, columns = entry.TaskRows[i], "/"+parts[3], taskColumns
+		}
+	}
+
+	// Fields of list items like `/tags/1` refer to the column of the list.
+	if parts := strings.SplitN(field, "/", 3); len(parts) == 3 {
+		field = "/" + parts[1]
+	}
+
+	return model.RowError{
+		Row:     row,
+		Column:  columns[field],
+		Message: violation.Err.Error(),
+	}
+}
+
+// ExportToDos returns all ToDo items ordered by ID, or the items matching the
+// given filter query if it isn't empty.
+func (a *App) ExportToDos(query string) ([]model.ToDo, error) {
+	var (
+		toDos []model.ToDo
+		err   error
+	)
+
+	if query != "" {
+		toDos, err = a.GetToDosByFilter(query)
+	} else {
+		toDos, err = a.GetToDos()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(toDos, func(i, j int) bool {
+		return toDos[i].ID < toDos[j].ID
+	})
+
+	return toDos, nil
+}
@@ -0,0 +1,174 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+	"github.com/dominikbraun/todo/todocsv"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestApp_ImportToDos(t *testing.T) {
+	app := newTestApp(t)
+
+	toDos := []model.ToDo{
+		{Name: "ToDo 1", Tags: []string{"Release"}},
+		{Name: "ToDo 2", Tasks: []model.Task{{Name: ""}}},
+	}
+
+	// A single invalid item must prevent the whole import.
+	if _, err := app.ImportToDos(toDos, false); !errors.Is(err, ErrNameMustNotBeEmpty) {
+		t.Errorf("expected error %v, got %v", ErrNameMustNotBeEmpty, err)
+	}
+
+	toDos[1].Tasks[0].Name = "Task 1"
+
+	result, err := app.ImportToDos(toDos, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !result.DryRun || len(result.ToDos) != 2 || result.ToDos[0].ID != 0 || result.ToDos[0].Tags[0] != "release" {
+		t.Errorf("expected 2 normalized ToDo items without IDs, got %v", result)
+	}
+
+	if stored, _ := app.GetToDos(); len(stored) != 0 {
+		t.Fatalf("expected no ToDo items after dry run, got %d", len(stored))
+	}
+
+	result, err = app.ImportToDos(toDos, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.DryRun || len(result.ToDos) != 2 || result.ToDos[1].ID == 0 {
+		t.Errorf("expected 2 created ToDo items, got %v", result)
+	}
+}
+
+func TestApp_ExportToDos(t *testing.T) {
+	app := newTestApp(t)
+
+	for _, name := range []string{"ToDo 1", "ToDo 2", "ToDo 3"} {
+		_, _ = app.CreateToDo(model.ToDo{Name: name, Done: name == "ToDo 2"})
+	}
+
+	toDos, err := app.ExportToDos("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, toDo := range toDos {
+		if toDo.ID != int64(i+1) {
+			t.Fatalf("expected ToDo items ordered by ID, got %v", toDos)
+		}
+	}
+
+	if toDos, _ = app.ExportToDos("done"); len(toDos) != 1 || toDos[0].Name != "ToDo 2" {
+		t.Errorf("expected only ToDo 2, got %v", toDos)
+	}
+}
+
+func TestApp_ImportCSV(t *testing.T) {
+	app := newTestApp(t)
+
+	entries := []todocsv.Entry{
+		{ToDo: model.ToDo{Name: "ToDo 1", Tasks: []model.Task{{Name: "Task 1"}, {Description: "No name"}}}, Row: 2, TaskRows: []int{2, 3}},
+		{ToDo: model.ToDo{Name: strings.Repeat("x", MaxNameLength+1)}, Row: 4},
+		{ToDo: model.ToDo{Name: "ToDo 3", Tags: []string{"Release"}}, Row: 5},
+		{ToDo: model.ToDo{Name: "ToDo 4"}, Row: 6, Errors: []model.RowError{{Row: 6, Column: todocsv.FieldDue, Message: "invalid"}}},
+	}
+
+	expectedErrors := []model.RowError{
+		{Row: 3, Column: todocsv.FieldTask, Message: ErrNameMustNotBeEmpty.Error()},
+		{Row: 4, Column: todocsv.FieldName, Message: ErrNameTooLong.Error()},
+		{Row: 6, Column: todocsv.FieldDue, Message: "invalid"},
+	}
+
+	_, err := app.ImportCSV(entries, true, false)
+
+	var invalidRows *InvalidRowsError
+
+	if !errors.As(err, &invalidRows) || !errors.Is(err, ErrInvalidRows) {
+		t.Fatalf("expected error %v, got %v", ErrInvalidRows, err)
+	}
+
+	if diff := cmp.Diff(expectedErrors, invalidRows.Rows); diff != "" {
+		t.Errorf("unexpected row errors (-expected +got):\n%s", diff)
+	}
+
+	if stored, _ := app.GetToDos(); len(stored) != 0 {
+		t.Fatalf("expected no ToDo items after refused import, got %d", len(stored))
+	}
+
+	result, err := app.ImportCSV(entries, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !result.DryRun || len(result.ToDos) != 1 || result.ToDos[0].Tags[0] != "release" {
+		t.Errorf("expected 1 normalized ToDo item, got %v", result)
+	}
+
+	if stored, _ := app.GetToDos(); len(stored) != 0 {
+		t.Fatalf("expected no ToDo items after dry run, got %d", len(stored))
+	}
+
+	result, err = app.ImportCSV(entries, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expectedErrors, result.Errors); diff != "" {
+		t.Errorf("unexpected row errors (-expected +got):\n%s", diff)
+	}
+
+	if stored, _ := app.GetToDos(); len(stored) != 1 || stored[0].Name != "ToDo 3" {
+		t.Errorf("expected only ToDo 3 to be created, got %v", stored)
+	}
+}
+
+// interruptedBatchStorage is a storage whose atomic batches fail after their
+// last operation has been applied.
+type interruptedBatchStorage struct {
+	storage.Storage
+}
+
+// Batch applies the operations along with an update of a missing ToDo item,
+// which makes the batch fail.
+func (i interruptedBatchStorage) Batch(operations []storage.BatchOperation, atomic bool) ([]storage.BatchResult, error) {
+	failing := storage.BatchOperation{Action: storage.BatchUpdate, ID: 1000, ToDo: model.ToDo{Name: "Missing"}}
+	return i.Storage.Batch(append(operations, failing), atomic)
+}
+
+func TestApp_ImportCSV_AllOrNothing(t *testing.T) {
+	app := newTestApp(t)
+	app.storage = interruptedBatchStorage{Storage: app.storage}
+
+	subscription := app.Subscribe(0)
+	defer subscription.Close()
+
+	entries := []todocsv.Entry{
+		{ToDo: model.ToDo{Name: "ToDo 1"}, Row: 2},
+		{ToDo: model.ToDo{Name: "ToDo 2"}, Row: 3},
+	}
+
+	if _, err := app.ImportCSV(entries, true, false); !errors.Is(err, storage.ErrToDoNotFound) {
+		t.Fatalf("expected error %v, got %v", storage.ErrToDoNotFound, err)
+	}
+
+	if stored, _ := app.GetToDos(); len(stored) != 0 {
+		t.Errorf("expected no ToDo items after failed import, got %v", stored)
+	}
+
+	select {
+	case event := <-subscription.Events:
+		t.Errorf("expected no events for a failed import, got %v", event)
+	default:
+	}
+}
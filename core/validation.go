@@ -0,0 +1,89 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ValidationError is returned for invalid values and lists all of their invalid
+// fields rather than only the first one. errors.Is reports whether any of the
+// violations is the given error, e.g. ErrNameMustNotBeEmpty.
+type ValidationError struct {
+	Violations []Violation
+}
+
+// Violation is an invalid field of a value. Field is a JSON pointer to the field
+// within the value, like `/tasks/2/name`, and Err is the validation error like
+// ErrNameTooLong, possibly wrapped by an error with details.
+type Violation struct {
+	Field string
+	Err   error
+}
+
+// Error returns the message of the violation if there is only one, so that the
+// error reads like the validation error itself. Otherwise, the messages of all
+// violations are listed along with their fields.
+func (e *ValidationError) Error() string {
+	if len(e.Violations) == 1 {
+		return e.Violations[0].Err.Error()
+	}
+
+	messages := make([]string, len(e.Violations))
+
+	for i, violation := range e.Violations {
+		messages[i] = fmt.Sprintf("%s: %s", violation.Field, violation.Err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Is reports whether one of the violations matches the target error.
+func (e *ValidationError) Is(target error) bool {
+	for _, violation := range e.Violations {
+		if errors.Is(violation.Err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validator collects the violations of a value.
+type validator struct {
+	violations []Violation
+}
+
+// add adds a violation of the field with the given JSON pointer.
+func (v *validator) add(field string, err error) {
+	v.violations = append(v.violations, Violation{Field: field, Err: err})
+}
+
+// err returns a *ValidationError if there are violations, or nil otherwise.
+func (v *validator) err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Violations: v.violations}
+}
+
+// prefixViolations prepends the given JSON pointer to the fields of a
+// *ValidationError, so that they refer to the value within an enclosing value.
+// Other errors are returned unchanged.
+func prefixViolations(err error, prefix string) error {
+	var validationErr *ValidationError
+
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+
+	violations := make([]Violation, len(validationErr.Violations))
+
+	for i, violation := range validationErr.Violations {
+		violations[i] = Violation{Field: prefix + violation.Field, Err: violation.Err}
+	}
+
+	return &ValidationError{Violations: violations}
+}
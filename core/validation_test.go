@@ -0,0 +1,153 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidationError(t *testing.T) {
+	app := newTestApp(t)
+
+	_, err := app.CreateToDo(model.ToDo{
+		Priority: "AB",
+		Tasks: []model.Task{
+			{Name: "Task 1"},
+			{Name: strings.Repeat("x", MaxNameLength+1), Description: strings.Repeat("x", MaxDescriptionLength+1)},
+			{},
+		},
+	})
+
+	var validationErr *ValidationError
+
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+
+	expected := []Violation{
+		{Field: "/name", Err: ErrNameMustNotBeEmpty},
+		{Field: "/tasks/1/name", Err: ErrNameTooLong},
+		{Field: "/tasks/1/description", Err: ErrDescriptionTooLong},
+		{Field: "/tasks/2/name", Err: ErrNameMustNotBeEmpty},
+		{Field: "/priority", Err: ErrInvalidPriority},
+	}
+
+	if diff := cmp.Diff(expected, validationErr.Violations, cmp.Comparer(func(a, b error) bool { return a == b })); diff != "" {
+		t.Errorf("unexpected violations (-expected +got):\n%s", diff)
+	}
+
+	for _, target := range []error{ErrNameMustNotBeEmpty, ErrNameTooLong, ErrDescriptionTooLong, ErrInvalidPriority} {
+		if !errors.Is(err, target) {
+			t.Errorf("expected error to match %v", target)
+		}
+	}
+
+	if errors.Is(err, ErrInvalidRecurrence) {
+		t.Errorf("expected error not to match %v", ErrInvalidRecurrence)
+	}
+
+	if !strings.HasPrefix(err.Error(), "/name: name must not be empty; /tasks/1/name: ") {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+
+	// A single violation reads like the validation error itself.
+	if _, err := app.CreateComment(1, 0, model.Comment{Author: "Alice"}); err == nil || err.Error() != ErrBodyMustNotBeEmpty.Error() {
+		t.Errorf("expected error %v, got %v", ErrBodyMustNotBeEmpty, err)
+	}
+}
+
+func TestApp_Normalization(t *testing.T) {
+	app := newTestApp(t)
+
+	// The name is 100 characters long in NFC, but 200 characters in NFD.
+	name := strings.Repeat("é", MaxNameLength)
+
+	createdToDo, err := app.CreateToDo(model.ToDo{
+		Name:    "  Café ",
+		Project: " Café",
+		Tags:    []string{"Café"},
+		Tasks:   []model.Task{{Name: name, Description: " Café "}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := model.ToDo{
+		ID:      createdToDo.ID,
+		Name:    "Caf\u00e9",
+		Project: "Caf\u00e9",
+		Tags:    []string{"caf\u00e9"},
+		Tasks: []model.Task{
+			{ID: createdToDo.Tasks[0].ID, Name: strings.Repeat("\u00e9", MaxNameLength), Description: " Caf\u00e9 "},
+		},
+		Version: 1,
+	}
+
+	if diff := cmp.Diff(expected, createdToDo); diff != "" {
+		t.Errorf("unexpected ToDo item (-expected +got):\n%s", diff)
+	}
+}
+
+func TestApp_Validation(t *testing.T) {
+	app := newTestApp(t)
+
+	createdToDo, err := app.CreateToDo(model.ToDo{Name: "ToDo 1", Tasks: []model.Task{{Name: "Task 1"}, {Name: "Task 2"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tooManyTasks := make([]model.Task, MaxTasks+1)
+
+	for i := range tooManyTasks {
+		tooManyTasks[i].Name = "Task"
+	}
+
+	duplicateTasks := []model.Task{
+		{ID: createdToDo.Tasks[0].ID, Name: "Task 1"},
+		{ID: createdToDo.Tasks[0].ID, Name: "Task 2"},
+	}
+
+	tests := map[string]struct {
+		toDo     model.ToDo
+		field    string
+		expected error
+	}{
+		"whitespace name":   {model.ToDo{Name: " \t "}, "/name", ErrNameMustNotBeEmpty},
+		"whitespace task":   {model.ToDo{Name: "ToDo 1", Tasks: []model.Task{{Name: "\n"}}}, "/tasks/0/name", ErrNameMustNotBeEmpty},
+		"long project":      {model.ToDo{Name: "ToDo 1", Project: strings.Repeat("ö", MaxProjectLength+1)}, "/project", ErrProjectTooLong},
+		"long tag":          {model.ToDo{Name: "ToDo 1", Tags: []string{"a", strings.Repeat("ö", MaxTagLength+1)}}, "/tags/1", ErrTagTooLong},
+		"long recurrence":   {model.ToDo{Name: "ToDo 1", Recurrence: "FREQ=DAILY;" + strings.Repeat("x", MaxRecurrenceLength)}, "/recurrence", ErrRecurrenceTooLong},
+		"long UID":          {model.ToDo{Name: "ToDo 1", UID: strings.Repeat("u", MaxUIDLength+1)}, "/uid", ErrUIDTooLong},
+		"long task UID":     {model.ToDo{Name: "ToDo 1", Tasks: []model.Task{{Name: "Task", UID: strings.Repeat("u", MaxUIDLength+1)}}}, "/tasks/0/uid", ErrUIDTooLong},
+		"too many tasks":    {model.ToDo{Name: "ToDo 1", Tasks: tooManyTasks}, "/tasks", ErrTooManyTasks},
+		"duplicate task ID": {model.ToDo{Name: "ToDo 1", Tasks: duplicateTasks}, "/tasks/1/id", ErrDuplicateTaskID},
+	}
+
+	for name, test := range tests {
+		err := app.UpdateToDo(createdToDo.ID, test.toDo)
+
+		var validationErr *ValidationError
+
+		if !errors.As(err, &validationErr) || len(validationErr.Violations) != 1 {
+			t.Errorf("%s: expected a single violation, got %v", name, err)
+			continue
+		}
+
+		if violation := validationErr.Violations[0]; violation.Field != test.field || !errors.Is(violation.Err, test.expected) {
+			t.Errorf("%s: expected error %v for %s, got %v for %s", name, test.expected, test.field, violation.Err, violation.Field)
+		}
+	}
+
+	if _, err := app.AddTask(createdToDo.ID, 0, model.Task{Name: "Task 3"}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if _, err := app.CreateComment(createdToDo.ID, 0, model.Comment{Author: strings.Repeat("a", MaxAuthorLength+1), Body: "Hi"}); !errors.Is(err, ErrAuthorTooLong) {
+		t.Errorf("expected error %v, got %v", ErrAuthorTooLong, err)
+	}
+}
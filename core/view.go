@@ -0,0 +1,252 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dominikbraun/todo/filter"
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+var (
+	// ErrOwnerMustNotBeEmpty indicates that no user has been provided for a
+	// user-specific resource like a view.
+	ErrOwnerMustNotBeEmpty = errors.New("owner must not be empty")
+
+	// ErrOwnerTooLong indicates that the user provided for a user-specific
+	// resource is longer than MaxOwnerLength characters.
+	ErrOwnerTooLong = fmt.Errorf("owner must not be longer than %d characters", MaxOwnerLength)
+
+	// ErrFilterTooLong indicates that the filter query of a view is longer than
+	// MaxFilterLength characters.
+	ErrFilterTooLong = fmt.Errorf("filter must not be longer than %d characters", MaxFilterLength)
+
+	// ErrViewIsReadOnly indicates an attempt to modify or delete a built-in view.
+	ErrViewIsReadOnly = errors.New("built-in views cannot be modified")
+
+	// ErrInvalidSortOrder indicates that the sort order of a view is unknown.
+	ErrInvalidSortOrder = errors.New("sort order must be one of id, name, due, optionally prefixed with -")
+)
+
+const (
+	// MaxOwnerLength is the maximum number of characters of the user owning a
+	// resource like a view.
+	MaxOwnerLength = 100
+
+	// MaxFilterLength is the maximum number of characters of the filter query
+	// of a view.
+	MaxFilterLength = 500
+)
+
+// builtInViews are the views available to every user. They can't be modified
+// or deleted and are addressed by their key.
+var builtInViews = []model.View{
+	{Key: "today", Name: "Today", Filter: "due:today AND NOT done", Sort: "due"},
+	{Key: "overdue", Name: "Overdue", Filter: "due<now AND NOT done", Sort: "due"},
+	{Key: "unfinished", Name: "Unfinished", Filter: "NOT done", Sort: "id"},
+}
+
+// toDoLess reports whether the first ToDo item should be sorted before the
+// second one. There is one function for each supported sort field.
+var toDoLess = map[string]func(a, b model.ToDo) bool{
+	"id": func(a, b model.ToDo) bool {
+		return a.ID < b.ID
+	},
+	"name": func(a, b model.ToDo) bool {
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	},
+	"due": func(a, b model.ToDo) bool {
+		if a.Due == nil || b.Due == nil {
+			return a.Due != nil && b.Due == nil
+		}
+		return a.Due.Before(*b.Due)
+	},
+}
+
+// BuiltInView returns the built-in view with the given key. The returned bool
+// indicates whether such a view exists.
+func BuiltInView(key string) (model.View, bool) {
+	for _, view := range builtInViews {
+		if view.Key == key {
+			return view, true
+		}
+	}
+
+	return model.View{}, false
+}
+
+// CreateView stores a new view for the given owner. The filter query of the
+// view has to be valid, otherwise a *filter.SyntaxError will be returned.
+func (a *App) CreateView(owner string, view model.View) (model.View, error) {
+	view.ID = 0
+	view.Key = ""
+	view.Owner = owner
+
+	if err := validateView(view); err != nil {
+		return model.View{}, err
+	}
+
+	return a.storage.CreateView(view)
+}
+
+// GetViews returns the built-in views followed by all views of the given owner.
+func (a *App) GetViews(owner string) ([]model.View, error) {
+	if err := validateOwner(owner); err != nil {
+		return nil, err
+	}
+
+	views, err := a.storage.FindViews(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(append([]model.View{}, builtInViews...), views...), nil
+}
+
+// GetView returns the view with the given ID. Views of other users are treated
+// as if they didn't exist.
+func (a *App) GetView(owner string, id int64) (model.View, error) {
+	if err := validateOwner(owner); err != nil {
+		return model.View{}, err
+	}
+
+	view, err := a.storage.FindViewByID(id)
+	if err != nil {
+		return model.View{}, err
+	}
+
+	if view.Owner != owner {
+		return model.View{}, storage.ErrViewNotFound
+	}
+
+	return view, nil
+}
+
+// UpdateView overwrites the view with the given ID. Views of other users can't
+// be updated.
+func (a *App) UpdateView(owner string, id int64, view model.View) error {
+	if _, err := a.GetView(owner, id); err != nil {
+		return err
+	}
+
+	view.Key = ""
+	view.Owner = owner
+
+	if err := validateView(view); err != nil {
+		return err
+	}
+
+	return a.storage.UpdateView(id, view)
+}
+
+// DeleteView deletes the view with the given ID. Views of other users can't be
+// deleted.
+func (a *App) DeleteView(owner string, id int64) error {
+	if _, err := a.GetView(owner, id); err != nil {
+		return err
+	}
+
+	return a.storage.DeleteView(id)
+}
+
+// GetViewToDos evaluates the given view against the stored ToDo items and
+// returns all matching items in the sort order of the view.
+func (a *App) GetViewToDos(view model.View) ([]model.ToDo, error) {
+	var (
+		toDos []model.ToDo
+		err   error
+	)
+
+	if strings.TrimSpace(view.Filter) == "" {
+		toDos, err = a.storage.FindToDos()
+	} else {
+		toDos, err = a.GetToDosByFilter(view.Filter)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	field, descending := parseSortOrder(view.Sort)
+	less := toDoLess[field]
+
+	sort.SliceStable(toDos, func(i, j int) bool {
+		if descending {
+			return less(toDos[j], toDos[i])
+		}
+		return less(toDos[i], toDos[j])
+	})
+
+	return toDos, nil
+}
+
+// validateView checks whether the given view has a valid owner, a name, a valid
+// filter query and a known sort order. Invalid fields are reported by a
+// *ValidationError, except for filter queries with a syntax error, for which
+// the *filter.SyntaxError is returned.
+func validateView(view model.View) error {
+	if err := validateOwner(view.Owner); err != nil {
+		return err
+	}
+
+	var v validator
+
+	if view.Name == "" {
+		v.add("/name", ErrNameMustNotBeEmpty)
+	} else if utf8.RuneCountInString(view.Name) > MaxNameLength {
+		v.add("/name", ErrNameTooLong)
+	}
+
+	if utf8.RuneCountInString(view.Filter) > MaxFilterLength {
+		v.add("/filter", ErrFilterTooLong)
+	}
+
+	if field, _ := parseSortOrder(view.Sort); toDoLess[field] == nil {
+		v.add("/sort", ErrInvalidSortOrder)
+	}
+
+	if err := v.err(); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(view.Filter) != "" {
+		if _, err := filter.Parse(view.Filter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateOwner checks whether the user provided for a user-specific resource
+// isn't empty and fits into the owner columns of the storage.
+func validateOwner(owner string) error {
+	if owner == "" {
+		return ErrOwnerMustNotBeEmpty
+	}
+
+	if utf8.RuneCountInString(owner) > MaxOwnerLength {
+		return ErrOwnerTooLong
+	}
+
+	return nil
+}
+
+// parseSortOrder splits a sort order like `-due` into its field and direction.
+// An empty sort order sorts by ID.
+func parseSortOrder(order string) (string, bool) {
+	if order == "" {
+		return "id", false
+	}
+
+	if strings.HasPrefix(order, "-") {
+		return order[1:], true
+	}
+
+	return order, false
+}
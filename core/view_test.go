@@ -0,0 +1,156 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dominikbraun/todo/filter"
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+func TestApp_CreateView(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := map[string]struct {
+		owner    string
+		view     model.View
+		expected error
+	}{
+		"valid view": {
+			owner: "alice",
+			view:  model.View{Name: "Release", Filter: "tag:release", Sort: "-due"},
+		},
+		"missing owner": {
+			view:     model.View{Name: "Release", Filter: "tag:release"},
+			expected: ErrOwnerMustNotBeEmpty,
+		},
+		"missing name": {
+			owner:    "alice",
+			view:     model.View{Filter: "tag:release"},
+			expected: ErrNameMustNotBeEmpty,
+		},
+		"too long owner": {
+			owner:    strings.Repeat("x", MaxOwnerLength+1),
+			view:     model.View{Name: "Release"},
+			expected: ErrOwnerTooLong,
+		},
+		"too long name": {
+			owner:    "alice",
+			view:     model.View{Name: strings.Repeat("x", MaxNameLength+1)},
+			expected: ErrNameTooLong,
+		},
+		"too long filter": {
+			owner:    "alice",
+			view:     model.View{Name: "Release", Filter: "tag:" + strings.Repeat("x", MaxFilterLength)},
+			expected: ErrFilterTooLong,
+		},
+		"unknown sort order": {
+			owner:    "alice",
+			view:     model.View{Name: "Release", Sort: "priority"},
+			expected: ErrInvalidSortOrder,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := app.CreateView(test.owner, test.view)
+			if !errors.Is(err, test.expected) {
+				t.Errorf("expected error %v, got %v", test.expected, err)
+			}
+		})
+	}
+
+	_, err := app.CreateView("alice", model.View{Name: "Broken", Filter: "tag:"})
+
+	var syntaxError *filter.SyntaxError
+
+	if !errors.As(err, &syntaxError) {
+		t.Errorf("expected a syntax error, got %v", err)
+	}
+}
+
+func TestApp_GetView(t *testing.T) {
+	app := newTestApp(t)
+
+	view, _ := app.CreateView("alice", model.View{Name: "Release", Filter: "tag:release"})
+
+	if _, err := app.GetView("alice", view.ID); err != nil {
+		t.Fatalf("error getting view: %s", err.Error())
+	}
+
+	if _, err := app.GetView("bob", view.ID); !errors.Is(err, storage.ErrViewNotFound) {
+		t.Errorf("expected error %v, got %v", storage.ErrViewNotFound, err)
+	}
+
+	if err := app.DeleteView("bob", view.ID); !errors.Is(err, storage.ErrViewNotFound) {
+		t.Errorf("expected error %v, got %v", storage.ErrViewNotFound, err)
+	}
+
+	views, _ := app.GetViews("alice")
+
+	if len(views) != len(builtInViews)+1 {
+		t.Errorf("expected %d views, got %d", len(builtInViews)+1, len(views))
+	}
+}
+
+func TestApp_GetViewToDos(t *testing.T) {
+	app := newTestApp(t)
+
+	now := time.Now().UTC()
+	yesterday := now.AddDate(0, 0, -1)
+	lastWeek := now.AddDate(0, 0, -7)
+
+	toDos := []model.ToDo{
+		{Name: "Beta", Due: &yesterday},
+		{Name: "alpha", Due: &lastWeek},
+		{Name: "Gamma", Due: &lastWeek, Done: true},
+		{Name: "Delta"},
+	}
+
+	for _, toDo := range toDos {
+		_, _ = app.CreateToDo(toDo)
+	}
+
+	overdue, _ := BuiltInView("overdue")
+
+	tests := map[string]struct {
+		view     model.View
+		expected []int64
+	}{
+		"overdue": {
+			view:     overdue,
+			expected: []int64{2, 1},
+		},
+		"by name": {
+			view:     model.View{Filter: "NOT done", Sort: "name"},
+			expected: []int64{2, 1, 4},
+		},
+		"all descending": {
+			view:     model.View{Sort: "-id"},
+			expected: []int64{4, 3, 2, 1},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := app.GetViewToDos(test.view)
+			if err != nil {
+				t.Fatalf("error evaluating view: %s", err.Error())
+			}
+
+			if len(result) != len(test.expected) {
+				t.Fatalf("expected %d ToDos, got %d", len(test.expected), len(result))
+			}
+
+			for i, toDo := range result {
+				if toDo.ID != test.expected[i] {
+					t.Errorf("expected ToDo %d at position %d, got %d", test.expected[i], i, toDo.ID)
+				}
+			}
+		})
+	}
+}
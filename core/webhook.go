@@ -0,0 +1,382 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+const (
+	// MaxDeliveryAttempts is the number of attempts after which a delivery is
+	// considered as failed.
+	MaxDeliveryAttempts = 8
+
+	// WebhookDispatchInterval is the interval in which RunWebhookDispatcher
+	// looks for due deliveries.
+	WebhookDispatchInterval = 5 * time.Second
+
+	// SignatureHeader is the request header containing the HMAC-SHA256 signature
+	// of a delivery, computed over the request body with the webhook secret.
+	SignatureHeader = "X-Todo-Signature"
+
+	// EventHeader is the request header containing the type of the event.
+	EventHeader = "X-Todo-Event"
+
+	// DeliveryHeader is the request header containing the ID of the delivery,
+	// which is the same for all attempts of a delivery.
+	DeliveryHeader = "X-Todo-Delivery"
+
+	// MaxWebhookURLLength is the maximum number of characters of a webhook URL.
+	MaxWebhookURLLength = 2000
+
+	// MaxSecretLength is the maximum number of characters of a webhook secret.
+	MaxSecretLength = 100
+)
+
+const (
+	deliveryBatchSize  = 50
+	deliveryTimeout    = 10 * time.Second
+	minDeliveryBackoff = 30 * time.Second
+	maxDeliveryBackoff = 6 * time.Hour
+	maxLastErrorLength = 500
+)
+
+var (
+	// ErrInvalidWebhookURL indicates that a webhook URL is not an absolute HTTP
+	// or HTTPS URL.
+	ErrInvalidWebhookURL = errors.New("url must be an absolute http or https URL")
+
+	// ErrUnknownEventType indicates that a webhook subscribes to an unknown
+	// event type.
+	ErrUnknownEventType = errors.New("events must be one of created, updated, deleted, completed")
+
+	// ErrWebhookURLTooLong indicates that a webhook URL is longer than
+	// MaxWebhookURLLength characters.
+	ErrWebhookURLTooLong = fmt.Errorf("url must not be longer than %d characters", MaxWebhookURLLength)
+
+	// ErrSecretTooLong indicates that a webhook secret is longer than
+	// MaxSecretLength characters.
+	ErrSecretTooLong = fmt.Errorf("secret must not be longer than %d characters", MaxSecretLength)
+)
+
+var deliveryClient = &http.Client{Timeout: deliveryTimeout}
+
+// CreateWebhook registers a new webhook. If the webhook has no secret, a random
+// secret will be generated. Event types that are listed more than once are
+// only stored once. Invalid fields are reported by a *ValidationError.
+func (a *App) CreateWebhook(webhook model.Webhook) (model.Webhook, error) {
+	if err := validateWebhook(webhook); err != nil {
+		return model.Webhook{}, err
+	}
+
+	webhook.Events = uniqueEventTypes(webhook.Events)
+
+	if webhook.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return model.Webhook{}, err
+		}
+		webhook.Secret = secret
+	}
+
+	webhook.CreatedAt = currentTime()
+
+	return a.storage.CreateWebhook(webhook)
+}
+
+// GetWebhooks returns a list of all webhooks.
+func (a *App) GetWebhooks() ([]model.Webhook, error) {
+	return a.storage.FindWebhooks()
+}
+
+// GetWebhook returns the webhook with the given ID.
+func (a *App) GetWebhook(id int64) (model.Webhook, error) {
+	return a.storage.FindWebhookByID(id)
+}
+
+// DeleteWebhook deletes the webhook with the given ID along with its pending
+// and completed deliveries.
+func (a *App) DeleteWebhook(id int64) error {
+	return a.storage.DeleteWebhook(id)
+}
+
+// GetDeliveries returns all deliveries of the webhook with the given ID.
+func (a *App) GetDeliveries(webhookID int64) ([]model.Delivery, error) {
+	if _, err := a.storage.FindWebhookByID(webhookID); err != nil {
+		return nil, err
+	}
+
+	return a.storage.FindDeliveries(webhookID)
+}
+
+// validateWebhook checks whether the given webhook has an absolute HTTP or HTTPS
+// URL, known event types and fields that fit into their storage columns.
+func validateWebhook(webhook model.Webhook) error {
+	var v validator
+
+	if utf8.RuneCountInString(webhook.URL) > MaxWebhookURLLength {
+		v.add("/url", ErrWebhookURLTooLong)
+	} else if target, err := url.Parse(webhook.URL); err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		v.add("/url", ErrInvalidWebhookURL)
+	}
+
+	if utf8.RuneCountInString(webhook.Secret) > MaxSecretLength {
+		v.add("/secret", ErrSecretTooLong)
+	}
+
+	for i, eventType := range webhook.Events {
+		switch eventType {
+		case model.EventCreated, model.EventUpdated, model.EventDeleted, model.EventCompleted:
+		default:
+			v.add(fmt.Sprintf("/events/%d", i), ErrUnknownEventType)
+		}
+	}
+
+	return v.err()
+}
+
+// uniqueEventTypes returns the given event types without duplicates, keeping
+// the order of their first occurrence.
+func uniqueEventTypes(eventTypes []model.EventType) []model.EventType {
+	if eventTypes == nil {
+		return nil
+	}
+
+	var (
+		unique = make([]model.EventType, 0, len(eventTypes))
+		seen   = make(map[model.EventType]bool)
+	)
+
+	for _, eventType := range eventTypes {
+		if !seen[eventType] {
+			unique = append(unique, eventType)
+			seen[eventType] = true
+		}
+	}
+
+	return unique
+}
+
+// RunWebhookDispatcher delivers all due deliveries in a fixed interval until
+// the context is canceled. Errors are logged and don't stop the dispatcher.
+func (a *App) RunWebhookDispatcher(ctx context.Context) {
+	ticker := time.NewTicker(WebhookDispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.DeliverWebhooks(); err != nil {
+				log.Printf("failed to deliver webhooks: %s", err.Error())
+			}
+		}
+	}
+}
+
+// DeliverWebhooks sends all deliveries that are due. Failed attempts are retried
+// with exponential backoff until MaxDeliveryAttempts is reached.
+//
+// Since the deliveries are stored before being sent, they survive restarts of
+// the application. If the outcome of an attempt can't be recorded, the error is
+// logged and the other deliveries are sent anyway.
+func (a *App) DeliverWebhooks() error {
+	for {
+		deliveries, err := a.storage.FindDueDeliveries(currentTime(), deliveryBatchSize)
+		if err != nil {
+			return err
+		}
+
+		failed := false
+
+		for _, delivery := range deliveries {
+			if err := a.attemptDelivery(delivery); err != nil {
+				log.Printf("failed to record attempt of delivery %d: %s", delivery.ID, err.Error())
+				failed = true
+			}
+		}
+
+		// Deliveries whose attempt couldn't be recorded are still due and would
+		// be found again, so they are left to the next call.
+		if failed || len(deliveries) < deliveryBatchSize {
+			return nil
+		}
+	}
+}
+
+// attemptDelivery sends a delivery to its webhook and records the outcome.
+func (a *App) attemptDelivery(delivery model.Delivery) error {
+	delivery.Attempts++
+
+	webhook, err := a.storage.FindWebhookByID(delivery.WebhookID)
+	if errors.Is(err, storage.ErrWebhookNotFound) {
+		delivery.Attempts = MaxDeliveryAttempts
+	} else if err != nil {
+		return err
+	} else {
+		delivery.ResponseCode, err = send(webhook, delivery)
+	}
+
+	now := currentTime()
+	delivery.UpdatedAt = now
+	delivery.LastError = ""
+
+	switch {
+	case err == nil:
+		delivery.Status = model.DeliverySucceeded
+	case delivery.Attempts >= MaxDeliveryAttempts:
+		delivery.Status = model.DeliveryFailed
+		delivery.LastError = truncate(err.Error(), maxLastErrorLength)
+	default:
+		delivery.LastError = truncate(err.Error(), maxLastErrorLength)
+		delivery.NextAttemptAt = now.Add(deliveryBackoff(delivery.Attempts))
+	}
+
+	return a.storage.UpdateDelivery(delivery.ID, delivery)
+}
+
+// enqueueDeliveries stores a delivery of the event for each webhook that has
+// subscribed to the event type.
+func (a *App) enqueueDeliveries(event model.Event) error {
+	webhooks, err := a.storage.FindWebhooks()
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := currentTime()
+
+	for _, webhook := range webhooks {
+		if !isSubscribedTo(webhook, event.Type) {
+			continue
+		}
+
+		_, err := a.storage.CreateDelivery(model.Delivery{
+			WebhookID:     webhook.ID,
+			EventType:     event.Type,
+			Payload:       string(payload),
+			Status:        model.DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// send posts the payload of a delivery to the webhook URL and returns the
+// response status code. All responses other than 2xx are considered as error.
+func send(webhook model.Webhook, delivery model.Delivery) (int, error) {
+	payload := []byte(delivery.Payload)
+
+	request, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, string(delivery.EventType))
+	request.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	request.Header.Set(SignatureHeader, Sign(webhook.Secret, payload))
+
+	response, err := deliveryClient.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+
+	return response.StatusCode, nil
+}
+
+// Sign returns the signature of a payload as sent in the SignatureHeader, i.e.
+// `sha256=` followed by the hex-encoded HMAC-SHA256 of the payload.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliveryBackoff returns the delay before the next attempt of a delivery that
+// already has been attempted the given number of times.
+func deliveryBackoff(attempts int) time.Duration {
+	backoff := minDeliveryBackoff
+
+	for i := 1; i < attempts && backoff < maxDeliveryBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > maxDeliveryBackoff {
+		return maxDeliveryBackoff
+	}
+
+	return backoff
+}
+
+// isSubscribedTo reports whether a webhook wants to receive the event type.
+func isSubscribedTo(webhook model.Webhook, eventType model.EventType) bool {
+	if len(webhook.Events) == 0 {
+		return true
+	}
+
+	for _, subscribed := range webhook.Events {
+		if subscribed == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newSecret generates a random, hex-encoded webhook secret.
+func newSecret() (string, error) {
+	secret := make([]byte, 32)
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+// truncate shortens a string to the given number of characters.
+func truncate(s string, length int) string {
+	if utf8.RuneCountInString(s) <= length {
+		return s
+	}
+
+	return string([]rune(s)[:length])
+}
@@ -0,0 +1,258 @@
+// Package core provides the core application functionality and business logic.
+package core
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestApp_CreateWebhook(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := map[string]struct {
+		webhook  model.Webhook
+		expected error
+	}{
+		"valid webhook": {
+			webhook: model.Webhook{URL: "https://example.com/hook"},
+		},
+		"relative URL": {
+			webhook:  model.Webhook{URL: "/hook"},
+			expected: ErrInvalidWebhookURL,
+		},
+		"unsupported scheme": {
+			webhook:  model.Webhook{URL: "ftp://example.com/hook"},
+			expected: ErrInvalidWebhookURL,
+		},
+		"unknown event type": {
+			webhook:  model.Webhook{URL: "https://example.com/hook", Events: []model.EventType{"renamed"}},
+			expected: ErrUnknownEventType,
+		},
+		"too long URL": {
+			webhook:  model.Webhook{URL: "https://example.com/" + strings.Repeat("x", MaxWebhookURLLength)},
+			expected: ErrWebhookURLTooLong,
+		},
+		"too long secret": {
+			webhook:  model.Webhook{URL: "https://example.com/hook", Secret: strings.Repeat("x", MaxSecretLength+1)},
+			expected: ErrSecretTooLong,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			webhook, err := app.CreateWebhook(test.webhook)
+			if !errors.Is(err, test.expected) {
+				t.Fatalf("expected error %v, got %v", test.expected, err)
+			}
+
+			if err == nil && len(webhook.Secret) != 64 {
+				t.Errorf("expected a generated secret, got %q", webhook.Secret)
+			}
+		})
+	}
+
+	events := []model.EventType{model.EventCreated, model.EventDeleted, model.EventCreated}
+
+	for i := 0; i < 10; i++ {
+		events = append(events, model.EventCompleted)
+	}
+
+	webhook, err := app.CreateWebhook(model.Webhook{URL: "https://example.com/hook", Events: events})
+	if err != nil {
+		t.Fatalf("error creating webhook: %s", err.Error())
+	}
+
+	expected := []model.EventType{model.EventCreated, model.EventDeleted, model.EventCompleted}
+
+	if diff := cmp.Diff(expected, webhook.Events); diff != "" {
+		t.Errorf("unexpected event types (-expected +got):\n%s", diff)
+	}
+}
+
+func TestApp_DeliverWebhooks(t *testing.T) {
+	app := newTestApp(t)
+
+	type received struct {
+		event     string
+		signature string
+		body      []byte
+	}
+
+	requests := make(chan received, 10)
+	status := http.StatusInternalServerError
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := ioutil.ReadAll(request.Body)
+		requests <- received{
+			event:     request.Header.Get(EventHeader),
+			signature: request.Header.Get(SignatureHeader),
+			body:      body,
+		}
+		writer.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	webhook, _ := app.CreateWebhook(model.Webhook{
+		URL:    receiver.URL,
+		Secret: "secret",
+		Events: []model.EventType{model.EventCreated},
+	})
+
+	toDo, _ := app.CreateToDo(model.ToDo{Name: "ToDo 1"})
+	_ = app.DeleteToDo(toDo.ID)
+
+	// The first attempt fails, so the delivery is rescheduled.
+	if err := app.DeliverWebhooks(); err != nil {
+		t.Fatalf("error delivering webhooks: %s", err.Error())
+	}
+
+	request := <-requests
+
+	if request.event != string(model.EventCreated) {
+		t.Errorf("expected event %s, got %s", model.EventCreated, request.event)
+	}
+
+	if request.signature != Sign("secret", request.body) {
+		t.Errorf("invalid signature %s", request.signature)
+	}
+
+	deliveries, _ := app.GetDeliveries(webhook.ID)
+
+	if len(deliveries) != 1 {
+		t.Fatalf("expected %d delivery, got %d", 1, len(deliveries))
+	}
+
+	delivery := deliveries[0]
+
+	if delivery.Status != model.DeliveryPending || delivery.Attempts != 1 || delivery.ResponseCode != status {
+		t.Errorf("expected pending delivery after one attempt, got %v", delivery)
+	}
+
+	if delay := delivery.NextAttemptAt.Sub(delivery.UpdatedAt); delay != minDeliveryBackoff {
+		t.Errorf("expected a backoff of %v, got %v", minDeliveryBackoff, delay)
+	}
+
+	// Make the delivery due immediately instead of waiting for the backoff.
+	delivery.NextAttemptAt = time.Time{}
+	_ = app.storage.UpdateDelivery(delivery.ID, delivery)
+	status = http.StatusOK
+
+	if err := app.DeliverWebhooks(); err != nil {
+		t.Fatalf("error delivering webhooks: %s", err.Error())
+	}
+
+	<-requests
+
+	deliveries, _ = app.GetDeliveries(webhook.ID)
+
+	if deliveries[0].Status != model.DeliverySucceeded || deliveries[0].Attempts != 2 {
+		t.Errorf("expected succeeded delivery after two attempts, got %v", deliveries[0])
+	}
+}
+
+// unavailableWebhookStorage is a storage whose webhooks can't be loaded.
+type unavailableWebhookStorage struct {
+	storage.Storage
+}
+
+// FindWebhooks always returns an error.
+func (u unavailableWebhookStorage) FindWebhooks() ([]model.Webhook, error) {
+	return nil, errors.New("webhooks are unavailable")
+}
+
+func TestApp_CreateToDo_EnqueueError(t *testing.T) {
+	app := newTestApp(t)
+	app.storage = unavailableWebhookStorage{Storage: app.storage}
+
+	// The ToDo item has been stored, so the request must not fail because its
+	// webhook deliveries can't be enqueued.
+	toDo, err := app.CreateToDo(model.ToDo{Name: "ToDo 1"})
+	if err != nil {
+		t.Fatalf("error creating ToDo: %s", err.Error())
+	}
+
+	if err := app.DeleteToDo(toDo.ID); err != nil {
+		t.Fatalf("error deleting ToDo: %s", err.Error())
+	}
+}
+
+// unrecordableDeliveryStorage is a storage whose deliveries can't be updated.
+type unrecordableDeliveryStorage struct {
+	storage.Storage
+}
+
+// UpdateDelivery always returns an error.
+func (u unrecordableDeliveryStorage) UpdateDelivery(id int64, delivery model.Delivery) error {
+	return errors.New("deliveries are unavailable")
+}
+
+func TestApp_DeliverWebhooks_UpdateError(t *testing.T) {
+	app := newTestApp(t)
+
+	requests := make(chan struct{}, 10)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests <- struct{}{}
+	}))
+	defer receiver.Close()
+
+	_, _ = app.CreateWebhook(model.Webhook{URL: receiver.URL})
+	_, _ = app.CreateToDo(model.ToDo{Name: "ToDo 1"})
+	_, _ = app.CreateToDo(model.ToDo{Name: "ToDo 2"})
+
+	app.storage = unrecordableDeliveryStorage{Storage: app.storage}
+
+	// The deliveries remain due, so DeliverWebhooks must neither fail nor look
+	// for them again and again.
+	if err := app.DeliverWebhooks(); err != nil {
+		t.Fatalf("error delivering webhooks: %s", err.Error())
+	}
+
+	if len(requests) != 2 {
+		t.Errorf("expected %d requests, got %d", 2, len(requests))
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s        string
+		length   int
+		expected string
+	}{
+		{s: "abc", length: 3, expected: "abc"},
+		{s: "abcd", length: 3, expected: "abc"},
+		{s: "äöüß", length: 3, expected: "äöü"},
+		{s: "ä😀b", length: 2, expected: "ä😀"},
+	}
+
+	for _, test := range tests {
+		if truncated := truncate(test.s, test.length); truncated != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, truncated)
+		}
+	}
+}
+
+func TestDeliveryBackoff(t *testing.T) {
+	tests := map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		4:  4 * time.Minute,
+		20: maxDeliveryBackoff,
+	}
+
+	for attempts, expected := range tests {
+		if backoff := deliveryBackoff(attempts); backoff != expected {
+			t.Errorf("expected backoff %v after %d attempts, got %v", expected, attempts, backoff)
+		}
+	}
+}
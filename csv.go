@@ -0,0 +1,157 @@
+// Package main provides the application entrypoint as well as functions for
+// parsing CLI flags and environment variables.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dominikbraun/todo/client"
+	"github.com/dominikbraun/todo/model"
+)
+
+// csvCommand runs a subcommand for CSV files, which is either `todo csv export`
+// or `todo csv import`.
+func csvCommand(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errInvalidArguments
+	}
+
+	switch subcommand := args[0]; subcommand {
+	case "export":
+		return exportCSV(args[1:], stdout)
+	case "import":
+		return importCSV(args[1:], stdout)
+	default:
+		return fmt.Errorf("%w: csv %s", errUnknownSubcommand, subcommand)
+	}
+}
+
+// exportCSV writes the ToDo items as CSV file with one row per task to the
+// given file, or to stdout if no file is given, e.g. `todo csv export todos.csv`.
+func exportCSV(args []string, stdout io.Writer) error {
+	flags := clientFlags("csv export")
+	flags.String("filter", "", "Only export ToDo items matching a filter query like tag:release")
+
+	config, err := loadConfig(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() > 1 {
+		return errInvalidArguments
+	}
+
+	c, err := newClient(config)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), transferTimeout)
+	defer cancel()
+
+	if flags.NArg() == 0 {
+		return c.ExportCSV(ctx, stdout, config.GetString("filter"))
+	}
+
+	file, err := os.Create(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if err := c.ExportCSV(ctx, file, config.GetString("filter")); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	printer, err := newPrinter(stdout, config.GetString("output"))
+	if err != nil {
+		return err
+	}
+
+	return printer.printMessage(fmt.Sprintf("wrote ToDo items to %s", file.Name()))
+}
+
+// importCSV creates the ToDo items of a CSV file, e.g.
+// `todo csv import tasks.csv --columns name:Title,task:Subtask`. Invalid rows
+// are skipped and listed, unless --all-or-nothing is set.
+func importCSV(args []string, stdout io.Writer) error {
+	flags := clientFlags("csv import")
+	flags.String("columns", "", "Map fields to column names or positions like name:Title,due:4")
+	flags.String("header", "auto", "Whether the first row is a header: auto, present or absent")
+	flags.String("delimiter", "", "The delimiter, a single character or tab; detected if empty")
+	flags.Bool("all-or-nothing", false, "Import nothing if any row is invalid")
+	flags.Bool("dry-run", false, "Only validate the rows without creating ToDo items")
+
+	config, c, printer, err := parseCommand(flags, args, 1, stdout)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), transferTimeout)
+	defer cancel()
+
+	result, err := c.ImportCSV(ctx, file, client.CSVOptions{
+		Columns:      config.GetString("columns"),
+		Header:       config.GetString("header"),
+		Delimiter:    config.GetString("delimiter"),
+		AllOrNothing: config.GetBool("all-or-nothing"),
+		DryRun:       config.GetBool("dry-run"),
+	})
+
+	var apiError *client.Error
+
+	if errors.As(err, &apiError) && len(apiError.Rows) > 0 {
+		return fmt.Errorf("%w\n%s", err, formatRowErrors(apiError.Rows))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if printer.format == outputJSON {
+		return printer.printJSON(result)
+	}
+
+	message := fmt.Sprintf("imported %d ToDo items", len(result.ToDos))
+	if result.DryRun {
+		message = fmt.Sprintf("would import %d ToDo items", len(result.ToDos))
+	}
+
+	if len(result.Errors) > 0 {
+		message += fmt.Sprintf(", skipped invalid rows:\n%s", formatRowErrors(result.Errors))
+	}
+
+	return printer.printMessage(message)
+}
+
+// formatRowErrors formats row errors as indented lines like
+// `  row 4, name: name must not be empty`.
+func formatRowErrors(rowErrors []model.RowError) string {
+	lines := make([]string, len(rowErrors))
+
+	for i, rowError := range rowErrors {
+		location := fmt.Sprintf("row %d", rowError.Row)
+		if rowError.Column != "" {
+			location += ", " + rowError.Column
+		}
+		lines[i] = fmt.Sprintf("  %s: %s", location, rowError.Message)
+	}
+
+	return strings.Join(lines, "\n")
+}
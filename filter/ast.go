@@ -0,0 +1,84 @@
+// Package filter provides a small query language for filtering ToDo items. A
+// filter query like `tag:release AND due<2026-11-01 AND NOT done` is parsed to
+// an abstract syntax tree, which can be evaluated by storage implementations.
+package filter
+
+import "time"
+
+// Field is a ToDo property that can be used in a condition.
+type Field string
+
+const (
+	FieldID          Field = "id"
+	FieldName        Field = "name"
+	FieldDescription Field = "description"
+	FieldTag         Field = "tag"
+	FieldDue         Field = "due"
+	FieldDone        Field = "done"
+)
+
+// Operator is a comparison operator used in a condition.
+type Operator string
+
+const (
+	// OperatorMatch checks whether a text contains the value, a ToDo item has
+	// the given tag or a timestamp is on the given day, depending on the field.
+	OperatorMatch        Operator = ":"
+	OperatorEqual        Operator = "="
+	OperatorNotEqual     Operator = "!="
+	OperatorLess         Operator = "<"
+	OperatorLessEqual    Operator = "<="
+	OperatorGreater      Operator = ">"
+	OperatorGreaterEqual Operator = ">="
+	// OperatorExists checks whether a field is set. It is used for conditions
+	// that only consist of a field name, e.g. `due`.
+	OperatorExists Operator = ""
+)
+
+// Expression is a node of the abstract syntax tree. It is one of And, Or, Not
+// and Condition.
+type Expression interface {
+	expression()
+}
+
+// And is satisfied if both operands are satisfied.
+type And struct {
+	Left  Expression
+	Right Expression
+}
+
+// Or is satisfied if at least one of the operands is satisfied.
+type Or struct {
+	Left  Expression
+	Right Expression
+}
+
+// Not is satisfied if its operand is not satisfied.
+type Not struct {
+	Operand Expression
+}
+
+// Condition compares a field of a ToDo item with a value. The value type
+// depends on the field: FieldID has an int64 value, FieldDone has a bool value,
+// FieldDue has a time.Time value and all other fields have a string value.
+//
+// For FieldDue, Day indicates that the value is a date without time, so that
+// OperatorMatch and OperatorEqual match any time on that day.
+type Condition struct {
+	Field    Field
+	Operator Operator
+	Value    interface{}
+	Day      bool
+}
+
+func (And) expression()       {}
+func (Or) expression()        {}
+func (Not) expression()       {}
+func (Condition) expression() {}
+
+// DayRange returns the start of the day of a FieldDue condition value and the
+// start of the following day.
+func (c Condition) DayRange() (time.Time, time.Time) {
+	start := c.Value.(time.Time)
+	return start, start.AddDate(0, 0, 1)
+}
@@ -0,0 +1,148 @@
+// Package filter provides a small query language for filtering ToDo items. A
+// filter query like `tag:release AND due<2026-11-01 AND NOT done` is parsed to
+// an abstract syntax tree, which can be evaluated by storage implementations.
+package filter
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+// Match evaluates the expression for the given ToDo item in-process. Text and
+// tag comparisons are case-insensitive. Comparisons on the due date are never
+// satisfied if the ToDo item has no due date, except for negated ones.
+func Match(expression Expression, toDo model.ToDo) bool {
+	switch e := expression.(type) {
+	case And:
+		return Match(e.Left, toDo) && Match(e.Right, toDo)
+	case Or:
+		return Match(e.Left, toDo) || Match(e.Right, toDo)
+	case Not:
+		return !Match(e.Operand, toDo)
+	case Condition:
+		return matchCondition(e, toDo)
+	default:
+		return false
+	}
+}
+
+func matchCondition(condition Condition, toDo model.ToDo) bool {
+	switch condition.Field {
+	case FieldID:
+		return compareInt(toDo.ID, condition.Operator, condition.Value.(int64))
+
+	case FieldName:
+		return matchText(toDo.Name, condition.Operator, condition.Value.(string))
+
+	case FieldDescription:
+		return matchText(toDo.Description, condition.Operator, condition.Value.(string))
+
+	case FieldDone:
+		isEqual := toDo.Done == condition.Value.(bool)
+		return isEqual == (condition.Operator != OperatorNotEqual)
+
+	case FieldTag:
+		hasTag := false
+		for _, tag := range toDo.Tags {
+			if strings.EqualFold(tag, condition.Value.(string)) {
+				hasTag = true
+			}
+		}
+		return hasTag == (condition.Operator != OperatorNotEqual)
+
+	case FieldDue:
+		if toDo.Due == nil {
+			return false
+		}
+		return matchTime(*toDo.Due, condition)
+	}
+
+	return false
+}
+
+// matchText checks whether a text contains the value for OperatorMatch and
+// whether it equals the value for OperatorEqual and OperatorNotEqual.
+func matchText(text string, operator Operator, value string) bool {
+	text, value = strings.ToLower(text), strings.ToLower(value)
+
+	switch operator {
+	case OperatorMatch:
+		return strings.Contains(text, value)
+	case OperatorEqual:
+		return text == value
+	case OperatorNotEqual:
+		return text != value
+	}
+
+	return false
+}
+
+func compareInt(actual int64, operator Operator, value int64) bool {
+	switch operator {
+	case OperatorMatch, OperatorEqual:
+		return actual == value
+	case OperatorNotEqual:
+		return actual != value
+	case OperatorLess:
+		return actual < value
+	case OperatorLessEqual:
+		return actual <= value
+	case OperatorGreater:
+		return actual > value
+	case OperatorGreaterEqual:
+		return actual >= value
+	}
+
+	return false
+}
+
+// matchTime compares a due date with the condition value. If the condition
+// refers to an entire day, the due date is compared with that day.
+func matchTime(due time.Time, condition Condition) bool {
+	if condition.Operator == OperatorExists {
+		return true
+	}
+
+	if !condition.Day {
+		value := condition.Value.(time.Time)
+
+		switch condition.Operator {
+		case OperatorMatch, OperatorEqual:
+			return due.Equal(value)
+		case OperatorNotEqual:
+			return !due.Equal(value)
+		case OperatorLess:
+			return due.Before(value)
+		case OperatorLessEqual:
+			return !due.After(value)
+		case OperatorGreater:
+			return due.After(value)
+		case OperatorGreaterEqual:
+			return !due.Before(value)
+		}
+
+		return false
+	}
+
+	start, end := condition.DayRange()
+	isOnDay := !due.Before(start) && due.Before(end)
+
+	switch condition.Operator {
+	case OperatorMatch, OperatorEqual:
+		return isOnDay
+	case OperatorNotEqual:
+		return !isOnDay
+	case OperatorLess:
+		return due.Before(start)
+	case OperatorLessEqual:
+		return due.Before(end)
+	case OperatorGreater:
+		return !due.Before(end)
+	case OperatorGreaterEqual:
+		return !due.Before(start)
+	}
+
+	return false
+}
@@ -0,0 +1,59 @@
+// Package filter provides a small query language for filtering ToDo items. A
+// filter query like `tag:release AND due<2026-11-01 AND NOT done` is parsed to
+// an abstract syntax tree, which can be evaluated by storage implementations.
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+func TestMatch(t *testing.T) {
+	due := time.Date(2026, 10, 31, 15, 0, 0, 0, time.UTC)
+
+	toDo := model.ToDo{
+		ID:          7,
+		Name:        "Prepare Release",
+		Description: "Write the changelog",
+		Due:         &due,
+		Tags:        []string{"release", "v2"},
+	}
+
+	tests := map[string]bool{
+		"tag:release AND due<2026-11-01 AND NOT done": true,
+		"tag:RELEASE":                    true,
+		"tag!=release":                   false,
+		"name:release":                   true,
+		"name=release":                   false,
+		"description:changelog OR done":  true,
+		"due:2026-10-31":                 true,
+		"due>=2026-10-31":                true,
+		"due>2026-10-31":                 false,
+		"due<=2026-10-31":                true,
+		"due<2026-10-31T16:00:00Z":       true,
+		"done:false AND id>5 AND id<=7":  true,
+		"NOT (tag:release OR tag:v3)":    false,
+		"done OR (tag:v3 AND name:prep)": false,
+	}
+
+	for query, expected := range tests {
+		t.Run(query, func(t *testing.T) {
+			expression, err := Parse(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if Match(expression, toDo) != expected {
+				t.Errorf("expected %s to evaluate to %v", query, expected)
+			}
+		})
+	}
+
+	expression, _ := Parse("due<2026-11-01")
+
+	if Match(expression, model.ToDo{}) {
+		t.Errorf("expected ToDo without due date not to match")
+	}
+}
@@ -0,0 +1,378 @@
+// Package filter provides a small query language for filtering ToDo items. A
+// filter query like `tag:release AND due<2026-11-01 AND NOT done` is parsed to
+// an abstract syntax tree, which can be evaluated by storage implementations.
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SyntaxError indicates that a filter query is invalid. Position is the 1-based
+// character position of the offending token in the query.
+type SyntaxError struct {
+	Position int
+	Token    string
+	Message  string
+}
+
+// Error returns a description of the syntax error pointing at the token.
+func (s *SyntaxError) Error() string {
+	if s.Token == "" {
+		return fmt.Sprintf("invalid filter at position %d: %s", s.Position, s.Message)
+	}
+	return fmt.Sprintf("invalid filter at position %d near %q: %s", s.Position, s.Token, s.Message)
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+)
+
+type token struct {
+	kind     tokenKind
+	text     string
+	position int
+}
+
+// Parse parses a filter query and returns its abstract syntax tree. The query
+// consists of conditions combined with AND, OR, NOT and parentheses, where NOT
+// binds stronger than AND and AND binds stronger than OR.
+//
+// A condition has the form <field><operator><value>, e.g. `due<2026-11-01`.
+// The fields `done` and `due` may also be used without operator and value for
+// checking whether a ToDo item is done or has a due date. Values containing
+// spaces or special characters can be put in double quotes.
+//
+// Due dates can be specified as YYYY-MM-DD, as RFC 3339 timestamp or as one of
+// `today`, `tomorrow` and `yesterday`. Dates are interpreted in UTC. `now`
+// refers to the current point in time.
+//
+// If the query is invalid, a *SyntaxError will be returned.
+func Parse(query string) (Expression, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	expression, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if next := p.peek(); next.kind != tokenEOF {
+		return nil, p.errorAt(next, "expected AND, OR or end of filter")
+	}
+
+	return expression, nil
+}
+
+// tokenize splits the query into tokens. Words may contain any character that
+// is not whitespace, a parenthesis, a quote or part of an operator. Values
+// directly following an operator may contain operator characters as well.
+func tokenize(query string) ([]token, error) {
+	runes := []rune(query)
+	tokens := make([]token, 0)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		position := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, text: "(", position: position})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, text: ")", position: position})
+			i++
+
+		case r == '"':
+			var builder strings.Builder
+			i++
+
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				builder.WriteRune(runes[i])
+			}
+
+			if i == len(runes) {
+				return nil, &SyntaxError{Position: position, Token: string(runes[position-1:]), Message: "unterminated string"}
+			}
+
+			tokens = append(tokens, token{kind: tokenString, text: builder.String(), position: position})
+			i++
+
+		case isOperatorRune(r):
+			end := i + 1
+			if end < len(runes) && runes[end] == '=' && r != ':' && r != '=' {
+				end++
+			}
+
+			text := string(runes[i:end])
+			if text == "!" {
+				return nil, &SyntaxError{Position: position, Token: text, Message: "expected != operator"}
+			}
+
+			tokens = append(tokens, token{kind: tokenOperator, text: text, position: position})
+			i = end
+
+		default:
+			// A word following an operator is a value, which may contain
+			// operator characters like the colons of a RFC 3339 timestamp.
+			isValue := len(tokens) > 0 && tokens[len(tokens)-1].kind == tokenOperator
+
+			end := i
+			for end < len(runes) && !isWordBoundary(runes[end], isValue) {
+				end++
+			}
+
+			tokens = append(tokens, token{kind: tokenWord, text: string(runes[i:end]), position: position})
+			i = end
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, position: len(runes) + 1})
+
+	return tokens, nil
+}
+
+func isOperatorRune(r rune) bool {
+	return r == ':' || r == '=' || r == '!' || r == '<' || r == '>'
+}
+
+func isWordBoundary(r rune, isValue bool) bool {
+	if isOperatorRune(r) {
+		return !isValue
+	}
+	return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+}
+
+type parser struct {
+	tokens []token
+	index  int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.index]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.index]
+	if t.kind != tokenEOF {
+		p.index++
+	}
+	return t
+}
+
+// isKeyword reports whether the token is the given keyword, ignoring case.
+func isKeyword(t token, keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+func (p *parser) parseOr() (Expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for isKeyword(p.peek(), "OR") {
+		p.next()
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = Or{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expression, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for isKeyword(p.peek(), "AND") {
+		p.next()
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		left = And{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseNot() (Expression, error) {
+	if isKeyword(p.peek(), "NOT") {
+		p.next()
+
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return Not{Operand: operand}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expression, error) {
+	t := p.next()
+
+	switch {
+	case t.kind == tokenLeftParen:
+		expression, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if closing := p.next(); closing.kind != tokenRightParen {
+			return nil, p.errorAt(closing, "expected )")
+		}
+
+		return expression, nil
+
+	case t.kind == tokenWord && !isKeyword(t, "AND") && !isKeyword(t, "OR"):
+		return p.parseCondition(t)
+
+	default:
+		return nil, p.errorAt(t, "expected a condition")
+	}
+}
+
+func (p *parser) parseCondition(fieldToken token) (Expression, error) {
+	field := Field(strings.ToLower(fieldToken.text))
+
+	switch field {
+	case FieldID, FieldName, FieldDescription, FieldTag, FieldDue, FieldDone:
+	default:
+		return nil, p.errorAt(fieldToken, "unknown field, expected one of id, name, description, tag, due, done")
+	}
+
+	operatorToken := p.peek()
+
+	if operatorToken.kind != tokenOperator {
+		switch field {
+		case FieldDone:
+			return Condition{Field: FieldDone, Operator: OperatorEqual, Value: true}, nil
+		case FieldDue:
+			return Condition{Field: FieldDue, Operator: OperatorExists}, nil
+		default:
+			return nil, p.errorAt(operatorToken, fmt.Sprintf("expected an operator after %s", field))
+		}
+	}
+
+	p.next()
+	operator := Operator(operatorToken.text)
+
+	if !isOperatorAllowed(field, operator) {
+		return nil, p.errorAt(operatorToken, fmt.Sprintf("operator %s is not supported for %s", operator, field))
+	}
+
+	valueToken := p.next()
+	if valueToken.kind != tokenWord && valueToken.kind != tokenString {
+		return nil, p.errorAt(valueToken, "expected a value")
+	}
+
+	condition := Condition{Field: field, Operator: operator}
+
+	switch field {
+	case FieldID:
+		id, err := strconv.ParseInt(valueToken.text, 10, 64)
+		if err != nil {
+			return nil, p.errorAt(valueToken, "expected an integer ID")
+		}
+		condition.Value = id
+
+	case FieldDone:
+		done, err := strconv.ParseBool(valueToken.text)
+		if err != nil {
+			return nil, p.errorAt(valueToken, "expected true or false")
+		}
+		condition.Value = done
+
+	case FieldDue:
+		due, isDay, err := parseTime(valueToken.text)
+		if err != nil {
+			return nil, p.errorAt(valueToken, "expected a date like 2006-01-02, a RFC 3339 timestamp, today or now")
+		}
+		condition.Value = due
+		condition.Day = isDay
+
+	default:
+		condition.Value = valueToken.text
+	}
+
+	return condition, nil
+}
+
+// isOperatorAllowed reports whether the operator can be used with the field.
+func isOperatorAllowed(field Field, operator Operator) bool {
+	switch field {
+	case FieldID, FieldDue:
+		return true
+	default:
+		return operator == OperatorMatch || operator == OperatorEqual || operator == OperatorNotEqual
+	}
+}
+
+// parseTime parses a due date value. The returned bool indicates whether the
+// value is a date without time, i.e. refers to an entire day.
+func parseTime(value string) (time.Time, bool, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	switch strings.ToLower(value) {
+	case "now":
+		return time.Now().UTC().Truncate(time.Second), false, nil
+	case "today":
+		return today, true, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true, nil
+	}
+
+	if day, err := time.Parse("2006-01-02", value); err == nil {
+		return day, true, nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	return timestamp.UTC(), false, nil
+}
+
+func (p *parser) errorAt(t token, message string) error {
+	text := t.text
+	if t.kind == tokenString {
+		text = `"` + text + `"`
+	}
+	return &SyntaxError{Position: t.position, Token: text, Message: message}
+}
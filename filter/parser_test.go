@@ -0,0 +1,121 @@
+// Package filter provides a small query language for filtering ToDo items. A
+// filter query like `tag:release AND due<2026-11-01 AND NOT done` is parsed to
+// an abstract syntax tree, which can be evaluated by storage implementations.
+package filter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		query    string
+		expected Expression
+	}{
+		"conjunction with negation": {
+			query: "tag:release AND due<2026-11-01 AND NOT done",
+			expected: And{
+				Left: And{
+					Left: Condition{Field: FieldTag, Operator: OperatorMatch, Value: "release"},
+					Right: Condition{
+						Field:    FieldDue,
+						Operator: OperatorLess,
+						Value:    time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+						Day:      true,
+					},
+				},
+				Right: Not{Operand: Condition{Field: FieldDone, Operator: OperatorEqual, Value: true}},
+			},
+		},
+		"precedence and parentheses": {
+			query: `name:"weekly sync" or (id>=3 and description!=draft)`,
+			expected: Or{
+				Left: Condition{Field: FieldName, Operator: OperatorMatch, Value: "weekly sync"},
+				Right: And{
+					Left:  Condition{Field: FieldID, Operator: OperatorGreaterEqual, Value: int64(3)},
+					Right: Condition{Field: FieldDescription, Operator: OperatorNotEqual, Value: "draft"},
+				},
+			},
+		},
+		"due date flag": {
+			query:    "due",
+			expected: Condition{Field: FieldDue, Operator: OperatorExists},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			expression, err := Parse(test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(expression, test.expected) {
+				t.Errorf("unexpected expression: %s", cmp.Diff(test.expected, expression))
+			}
+		})
+	}
+}
+
+func TestParse_SyntaxError(t *testing.T) {
+	tests := map[string]struct {
+		query    string
+		position int
+		token    string
+	}{
+		"unknown field": {
+			query:    "tag:release AND priority>1",
+			position: 17,
+			token:    "priority",
+		},
+		"missing value": {
+			query:    "due< AND done",
+			position: 6,
+			token:    "AND",
+		},
+		"invalid date": {
+			query:    "due<2026-13-01",
+			position: 5,
+			token:    "2026-13-01",
+		},
+		"unsupported operator": {
+			query:    "tag<release",
+			position: 4,
+			token:    "<",
+		},
+		"missing closing parenthesis": {
+			query:    "(done OR due",
+			position: 13,
+			token:    "",
+		},
+		"missing conjunction": {
+			query:    "done due",
+			position: 6,
+			token:    "due",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(test.query)
+
+			var syntaxError *SyntaxError
+
+			if !errors.As(err, &syntaxError) {
+				t.Fatalf("expected a syntax error, got %v", err)
+			}
+
+			if syntaxError.Position != test.position {
+				t.Errorf("expected position %d, got %d", test.position, syntaxError.Position)
+			}
+
+			if syntaxError.Token != test.token {
+				t.Errorf("expected token %q, got %q", test.token, syntaxError.Token)
+			}
+		})
+	}
+}
@@ -4,14 +4,22 @@ go 1.15
 
 require (
 	github.com/Masterminds/squirrel v1.5.0
+	github.com/fxamacker/cbor/v2 v2.2.0
+	github.com/gdamore/tcell/v2 v2.4.0
 	github.com/go-chi/chi v1.5.4
-	github.com/go-chi/render v1.0.1
 	github.com/go-sql-driver/mysql v1.5.0
-	github.com/google/go-cmp v0.3.0
+	github.com/google/go-cmp v0.5.5
+	github.com/gorilla/websocket v1.4.2
+	github.com/graph-gophers/graphql-go v1.3.0
 	github.com/jmoiron/sqlx v1.3.1
+	github.com/mattn/go-runewidth v0.0.10
 	github.com/pelletier/go-toml v1.7.0 // indirect
 	github.com/spf13/pflag v1.0.3
 	github.com/spf13/viper v1.7.1
-	github.com/stretchr/testify v1.6.1 // indirect
-	golang.org/x/text v0.3.3 // indirect
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+	golang.org/x/text v0.3.3
+	google.golang.org/grpc v1.40.0
+	google.golang.org/grpc/cmd/protoc-gen-go-grpc v1.1.0
+	google.golang.org/protobuf v1.26.0
+	sigs.k8s.io/yaml v1.2.0
 )
@@ -0,0 +1,417 @@
+// Package ical converts ToDo items from and to iCalendar files as specified by
+// RFC 5545. Each ToDo item is written as a VTODO component, and each of its
+// tasks as a VTODO component of its own that refers to the component of its
+// ToDo item with a RELATED-TO property.
+//
+// The name, description, due date, completion state, priority, tags and
+// recurrence rule of a ToDo item are mapped to the SUMMARY, DESCRIPTION, DUE,
+// STATUS, PRIORITY, CATEGORIES and RRULE properties.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	// Due dates may refer to any IANA time zone, which has to be resolvable
+	// even if the system has no time zone database.
+	_ "time/tzdata"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+// singleProperties are the VTODO properties that must not occur more than once.
+var singleProperties = []string{
+	"CLASS", "COMPLETED", "CREATED", "DESCRIPTION", "DTSTAMP", "DTSTART", "DUE",
+	"DURATION", "GEO", "LAST-MODIFIED", "LOCATION", "ORGANIZER", "PERCENT-COMPLETE",
+	"PRIORITY", "RECURRENCE-ID", "RRULE", "SEQUENCE", "STATUS", "SUMMARY", "UID", "URL",
+}
+
+// statusValues are the valid values of the STATUS property of a VTODO.
+var statusValues = oneOf("NEEDS-ACTION", "COMPLETED", "IN-PROCESS", "CANCELLED")
+
+// contentLine is an unfolded content line along with the number of its first
+// physical line.
+type contentLine struct {
+	line int
+	text string
+}
+
+// component is a parsed VTODO component. The parent is the UID the component
+// is related to, which is empty for ToDo items.
+type component struct {
+	line   int
+	uid    string
+	parent string
+	toDo   model.ToDo
+}
+
+// Object is the component of a calendar object resource as used by CalDAV.
+// Parent is the UID of the component it is related to, if any.
+type Object struct {
+	UID    string
+	Parent string
+	ToDo   model.ToDo
+}
+
+// Decode reads all VTODO components of an iCalendar file, which may contain
+// multiple VCALENDAR objects. Components related to another component of the
+// file by a RELATED-TO property are read as tasks of the related ToDo item,
+// all other components as ToDo items. Overrides of recurring components, i.e.
+// components with a RECURRENCE-ID, and all other component types are ignored.
+//
+// The returned ToDo items don't have IDs or UIDs. If the file is malformed, a
+// *SyntaxError will be returned.
+func Decode(reader io.Reader) ([]model.ToDo, error) {
+	components, _, err := parse(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return assemble(components)
+}
+
+// DecodeObject reads a calendar object resource, which must contain a single
+// VTODO component apart from overrides of recurring instances and time zones.
+// Unlike Decode, the UID of the component is kept as UID of the ToDo item.
+func DecodeObject(reader io.Reader) (Object, error) {
+	components, others, err := parse(reader)
+	if err != nil {
+		return Object{}, err
+	}
+
+	if len(components) != 1 || others > 0 {
+		return Object{}, &SyntaxError{Line: 1, Message: "calendar object must contain exactly one VTODO"}
+	}
+
+	c := components[0]
+	c.toDo.UID = c.uid
+
+	return Object{UID: c.uid, Parent: c.parent, ToDo: c.toDo}, nil
+}
+
+// parse reads the VTODO components of all VCALENDAR objects and returns them
+// along with the number of other components except for VTIMEZONE.
+func parse(reader io.Reader) ([]component, int, error) {
+	lines, err := unfold(reader)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		stack      []string
+		calendar   []property
+		todo       []property
+		components []component
+		calendars  int
+		others     int
+	)
+
+	for _, line := range lines {
+		p, err := parseProperty(line.line, line.text)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		switch {
+		case p.name == "BEGIN":
+			name := strings.ToUpper(p.value)
+
+			switch {
+			case len(stack) == 0 && name != "VCALENDAR":
+				return nil, 0, &SyntaxError{Line: p.line, Message: "expected BEGIN:VCALENDAR"}
+			case name == "VCALENDAR" && len(stack) > 0:
+				return nil, 0, &SyntaxError{Line: p.line, Message: "VCALENDAR must not be nested"}
+			case name == "VTODO" && len(stack) != 1:
+				return nil, 0, &SyntaxError{Line: p.line, Message: "VTODO must be part of VCALENDAR"}
+			}
+
+			stack = append(stack, name)
+
+			if len(stack) == 2 && name != "VTODO" && name != "VTIMEZONE" {
+				others++
+			}
+
+			if name == "VTODO" {
+				todo = []property{p}
+			}
+
+		case p.name == "END":
+			name := strings.ToUpper(p.value)
+
+			if len(stack) == 0 || stack[len(stack)-1] != name {
+				return nil, 0, &SyntaxError{Line: p.line, Message: "unexpected END:" + p.value}
+			}
+
+			stack = stack[:len(stack)-1]
+
+			switch name {
+			case "VCALENDAR":
+				if err := checkCalendar(p.line, calendar); err != nil {
+					return nil, 0, err
+				}
+				calendar = nil
+				calendars++
+			case "VTODO":
+				c, skip, err := parseComponent(todo)
+				if err != nil {
+					return nil, 0, err
+				}
+				if !skip {
+					components = append(components, c)
+				}
+			}
+
+		case len(stack) == 0:
+			return nil, 0, &SyntaxError{Line: p.line, Message: "expected BEGIN:VCALENDAR"}
+
+		case len(stack) == 1:
+			calendar = append(calendar, p)
+
+		case len(stack) == 2 && stack[1] == "VTODO":
+			todo = append(todo, p)
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, 0, &SyntaxError{Line: lines[len(lines)-1].line, Message: "missing END:" + stack[len(stack)-1]}
+	}
+
+	if calendars == 0 {
+		return nil, 0, &SyntaxError{Line: 1, Message: "file contains no VCALENDAR"}
+	}
+
+	return components, others, nil
+}
+
+// unfold reads all content lines and joins folded lines. Lines may end with
+// CRLF as required by RFC 5545 or with LF only. Empty lines are ignored.
+func unfold(reader io.Reader) ([]contentLine, error) {
+	var lines []contentLine
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	for number := 1; scanner.Scan(); number++ {
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+
+		if text == "" {
+			continue
+		}
+
+		if text[0] == ' ' || text[0] == '\t' {
+			if len(lines) == 0 {
+				return nil, &SyntaxError{Line: number, Message: "continuation line without content line"}
+			}
+			lines[len(lines)-1].text += text[1:]
+			continue
+		}
+
+		lines = append(lines, contentLine{line: number, text: text})
+	}
+
+	return lines, scanner.Err()
+}
+
+// checkCalendar checks the properties of a VCALENDAR object, which has to have
+// a PRODID and the VERSION 2.0.
+func checkCalendar(line int, properties []property) error {
+	var version, productID string
+
+	for _, p := range properties {
+		switch p.name {
+		case "VERSION":
+			version = p.value
+		case "PRODID":
+			productID = p.value
+		case "CALSCALE":
+			if strings.ToUpper(p.value) != "GREGORIAN" {
+				return &SyntaxError{Line: p.line, Message: "unsupported calendar scale " + p.value}
+			}
+		}
+	}
+
+	if productID == "" {
+		return &SyntaxError{Line: line, Message: "VCALENDAR has no PRODID"}
+	}
+
+	if version != "2.0" {
+		return &SyntaxError{Line: line, Message: "VCALENDAR must have VERSION 2.0"}
+	}
+
+	return nil
+}
+
+// parseComponent converts the properties of a VTODO component, starting with
+// its BEGIN line, to a component. The returned bool indicates whether the
+// component overrides an instance of a recurring component and should be
+// skipped.
+func parseComponent(properties []property) (component, bool, error) {
+	c := component{line: properties[0].line}
+	counts := make(map[string]int)
+
+	for _, p := range properties[1:] {
+		counts[p.name]++
+	}
+
+	for _, name := range singleProperties {
+		if counts[name] > 1 {
+			return component{}, false, &SyntaxError{Line: c.line, Message: fmt.Sprintf("VTODO must not have more than one %s", name)}
+		}
+	}
+
+	for _, name := range []string{"UID", "DTSTAMP"} {
+		if counts[name] == 0 {
+			return component{}, false, &SyntaxError{Line: c.line, Message: "VTODO has no " + name}
+		}
+	}
+
+	if counts["DUE"] > 0 && counts["DURATION"] > 0 {
+		return component{}, false, &SyntaxError{Line: c.line, Message: "VTODO must not have both DUE and DURATION"}
+	}
+
+	if counts["RECURRENCE-ID"] > 0 {
+		return component{}, true, nil
+	}
+
+	for _, p := range properties[1:] {
+		switch p.name {
+		case "UID":
+			c.uid = p.value
+
+		case "DTSTAMP":
+			if _, err := time.Parse(dateTimeFormat, p.value); err != nil {
+				return component{}, false, &SyntaxError{Line: p.line, Message: "DTSTAMP must be a UTC date-time"}
+			}
+
+		case "SUMMARY":
+			c.toDo.Name = unescapeText(p.value)
+
+		case "DESCRIPTION":
+			c.toDo.Description = unescapeText(p.value)
+
+		case "DUE":
+			due, err := parseDue(p)
+			if err != nil {
+				return component{}, false, err
+			}
+			c.toDo.Due = &due
+
+		case "STATUS":
+			value := strings.ToUpper(p.value)
+			if !statusValues(value) {
+				return component{}, false, &SyntaxError{Line: p.line, Message: "invalid STATUS " + p.value}
+			}
+			c.toDo.Done = c.toDo.Done || value == "COMPLETED"
+
+		case "COMPLETED":
+			c.toDo.Done = true
+
+		case "PRIORITY":
+			level, err := strconv.Atoi(p.value)
+			if err != nil || level < 0 || level > 9 {
+				return component{}, false, &SyntaxError{Line: p.line, Message: "PRIORITY must be an integer from 0 to 9"}
+			}
+			c.toDo.Priority = levelToPriority(level)
+
+		case "CATEGORIES":
+			c.toDo.Tags = append(c.toDo.Tags, splitText(p.value)...)
+
+		case "RRULE":
+			if err := ValidateRecurrence(p.value); err != nil {
+				return component{}, false, &SyntaxError{Line: p.line, Message: "invalid RRULE: " + err.Error()}
+			}
+			c.toDo.Recurrence = p.value
+
+		case "RELATED-TO":
+			if relType := strings.ToUpper(p.param("RELTYPE")); (relType == "" || relType == "PARENT") && c.parent == "" {
+				c.parent = p.value
+			}
+		}
+	}
+
+	return c, false, nil
+}
+
+// parseDue parses the value of a DUE property, which is either a DATE or a
+// DATE-TIME in UTC, in the time zone given by the TZID parameter or in local
+// time. Local time is interpreted as UTC.
+func parseDue(p property) (time.Time, error) {
+	var (
+		due time.Time
+		err error
+	)
+
+	switch {
+	case strings.ToUpper(p.param("VALUE")) == "DATE":
+		due, err = time.Parse(dateFormat, p.value)
+	case strings.HasSuffix(p.value, "Z"):
+		due, err = time.Parse(dateTimeFormat, p.value)
+	case p.param("TZID") != "":
+		location, locationErr := time.LoadLocation(strings.TrimPrefix(p.param("TZID"), "/"))
+		if locationErr != nil {
+			return time.Time{}, &SyntaxError{Line: p.line, Message: "unknown time zone " + p.param("TZID")}
+		}
+		due, err = time.ParseInLocation("20060102T150405", p.value, location)
+	default:
+		due, err = time.Parse("20060102T150405", p.value)
+	}
+
+	if err != nil {
+		return time.Time{}, &SyntaxError{Line: p.line, Message: "invalid DUE " + p.value}
+	}
+
+	return due.UTC(), nil
+}
+
+// assemble converts the components to ToDo items. Components related to a ToDo
+// item of the file become its tasks, while components related to a task or an
+// unknown component become ToDo items of their own.
+func assemble(components []component) ([]model.ToDo, error) {
+	byUID := make(map[string]component)
+
+	for _, c := range components {
+		if _, exists := byUID[c.uid]; exists {
+			return nil, &SyntaxError{Line: c.line, Message: "duplicate UID " + c.uid}
+		}
+		byUID[c.uid] = c
+	}
+
+	isToDo := func(c component) bool {
+		_, exists := byUID[c.parent]
+		return c.parent == "" || !exists
+	}
+
+	toDos := make([]model.ToDo, 0)
+	indexes := make(map[string]int)
+
+	for _, c := range components {
+		if isToDo(c) {
+			indexes[c.uid] = len(toDos)
+			toDos = append(toDos, c.toDo)
+		}
+	}
+
+	for _, c := range components {
+		if isToDo(c) {
+			continue
+		}
+
+		i, exists := indexes[c.parent]
+		if !exists {
+			toDos = append(toDos, c.toDo)
+			continue
+		}
+
+		toDos[i].Tasks = append(toDos[i].Tasks, model.Task{
+			Name:        c.toDo.Name,
+			Description: c.toDo.Description,
+			Done:        c.toDo.Done,
+		})
+	}
+
+	return toDos, nil
+}
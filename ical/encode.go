@@ -0,0 +1,203 @@
+// Package ical converts ToDo items from and to iCalendar files as specified by
+// RFC 5545. Each ToDo item is written as a VTODO component, and each of its
+// tasks as a VTODO component of its own that refers to the component of its
+// ToDo item with a RELATED-TO property.
+//
+// The name, description, due date, completion state, priority, tags and
+// recurrence rule of a ToDo item are mapped to the SUMMARY, DESCRIPTION, DUE,
+// STATUS, PRIORITY, CATEGORIES and RRULE properties.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+// productID identifies the application as the creator of iCalendar files.
+const productID = "-//dominikbraun//todo//EN"
+
+const (
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405Z"
+)
+
+// Encode writes the given ToDo items as an iCalendar file. The stamp is used as
+// DTSTAMP of all components and should be the time the file is created.
+//
+// ToDo items whose due date is at midnight UTC are written with a DATE value,
+// i.e. as due on that day, and all other ToDo items with a DATE-TIME value.
+func Encode(writer io.Writer, toDos []model.ToDo, stamp time.Time) error {
+	e := newEncoder(writer, stamp)
+
+	for _, toDo := range toDos {
+		e.writeToDo(toDo)
+
+		for _, task := range toDo.Tasks {
+			e.writeTask(toDo, task)
+		}
+	}
+
+	return e.close()
+}
+
+// EncodeObject writes a calendar object resource as used by CalDAV, which is an
+// iCalendar file containing a single component. The component is the given
+// task of the ToDo item, or the ToDo item itself if task is nil.
+func EncodeObject(writer io.Writer, toDo model.ToDo, task *model.Task, stamp time.Time) error {
+	e := newEncoder(writer, stamp)
+
+	if task != nil {
+		e.writeTask(toDo, *task)
+	} else {
+		e.writeToDo(toDo)
+	}
+
+	return e.close()
+}
+
+// ToDoUID returns the UID of a ToDo item's component, which is the item's UID
+// or an identifier derived from its ID if it has no UID.
+func ToDoUID(toDo model.ToDo) string {
+	if toDo.UID != "" {
+		return toDo.UID
+	}
+
+	return fmt.Sprintf("%d@todo", toDo.ID)
+}
+
+// TaskUID returns the UID of a task's component like ToDoUID.
+func TaskUID(toDo model.ToDo, task model.Task) string {
+	if task.UID != "" {
+		return task.UID
+	}
+
+	return fmt.Sprintf("%d-%d@todo", toDo.ID, task.ID)
+}
+
+// encoder writes the components of a VCALENDAR object.
+type encoder struct {
+	writer  *bufio.Writer
+	dtStamp string
+}
+
+// newEncoder starts a VCALENDAR object.
+func newEncoder(writer io.Writer, stamp time.Time) *encoder {
+	e := &encoder{
+		writer:  bufio.NewWriter(writer),
+		dtStamp: stamp.UTC().Format(dateTimeFormat),
+	}
+
+	e.write("BEGIN", "VCALENDAR")
+	e.write("VERSION", "2.0")
+	e.write("PRODID", productID)
+
+	return e
+}
+
+// write writes a folded content line.
+func (e *encoder) write(name, value string) {
+	_, _ = e.writer.WriteString(fold(name + ":" + value))
+}
+
+// writeToDo writes the VTODO component of a ToDo item without its tasks.
+func (e *encoder) writeToDo(toDo model.ToDo) {
+	e.write("BEGIN", "VTODO")
+	e.write("UID", ToDoUID(toDo))
+	e.write("DTSTAMP", e.dtStamp)
+	e.write("SUMMARY", escapeText(toDo.Name))
+
+	if toDo.Description != "" {
+		e.write("DESCRIPTION", escapeText(toDo.Description))
+	}
+
+	if toDo.Due != nil {
+		due := toDo.Due.UTC()
+		if due.Equal(due.Truncate(24 * time.Hour)) {
+			e.write("DUE;VALUE=DATE", due.Format(dateFormat))
+		} else {
+			e.write("DUE", due.Format(dateTimeFormat))
+		}
+	}
+
+	e.write("STATUS", status(toDo.Done))
+
+	if toDo.Priority != "" {
+		e.write("PRIORITY", fmt.Sprint(priorityToLevel(toDo.Priority)))
+	}
+
+	if len(toDo.Tags) > 0 {
+		categories := make([]string, len(toDo.Tags))
+		for i, tag := range toDo.Tags {
+			categories[i] = escapeText(tag)
+		}
+		e.write("CATEGORIES", strings.Join(categories, ","))
+	}
+
+	if toDo.Recurrence != "" {
+		e.write("RRULE", toDo.Recurrence)
+	}
+
+	if toDo.Version > 0 {
+		e.write("SEQUENCE", fmt.Sprint(toDo.Version-1))
+	}
+
+	e.write("END", "VTODO")
+}
+
+// writeTask writes the VTODO component of a task, which refers to the component
+// of its ToDo item.
+func (e *encoder) writeTask(toDo model.ToDo, task model.Task) {
+	e.write("BEGIN", "VTODO")
+	e.write("UID", TaskUID(toDo, task))
+	e.write("DTSTAMP", e.dtStamp)
+	e.write("SUMMARY", escapeText(task.Name))
+
+	if task.Description != "" {
+		e.write("DESCRIPTION", escapeText(task.Description))
+	}
+
+	e.write("STATUS", status(task.Done))
+	e.write("RELATED-TO;RELTYPE=PARENT", ToDoUID(toDo))
+	e.write("END", "VTODO")
+}
+
+// close ends the VCALENDAR object and flushes the output.
+func (e *encoder) close() error {
+	e.write("END", "VCALENDAR")
+	return e.writer.Flush()
+}
+
+// status returns the STATUS value for the given completion state.
+func status(done bool) string {
+	if done {
+		return "COMPLETED"
+	}
+
+	return "NEEDS-ACTION"
+}
+
+// priorityToLevel maps a priority from A to Z to a PRIORITY value from 1 to 9.
+// Since there are less levels than letters, all letters after I are mapped to
+// the lowest priority.
+func priorityToLevel(priority string) int {
+	if level := int(priority[0]-'A') + 1; level < 9 {
+		return level
+	}
+
+	return 9
+}
+
+// levelToPriority maps a PRIORITY value from 1 to 9 to a priority from A to I.
+// The value 0 means undefined and is mapped to no priority.
+func levelToPriority(level int) string {
+	if level == 0 {
+		return ""
+	}
+
+	return string(rune('A' + level - 1))
+}
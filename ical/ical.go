@@ -0,0 +1,207 @@
+// Package ical converts ToDo items from and to iCalendar files as specified by
+// RFC 5545. Each ToDo item is written as a VTODO component, and each of its
+// tasks as a VTODO component of its own that refers to the component of its
+// ToDo item with a RELATED-TO property.
+//
+// The name, description, due date, completion state, priority, tags and
+// recurrence rule of a ToDo item are mapped to the SUMMARY, DESCRIPTION, DUE,
+// STATUS, PRIORITY, CATEGORIES and RRULE properties.
+package ical
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxLineLength is the maximum length of a content line in octets, excluding
+// the line break. Longer lines are folded.
+const maxLineLength = 75
+
+// SyntaxError indicates that an iCalendar file is malformed or violates the
+// constraints of RFC 5545.
+type SyntaxError struct {
+	Line    int
+	Message string
+}
+
+// Error returns the error message along with the line number.
+func (s *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", s.Line, s.Message)
+}
+
+// property is a parsed content line, e.g. `DUE;VALUE=DATE:20261101`. Property
+// and parameter names are upper-cased, the value is kept as it is.
+type property struct {
+	line   int
+	name   string
+	params map[string][]string
+	value  string
+}
+
+// param returns the first value of the given parameter or an empty string.
+func (p property) param(name string) string {
+	if values := p.params[name]; len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// fold splits a content line into lines of at most maxLineLength octets. The
+// continuation lines start with a space. Multi-byte characters are not split.
+func fold(line string) string {
+	var builder strings.Builder
+
+	limit := maxLineLength
+
+	for len(line) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(line[i]) {
+			i--
+		}
+
+		builder.WriteString(line[:i])
+		builder.WriteString("\r\n ")
+		line = line[i:]
+
+		// The leading space counts towards the length of the next line.
+		limit = maxLineLength - 1
+	}
+
+	builder.WriteString(line)
+	builder.WriteString("\r\n")
+
+	return builder.String()
+}
+
+// escapeText escapes a TEXT value, i.e. backslashes, semicolons, commas and line
+// breaks.
+func escapeText(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(text)
+}
+
+// unescapeText reverses escapeText.
+func unescapeText(text string) string {
+	return strings.NewReplacer(
+		`\\`, `\`,
+		`\;`, ";",
+		`\,`, ",",
+		`\n`, "\n",
+		`\N`, "\n",
+	).Replace(text)
+}
+
+// splitText splits a list of TEXT values at all commas that aren't escaped and
+// unescapes the values.
+func splitText(text string) []string {
+	var (
+		values []string
+		start  int
+	)
+
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case ',':
+			values = append(values, unescapeText(text[start:i]))
+			start = i + 1
+		}
+	}
+
+	return append(values, unescapeText(text[start:]))
+}
+
+// parseProperty parses a single unfolded content line.
+func parseProperty(line int, text string) (property, error) {
+	p := property{
+		line:   line,
+		params: make(map[string][]string),
+	}
+
+	i := strings.IndexAny(text, ";:")
+	if i < 0 {
+		return property{}, &SyntaxError{Line: line, Message: "content line has no value"}
+	}
+
+	p.name = strings.ToUpper(text[:i])
+	if !isName(p.name) {
+		return property{}, &SyntaxError{Line: line, Message: fmt.Sprintf("invalid property name %q", text[:i])}
+	}
+
+	for text[i] == ';' {
+		text = text[i+1:]
+
+		j := strings.IndexByte(text, '=')
+		if j < 0 || !isName(text[:j]) {
+			return property{}, &SyntaxError{Line: line, Message: "invalid parameter in property " + p.name}
+		}
+
+		name := strings.ToUpper(text[:j])
+		text = text[j+1:]
+		i = 0
+
+		// Parameter values are separated by commas and may be quoted, in which
+		// case they may contain the delimiters ; : and ,.
+		for {
+			var value string
+
+			if strings.HasPrefix(text[i:], `"`) {
+				end := strings.IndexByte(text[i+1:], '"')
+				if end < 0 {
+					return property{}, &SyntaxError{Line: line, Message: "unterminated quoted parameter value"}
+				}
+				value = text[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				end := strings.IndexAny(text[i:], ";:,")
+				if end < 0 {
+					return property{}, &SyntaxError{Line: line, Message: "content line has no value"}
+				}
+				value = text[i : i+end]
+				i += end
+			}
+
+			p.params[name] = append(p.params[name], value)
+
+			if i >= len(text) {
+				return property{}, &SyntaxError{Line: line, Message: "content line has no value"}
+			}
+			if text[i] != ',' {
+				break
+			}
+			i++
+		}
+
+		if text[i] != ';' && text[i] != ':' {
+			return property{}, &SyntaxError{Line: line, Message: "invalid parameter in property " + p.name}
+		}
+	}
+
+	p.value = text[i+1:]
+
+	return p, nil
+}
+
+// isName reports whether the given string is a valid property or parameter
+// name, consisting of letters, digits and dashes.
+func isName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, r := range name {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+
+	return true
+}
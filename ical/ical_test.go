@@ -0,0 +1,353 @@
+// Package ical converts ToDo items from and to iCalendar files as specified by
+// RFC 5545. Each ToDo item is written as a VTODO component, and each of its
+// tasks as a VTODO component of its own that refers to the component of its
+// ToDo item with a RELATED-TO property.
+//
+// The name, description, due date, completion state, priority, tags and
+// recurrence rule of a ToDo item are mapped to the SUMMARY, DESCRIPTION, DUE,
+// STATUS, PRIORITY, CATEGORIES and RRULE properties.
+package ical
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/google/go-cmp/cmp"
+)
+
+// calendar wraps the given lines into a VCALENDAR object with CRLF line breaks.
+func calendar(lines ...string) string {
+	lines = append([]string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//test//EN"}, lines...)
+	return strings.Join(append(lines, "END:VCALENDAR", ""), "\r\n")
+}
+
+func TestDecode(t *testing.T) {
+	dueDate := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	dueTime := time.Date(2026, 11, 1, 11, 30, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		input    string
+		expected []model.ToDo
+	}{
+		"all properties": {
+			input: calendar(
+				"BEGIN:VTODO",
+				"UID:release@example.com",
+				"DTSTAMP:20261018T120000Z",
+				`SUMMARY:Release 1.0\, finally`,
+				`DESCRIPTION:Line 1\nLine 2`,
+				"DUE;VALUE=DATE:20261101",
+				"STATUS:NEEDS-ACTION",
+				"PRIORITY:2",
+				"CATEGORIES:release,web\\,site",
+				"CATEGORIES:team",
+				"RRULE:FREQ=WEEKLY;BYDAY=MO",
+				"BEGIN:VALARM",
+				"ACTION:DISPLAY",
+				"TRIGGER:-PT15M",
+				"END:VALARM",
+				"END:VTODO",
+			),
+			expected: []model.ToDo{{
+				Name:        "Release 1.0, finally",
+				Description: "Line 1\nLine 2",
+				Due:         &dueDate,
+				Priority:    "B",
+				Tags:        []string{"release", "web,site", "team"},
+				Recurrence:  "FREQ=WEEKLY;BYDAY=MO",
+			}},
+		},
+		"folded lines, time zones and LF line breaks": {
+			input: "BEGIN:VCALENDAR\nPRODID:-//test//EN\nVERSION:2.0\n" +
+				"BEGIN:VTODO\nUID:1\nDTSTAMP:20261018T120000Z\nSUMMARY:Call\n  mom\n" +
+				"DUE;TZID=Europe/Berlin:20261101T123000\nEND:VTODO\n" +
+				"BEGIN:VTODO\nUID:2\nDTSTAMP:20261018T120000Z\nSUMMARY:Water plants\n" +
+				"DUE:20261101T113000\nCOMPLETED:20261018T120000Z\nEND:VTODO\nEND:VCALENDAR\n",
+			expected: []model.ToDo{
+				{Name: "Call mom", Due: &dueTime},
+				{Name: "Water plants", Due: &dueTime, Done: true},
+			},
+		},
+		"related components": {
+			input: calendar(
+				"BEGIN:VTODO", "UID:task-1", "DTSTAMP:20261018T120000Z", "SUMMARY:Write notes",
+				"RELATED-TO:parent", "END:VTODO",
+				"BEGIN:VTODO", "UID:parent", "DTSTAMP:20261018T120000Z", "SUMMARY:Release 1.0", "END:VTODO",
+				"BEGIN:VTODO", "UID:task-2", "DTSTAMP:20261018T120000Z", "SUMMARY:Tag commit",
+				`RELATED-TO;RELTYPE="PARENT":parent`, "STATUS:COMPLETED", "END:VTODO",
+				"BEGIN:VTODO", "UID:orphan", "DTSTAMP:20261018T120000Z", "SUMMARY:Orphan",
+				"RELATED-TO:unknown", "END:VTODO",
+				"BEGIN:VTODO", "UID:sibling", "DTSTAMP:20261018T120000Z", "SUMMARY:Sibling",
+				"RELATED-TO;RELTYPE=SIBLING:parent", "END:VTODO",
+			),
+			expected: []model.ToDo{
+				{
+					Name:  "Release 1.0",
+					Tasks: []model.Task{{Name: "Write notes"}, {Name: "Tag commit", Done: true}},
+				},
+				{Name: "Orphan"},
+				{Name: "Sibling"},
+			},
+		},
+		"other components and recurrence overrides": {
+			input: calendar(
+				"BEGIN:VEVENT", "UID:event", "DTSTAMP:20261018T120000Z", "SUMMARY:Meeting", "END:VEVENT",
+				"BEGIN:VTODO", "UID:1", "DTSTAMP:20261018T120000Z", "SUMMARY:Weekly review",
+				"RRULE:FREQ=WEEKLY", "END:VTODO",
+				"BEGIN:VTODO", "UID:1", "DTSTAMP:20261018T120000Z", "SUMMARY:Moved review",
+				"RECURRENCE-ID:20261026T090000Z", "END:VTODO",
+			),
+			expected: []model.ToDo{{Name: "Weekly review", Recurrence: "FREQ=WEEKLY"}},
+		},
+	}
+
+	for name, test := range tests {
+		toDos, err := Decode(strings.NewReader(test.input))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", name, err.Error())
+			continue
+		}
+
+		if diff := cmp.Diff(test.expected, toDos); diff != "" {
+			t.Errorf("%s: unexpected ToDo items (-expected +got):\n%s", name, diff)
+		}
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		line  int
+	}{
+		"empty file":             {"", 1},
+		"no calendar":            {"BEGIN:VTODO\r\nEND:VTODO\r\n", 1},
+		"no version":             {"BEGIN:VCALENDAR\r\nPRODID:-//test//EN\r\nEND:VCALENDAR\r\n", 3},
+		"no product ID":          {"BEGIN:VCALENDAR\r\nVERSION:2.0\r\nEND:VCALENDAR\r\n", 3},
+		"missing end":            {"BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:x\r\n", 3},
+		"mismatched end":         {calendar("BEGIN:VTODO", "END:VEVENT"), 5},
+		"no value":               {calendar("BEGIN:VTODO", "UID"), 5},
+		"invalid name":           {calendar("SUMMARY@X:y"), 4},
+		"unterminated quote":     {calendar(`X-TEST;X-PARAM="abc:d`), 4},
+		"no UID":                 {calendar("BEGIN:VTODO", "DTSTAMP:20261018T120000Z", "END:VTODO"), 4},
+		"no DTSTAMP":             {calendar("BEGIN:VTODO", "UID:1", "END:VTODO"), 4},
+		"local DTSTAMP":          {calendar("BEGIN:VTODO", "UID:1", "DTSTAMP:20261018T120000", "END:VTODO"), 6},
+		"duplicate summary":      {calendar("BEGIN:VTODO", "UID:1", "DTSTAMP:20261018T120000Z", "SUMMARY:a", "SUMMARY:b", "END:VTODO"), 4},
+		"due and duration":       {calendar("BEGIN:VTODO", "UID:1", "DTSTAMP:20261018T120000Z", "DUE:20261101T120000Z", "DURATION:PT1H", "END:VTODO"), 4},
+		"invalid due":            {calendar("BEGIN:VTODO", "UID:1", "DTSTAMP:20261018T120000Z", "DUE:tomorrow", "END:VTODO"), 7},
+		"unknown time zone":      {calendar("BEGIN:VTODO", "UID:1", "DTSTAMP:20261018T120000Z", "DUE;TZID=Mars/Olympus:20261101T120000", "END:VTODO"), 7},
+		"invalid status":         {calendar("BEGIN:VTODO", "UID:1", "DTSTAMP:20261018T120000Z", "STATUS:DONE", "END:VTODO"), 7},
+		"invalid priority":       {calendar("BEGIN:VTODO", "UID:1", "DTSTAMP:20261018T120000Z", "PRIORITY:10", "END:VTODO"), 7},
+		"invalid recurrence":     {calendar("BEGIN:VTODO", "UID:1", "DTSTAMP:20261018T120000Z", "RRULE:BYDAY=MO", "END:VTODO"), 7},
+		"duplicate UID":          {calendar("BEGIN:VTODO", "UID:1", "DTSTAMP:20261018T120000Z", "END:VTODO", "BEGIN:VTODO", "UID:1", "DTSTAMP:20261018T120000Z", "END:VTODO"), 8},
+		"nested VTODO":           {calendar("BEGIN:VTODO", "BEGIN:VTODO"), 5},
+		"unknown calendar scale": {calendar("CALSCALE:HEBREW"), 4},
+	}
+
+	for name, test := range tests {
+		_, err := Decode(strings.NewReader(test.input))
+
+		var syntaxError *SyntaxError
+
+		if !errors.As(err, &syntaxError) || syntaxError.Line != test.line {
+			t.Errorf("%s: expected syntax error at line %d, got %v", name, test.line, err)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	dueDate := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	dueTime := time.Date(2026, 11, 1, 12, 30, 0, 0, time.FixedZone("CET", 3600))
+
+	toDos := []model.ToDo{
+		{
+			ID:          4,
+			Name:        "Release 1.0; finally",
+			Description: "Line 1\nLine 2",
+			Priority:    "K",
+			Tags:        []string{"release", "web,site"},
+			Due:         &dueTime,
+			Recurrence:  "FREQ=MONTHLY;BYMONTHDAY=1",
+			Tasks:       []model.Task{{ID: 1, Name: "Write notes", Done: true}},
+			Version:     3,
+		},
+		{ID: 7, Name: strings.Repeat("Long name ", 8), Due: &dueDate},
+	}
+
+	expected := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//dominikbraun//todo//EN\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:4@todo\r\n" +
+		"DTSTAMP:20261018T120000Z\r\n" +
+		"SUMMARY:Release 1.0\\; finally\r\n" +
+		"DESCRIPTION:Line 1\\nLine 2\r\n" +
+		"DUE:20261101T113000Z\r\n" +
+		"STATUS:NEEDS-ACTION\r\n" +
+		"PRIORITY:9\r\n" +
+		"CATEGORIES:release,web\\,site\r\n" +
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=1\r\n" +
+		"SEQUENCE:2\r\n" +
+		"END:VTODO\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:4-1@todo\r\n" +
+		"DTSTAMP:20261018T120000Z\r\n" +
+		"SUMMARY:Write notes\r\n" +
+		"STATUS:COMPLETED\r\n" +
+		"RELATED-TO;RELTYPE=PARENT:4@todo\r\n" +
+		"END:VTODO\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:7@todo\r\n" +
+		"DTSTAMP:20261018T120000Z\r\n" +
+		"SUMMARY:Long name Long name Long name Long name Long name Long name Long na\r\n" +
+		" me Long name \r\n" +
+		"DUE;VALUE=DATE:20261101\r\n" +
+		"STATUS:NEEDS-ACTION\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	var buffer bytes.Buffer
+
+	if err := Encode(&buffer, toDos, time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expected, buffer.String()); diff != "" {
+		t.Errorf("unexpected output (-expected +got):\n%s", diff)
+	}
+}
+
+func TestFold(t *testing.T) {
+	// Multi-byte characters must not be split across lines.
+	line := "SUMMARY:" + strings.Repeat("ä", 40)
+	folded := fold(line)
+
+	for _, physical := range strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n") {
+		if len(physical) > maxLineLength {
+			t.Errorf("expected line of at most %d octets, got %d", maxLineLength, len(physical))
+		}
+	}
+
+	lines, err := unfold(strings.NewReader(folded))
+	if err != nil || len(lines) != 1 || lines[0].text != line {
+		t.Errorf("expected unfolded line %q, got %v (%v)", line, lines, err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	due := time.Date(2026, 11, 1, 12, 30, 0, 0, time.UTC)
+
+	toDos := []model.ToDo{
+		{
+			ID:          1,
+			Name:        "Release 1.0",
+			Description: "Semicolons; commas, and\nline breaks",
+			Due:         &due,
+			Priority:    "C",
+			Tags:        []string{"release", "team"},
+			Recurrence:  "FREQ=YEARLY;COUNT=3",
+			Tasks:       []model.Task{{ID: 1, Name: "Write notes", Done: true}, {ID: 2, Name: "Tag commit"}},
+		},
+		{ID: 2, Name: "Call mom", Done: true},
+	}
+
+	var buffer bytes.Buffer
+
+	if err := Encode(&buffer, toDos, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := Decode(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// IDs and versions are not read from iCalendar files.
+	for i := range toDos {
+		toDos[i].ID = 0
+		for j := range toDos[i].Tasks {
+			toDos[i].Tasks[j].ID = 0
+		}
+	}
+
+	if diff := cmp.Diff(toDos, decoded); diff != "" {
+		t.Errorf("unexpected ToDo items (-expected +got):\n%s", diff)
+	}
+}
+
+func TestEncodeObject(t *testing.T) {
+	toDo := model.ToDo{
+		ID:    4,
+		UID:   "release@example.com",
+		Name:  "Release 1.0",
+		Tasks: []model.Task{{ID: 1, Name: "Write notes"}},
+	}
+
+	var buffer bytes.Buffer
+
+	if err := EncodeObject(&buffer, toDo, &toDo.Tasks[0], time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//dominikbraun//todo//EN\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:4-1@todo\r\n" +
+		"DTSTAMP:20261018T120000Z\r\n" +
+		"SUMMARY:Write notes\r\n" +
+		"STATUS:NEEDS-ACTION\r\n" +
+		"RELATED-TO;RELTYPE=PARENT:release@example.com\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	if diff := cmp.Diff(expected, buffer.String()); diff != "" {
+		t.Errorf("unexpected output (-expected +got):\n%s", diff)
+	}
+}
+
+func TestDecodeObject(t *testing.T) {
+	input := calendar(
+		"BEGIN:VTIMEZONE", "TZID:Europe/Berlin", "END:VTIMEZONE",
+		"BEGIN:VTODO", "UID:task@example.com", "DTSTAMP:20261018T120000Z", "SUMMARY:Write notes",
+		"RELATED-TO:release@example.com", "END:VTODO",
+	)
+
+	object, err := DecodeObject(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Object{
+		UID:    "task@example.com",
+		Parent: "release@example.com",
+		ToDo:   model.ToDo{UID: "task@example.com", Name: "Write notes"},
+	}
+
+	if diff := cmp.Diff(expected, object); diff != "" {
+		t.Errorf("unexpected object (-expected +got):\n%s", diff)
+	}
+
+	invalid := map[string]string{
+		"no VTODO": calendar(),
+		"two VTODO components": calendar(
+			"BEGIN:VTODO", "UID:1", "DTSTAMP:20261018T120000Z", "END:VTODO",
+			"BEGIN:VTODO", "UID:2", "DTSTAMP:20261018T120000Z", "END:VTODO",
+		),
+		"VEVENT": calendar(
+			"BEGIN:VTODO", "UID:1", "DTSTAMP:20261018T120000Z", "END:VTODO",
+			"BEGIN:VEVENT", "UID:1", "DTSTAMP:20261018T120000Z", "END:VEVENT",
+		),
+	}
+
+	for name, input := range invalid {
+		var syntaxError *SyntaxError
+
+		if _, err := DecodeObject(strings.NewReader(input)); !errors.As(err, &syntaxError) {
+			t.Errorf("%s: expected syntax error, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Package ical converts ToDo items from and to iCalendar files as specified by
+// RFC 5545. Each ToDo item is written as a VTODO component, and each of its
+// tasks as a VTODO component of its own that refers to the component of its
+// ToDo item with a RELATED-TO property.
+//
+// The name, description, due date, completion state, priority, tags and
+// recurrence rule of a ToDo item are mapped to the SUMMARY, DESCRIPTION, DUE,
+// STATUS, PRIORITY, CATEGORIES and RRULE properties.
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// weekdays are the valid weekday values of the BYDAY and WKST rule parts.
+var weekdays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// ruleParts maps the name of each rule part defined by RFC 5545 to a function
+// checking whether a value is valid for that part.
+var ruleParts = map[string]func(string) bool{
+	"FREQ":       oneOf("SECONDLY", "MINUTELY", "HOURLY", "DAILY", "WEEKLY", "MONTHLY", "YEARLY"),
+	"UNTIL":      isDateOrDateTime,
+	"COUNT":      isPositiveInteger,
+	"INTERVAL":   isPositiveInteger,
+	"BYSECOND":   integerList(0, 60, false),
+	"BYMINUTE":   integerList(0, 59, false),
+	"BYHOUR":     integerList(0, 23, false),
+	"BYDAY":      isWeekdayList,
+	"BYMONTHDAY": integerList(1, 31, true),
+	"BYYEARDAY":  integerList(1, 366, true),
+	"BYWEEKNO":   integerList(1, 53, true),
+	"BYMONTH":    integerList(1, 12, false),
+	"BYSETPOS":   integerList(1, 366, true),
+	"WKST":       oneOf(weekdays...),
+}
+
+// ValidateRecurrence checks whether the given string is a recurrence rule as
+// specified by RFC 5545, e.g. `FREQ=WEEKLY;BYDAY=MO,WE`. The rule is expected
+// without the `RRULE:` prefix.
+func ValidateRecurrence(rule string) error {
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(rule, ";") {
+		i := strings.IndexByte(part, '=')
+		if i < 0 {
+			return fmt.Errorf("rule part %q has no value", part)
+		}
+
+		name, value := strings.ToUpper(part[:i]), strings.ToUpper(part[i+1:])
+
+		isValid, exists := ruleParts[name]
+		if !exists {
+			return fmt.Errorf("unknown rule part %s", part[:i])
+		}
+
+		if seen[name] {
+			return fmt.Errorf("rule part %s must not occur more than once", name)
+		}
+		seen[name] = true
+
+		if !isValid(value) {
+			return fmt.Errorf("invalid value %q for rule part %s", part[i+1:], name)
+		}
+	}
+
+	if !seen["FREQ"] {
+		return errors.New("rule part FREQ is required")
+	}
+
+	if seen["UNTIL"] && seen["COUNT"] {
+		return errors.New("rule parts UNTIL and COUNT must not occur together")
+	}
+
+	return nil
+}
+
+// oneOf returns a function checking whether a value is one of the given values.
+func oneOf(values ...string) func(string) bool {
+	return func(value string) bool {
+		for _, v := range values {
+			if value == v {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// isDateOrDateTime reports whether the value is a DATE or DATE-TIME value.
+func isDateOrDateTime(value string) bool {
+	for _, layout := range []string{dateFormat, dateTimeFormat, "20060102T150405"} {
+		if _, err := time.Parse(layout, value); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isPositiveInteger reports whether the value is an integer greater than 0.
+func isPositiveInteger(value string) bool {
+	n, err := strconv.Atoi(value)
+	return err == nil && n > 0 && !strings.HasPrefix(value, "+")
+}
+
+// integerList returns a function checking whether a value is a comma-separated
+// list of integers in the range from min to max. If signed is true, negative
+// values in the range from -max to -min are allowed as well.
+func integerList(min, max int, signed bool) func(string) bool {
+	return func(value string) bool {
+		for _, item := range strings.Split(value, ",") {
+			n, err := strconv.Atoi(item)
+			if err != nil {
+				return false
+			}
+			if signed && n < 0 {
+				n = -n
+			} else if strings.HasPrefix(item, "-") {
+				return false
+			}
+			if n < min || n > max {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// isWeekdayList reports whether the value is a comma-separated list of weekdays
+// that are optionally prefixed with an ordinal like in `1MO` or `-1FR`.
+func isWeekdayList(value string) bool {
+	isWeekday := oneOf(weekdays...)
+
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 || !isWeekday(item[len(item)-2:]) {
+			return false
+		}
+
+		if ordinal := item[:len(item)-2]; ordinal != "" && !integerList(1, 53, true)(ordinal) {
+			return false
+		}
+	}
+
+	return true
+}
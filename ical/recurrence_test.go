@@ -0,0 +1,55 @@
+// Package ical converts ToDo items from and to iCalendar files as specified by
+// RFC 5545. Each ToDo item is written as a VTODO component, and each of its
+// tasks as a VTODO component of its own that refers to the component of its
+// ToDo item with a RELATED-TO property.
+//
+// The name, description, due date, completion state, priority, tags and
+// recurrence rule of a ToDo item are mapped to the SUMMARY, DESCRIPTION, DUE,
+// STATUS, PRIORITY, CATEGORIES and RRULE properties.
+package ical
+
+import "testing"
+
+func TestValidateRecurrence(t *testing.T) {
+	valid := []string{
+		"FREQ=DAILY",
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE,FR",
+		"FREQ=MONTHLY;BYDAY=-1FR;COUNT=10",
+		"FREQ=MONTHLY;BYMONTHDAY=1,-1;UNTIL=20271231",
+		"FREQ=YEARLY;BYMONTH=1;BYDAY=1MO;UNTIL=20271231T235959Z",
+		"freq=weekly;wkst=su",
+		"FREQ=YEARLY;BYYEARDAY=-366;BYWEEKNO=53;BYSETPOS=-1",
+		"FREQ=HOURLY;BYHOUR=0,23;BYMINUTE=0,59;BYSECOND=60",
+	}
+
+	for _, rule := range valid {
+		if err := ValidateRecurrence(rule); err != nil {
+			t.Errorf("expected %s to be valid, got %s", rule, err.Error())
+		}
+	}
+
+	invalid := []string{
+		"",
+		"BYDAY=MO",
+		"FREQ=FORTNIGHTLY",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;COUNT=2;UNTIL=20271231",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;INTERVAL=-1",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=WEEKLY;BYDAY=MONDAY",
+		"FREQ=MONTHLY;BYDAY=0MO",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=YEARLY;BYMONTH=-1",
+		"FREQ=YEARLY;BYMONTH=",
+		"FREQ=DAILY;X-NAME=1",
+		"FREQ=DAILY;",
+	}
+
+	for _, rule := range invalid {
+		if err := ValidateRecurrence(rule); err == nil {
+			t.Errorf("expected %q to be invalid", rule)
+		}
+	}
+}
@@ -3,75 +3,106 @@
 package main
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"io"
+	"os"
 	"strings"
 
-	"github.com/dominikbraun/todo/core"
-	"github.com/dominikbraun/todo/server"
-	"github.com/dominikbraun/todo/storage"
-
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
-// config stores all configuration values required to run the ToDo app.
-type config struct {
-	mariaDB    storage.MariaDBConfig
-	serverPort uint
-}
+// usage is printed for `todo help` and when no valid command has been given.
+const usage = `Usage: todo <command> [flags] [arguments]
+
+Commands:
+  serve                     Run the ToDo server
+  add <name>                Create a ToDo item
+  ls                        List ToDo items
+  show <id>                 Show a ToDo item along with its tasks
+  done <id>[/<task id>]     Mark a ToDo item or task as done
+  rm <id>                   Delete a ToDo item
+  tui                       Browse and edit ToDo items interactively
+  backup [file]             Write a backup of all data to a file or stdout
+  restore <file>            Restore a backup, overwriting data with --force
+  storage copy              Copy all data between storages, see --from and --to
+  csv export [file]         Write ToDo items as CSV file with one row per task
+  csv import <file>         Create ToDo items from a CSV file
+  help                      Show this help
+
+Run 'todo <command> --help' for the flags of a command.
+`
+
+var (
+	// errUnknownCommand indicates that the given command doesn't exist.
+	errUnknownCommand = errors.New("unknown command")
+)
 
 func main() {
-	flags := parseApplicationConfig()
-
-	mariaDB, err := storage.NewMariaDB(flags.mariaDB)
-	if err != nil {
-		log.Fatal(err)
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		if !errors.Is(err, pflag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "todo: %s\n", err.Error())
+		}
+		os.Exit(1)
 	}
+}
 
-	if err := mariaDB.Initialize(); err != nil {
-		log.Fatal(err)
+// run executes the command given in args and writes its output to stdout.
+//
+// For backwards compatibility, the server is started if no command but only
+// flags are given, e.g. `todo --port 8000`.
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return serve(args)
 	}
 
-	app := core.NewApp(mariaDB)
-	srv := server.New(flags.serverPort, app)
-
-	log.Printf("serving app on port %d\n", flags.serverPort)
+	commands := map[string]func(args []string, stdout io.Writer) error{
+		"add":     add,
+		"ls":      list,
+		"show":    show,
+		"done":    done,
+		"rm":      remove,
+		"tui":     interactive,
+		"backup":  backupData,
+		"restore": restoreData,
+		"storage": storageCommand,
+		"csv":     csvCommand,
+	}
 
-	if err := srv.Run(); err != nil {
-		log.Fatal(err)
+	switch command := args[0]; command {
+	case "serve":
+		return serve(args[1:])
+	case "help":
+		_, err := io.WriteString(stdout, usage)
+		return err
+	default:
+		if run, exists := commands[command]; exists {
+			return run(args[1:], stdout)
+		}
+		_, _ = io.WriteString(os.Stderr, usage)
+		return fmt.Errorf("%w: %s", errUnknownCommand, command)
 	}
 }
 
-// parseApplicationConfig parses the application configuration from multiple
-// sources. Currently, these sources are CLI flags and environment variables.
+// loadConfig parses the flags of a command and returns a viper instance that
+// reads the flag values from multiple sources. Currently, these sources are CLI
+// flags and environment variables.
 //
 // A configuration value like `port` can either be passed to the binary as a
 // --port flag or specified as a TODO_PORT environment variable.
-func parseApplicationConfig() config {
-
-	pflag.String("mariadb-user", "admin", "The MariaDB user")
-	pflag.String("mariadb-password", "admin", "The MariaDB password")
-	pflag.String("mariadb-address", "0.0.0.0:3306", "The MariaDB address")
-	pflag.String("mariadb-dbname", "todo_app", "The MariaDB database name")
-	pflag.Uint("port", 8000, "The port the server should listen on")
-
-	pflag.Parse()
+func loadConfig(flags *pflag.FlagSet, args []string) (*viper.Viper, error) {
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
 
-	_ = viper.BindPFlags(pflag.CommandLine)
+	config := viper.New()
 
-	viper.AutomaticEnv()
-	viper.SetEnvPrefix("TODO")
-	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	_ = config.BindPFlags(flags)
 
-	flags := config{
-		mariaDB: storage.MariaDBConfig{
-			User:     viper.GetString("mariadb-user"),
-			Password: viper.GetString("mariadb-password"),
-			Address:  viper.GetString("mariadb-address"),
-			DBName:   viper.GetString("mariadb-dbname"),
-		},
-		serverPort: viper.GetUint("port"),
-	}
+	config.AutomaticEnv()
+	config.SetEnvPrefix("TODO")
+	config.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 
-	return flags
+	return config, nil
 }
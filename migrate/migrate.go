@@ -0,0 +1,478 @@
+// Package migrate copies all data from one storage.Storage implementation to
+// another, e.g. from the in-memory storage to MariaDB. All entities keep their
+// IDs, so that a copy can be resumed after an interruption and verified against
+// the source afterwards.
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dominikbraun/todo/backup"
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+)
+
+// Types lists the entity types in the order they are copied. The names are the
+// record types of backup archives.
+var Types = []string{
+	backup.TypeToDo,
+	backup.TypeComment,
+	backup.TypeAttachment,
+	backup.TypeView,
+	backup.TypeWebhook,
+	backup.TypeDelivery,
+	backup.TypeCalendarFeed,
+}
+
+var (
+	// ErrTargetNotEmpty indicates that the target storage already contains
+	// data and the copy is not being resumed.
+	ErrTargetNotEmpty = errors.New("target storage is not empty, resume the copy to keep its data")
+
+	// ErrConflict indicates that the target storage contains an entity with the
+	// same ID as an entity of the source storage, but with different data.
+	ErrConflict = errors.New("target storage contains different data with the same ID")
+
+	// ErrVerificationFailed indicates that the counts or checksums of the
+	// target storage don't match the source storage after copying.
+	ErrVerificationFailed = errors.New("target storage doesn't match the source storage")
+)
+
+// Options configures a copy.
+type Options struct {
+	// Resume allows copying to a non-empty target storage, like one that a
+	// previous copy has been interrupted for. Entities that already exist in
+	// the target storage with the same data are skipped. ToDo items with
+	// different data are replaced, since their tasks may have been copied
+	// partially, and other entities with different data are a conflict.
+	Resume bool
+
+	// Progress is called after each copied or skipped entity with the entity
+	// type, the number of processed entities of that type and their total.
+	Progress func(entityType string, done, total int)
+}
+
+// Result describes a successful copy. Counts and Checksums are the number of
+// entities and their checksum per entity type, which are equal for the source
+// and the target storage.
+type Result struct {
+	Copied    map[string]int    `json:"copied"`
+	Skipped   map[string]int    `json:"skipped"`
+	Counts    map[string]int    `json:"counts"`
+	Checksums map[string]string `json:"checksums"`
+}
+
+// Copy copies all entities from one storage to another and verifies that both
+// storages contain the same data afterwards. Attachment contents are not part
+// of the storage and have to be copied separately if the blob store changes.
+func Copy(from, to storage.Storage, options Options) (Result, error) {
+	source, err := load(from)
+	if err != nil {
+		return Result{}, fmt.Errorf("reading source storage: %w", err)
+	}
+
+	target, err := load(to)
+	if err != nil {
+		return Result{}, fmt.Errorf("reading target storage: %w", err)
+	}
+
+	if !options.Resume && !target.isEmpty() {
+		return Result{}, ErrTargetNotEmpty
+	}
+
+	c := copier{
+		to:      to,
+		options: options,
+		result: Result{
+			Copied:  make(map[string]int),
+			Skipped: make(map[string]int),
+		},
+	}
+
+	if err := c.copyAll(source, target); err != nil {
+		return Result{}, err
+	}
+
+	for _, toDo := range source.toDos {
+		if err := to.Reindex(toDo.ID); err != nil {
+			return Result{}, err
+		}
+	}
+
+	copied, err := load(to)
+	if err != nil {
+		return Result{}, fmt.Errorf("reading target storage: %w", err)
+	}
+
+	expected, actual := source.summarize(), copied.summarize()
+
+	for _, entityType := range Types {
+		if expected.counts[entityType] != actual.counts[entityType] {
+			return Result{}, fmt.Errorf("%w: expected %d %s entities, got %d", ErrVerificationFailed,
+				expected.counts[entityType], entityType, actual.counts[entityType])
+		}
+		if expected.checksums[entityType] != actual.checksums[entityType] {
+			return Result{}, fmt.Errorf("%w: checksum of %s entities differs", ErrVerificationFailed, entityType)
+		}
+	}
+
+	c.result.Counts = expected.counts
+	c.result.Checksums = expected.checksums
+
+	return c.result, nil
+}
+
+// copier copies the entities of a snapshot to the target storage.
+type copier struct {
+	to      storage.Storage
+	options Options
+	result  Result
+}
+
+// copyAll copies all entities of the source snapshot that are missing in the
+// target snapshot, in the order of Types.
+func (c *copier) copyAll(source, target snapshot) error {
+	existingToDos := make(map[int64]model.ToDo)
+	for _, toDo := range target.toDos {
+		existingToDos[toDo.ID] = toDo
+	}
+
+	replaced := false
+
+	err := c.copyEach(backup.TypeToDo, len(source.toDos), func(i int) (bool, error) {
+		toDo := source.toDos[i]
+
+		existing, exists := existingToDos[toDo.ID]
+		if exists && canonical(existing) == canonical(toDo) {
+			return false, nil
+		}
+
+		if exists {
+			if err := c.to.DeleteToDo(toDo.ID); err != nil {
+				return false, err
+			}
+			replaced = true
+		}
+
+		return true, c.to.RestoreToDo(toDo)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Deleting a ToDo item deletes its comments and attachments as well, so
+	// they have to be copied again.
+	if replaced {
+		if target, err = load(c.to); err != nil {
+			return fmt.Errorf("reading target storage: %w", err)
+		}
+	}
+
+	existingComments := make(map[int64]interface{})
+	for _, comment := range target.comments {
+		existingComments[comment.ID] = comment
+	}
+
+	err = c.copyEach(backup.TypeComment, len(source.comments), func(i int) (bool, error) {
+		comment := source.comments[i]
+		return c.copyEntity(existingComments, comment.ID, comment, func() error {
+			return c.to.RestoreComment(comment)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	existingAttachments := make(map[int64]interface{})
+	for _, attachment := range target.attachments {
+		existingAttachments[attachment.ID] = attachment
+	}
+
+	err = c.copyEach(backup.TypeAttachment, len(source.attachments), func(i int) (bool, error) {
+		attachment := source.attachments[i]
+		return c.copyEntity(existingAttachments, attachment.ID, attachment, func() error {
+			return c.to.RestoreAttachment(attachment)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	existingViews := make(map[int64]interface{})
+	for _, view := range target.views {
+		existingViews[view.ID] = view
+	}
+
+	err = c.copyEach(backup.TypeView, len(source.views), func(i int) (bool, error) {
+		view := source.views[i]
+		return c.copyEntity(existingViews, view.ID, view, func() error {
+			return c.to.RestoreView(view)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	existingWebhooks := make(map[int64]interface{})
+	for _, webhook := range target.webhooks {
+		existingWebhooks[webhook.ID] = webhook
+	}
+
+	err = c.copyEach(backup.TypeWebhook, len(source.webhooks), func(i int) (bool, error) {
+		webhook := source.webhooks[i]
+		return c.copyEntity(existingWebhooks, webhook.ID, webhook, func() error {
+			return c.to.RestoreWebhook(webhook)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	existingDeliveries := make(map[int64]interface{})
+	for _, delivery := range target.deliveries {
+		existingDeliveries[delivery.ID] = delivery
+	}
+
+	err = c.copyEach(backup.TypeDelivery, len(source.deliveries), func(i int) (bool, error) {
+		delivery := source.deliveries[i]
+		return c.copyEntity(existingDeliveries, delivery.ID, delivery, func() error {
+			return c.to.RestoreDelivery(delivery)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	// Calendar feeds are identified by their owner and saving them replaces an
+	// existing feed, so they are simply saved again if they differ.
+	existingFeeds := make(map[string]model.CalendarFeed)
+	for _, feed := range target.feeds {
+		existingFeeds[feed.Owner] = feed
+	}
+
+	return c.copyEach(backup.TypeCalendarFeed, len(source.feeds), func(i int) (bool, error) {
+		feed := source.feeds[i]
+
+		if existing, exists := existingFeeds[feed.Owner]; exists && canonical(existing) == canonical(feed) {
+			return false, nil
+		}
+
+		return true, c.to.SaveCalendarFeed(feed)
+	})
+}
+
+// copyEach calls copyOne for the entities of the given type with the indices
+// 0 to total-1, records whether they have been copied or skipped and reports
+// the progress.
+func (c *copier) copyEach(entityType string, total int, copyOne func(i int) (bool, error)) error {
+	for i := 0; i < total; i++ {
+		copied, err := copyOne(i)
+		if err != nil {
+			return fmt.Errorf("copying %s entities: %w", entityType, err)
+		}
+
+		if copied {
+			c.result.Copied[entityType]++
+		} else {
+			c.result.Skipped[entityType]++
+		}
+
+		if c.options.Progress != nil {
+			c.options.Progress(entityType, i+1, total)
+		}
+	}
+
+	return nil
+}
+
+// copyEntity restores an entity unless an entity with the same ID exists in the
+// target storage. If the existing entity has different data, ErrConflict will
+// be returned. The returned bool indicates whether the entity has been copied.
+func (c *copier) copyEntity(existing map[int64]interface{}, id int64, entity interface{}, restore func() error) (bool, error) {
+	if existingEntity, exists := existing[id]; exists {
+		if canonical(existingEntity) != canonical(entity) {
+			return false, fmt.Errorf("%w: ID %d", ErrConflict, id)
+		}
+		return false, nil
+	}
+
+	return true, restore()
+}
+
+// snapshot holds all entities of a storage, ordered by their ID.
+type snapshot struct {
+	toDos       []model.ToDo
+	comments    []model.Comment
+	attachments []model.Attachment
+	views       []model.View
+	webhooks    []model.Webhook
+	deliveries  []model.Delivery
+	feeds       []model.CalendarFeed
+}
+
+// load reads all entities of a storage.
+func load(s storage.Storage) (snapshot, error) {
+	var (
+		snapshot snapshot
+		err      error
+	)
+
+	if snapshot.toDos, err = s.FindToDos(); err != nil {
+		return snapshot, err
+	}
+
+	sort.Slice(snapshot.toDos, func(i, j int) bool {
+		return snapshot.toDos[i].ID < snapshot.toDos[j].ID
+	})
+
+	toDoIDs := make([]int64, len(snapshot.toDos))
+	for i, toDo := range snapshot.toDos {
+		toDoIDs[i] = toDo.ID
+	}
+
+	if snapshot.comments, err = s.FindCommentsByToDoIDs(toDoIDs); err != nil {
+		return snapshot, err
+	}
+
+	if snapshot.attachments, err = s.FindAttachmentsByToDoIDs(toDoIDs); err != nil {
+		return snapshot, err
+	}
+
+	if snapshot.views, err = s.FindAllViews(); err != nil {
+		return snapshot, err
+	}
+
+	if snapshot.webhooks, err = s.FindWebhooks(); err != nil {
+		return snapshot, err
+	}
+
+	sort.Slice(snapshot.webhooks, func(i, j int) bool {
+		return snapshot.webhooks[i].ID < snapshot.webhooks[j].ID
+	})
+
+	for _, webhook := range snapshot.webhooks {
+		deliveries, err := s.FindDeliveries(webhook.ID)
+		if err != nil {
+			return snapshot, err
+		}
+		snapshot.deliveries = append(snapshot.deliveries, deliveries...)
+	}
+
+	sort.Slice(snapshot.deliveries, func(i, j int) bool {
+		return snapshot.deliveries[i].ID < snapshot.deliveries[j].ID
+	})
+
+	if snapshot.feeds, err = s.FindCalendarFeeds(); err != nil {
+		return snapshot, err
+	}
+
+	return snapshot, nil
+}
+
+// isEmpty reports whether the snapshot contains no entities. Comments,
+// attachments and deliveries always belong to a ToDo item or webhook.
+func (s snapshot) isEmpty() bool {
+	return len(s.toDos) == 0 && len(s.views) == 0 && len(s.webhooks) == 0 && len(s.feeds) == 0
+}
+
+// summary holds the number of entities and their checksum per entity type.
+type summary struct {
+	counts    map[string]int
+	checksums map[string]string
+}
+
+// summarize counts the entities of the snapshot and computes their checksums.
+// The checksum of an entity type is the SHA-256 hash of the canonical JSON
+// encodings of all its entities in the order of their IDs.
+func (s snapshot) summarize() summary {
+	entities := map[string][]interface{}{}
+
+	for _, toDo := range s.toDos {
+		entities[backup.TypeToDo] = append(entities[backup.TypeToDo], toDo)
+	}
+	for _, comment := range s.comments {
+		entities[backup.TypeComment] = append(entities[backup.TypeComment], comment)
+	}
+	for _, attachment := range s.attachments {
+		entities[backup.TypeAttachment] = append(entities[backup.TypeAttachment], attachment)
+	}
+	for _, view := range s.views {
+		entities[backup.TypeView] = append(entities[backup.TypeView], view)
+	}
+	for _, webhook := range s.webhooks {
+		entities[backup.TypeWebhook] = append(entities[backup.TypeWebhook], webhook)
+	}
+	for _, delivery := range s.deliveries {
+		entities[backup.TypeDelivery] = append(entities[backup.TypeDelivery], delivery)
+	}
+	for _, feed := range s.feeds {
+		entities[backup.TypeCalendarFeed] = append(entities[backup.TypeCalendarFeed], feed)
+	}
+
+	summary := summary{
+		counts:    make(map[string]int),
+		checksums: make(map[string]string),
+	}
+
+	for _, entityType := range Types {
+		hash := sha256.New()
+
+		for _, entity := range entities[entityType] {
+			_, _ = fmt.Fprintln(hash, canonical(entity))
+		}
+
+		summary.counts[entityType] = len(entities[entityType])
+		summary.checksums[entityType] = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	return summary
+}
+
+// canonical returns the JSON encoding of an entity in a form that doesn't depend
+// on the storage implementation: All timestamps are converted to UTC with a
+// precision of seconds, since MariaDB stores neither time zones nor fractional
+// seconds. The blob keys of attachments are included, since they link the
+// attachments to their content.
+func canonical(entity interface{}) string {
+	switch e := entity.(type) {
+	case model.ToDo:
+		if e.Due != nil {
+			due := canonicalTime(*e.Due)
+			e.Due = &due
+		}
+		entity = e
+	case model.Comment:
+		e.CreatedAt, e.UpdatedAt = canonicalTime(e.CreatedAt), canonicalTime(e.UpdatedAt)
+		entity = e
+	case model.Attachment:
+		e.CreatedAt = canonicalTime(e.CreatedAt)
+		entity = struct {
+			model.Attachment
+			BlobKey string `json:"blob_key"`
+		}{e, e.BlobKey}
+	case model.Webhook:
+		e.CreatedAt = canonicalTime(e.CreatedAt)
+		entity = e
+	case model.Delivery:
+		e.NextAttemptAt = canonicalTime(e.NextAttemptAt)
+		e.CreatedAt, e.UpdatedAt = canonicalTime(e.CreatedAt), canonicalTime(e.UpdatedAt)
+		entity = e
+	case model.CalendarFeed:
+		e.CreatedAt = canonicalTime(e.CreatedAt)
+		entity = e
+	}
+
+	data, _ := json.Marshal(entity)
+	return string(data)
+}
+
+// canonicalTime converts a timestamp to UTC and truncates it to seconds.
+func canonicalTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
@@ -0,0 +1,220 @@
+// Package migrate copies all data from one storage.Storage implementation to
+// another, e.g. from the in-memory storage to MariaDB. All entities keep their
+// IDs, so that a copy can be resumed after an interruption and verified against
+// the source afterwards.
+package migrate
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dominikbraun/todo/backup"
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newSourceStorage returns an in-memory storage containing an entity of each
+// type. The first ToDo item has been deleted, so that the IDs have a gap.
+func newSourceStorage(t *testing.T) storage.Storage {
+	s := storage.NewMemory()
+	_ = s.Initialize()
+
+	timestamp := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	due := time.Date(2021, 3, 2, 14, 0, 0, 0, time.FixedZone("CET", 3600))
+
+	deleted, _ := s.CreateToDo(model.ToDo{Name: "Deleted"})
+	_ = s.DeleteToDo(deleted.ID)
+
+	toDos := []model.ToDo{
+		{Name: "Release 1.0", Due: &due, Tags: []string{"release"}, Tasks: []model.Task{{Name: "Write notes"}, {Name: "Tag commit"}}},
+		{Name: "Buy milk"},
+	}
+
+	for _, toDo := range toDos {
+		if _, err := s.CreateToDo(toDo); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	webhook, _ := s.CreateWebhook(model.Webhook{URL: "https://example.com/hook", Secret: "secret", CreatedAt: timestamp})
+
+	_, _ = s.CreateComment(model.Comment{ToDoID: 2, Author: "alice", Body: "Soon", CreatedAt: timestamp, UpdatedAt: timestamp})
+	_, _ = s.CreateAttachment(model.Attachment{ToDoID: 2, Filename: "notes.txt", BlobKey: "key", CreatedAt: timestamp})
+	_, _ = s.CreateView(model.View{Owner: "alice", Name: "Release", Filter: "tag:release"})
+	_, _ = s.CreateDelivery(model.Delivery{WebhookID: webhook.ID, EventType: model.EventCreated, Status: model.DeliveryPending, NextAttemptAt: timestamp, CreatedAt: timestamp, UpdatedAt: timestamp})
+	_ = s.SaveCalendarFeed(model.CalendarFeed{Owner: "alice", Token: "token", CreatedAt: timestamp})
+
+	return s
+}
+
+// newTargetStorage returns an empty in-memory storage.
+func newTargetStorage() storage.Storage {
+	s := storage.NewMemory()
+	_ = s.Initialize()
+
+	return s
+}
+
+func TestCopy(t *testing.T) {
+	source, target := newSourceStorage(t), newTargetStorage()
+
+	var progress []int
+
+	result, err := Copy(source, target, Options{
+		Progress: func(entityType string, done, total int) {
+			if entityType == backup.TypeToDo {
+				progress = append(progress, done, total)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(progress, []int{1, 2, 2, 2}) {
+		t.Errorf("unexpected progress %v", progress)
+	}
+
+	for _, entityType := range Types {
+		expected := 1
+		if entityType == backup.TypeToDo {
+			expected = 2
+		}
+
+		if result.Counts[entityType] != expected || result.Copied[entityType] != expected || result.Checksums[entityType] == "" {
+			t.Errorf("%s: expected %d copied entities, got %v", entityType, expected, result)
+		}
+	}
+
+	expectedToDo, _ := source.FindToDoByID(2)
+	if toDo, _ := target.FindToDoByID(2); !cmp.Equal(toDo, expectedToDo) {
+		t.Errorf("expected ToDo %v, got %v", expectedToDo, toDo)
+	}
+
+	if results, _ := target.Search("notes", 10); len(results) == 0 {
+		t.Error("expected copied ToDo items to be searchable")
+	}
+
+	if _, err := Copy(source, target, Options{}); !errors.Is(err, ErrTargetNotEmpty) {
+		t.Errorf("expected error %v, got %v", ErrTargetNotEmpty, err)
+	}
+}
+
+func TestCopy_Resume(t *testing.T) {
+	source, target := newSourceStorage(t), newTargetStorage()
+
+	// Simulate a copy that has been interrupted while copying the first ToDo
+	// item, whose second task is missing.
+	partial, _ := source.FindToDoByID(2)
+	partial.Tasks = partial.Tasks[:1]
+
+	if err := target.RestoreToDo(partial); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Copy(source, target, Options{Resume: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Copied[backup.TypeToDo] != 2 || result.Copied[backup.TypeView] != 1 {
+		t.Errorf("expected all entities to be copied, got %v", result.Copied)
+	}
+
+	result, err = Copy(source, target, Options{Resume: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Copied) != 0 || result.Skipped[backup.TypeToDo] != 2 {
+		t.Errorf("expected all entities to be skipped, got %v", result)
+	}
+
+	// A ToDo item that has changed since the previous copy is replaced, which
+	// deletes its comments and attachments in the target storage.
+	changed, _ := source.FindToDoByID(2)
+	changed.Name = "Release 1.1"
+	_ = source.UpdateToDo(changed.ID, changed)
+
+	result, err = Copy(source, target, Options{Resume: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Copied[backup.TypeToDo] != 1 || result.Copied[backup.TypeComment] != 1 || result.Copied[backup.TypeAttachment] != 1 {
+		t.Errorf("expected the changed ToDo item to be copied with its comment and attachment, got %v", result.Copied)
+	}
+
+	comment, _ := target.FindCommentByID(1)
+	comment.Body = "Changed"
+	_ = target.UpdateComment(comment.ID, comment)
+
+	if _, err := Copy(source, target, Options{Resume: true}); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected error %v, got %v", ErrConflict, err)
+	}
+}
+
+// lossyStorage is a storage that silently drops restored views.
+type lossyStorage struct {
+	storage.Storage
+}
+
+func (l lossyStorage) RestoreView(model.View) error {
+	return nil
+}
+
+func TestCopy_Verification(t *testing.T) {
+	source := newSourceStorage(t)
+
+	if _, err := Copy(source, lossyStorage{newTargetStorage()}, Options{}); !errors.Is(err, ErrVerificationFailed) {
+		t.Errorf("expected error %v, got %v", ErrVerificationFailed, err)
+	}
+}
+
+// TestCopy_MariaDB copies the data of an in-memory storage to MariaDB and back
+// if the TODO_TEST_MARIADB environment variable is set, using the same
+// variables as the storage tests.
+func TestCopy_MariaDB(t *testing.T) {
+	if os.Getenv("TODO_TEST_MARIADB") == "" {
+		t.Skip("TODO_TEST_MARIADB is not set")
+	}
+
+	mariaDB, err := storage.NewMariaDB(storage.MariaDBConfig{
+		User:     os.Getenv("TODO_TEST_MARIADB_USER"),
+		Password: os.Getenv("TODO_TEST_MARIADB_PASSWORD"),
+		Address:  os.Getenv("TODO_TEST_MARIADB_ADDRESS"),
+		DBName:   os.Getenv("TODO_TEST_MARIADB_DBNAME"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		_ = mariaDB.Remove()
+		_ = mariaDB.Close()
+	}()
+
+	if err := mariaDB.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+
+	source := newSourceStorage(t)
+
+	expected, err := Copy(source, mariaDB, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Copy(mariaDB, newTargetStorage(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(result.Checksums, expected.Checksums) {
+		t.Errorf("expected checksums %v, got %v", expected.Checksums, result.Checksums)
+	}
+}
@@ -0,0 +1,19 @@
+// Package model provides domain entities for the application. These entities
+// are expected and returned by the API and will be stored in the database.
+package model
+
+import "time"
+
+// Attachment represents the metadata of a file attached to a ToDo item or one
+// of its tasks. The file content itself is stored in a blob store under the
+// blob key, which is not exposed by the API.
+type Attachment struct {
+	ID          int64     `json:"id"`
+	ToDoID      int64     `json:"todo_id" db:"todo_id"`
+	TaskID      int64     `json:"task_id,omitempty" db:"task_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	Size        int64     `json:"size"`
+	BlobKey     string    `json:"-" db:"blob_key"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,12 @@
+// Package model provides domain entities for the application. These entities
+// are expected and returned by the API and will be stored in the database.
+package model
+
+import "time"
+
+// RestoreResult describes a restored backup: The time the backup has been
+// created at and the number of restored entities per record type.
+type RestoreResult struct {
+	CreatedAt time.Time      `json:"created_at"`
+	Counts    map[string]int `json:"counts"`
+}
@@ -0,0 +1,51 @@
+// Package model provides domain entities for the application. These entities
+// are expected and returned by the API and will be stored in the database.
+package model
+
+// BatchAction is the kind of change made by a BatchOperation.
+type BatchAction string
+
+const (
+	// BatchCreate creates the ToDo item of the operation.
+	BatchCreate BatchAction = "create"
+
+	// BatchUpdate overwrites the ToDo item with the ID of the operation.
+	BatchUpdate BatchAction = "update"
+
+	// BatchDelete deletes the ToDo item with the ID of the operation.
+	BatchDelete BatchAction = "delete"
+
+	// BatchComplete marks the ToDo item with the ID of the operation as done.
+	BatchComplete BatchAction = "complete"
+)
+
+// BatchRequest is a list of operations executed by a single request. If Atomic
+// is true, either all operations are applied or none of them.
+type BatchRequest struct {
+	Atomic     bool             `json:"atomic"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchOperation is a change of a single ToDo item. ID is required by all
+// actions except for BatchCreate, and ToDo is required by BatchCreate and
+// BatchUpdate.
+type BatchOperation struct {
+	Action BatchAction `json:"op"`
+	ID     int64       `json:"id,omitempty"`
+	ToDo   *ToDo       `json:"todo,omitempty"`
+}
+
+// BatchResponse lists the results of a batch in the order of its operations.
+type BatchResponse struct {
+	Atomic  bool          `json:"atomic"`
+	Results []BatchResult `json:"results"`
+}
+
+// BatchResult is the outcome of a BatchOperation. Status is the HTTP status
+// code the operation would have had as a single request. ToDo is the created
+// or changed ToDo item, and Error describes why the operation failed.
+type BatchResult struct {
+	Status int      `json:"status"`
+	ToDo   *ToDo    `json:"todo,omitempty"`
+	Error  *Problem `json:"error,omitempty"`
+}
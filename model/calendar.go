@@ -0,0 +1,16 @@
+// Package model provides domain entities for the application. These entities
+// are expected and returned by the API and will be stored in the database.
+package model
+
+import "time"
+
+// CalendarFeed represents a user's secret iCalendar feed URL, which can be
+// subscribed to by calendar apps that can't send custom headers. Everyone who
+// knows the token can read the feed. If Filter is set, the feed only contains
+// the ToDo items matching the filter query.
+type CalendarFeed struct {
+	Owner     string    `json:"owner"`
+	Token     string    `json:"token"`
+	Filter    string    `json:"filter,omitempty"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
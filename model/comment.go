@@ -0,0 +1,17 @@
+// Package model provides domain entities for the application. These entities
+// are expected and returned by the API and will be stored in the database.
+package model
+
+import "time"
+
+// Comment represents a comment in the activity thread of a ToDo item or one of
+// its tasks. Comments that belong to the ToDo item itself have no task ID.
+type Comment struct {
+	ID        int64     `json:"id"`
+	ToDoID    int64     `json:"todo_id" db:"todo_id"`
+	TaskID    int64     `json:"task_id,omitempty" db:"task_id"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
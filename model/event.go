@@ -0,0 +1,26 @@
+// Package model provides domain entities for the application. These entities
+// are expected and returned by the API and will be stored in the database.
+package model
+
+import "time"
+
+// EventType indicates what kind of change an Event describes.
+type EventType string
+
+const (
+	EventCreated   EventType = "created"
+	EventUpdated   EventType = "updated"
+	EventDeleted   EventType = "deleted"
+	EventCompleted EventType = "completed"
+)
+
+// Event represents a change of a ToDo item. Events are numbered consecutively,
+// so that consumers can resume after the last event they've received. For
+// deleted ToDo items, ToDo holds the item as it was before the deletion.
+type Event struct {
+	ID     int64     `json:"id"`
+	Type   EventType `json:"type"`
+	ToDoID int64     `json:"todo_id"`
+	ToDo   ToDo      `json:"todo"`
+	Time   time.Time `json:"time"`
+}
@@ -0,0 +1,24 @@
+// Package model provides domain entities for the application. These entities
+// are expected and returned by the API and will be stored in the database.
+package model
+
+import "time"
+
+// IdempotencyRecord stores the response to a request sent with an
+// `Idempotency-Key` header, so that the response can be replayed if the request
+// is retried. Keys are scoped to the user sending them, who is the owner of
+// the record.
+//
+// Fingerprint identifies the request the key has been used for. A record with
+// a Status of 0 belongs to a request that is still being processed, and its
+// Fingerprint is a random token identifying the claim of the key.
+type IdempotencyRecord struct {
+	Owner       string    `json:"owner"`
+	Key         string    `json:"key" db:"idempotency_key"`
+	Fingerprint string    `json:"fingerprint"`
+	Status      int       `json:"status"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	Body        []byte    `json:"body"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at" db:"expires_at"`
+}
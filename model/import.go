@@ -0,0 +1,21 @@
+// Package model provides domain entities for the application. These entities
+// are expected and returned by the API and will be stored in the database.
+package model
+
+// ImportResult describes the ToDo items created by an import. For dry runs,
+// the items have only been validated and have no IDs. Imports that skip
+// invalid rows instead of failing as a whole list them as errors.
+type ImportResult struct {
+	DryRun bool       `json:"dry_run"`
+	ToDos  []ToDo     `json:"todos"`
+	Errors []RowError `json:"errors,omitempty"`
+}
+
+// RowError describes why a row of an imported file is invalid. Row is the
+// 1-based number of the row including the header, and Column is the field the
+// error refers to, if any.
+type RowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,27 @@
+// Package model provides domain entities for the application. These entities
+// are expected and returned by the API and will be stored in the database.
+package model
+
+// Problem is an error response as specified by RFC 7807. Type is a URI
+// identifying the kind of problem, or `about:blank` if the problem has no
+// specific type. In that case, Title is the text of the status code.
+//
+// Errors lists the invalid fields of a request body, Rows lists the invalid
+// rows of a refused import, and Results lists the outcome of all operations of
+// a failed atomic batch.
+type Problem struct {
+	Type    string        `json:"type"`
+	Title   string        `json:"title"`
+	Status  int           `json:"status"`
+	Detail  string        `json:"detail,omitempty"`
+	Errors  []FieldError  `json:"errors,omitempty"`
+	Rows    []RowError    `json:"rows,omitempty"`
+	Results []BatchResult `json:"results,omitempty"`
+}
+
+// FieldError describes why a field of a request body is invalid. Pointer is a
+// JSON pointer as specified by RFC 6901 to the field, like `/tasks/2/name`.
+type FieldError struct {
+	Pointer string `json:"pointer"`
+	Detail  string `json:"detail"`
+}
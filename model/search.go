@@ -0,0 +1,29 @@
+// Package model provides domain entities for the application. These entities
+// are expected and returned by the API and will be stored in the database.
+package model
+
+// SearchResultType indicates which kind of entity a search result refers to.
+type SearchResultType string
+
+const (
+	SearchResultToDo    SearchResultType = "todo"
+	SearchResultTask    SearchResultType = "task"
+	SearchResultComment SearchResultType = "comment"
+)
+
+// SearchResult represents a ToDo item, task or comment matching a full-text
+// search query. Name is the name of the ToDo item or task, or the name of the
+// ToDo item a comment belongs to.
+//
+// Text is the full text of the matched entity that Snippet is created from. It
+// is only used internally and not exposed by the API.
+type SearchResult struct {
+	Type      SearchResultType `json:"type"`
+	ToDoID    int64            `json:"todo_id" db:"todo_id"`
+	TaskID    int64            `json:"task_id,omitempty" db:"task_id"`
+	CommentID int64            `json:"comment_id,omitempty" db:"comment_id"`
+	Name      string           `json:"name"`
+	Snippet   string           `json:"snippet"`
+	Score     float64          `json:"score"`
+	Text      string           `json:"-"`
+}
@@ -2,17 +2,35 @@
 // are expected and returned by the API and will be stored in the database.
 package model
 
-// ToDo represents a ToDo item, typically consisting of multiple sub-tasks.
+import "time"
+
+// ToDo represents a ToDo item, typically consisting of multiple sub-tasks. The
+// version is incremented with each update and allows to detect conflicts.
+//
+// The UID is the iCalendar UID of items created by calendar clients. It is kept
+// by updates that don't provide a UID. Items without UID are identified by
+// their ID in iCalendar files.
 type ToDo struct {
-	ID          int64  `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
-	Tasks       []Task `json:"tasks,omitempty"`
+	ID          int64      `json:"id"`
+	UID         string     `json:"uid,omitempty"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Done        bool       `json:"done"`
+	Due         *time.Time `json:"due,omitempty"`
+	Project     string     `json:"project,omitempty"`
+	Priority    string     `json:"priority,omitempty"`
+	Recurrence  string     `json:"recurrence,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	Tasks       []Task     `json:"tasks,omitempty"`
+	Version     int64      `json:"version"`
 }
 
-// Task represents a sub-task that is part of a ToDo item.
+// Task represents a sub-task that is part of a ToDo item. The UID is treated
+// like the UID of a ToDo item.
 type Task struct {
 	ID          int64  `json:"id"`
+	UID         string `json:"uid,omitempty"`
 	Name        string `json:"name"`
 	Description string `json:"description,omitempty"`
+	Done        bool   `json:"done"`
 }
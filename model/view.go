@@ -0,0 +1,15 @@
+// Package model provides domain entities for the application. These entities
+// are expected and returned by the API and will be stored in the database.
+package model
+
+// View represents a named, saved filter query along with a sort order. Views
+// are owned by a user, except for built-in views, which are identified by a
+// key instead of an ID and are available to all users.
+type View struct {
+	ID     int64  `json:"id,omitempty"`
+	Key    string `json:"key,omitempty"`
+	Owner  string `json:"owner,omitempty"`
+	Name   string `json:"name"`
+	Filter string `json:"filter"`
+	Sort   string `json:"sort,omitempty"`
+}
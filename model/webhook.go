@@ -0,0 +1,42 @@
+// Package model provides domain entities for the application. These entities
+// are expected and returned by the API and will be stored in the database.
+package model
+
+import "time"
+
+// Webhook represents a subscription of an external URL to the changes of ToDo
+// items. Deliveries are signed with the secret, which is only returned when the
+// webhook is created. If Events is empty, all events are delivered.
+type Webhook struct {
+	ID        int64       `json:"id"`
+	URL       string      `json:"url"`
+	Secret    string      `json:"secret,omitempty"`
+	Events    []EventType `json:"events,omitempty"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+}
+
+// DeliveryStatus indicates whether a delivery has been completed.
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// Delivery represents an event that has to be sent or has been sent to a webhook.
+// Pending deliveries are retried until NextAttemptAt, and the response code or
+// error of the last attempt is recorded for debugging.
+type Delivery struct {
+	ID            int64          `json:"id"`
+	WebhookID     int64          `json:"webhook_id" db:"webhook_id"`
+	EventType     EventType      `json:"event_type" db:"event_type"`
+	Payload       string         `json:"payload"`
+	Status        DeliveryStatus `json:"status"`
+	Attempts      int            `json:"attempts"`
+	ResponseCode  int            `json:"response_code,omitempty" db:"response_code"`
+	LastError     string         `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt time.Time      `json:"next_attempt_at" db:"next_attempt_at"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at" db:"updated_at"`
+}
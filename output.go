@@ -0,0 +1,202 @@
+// Package main provides the application entrypoint as well as functions for
+// parsing CLI flags and environment variables.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+// The output formats of the CLI commands. The table format is meant for humans,
+// the JSON format for scripts and the plain format for line-based tools like
+// grep, with one item per line and no header.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputPlain = "plain"
+)
+
+var (
+	// errUnknownOutput indicates that the requested output format is unknown.
+	errUnknownOutput = errors.New("output must be one of table, json, plain")
+)
+
+// printer writes the results of CLI commands in the configured output format.
+type printer struct {
+	writer io.Writer
+	format string
+}
+
+// newPrinter creates a printer writing to the given writer.
+func newPrinter(writer io.Writer, format string) (*printer, error) {
+	switch format {
+	case outputTable, outputJSON, outputPlain:
+	default:
+		return nil, errUnknownOutput
+	}
+
+	return &printer{
+		writer: writer,
+		format: format,
+	}, nil
+}
+
+// printToDos prints a list of ToDo items.
+func (p *printer) printToDos(toDos []model.ToDo) error {
+	switch p.format {
+	case outputJSON:
+		return p.printJSON(toDos)
+	case outputPlain:
+		for _, toDo := range toDos {
+			if _, err := fmt.Fprintf(p.writer, "%d %s %s\n", toDo.ID, checkbox(toDo.Done), toDo.Name); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		table := tabwriter.NewWriter(p.writer, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(table, "ID\tDONE\tNAME\tTASKS\tDUE\tPROJECT\tTAGS")
+
+		for _, toDo := range toDos {
+			fmt.Fprintf(table, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				toDo.ID, checkbox(toDo.Done), toDo.Name, taskProgress(toDo),
+				formatDue(toDo), toDo.Project, strings.Join(toDo.Tags, ","))
+		}
+
+		return table.Flush()
+	}
+}
+
+// printToDo prints a single ToDo item along with its tasks.
+func (p *printer) printToDo(toDo model.ToDo) error {
+	switch p.format {
+	case outputJSON:
+		return p.printJSON(toDo)
+	case outputPlain:
+		if _, err := fmt.Fprintf(p.writer, "%d %s %s\n", toDo.ID, checkbox(toDo.Done), toDo.Name); err != nil {
+			return err
+		}
+		for _, task := range toDo.Tasks {
+			if _, err := fmt.Fprintf(p.writer, "%d/%d %s %s\n", toDo.ID, task.ID, checkbox(task.Done), task.Name); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		table := tabwriter.NewWriter(p.writer, 0, 4, 2, ' ', 0)
+
+		fmt.Fprintf(table, "ID:\t%d\n", toDo.ID)
+		fmt.Fprintf(table, "Name:\t%s\n", toDo.Name)
+		fmt.Fprintf(table, "Done:\t%s\n", checkbox(toDo.Done))
+
+		if toDo.Description != "" {
+			fmt.Fprintf(table, "Description:\t%s\n", toDo.Description)
+		}
+		if toDo.Due != nil {
+			fmt.Fprintf(table, "Due:\t%s\n", formatDue(toDo))
+		}
+		if toDo.Project != "" {
+			fmt.Fprintf(table, "Project:\t%s\n", toDo.Project)
+		}
+		if toDo.Priority != "" {
+			fmt.Fprintf(table, "Priority:\t%s\n", toDo.Priority)
+		}
+		if toDo.Recurrence != "" {
+			fmt.Fprintf(table, "Repeats:\t%s\n", toDo.Recurrence)
+		}
+		if len(toDo.Tags) > 0 {
+			fmt.Fprintf(table, "Tags:\t%s\n", strings.Join(toDo.Tags, ", "))
+		}
+
+		fmt.Fprintf(table, "Version:\t%d\n", toDo.Version)
+
+		if err := table.Flush(); err != nil {
+			return err
+		}
+
+		if len(toDo.Tasks) == 0 {
+			return nil
+		}
+
+		table = tabwriter.NewWriter(p.writer, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(table, "\nTASK\tDONE\tNAME\tDESCRIPTION")
+
+		for _, task := range toDo.Tasks {
+			fmt.Fprintf(table, "%d/%d\t%s\t%s", toDo.ID, task.ID, checkbox(task.Done), task.Name)
+			if task.Description != "" {
+				fmt.Fprintf(table, "\t%s", task.Description)
+			}
+			fmt.Fprintln(table)
+		}
+
+		return table.Flush()
+	}
+}
+
+// printMessage prints a confirmation message. Since there's no result to be
+// processed by scripts, nothing is printed in JSON format.
+func (p *printer) printMessage(message string) error {
+	if p.format == outputJSON {
+		return nil
+	}
+
+	_, err := fmt.Fprintln(p.writer, message)
+	return err
+}
+
+// printJSON prints the value as indented JSON.
+func (p *printer) printJSON(v interface{}) error {
+	encoder := json.NewEncoder(p.writer)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
+
+// checkbox renders the completion state of a ToDo item or task.
+func checkbox(done bool) string {
+	if done {
+		return "[x]"
+	}
+
+	return "[ ]"
+}
+
+// taskProgress renders the number of finished tasks and the total number of
+// tasks of a ToDo item, e.g. `1/3`.
+func taskProgress(toDo model.ToDo) string {
+	if len(toDo.Tasks) == 0 {
+		return "-"
+	}
+
+	finished := 0
+
+	for _, task := range toDo.Tasks {
+		if task.Done {
+			finished++
+		}
+	}
+
+	return fmt.Sprintf("%d/%d", finished, len(toDo.Tasks))
+}
+
+// formatDue renders the due date of a ToDo item. Due dates at midnight are
+// rendered as date only.
+func formatDue(toDo model.ToDo) string {
+	if toDo.Due == nil {
+		return "-"
+	}
+
+	due := toDo.Due.UTC()
+
+	if due.Hour() == 0 && due.Minute() == 0 && due.Second() == 0 {
+		return due.Format("2006-01-02")
+	}
+
+	return due.Format("2006-01-02 15:04")
+}
@@ -0,0 +1,215 @@
+// Package search provides full-text search primitives: a tokenizer, a simple
+// in-memory inverted index and a function for creating highlighted snippets.
+package search
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Hit represents a document that matches a search query.
+type Hit struct {
+	Key   string
+	Score float64
+}
+
+// Index is an inverted index that maps terms to the documents containing them.
+// Documents are identified by an arbitrary key chosen by the caller.
+type Index struct {
+	postings map[string]map[string]int
+	terms    map[string][]string
+}
+
+// NewIndex creates a new, empty inverted index.
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[string]int),
+		terms:    make(map[string][]string),
+	}
+}
+
+// Add adds a document consisting of the given texts to the index. If there is
+// a document with the same key already, it will be replaced.
+func (i *Index) Add(key string, texts ...string) {
+	i.Remove(key)
+
+	frequencies := make(map[string]int)
+
+	for _, text := range texts {
+		for _, term := range Tokenize(text) {
+			frequencies[term]++
+		}
+	}
+
+	terms := make([]string, 0, len(frequencies))
+
+	for term, frequency := range frequencies {
+		if i.postings[term] == nil {
+			i.postings[term] = make(map[string]int)
+		}
+		i.postings[term][key] = frequency
+		terms = append(terms, term)
+	}
+
+	i.terms[key] = terms
+}
+
+// Remove removes the document with the given key from the index.
+func (i *Index) Remove(key string) {
+	for _, term := range i.terms[key] {
+		delete(i.postings[term], key)
+		if len(i.postings[term]) == 0 {
+			delete(i.postings, term)
+		}
+	}
+
+	delete(i.terms, key)
+}
+
+// Search returns all documents that contain at least one term of the query,
+// ranked by their TF-IDF score in descending order. Documents with the same
+// score are ordered by their key.
+func (i *Index) Search(query string) []Hit {
+	scores := make(map[string]float64)
+	documents := float64(len(i.terms))
+
+	for _, term := range uniqueTerms(Tokenize(query)) {
+		postings := i.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+
+		idf := math.Log(1 + documents/float64(len(postings)))
+
+		for key, frequency := range postings {
+			scores[key] += float64(frequency) * idf
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+
+	for key, score := range scores {
+		hits = append(hits, Hit{Key: key, Score: score})
+	}
+
+	sort.Slice(hits, func(a, b int) bool {
+		if hits[a].Score != hits[b].Score {
+			return hits[a].Score > hits[b].Score
+		}
+		return hits[a].Key < hits[b].Key
+	})
+
+	return hits
+}
+
+// Tokenize splits a text into lower-case terms. All characters that are not a
+// letter or a digit are considered to be separators.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), isSeparator)
+}
+
+// snippetRadius is the number of characters displayed around the first match.
+const snippetRadius = 60
+
+// Snippet returns an excerpt of the text around the first occurrence of one of
+// the query terms. All occurrences of query terms in the excerpt are wrapped in
+// <mark> tags. The remaining text is HTML-escaped, so that the snippet can be
+// safely rendered as HTML.
+//
+// If none of the query terms occurs in the text, the beginning of the text is
+// returned without any highlighting.
+func Snippet(text, query string) string {
+	runes := []rune(strings.TrimSpace(text))
+	terms := make(map[string]bool)
+
+	for _, term := range Tokenize(query) {
+		terms[term] = true
+	}
+
+	type match struct {
+		start, end int
+	}
+
+	var matches []match
+
+	for start := 0; start < len(runes); {
+		if isSeparator(runes[start]) {
+			start++
+			continue
+		}
+
+		end := start
+		for end < len(runes) && !isSeparator(runes[end]) {
+			end++
+		}
+
+		if terms[strings.ToLower(string(runes[start:end]))] {
+			matches = append(matches, match{start: start, end: end})
+		}
+
+		start = end
+	}
+
+	from, to := 0, len(runes)
+
+	if len(matches) > 0 {
+		from = matches[0].start - snippetRadius
+	}
+	if from < 0 {
+		from = 0
+	}
+	if from+2*snippetRadius < to {
+		to = from + 2*snippetRadius
+	}
+
+	var builder strings.Builder
+
+	if from > 0 {
+		builder.WriteString("…")
+	}
+
+	position := from
+
+	for _, m := range matches {
+		if m.start < from || m.end > to {
+			continue
+		}
+
+		builder.WriteString(html.EscapeString(string(runes[position:m.start])))
+		builder.WriteString("<mark>")
+		builder.WriteString(html.EscapeString(string(runes[m.start:m.end])))
+		builder.WriteString("</mark>")
+		position = m.end
+	}
+
+	builder.WriteString(html.EscapeString(string(runes[position:to])))
+
+	if to < len(runes) {
+		builder.WriteString("…")
+	}
+
+	return builder.String()
+}
+
+// isSeparator reports whether r separates two terms.
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// uniqueTerms removes duplicate terms while retaining their order.
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool)
+	unique := make([]string, 0, len(terms))
+
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			unique = append(unique, term)
+		}
+	}
+
+	return unique
+}
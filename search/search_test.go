@@ -0,0 +1,76 @@
+// Package search provides full-text search primitives: a tokenizer, a simple
+// in-memory inverted index and a function for creating highlighted snippets.
+package search
+
+import (
+	"testing"
+)
+
+func TestIndex_Search(t *testing.T) {
+	index := NewIndex()
+
+	index.Add("todo:1", "Release preparation", "Prepare the release of version 2")
+	index.Add("task:1", "Write changelog for the release")
+	index.Add("task:2", "Update dependencies")
+
+	hits := index.Search("Release")
+
+	if len(hits) != 2 {
+		t.Fatalf("expected %d hits, got %d", 2, len(hits))
+	}
+
+	// The ToDo item mentions the release twice and should thus rank higher.
+	if hits[0].Key != "todo:1" {
+		t.Errorf("expected first hit %s, got %s", "todo:1", hits[0].Key)
+	}
+
+	index.Remove("todo:1")
+
+	hits = index.Search("release")
+
+	if len(hits) != 1 {
+		t.Fatalf("expected %d hits, got %d", 1, len(hits))
+	}
+
+	index.Add("task:2", "Update the release dependencies")
+
+	hits = index.Search("dependencies release")
+
+	if len(hits) != 2 || hits[0].Key != "task:2" {
+		t.Errorf("expected task:2 to rank first, got %v", hits)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	tests := map[string]struct {
+		text     string
+		query    string
+		expected string
+	}{
+		"highlighted term": {
+			text:     "Write the changelog",
+			query:    "changelog",
+			expected: "Write the <mark>changelog</mark>",
+		},
+		"case-insensitive match": {
+			text:     "Changelog <draft>",
+			query:    "CHANGELOG",
+			expected: "<mark>Changelog</mark> &lt;draft&gt;",
+		},
+		"no match": {
+			text:     "Write the changelog",
+			query:    "release",
+			expected: "Write the changelog",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			snippet := Snippet(test.text, test.query)
+
+			if snippet != test.expected {
+				t.Errorf("expected snippet %s, got %s", test.expected, snippet)
+			}
+		})
+	}
+}
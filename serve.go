@@ -0,0 +1,96 @@
+// Package main provides the application entrypoint as well as functions for
+// parsing CLI flags and environment variables.
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/server"
+	"github.com/dominikbraun/todo/storage"
+
+	"github.com/spf13/pflag"
+)
+
+// serverConfig stores all configuration values required to run the ToDo app.
+type serverConfig struct {
+	mariaDB           storage.MariaDBConfig
+	attachmentsDir    string
+	serverPort        uint
+	grpcPort          uint
+	adminToken        string
+	idempotencyWindow time.Duration
+	idempotencyLease  time.Duration
+}
+
+// serve runs the ToDo server until an interrupt signal has been received.
+func serve(args []string) error {
+	config, err := parseServerConfig(args)
+	if err != nil {
+		return err
+	}
+
+	mariaDB, err := storage.NewMariaDB(config.mariaDB)
+	if err != nil {
+		return err
+	}
+
+	if err := mariaDB.Initialize(); err != nil {
+		return err
+	}
+
+	fileSystem, err := storage.NewFileSystem(config.attachmentsDir)
+	if err != nil {
+		return err
+	}
+
+	app := core.NewApp(mariaDB, fileSystem)
+	app.SetIdempotencyWindow(config.idempotencyWindow)
+	app.SetIdempotencyLease(config.idempotencyLease)
+	srv := server.New(config.serverPort, config.grpcPort, app, config.adminToken)
+
+	go app.RunWebhookDispatcher(context.Background())
+
+	log.Printf("serving app on port %d and gRPC on port %d\n", config.serverPort, config.grpcPort)
+
+	return srv.Run()
+}
+
+// parseServerConfig parses the server configuration from the flags of the serve
+// command and their TODO_* environment variables.
+func parseServerConfig(args []string) (serverConfig, error) {
+	flags := pflag.NewFlagSet("serve", pflag.ContinueOnError)
+
+	flags.String("mariadb-user", "admin", "The MariaDB user")
+	flags.String("mariadb-password", "admin", "The MariaDB password")
+	flags.String("mariadb-address", "0.0.0.0:3306", "The MariaDB address")
+	flags.String("mariadb-dbname", "todo_app", "The MariaDB database name")
+	flags.String("attachments-dir", "attachments", "The directory for storing attachments")
+	flags.Uint("port", 8000, "The port the server should listen on")
+	flags.Uint("grpc-port", 9000, "The port the gRPC server should listen on")
+	flags.String("admin-token", "", "The token required by the admin endpoints, which are disabled if empty")
+	flags.Duration("idempotency-window", core.DefaultIdempotencyWindow, "How long responses to requests with an Idempotency-Key are stored")
+	flags.Duration("idempotency-lease", core.DefaultIdempotencyLease, "How long an Idempotency-Key is reserved for a request that is being processed")
+
+	config, err := loadConfig(flags, args)
+	if err != nil {
+		return serverConfig{}, err
+	}
+
+	return serverConfig{
+		mariaDB: storage.MariaDBConfig{
+			User:     config.GetString("mariadb-user"),
+			Password: config.GetString("mariadb-password"),
+			Address:  config.GetString("mariadb-address"),
+			DBName:   config.GetString("mariadb-dbname"),
+		},
+		attachmentsDir:    config.GetString("attachments-dir"),
+		serverPort:        config.GetUint("port"),
+		grpcPort:          config.GetUint("grpc-port"),
+		adminToken:        config.GetString("admin-token"),
+		idempotencyWindow: config.GetDuration("idempotency-window"),
+		idempotencyLease:  config.GetDuration("idempotency-lease"),
+	}, nil
+}
@@ -2,6 +2,11 @@
 package server
 
 import (
+	"net/http"
+	"strings"
+
+	"github.com/dominikbraun/todo/controller"
+
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 )
@@ -11,21 +16,122 @@ import (
 //
 // Also, initializeRouter registers any middleware to be used by the router.
 func (s *Server) initializeRouter() {
+	// The WebDAV methods used by CalDAV clients have to be registered before
+	// any handlers are mounted.
+	chi.RegisterMethod("PROPFIND")
+	chi.RegisterMethod("REPORT")
+
 	s.router = chi.NewRouter()
 
 	s.router.Use(
 		middleware.Logger,
-		middleware.RedirectSlashes,
+		redirectSlashes,
+		s.controller.Idempotency(),
 	)
 
 	s.router.Route("/todos", func(r chi.Router) {
 		r.Post("/", s.controller.CreateToDo())
 		r.Get("/", s.controller.GetToDos())
+		r.Post("/batch", s.controller.Batch())
 
 		r.Route("/{id}", func(r chi.Router) {
 			r.Get("/", s.controller.GetToDo())
 			r.Put("/", s.controller.UpdateToDo())
 			r.Delete("/", s.controller.DeleteToDo())
+
+			r.Route("/comments", s.mountCommentRoutes)
+			r.Route("/tasks/{taskID}/comments", s.mountCommentRoutes)
+
+			r.Route("/attachments", func(r chi.Router) {
+				r.Post("/", s.controller.CreateAttachment())
+				r.Get("/", s.controller.GetAttachments())
+				r.Get("/{attachmentID}", s.controller.DownloadAttachment())
+				r.Delete("/{attachmentID}", s.controller.DeleteAttachment())
+			})
+		})
+	})
+
+	s.router.Get("/search", s.controller.Search())
+	s.router.Get("/export/todotxt", s.controller.ExportToDoTxt())
+	s.router.Post("/import/todotxt", s.controller.ImportToDoTxt())
+	s.router.Get("/export/csv", s.controller.ExportCSV())
+	s.router.Post("/import/csv", s.controller.ImportCSV())
+	s.router.Get("/export/ical", s.controller.ExportICal())
+	s.router.Post("/import/ical", s.controller.ImportICal())
+	s.router.Get("/events", s.controller.Events())
+	s.router.Get("/ws", s.controller.WebSocket())
+
+	graphQL := s.controller.GraphQL()
+	s.router.Get("/graphql", graphQL)
+	s.router.Post("/graphql", graphQL)
+
+	s.router.Route("/webhooks", func(r chi.Router) {
+		r.Post("/", s.controller.CreateWebhook())
+		r.Get("/", s.controller.GetWebhooks())
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", s.controller.GetWebhook())
+			r.Delete("/", s.controller.DeleteWebhook())
+			r.Get("/deliveries", s.controller.GetDeliveries())
 		})
 	})
+
+	s.router.Route("/calendar", func(r chi.Router) {
+		r.Post("/feed", s.controller.CreateCalendarFeed())
+		r.Get("/feed", s.controller.GetCalendarFeed())
+		r.Delete("/feed", s.controller.DeleteCalendarFeed())
+		r.Get("/{token}.ics", s.controller.GetCalendarFeedICal())
+	})
+
+	calDAV := s.controller.CalDAV()
+	s.router.Handle("/caldav", calDAV)
+	s.router.Handle("/caldav/*", calDAV)
+	s.router.Handle("/.well-known/caldav", http.RedirectHandler("/caldav/", http.StatusMovedPermanently))
+
+	s.router.Route("/views", func(r chi.Router) {
+		r.Post("/", s.controller.CreateView())
+		r.Get("/", s.controller.GetViews())
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", s.controller.GetView())
+			r.Put("/", s.controller.UpdateView())
+			r.Delete("/", s.controller.DeleteView())
+			r.Get("/todos", s.controller.GetViewToDos())
+		})
+	})
+
+	s.router.Route("/admin", func(r chi.Router) {
+		r.Use(controller.RequireAdminToken(s.adminToken))
+		r.Get("/backup", s.controller.Backup())
+		r.Post("/restore", s.controller.Restore())
+	})
+}
+
+// mountCommentRoutes mounts the routes for managing the comments of a thread.
+// Depending on the parent route, this is the thread of a ToDo item or a task.
+func (s *Server) mountCommentRoutes(r chi.Router) {
+	r.Post("/", s.controller.CreateComment())
+	r.Get("/", s.controller.GetComments())
+
+	r.Route("/{commentID}", func(r chi.Router) {
+		r.Put("/", s.controller.UpdateComment())
+		r.Delete("/", s.controller.DeleteComment())
+	})
+}
+
+// redirectSlashes redirects requests with a trailing slash like the middleware
+// of the same name, except for CalDAV requests: Calendar clients address
+// collections with a trailing slash and don't follow redirects for methods
+// like PROPFIND.
+func redirectSlashes(next http.Handler) http.Handler {
+	redirect := middleware.RedirectSlashes(next)
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if strings.HasPrefix(request.URL.Path, "/caldav/") {
+			next.ServeHTTP(writer, request)
+			return
+		}
+
+		redirect.ServeHTTP(writer, request)
+	})
 }
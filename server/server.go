@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,36 +14,65 @@ import (
 
 	"github.com/dominikbraun/todo/controller"
 	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/todopb"
 
 	"github.com/go-chi/chi"
+	"google.golang.org/grpc"
 )
 
-// Server represents an easy-to-use HTTP server that exposes a REST API.
+// Server represents an easy-to-use HTTP server that exposes a REST API. It also
+// serves the gRPC API on a separate port.
 type Server struct {
-	router     chi.Router
-	internal   *http.Server
-	controller *controller.RESTController
+	router      chi.Router
+	internal    *http.Server
+	controller  *controller.RESTController
+	grpc        *grpc.Server
+	grpcAddress string
+	adminToken  string
 }
 
-// New creates a server that uses the given app instance to handle requests.
-func New(port uint, app *core.App) *Server {
+// New creates a server that uses the given app instance to handle requests. The
+// REST API is served on port, the gRPC API on grpcPort. The admin endpoints
+// require the given admin token and are disabled if it is empty.
+func New(port uint, grpcPort uint, app *core.App, adminToken string) *Server {
 	address := fmt.Sprintf("0.0.0.0:%v", port)
 
+	// All request contexts are canceled on shutdown, which terminates long-lived
+	// requests like event streams that otherwise would block the shutdown.
+	ctx, cancel := context.WithCancel(context.Background())
+
 	server := &Server{
-		internal:   &http.Server{Addr: address},
-		controller: controller.NewRESTController(app),
+		internal: &http.Server{
+			Addr: address,
+			BaseContext: func(net.Listener) context.Context {
+				return ctx
+			},
+		},
+		controller:  controller.NewRESTController(app),
+		grpc:        grpc.NewServer(),
+		grpcAddress: fmt.Sprintf("0.0.0.0:%v", grpcPort),
+		adminToken:  adminToken,
 	}
 
 	server.initializeRouter()
 	server.internal.Handler = server.router
+	server.internal.RegisterOnShutdown(cancel)
+
+	todopb.RegisterToDoServiceServer(server.grpc, controller.NewGRPCController(app))
 
 	return server
 }
 
+// Handler returns the HTTP handler serving the REST API. It allows serving the
+// API with a custom http.Server, e.g. an httptest.Server in tests.
+func (s *Server) Handler() http.Handler {
+	return s.router
+}
+
 // Run starts the server. It will serve requests on the configured address until
 // an interrupt signal has been received, e.g. by pressing Ctrl + C.
 func (s *Server) Run() error {
-	shutdown := make(chan os.Signal)
+	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt)
 
 	go func() {
@@ -52,15 +82,41 @@ func (s *Server) Run() error {
 		}
 	}()
 
+	listener, err := net.Listen("tcp", s.grpcAddress)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.grpc.Serve(listener); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
 	// Wait until an interrupt signal has been received.
 	<-shutdown
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
 
+	// GracefulStop waits for all streams to finish, so streaming calls like
+	// WatchToDos are canceled if they're still running after the timeout.
+	stopped := make(chan struct{})
+
+	go func() {
+		s.grpc.GracefulStop()
+		close(stopped)
+	}()
+
 	if err := s.internal.Shutdown(ctx); err != nil {
 		return err
 	}
 
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.grpc.Stop()
+	}
+
 	return nil
 }
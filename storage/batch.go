@@ -0,0 +1,73 @@
+// Package storage provides a generic storage interface and its implementations.
+package storage
+
+import (
+	"errors"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+// ErrUnknownBatchAction indicates that a batch operation has an action other
+// than BatchCreate, BatchUpdate and BatchDelete. It is also the validation
+// error of API batch operations with an unknown action, whose complete
+// operations are applied as BatchUpdate.
+var ErrUnknownBatchAction = errors.New("op must be one of create, update, delete and complete")
+
+// BatchAction is the kind of change made by a BatchOperation.
+type BatchAction int
+
+const (
+	// BatchCreate creates the ToDo item of the operation like CreateToDo.
+	BatchCreate BatchAction = iota + 1
+
+	// BatchUpdate overwrites the ToDo item with the ID of the operation like
+	// UpdateToDo.
+	BatchUpdate
+
+	// BatchDelete deletes the ToDo item with the ID of the operation like
+	// DeleteToDo.
+	BatchDelete
+)
+
+// BatchOperation is a change of a ToDo item applied by Storage.Batch. The ID
+// is ignored by BatchCreate, and the ToDo item is ignored by BatchDelete.
+type BatchOperation struct {
+	Action BatchAction
+	ID     int64
+	ToDo   model.ToDo
+}
+
+// BatchResult is the outcome of a BatchOperation. ID is the ID of the changed
+// ToDo item, which is the ID of the created item for BatchCreate. Err is the
+// error of the operation, or nil if it has succeeded.
+type BatchResult struct {
+	ID  int64
+	Err error
+}
+
+// applyBatchOperation applies a single operation to the given storage, which
+// may be bound to a transaction.
+func applyBatchOperation(storage Storage, operation BatchOperation) BatchResult {
+	result := BatchResult{ID: operation.ID}
+
+	switch operation.Action {
+	case BatchCreate:
+		// Copy the tasks, since CreateToDo assigns their IDs.
+		toDo := operation.ToDo
+		toDo.Tasks = append([]model.Task(nil), toDo.Tasks...)
+
+		createdToDo, err := storage.CreateToDo(toDo)
+		result.ID, result.Err = createdToDo.ID, err
+	case BatchUpdate:
+		toDo := operation.ToDo
+		toDo.Tasks = append([]model.Task(nil), toDo.Tasks...)
+
+		result.Err = storage.UpdateToDo(operation.ID, toDo)
+	case BatchDelete:
+		result.Err = storage.DeleteToDo(operation.ID)
+	default:
+		result.Err = ErrUnknownBatchAction
+	}
+
+	return result
+}
@@ -0,0 +1,34 @@
+// Package storage provides a generic storage interface and its implementations.
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	// ErrBlobNotFound indicates that a requested blob cannot be found.
+	ErrBlobNotFound = errors.New("requested blob not found")
+
+	// ErrInvalidBlobKey indicates that a blob key contains illegal characters.
+	ErrInvalidBlobKey = errors.New("invalid blob key")
+)
+
+// BlobStore represents a storage backend for binary large objects like file
+// attachments. In contrast to Storage, a BlobStore only stores plain content
+// identified by a key. Any metadata has to be stored in a Storage.
+type BlobStore interface {
+
+	// Put stores the content read from r under the given key and returns the
+	// number of bytes written. An existing blob with that key is overwritten.
+	Put(key string, r io.Reader) (int64, error)
+
+	// Open returns a reader for the blob with the given key. The reader has to
+	// be closed by the caller. In case the blob cannot be found, an error will
+	// be returned.
+	Open(key string) (io.ReadCloser, error)
+
+	// Delete deletes the blob with the given key. Deleting a blob that doesn't
+	// exist is not considered an error.
+	Delete(key string) error
+}
@@ -0,0 +1,97 @@
+// Package storage provides a generic storage interface and its implementations.
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// blobKeyPattern restricts blob keys to characters that are safe to use as a
+// file name, preventing keys from escaping the root directory.
+var blobKeyPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+type fileSystem struct {
+	root string
+}
+
+// NewFileSystem creates a blob store that stores each blob as a file in the
+// given root directory. The directory will be created if it doesn't exist.
+func NewFileSystem(root string) (*fileSystem, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+
+	fileSystem := &fileSystem{
+		root: root,
+	}
+
+	return fileSystem, nil
+}
+
+// Put writes the content read from r to a file named like the key. If writing
+// the content fails, the incomplete file will be removed.
+func (f *fileSystem) Put(key string, r io.Reader) (int64, error) {
+	path, err := f.path(key)
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+
+	written, err := io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(path)
+		return 0, err
+	}
+
+	return written, nil
+}
+
+// Open opens the file for the given key. If the file doesn't exist,
+// ErrBlobNotFound will be returned.
+func (f *fileSystem) Open(key string) (io.ReadCloser, error) {
+	path, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrBlobNotFound
+	}
+
+	return file, err
+}
+
+// Delete removes the file for the given key if it exists.
+func (f *fileSystem) Delete(key string) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// path returns the file path for the given key. If the key is not a valid file
+// name, ErrInvalidBlobKey will be returned.
+func (f *fileSystem) path(key string) (string, error) {
+	if !blobKeyPattern.MatchString(key) {
+		return "", ErrInvalidBlobKey
+	}
+
+	return filepath.Join(f.root, key), nil
+}
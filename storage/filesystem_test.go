@@ -0,0 +1,51 @@
+// Package storage provides a generic storage interface and its implementations.
+package storage
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestFileSystem(t *testing.T) {
+	fileSystem, err := NewFileSystem(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := "Blob content"
+
+	written, err := fileSystem.Put("blob1", strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if written != int64(len(content)) {
+		t.Errorf("expected %d bytes to be written, got %d", len(content), written)
+	}
+
+	reader, err := fileSystem.Open("blob1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storedContent, _ := ioutil.ReadAll(reader)
+	_ = reader.Close()
+
+	if string(storedContent) != content {
+		t.Errorf("expected content %s, got %s", content, storedContent)
+	}
+
+	if err := fileSystem.Delete("blob1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fileSystem.Open("blob1"); !errors.Is(err, ErrBlobNotFound) {
+		t.Fatalf("expected error %v, got %v", ErrBlobNotFound, err)
+	}
+
+	if _, err := fileSystem.Put("../blob1", strings.NewReader(content)); !errors.Is(err, ErrInvalidBlobKey) {
+		t.Fatalf("expected error %v, got %v", ErrInvalidBlobKey, err)
+	}
+}
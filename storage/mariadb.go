@@ -2,12 +2,17 @@
 package storage
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/dominikbraun/todo/filter"
 	"github.com/dominikbraun/todo/model"
 
 	"github.com/Masterminds/squirrel"
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -26,10 +31,21 @@ func (m MariaDBConfig) URI() string {
 
 type mariaDB struct {
 	config        MariaDBConfig
-	db            *sqlx.DB
+	pool          *sqlx.DB
+	db            database
 	isInitialized bool
 }
 
+// database is implemented by *sqlx.DB and *sqlx.Tx, so that the same methods
+// can run their statements directly or as part of a transaction.
+type database interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Queryx(query string, args ...interface{}) (*sqlx.Rows, error)
+	QueryRowx(query string, args ...interface{}) *sqlx.Row
+}
+
 // NewMariaDB creates a new MariaDB connection using the given configuration.
 func NewMariaDB(config MariaDBConfig) (*mariaDB, error) {
 	mariaDB := &mariaDB{
@@ -46,17 +62,22 @@ func NewMariaDB(config MariaDBConfig) (*mariaDB, error) {
 // connect tries to establish a connection to the configured MariaDB host. If
 // MariaDB has already been initialized using Initialize, connect will try to
 // directly connect to the database.
+//
+// The parseTime parameter makes the driver scan DATETIME columns into values
+// of type time.Time, which is required for timestamps like Comment.CreatedAt.
 func (m *mariaDB) connect() error {
 	uri := m.config.URI()
 	if m.isInitialized {
 		uri = uri + m.config.DBName
 	}
+	uri = uri + "?parseTime=true"
 
 	db, err := sqlx.Connect("mysql", uri)
 	if err != nil {
 		return err
 	}
 
+	m.pool = db
 	m.db = db
 	return nil
 }
@@ -64,19 +85,117 @@ func (m *mariaDB) connect() error {
 // Initialize creates the MariaDB database and tables if they don't exist yet.
 func (m *mariaDB) Initialize() error {
 	statements := []string{
-		`CREATE DATABASE IF NOT EXISTS ` + m.config.DBName,
+		`CREATE DATABASE IF NOT EXISTS ` + m.config.DBName + ` CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci`,
 		`USE ` + m.config.DBName,
+		// Databases created by earlier versions use the default character set
+		// of the server, which may not support all Unicode characters.
+		`ALTER DATABASE ` + m.config.DBName + ` CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci`,
 		`CREATE TABLE IF NOT EXISTS todos (
 			id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
 			name VARCHAR(100) NOT NULL,
-			description VARCHAR(500)
+			description VARCHAR(500),
+			done BOOLEAN NOT NULL DEFAULT FALSE,
+			due DATETIME NULL,
+			project VARCHAR(100) NOT NULL DEFAULT '',
+			priority CHAR(1) NOT NULL DEFAULT '',
+			recurrence VARCHAR(255) NOT NULL DEFAULT '',
+			uid VARCHAR(255) NOT NULL DEFAULT '',
+			version BIGINT UNSIGNED NOT NULL DEFAULT 1
 		)`,
+		// Add the columns introduced after the first release to tables that
+		// have been created by an earlier version.
+		`ALTER TABLE todos
+			ADD COLUMN IF NOT EXISTS done BOOLEAN NOT NULL DEFAULT FALSE,
+			ADD COLUMN IF NOT EXISTS due DATETIME NULL,
+			ADD COLUMN IF NOT EXISTS project VARCHAR(100) NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS priority CHAR(1) NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS recurrence VARCHAR(255) NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS uid VARCHAR(255) NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS version BIGINT UNSIGNED NOT NULL DEFAULT 1`,
 		`CREATE TABLE IF NOT EXISTS tasks (
 			id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
 			name VARCHAR(100) NOT NULL,
 			description VARCHAR(500),
+			done BOOLEAN NOT NULL DEFAULT FALSE,
+			uid VARCHAR(255) NOT NULL DEFAULT '',
 			todo_id BIGINT UNSIGNED NOT NULL
 		)`,
+		`ALTER TABLE tasks
+			ADD COLUMN IF NOT EXISTS done BOOLEAN NOT NULL DEFAULT FALSE,
+			ADD COLUMN IF NOT EXISTS uid VARCHAR(255) NOT NULL DEFAULT ''`,
+		`CREATE TABLE IF NOT EXISTS tags (
+			todo_id BIGINT UNSIGNED NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			PRIMARY KEY (todo_id, name)
+		)`,
+		`CREATE TABLE IF NOT EXISTS comments (
+			id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+			todo_id BIGINT UNSIGNED NOT NULL,
+			task_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+			author VARCHAR(100) NOT NULL,
+			body TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS attachments (
+			id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+			todo_id BIGINT UNSIGNED NOT NULL,
+			task_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+			filename VARCHAR(255) NOT NULL,
+			content_type VARCHAR(100) NOT NULL,
+			size BIGINT UNSIGNED NOT NULL,
+			blob_key VARCHAR(100) NOT NULL,
+			created_at DATETIME NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS views (
+			id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+			owner VARCHAR(100) NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			filter VARCHAR(500) NOT NULL,
+			sort VARCHAR(20) NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS webhooks (
+			id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+			url VARCHAR(2000) NOT NULL,
+			secret VARCHAR(100) NOT NULL,
+			events VARCHAR(100) NOT NULL,
+			created_at DATETIME NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS calendar_feeds (
+			owner VARCHAR(100) NOT NULL PRIMARY KEY,
+			token CHAR(64) NOT NULL UNIQUE,
+			filter VARCHAR(500) NOT NULL,
+			created_at DATETIME NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS deliveries (
+			id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+			webhook_id BIGINT UNSIGNED NOT NULL,
+			event_type VARCHAR(20) NOT NULL,
+			payload MEDIUMTEXT NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			attempts INT NOT NULL,
+			response_code INT NOT NULL,
+			last_error VARCHAR(500) NOT NULL,
+			next_attempt_at DATETIME NOT NULL,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL,
+			INDEX deliveries_due (status, next_attempt_at)
+		)`,
+		`CREATE TABLE IF NOT EXISTS idempotency_records (
+			owner VARCHAR(100) NOT NULL,
+			idempotency_key VARCHAR(255) NOT NULL,
+			fingerprint CHAR(64) NOT NULL,
+			status INT NOT NULL,
+			content_type VARCHAR(255) NOT NULL,
+			body MEDIUMBLOB NOT NULL,
+			created_at DATETIME NOT NULL,
+			expires_at DATETIME NOT NULL,
+			PRIMARY KEY (owner, idempotency_key),
+			INDEX idempotency_records_expiry (expires_at)
+		)`,
+		`CREATE FULLTEXT INDEX IF NOT EXISTS todos_fulltext ON todos (name, description)`,
+		`CREATE FULLTEXT INDEX IF NOT EXISTS tasks_fulltext ON tasks (name, description)`,
+		`CREATE FULLTEXT INDEX IF NOT EXISTS comments_fulltext ON comments (body)`,
 	}
 
 	for _, statement := range statements {
@@ -85,20 +204,48 @@ func (m *mariaDB) Initialize() error {
 		}
 	}
 
+	if err := m.convertToUTF8MB4(); err != nil {
+		return err
+	}
+
 	m.isInitialized = true
 
 	// Close the database connection and re-connect directly to the database.
 	// See https://stackoverflow.com/q/19927879 for more information.
-	_ = m.db.Close()
+	_ = m.pool.Close()
 	return m.connect()
 }
 
+// convertToUTF8MB4 converts tables that have been created by an earlier version
+// with another character set to utf8mb4, so that they can store all Unicode
+// characters like emojis. Tables that already use utf8mb4 are skipped, since
+// converting a table copies all of its rows.
+func (m *mariaDB) convertToUTF8MB4() error {
+	var tables []string
+
+	err := m.pool.Select(&tables, `SELECT table_name FROM information_schema.tables
+			WHERE table_schema = ? AND table_type = 'BASE TABLE' AND table_collation <> 'utf8mb4_unicode_ci'
+		UNION SELECT table_name FROM information_schema.columns
+			WHERE table_schema = ? AND collation_name <> 'utf8mb4_unicode_ci'`, m.config.DBName, m.config.DBName)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		if _, err := m.db.Exec("ALTER TABLE `" + table + "` CONVERT TO CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // CreateToDo inserts the given ToDo item, which is expected to not have an ID.
 func (m *mariaDB) CreateToDo(toDo model.ToDo) (model.ToDo, error) {
 	sql, args, _ := squirrel.
 		Insert("todos").
-		Columns("name", "description").
-		Values(toDo.Name, toDo.Description).
+		Columns("name", "description", "done", "due", "project", "priority", "recurrence", "uid").
+		Values(toDo.Name, toDo.Description, toDo.Done, toDo.Due, toDo.Project, toDo.Priority, toDo.Recurrence, toDo.UID).
 		ToSql()
 
 	result, err := m.db.Exec(sql, args...)
@@ -108,6 +255,11 @@ func (m *mariaDB) CreateToDo(toDo model.ToDo) (model.ToDo, error) {
 
 	id, _ := result.LastInsertId()
 	toDo.ID = id
+	toDo.Version = 1
+
+	if err := m.createTagsForToDo(toDo.ID, toDo.Tags); err != nil {
+		return model.ToDo{}, err
+	}
 
 	for i, task := range toDo.Tasks {
 		createdTask, err := m.createTaskForToDo(toDo.ID, task)
@@ -122,12 +274,69 @@ func (m *mariaDB) CreateToDo(toDo model.ToDo) (model.ToDo, error) {
 
 // FindToDos returns all ToDo items stored in the MariaDB database.
 func (m *mariaDB) FindToDos() ([]model.ToDo, error) {
-	sql, _, _ := squirrel.
-		Select("id", "name", "description").
+	return m.findToDos(nil, 0)
+}
+
+// FindToDosByFilter returns all ToDo items matching the filter expression. The
+// expression is compiled to a WHERE condition, see filterCondition.
+func (m *mariaDB) FindToDosByFilter(expression filter.Expression) ([]model.ToDo, error) {
+	return m.findToDos(filterCondition(expression), 0)
+}
+
+// FindToDoPage returns a page of the ToDo items matching the filter expression.
+// The page is limited by the database, and the matching items are counted by a
+// separate query.
+func (m *mariaDB) FindToDoPage(expression filter.Expression, afterID int64, limit int) ([]model.ToDo, int, error) {
+	var (
+		count     = squirrel.Select("COUNT(*)").From("todos")
+		condition = squirrel.And{squirrel.Gt{"id": afterID}}
+	)
+
+	if expression != nil {
+		count = count.Where(filterCondition(expression))
+		condition = append(condition, filterCondition(expression))
+	}
+
+	sql, args, _ := count.ToSql()
+
+	var total int
+
+	if err := m.db.QueryRow(sql, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	if limit <= 0 {
+		return make([]model.ToDo, 0), total, nil
+	}
+
+	toDos, err := m.findToDos(condition, uint64(limit))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return toDos, total, nil
+}
+
+// findToDos returns all ToDo items matching the given condition ordered by ID.
+// If the condition is nil, all ToDo items will be returned. A limit other than
+// 0 restricts the number of returned items.
+func (m *mariaDB) findToDos(condition squirrel.Sqlizer, limit uint64) ([]model.ToDo, error) {
+	query := squirrel.
+		Select("id", "name", "description", "done", "due", "project", "priority", "recurrence", "uid", "version").
 		From("todos").
-		ToSql()
+		OrderBy("id")
+
+	if condition != nil {
+		query = query.Where(condition)
+	}
 
-	rows, err := m.db.Queryx(sql)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	sql, args, _ := query.ToSql()
+
+	rows, err := m.db.Queryx(sql, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -140,15 +349,13 @@ func (m *mariaDB) FindToDos() ([]model.ToDo, error) {
 			return nil, err
 		}
 
-		tasks, err := m.findTasksByToDoID(toDo.ID)
-		if err != nil {
-			return nil, err
-		}
-
-		toDo.Tasks = tasks
 		toDos = append(toDos, toDo)
 	}
 
+	if err := m.loadTagsAndTasks(toDos); err != nil {
+		return nil, err
+	}
+
 	return toDos, nil
 }
 
@@ -156,7 +363,7 @@ func (m *mariaDB) FindToDos() ([]model.ToDo, error) {
 // if it was found. Otherwise, ErrToDoNotFound will be returned.
 func (m *mariaDB) FindToDoByID(id int64) (model.ToDo, error) {
 	sql, args, _ := squirrel.
-		Select("id", "name", "description").
+		Select("id", "name", "description", "done", "due", "project", "priority", "recurrence", "uid", "version").
 		From("todos").
 		Where(squirrel.Eq{"id": id}).
 		ToSql()
@@ -168,14 +375,13 @@ func (m *mariaDB) FindToDoByID(id int64) (model.ToDo, error) {
 		return model.ToDo{}, ErrToDoNotFound
 	}
 
-	tasks, err := m.findTasksByToDoID(toDo.ID)
-	if err != nil {
+	toDos := []model.ToDo{toDo}
+
+	if err := m.loadTagsAndTasks(toDos); err != nil {
 		return model.ToDo{}, err
 	}
 
-	toDo.Tasks = tasks
-
-	return toDo, nil
+	return toDos[0], nil
 }
 
 // UpdateToDo overwrites a stored ToDo item with the provided ToDo instance. If
@@ -199,6 +405,38 @@ func (m *mariaDB) UpdateToDo(id int64, toDo model.ToDo) error {
 		return err
 	}
 
+	// Update the item itself first, so that the tasks remain untouched if the
+	// version doesn't match. Incrementing the version guarantees that a row is
+	// affected if the item has been found.
+	condition := squirrel.And{squirrel.Eq{"id": id}}
+
+	if toDo.Version != 0 {
+		condition = append(condition, squirrel.Eq{"version": toDo.Version})
+	}
+
+	sql, args, _ := squirrel.
+		Update("todos").
+		Set("name", toDo.Name).
+		Set("description", toDo.Description).
+		Set("done", toDo.Done).
+		Set("due", toDo.Due).
+		Set("project", toDo.Project).
+		Set("priority", toDo.Priority).
+		Set("recurrence", toDo.Recurrence).
+		Set("uid", toDo.UID).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(condition).
+		ToSql()
+
+	result, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrVersionConflict
+	}
+
 	taskIDs := make([]int64, 0)
 
 	for _, task := range toDo.Tasks {
@@ -209,6 +447,8 @@ func (m *mariaDB) UpdateToDo(id int64, toDo model.ToDo) error {
 				Update("tasks").
 				Set("name", task.Name).
 				Set("description", task.Description).
+				Set("done", task.Done).
+				Set("uid", task.UID).
 				Where(squirrel.Eq{"id": task.ID}).
 				ToSql()
 
@@ -219,9 +459,24 @@ func (m *mariaDB) UpdateToDo(id int64, toDo model.ToDo) error {
 		}
 	}
 
+	// Delete the comments of all tasks that are about to be deleted so that
+	// they don't remain in the database without a task they belong to.
+	sql, args, _ = squirrel.
+		Delete("comments").
+		Where(squirrel.And{
+			squirrel.Eq{"todo_id": id},
+			squirrel.NotEq{"task_id": 0},
+			squirrel.NotEq{"task_id": taskIDs},
+		}).
+		ToSql()
+
+	if _, err := m.db.Exec(sql, args...); err != nil {
+		return err
+	}
+
 	// Delete all tasks that are not listed in the ToDo item, i.e. all tasks
 	// that exist in the database but have not just been updated.
-	sql, args, _ := squirrel.
+	sql, args, _ = squirrel.
 		Delete("tasks").
 		Where(squirrel.And{
 			squirrel.Eq{"todo_id": id},
@@ -235,11 +490,11 @@ func (m *mariaDB) UpdateToDo(id int64, toDo model.ToDo) error {
 
 	insert := squirrel.
 		Insert("tasks").
-		Columns("name", "description", "todo_id")
+		Columns("name", "description", "done", "uid", "todo_id")
 
 	for _, task := range toDo.Tasks {
 		if task.ID == 0 {
-			insert = insert.Values(task.Name, task.Description, id)
+			insert = insert.Values(task.Name, task.Description, task.Done, task.UID, id)
 		}
 	}
 
@@ -252,19 +507,12 @@ func (m *mariaDB) UpdateToDo(id int64, toDo model.ToDo) error {
 		}
 	}
 
-	sql, args, _ = squirrel.
-		Update("todos").
-		Set("name", toDo.Name).
-		Set("description", toDo.Description).
-		Where(squirrel.Eq{"id": id}).
-		ToSql()
-
-	_, err := m.db.Exec(sql, args...)
-	if err != nil {
+	// Tags have no identity of their own, so they can simply be replaced.
+	if err := m.deleteTagsOfToDo(id); err != nil {
 		return err
 	}
 
-	return nil
+	return m.createTagsForToDo(id, toDo.Tags)
 }
 
 // DeleteToDo deletes the ToDo item with the given ID. If the ToDo item cannot
@@ -275,7 +523,7 @@ func (m *mariaDB) DeleteToDo(id int64) error {
 	}
 
 	sql, args, _ := squirrel.
-		Delete("tasks").
+		Delete("attachments").
 		Where(squirrel.Eq{"todo_id": id}).
 		ToSql()
 
@@ -284,6 +532,30 @@ func (m *mariaDB) DeleteToDo(id int64) error {
 		return err
 	}
 
+	sql, args, _ = squirrel.
+		Delete("comments").
+		Where(squirrel.Eq{"todo_id": id}).
+		ToSql()
+
+	_, err = m.db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	sql, args, _ = squirrel.
+		Delete("tasks").
+		Where(squirrel.Eq{"todo_id": id}).
+		ToSql()
+
+	_, err = m.db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if err := m.deleteTagsOfToDo(id); err != nil {
+		return err
+	}
+
 	sql, args, _ = squirrel.
 		Delete("todos").
 		Where(squirrel.Eq{"id": id}).
@@ -297,12 +569,155 @@ func (m *mariaDB) DeleteToDo(id int64) error {
 	return nil
 }
 
+// Batch applies the operations in order. Atomic batches run in a transaction
+// using a copy of the storage, which is rolled back if an operation fails.
+func (m *mariaDB) Batch(operations []BatchOperation, atomic bool) ([]BatchResult, error) {
+	results := make([]BatchResult, 0, len(operations))
+
+	if !atomic {
+		for _, operation := range operations {
+			results = append(results, applyBatchOperation(m, operation))
+		}
+		return results, nil
+	}
+
+	tx, err := m.pool.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := *m
+	transaction.db = tx
+
+	for _, operation := range operations {
+		result := applyBatchOperation(&transaction, operation)
+		results = append(results, result)
+
+		if result.Err != nil {
+			_ = tx.Rollback()
+			return results, result.Err
+		}
+	}
+
+	return results, tx.Commit()
+}
+
+// loadTagsAndTasks loads the tags and tasks of the given ToDo items. Instead of
+// querying them for each item separately, the tags and tasks of all items are
+// loaded with a single query each.
+func (m *mariaDB) loadTagsAndTasks(toDos []model.ToDo) error {
+	if len(toDos) == 0 {
+		return nil
+	}
+
+	toDoIDs := make([]int64, len(toDos))
+
+	for i, toDo := range toDos {
+		toDoIDs[i] = toDo.ID
+	}
+
+	tags, err := m.findTagsByToDoIDs(toDoIDs)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := m.findTasksByToDoIDs(toDoIDs)
+	if err != nil {
+		return err
+	}
+
+	for i, toDo := range toDos {
+		toDos[i].Tags = tags[toDo.ID]
+		toDos[i].Tasks = tasks[toDo.ID]
+
+		if toDos[i].Tasks == nil {
+			toDos[i].Tasks = make([]model.Task, 0)
+		}
+	}
+
+	return nil
+}
+
+// createTagsForToDo inserts the given tags for the given ToDo ID.
+func (m *mariaDB) createTagsForToDo(toDoID int64, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	insert := squirrel.
+		Insert("tags").
+		Columns("todo_id", "name")
+
+	for _, tag := range tags {
+		insert = insert.Values(toDoID, tag)
+	}
+
+	sql, args, _ := insert.ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// findTagsByToDoIDs returns the tags of the given ToDo IDs in alphabetical
+// order, grouped by ToDo ID. ToDo items without tags have no entry, so that their
+// tags are nil just like for an unmarshalled ToDo item without tags.
+func (m *mariaDB) findTagsByToDoIDs(toDoIDs []int64) (map[int64][]string, error) {
+	sql, args, _ := squirrel.
+		Select("todo_id", "name").
+		From("tags").
+		Where(squirrel.Eq{"todo_id": toDoIDs}).
+		OrderBy("name").
+		ToSql()
+
+	rows, err := m.db.Query(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[int64][]string)
+
+	for rows.Next() {
+		var (
+			toDoID int64
+			tag    string
+		)
+
+		if err := rows.Scan(&toDoID, &tag); err != nil {
+			return nil, err
+		}
+
+		tags[toDoID] = append(tags[toDoID], tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// deleteTagsOfToDo deletes all tags of the given ToDo ID.
+func (m *mariaDB) deleteTagsOfToDo(toDoID int64) error {
+	sql, args, _ := squirrel.
+		Delete("tags").
+		Where(squirrel.Eq{"todo_id": toDoID}).
+		ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // createTaskForToDo inserts a task that references the given ToDo ID.
 func (m *mariaDB) createTaskForToDo(toDoId int64, task model.Task) (model.Task, error) {
 	sql, args, _ := squirrel.
 		Insert("tasks").
-		Columns("name", "description", "todo_id").
-		Values(task.Name, task.Description, toDoId).
+		Columns("name", "description", "done", "uid", "todo_id").
+		Values(task.Name, task.Description, task.Done, task.UID, toDoId).
 		ToSql()
 
 	result, err := m.db.Exec(sql, args...)
@@ -316,12 +731,67 @@ func (m *mariaDB) createTaskForToDo(toDoId int64, task model.Task) (model.Task,
 	return task, nil
 }
 
-// findTasksByToDoID returns all tasks that reference the given ToDo ID.
-func (m *mariaDB) findTasksByToDoID(toDoID int64) ([]model.Task, error) {
+// findTasksByToDoIDs returns the tasks that reference the given ToDo IDs,
+// grouped by ToDo ID.
+func (m *mariaDB) findTasksByToDoIDs(toDoIDs []int64) (map[int64][]model.Task, error) {
 	sql, args, _ := squirrel.
-		Select("id", "name", "description").
+		Select("id", "name", "description", "done", "uid", "todo_id").
 		From("tasks").
-		Where(squirrel.Eq{"todo_id": toDoID}).
+		Where(squirrel.Eq{"todo_id": toDoIDs}).
+		OrderBy("id").
+		ToSql()
+
+	rows, err := m.db.Query(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := make(map[int64][]model.Task)
+
+	for rows.Next() {
+		var (
+			task   model.Task
+			toDoID int64
+		)
+
+		if err := rows.Scan(&task.ID, &task.Name, &task.Description, &task.Done, &task.UID, &toDoID); err != nil {
+			return nil, err
+		}
+
+		tasks[toDoID] = append(tasks[toDoID], task)
+	}
+
+	return tasks, rows.Err()
+}
+
+// CreateComment inserts the given comment, which is expected to not have an ID.
+func (m *mariaDB) CreateComment(comment model.Comment) (model.Comment, error) {
+	sql, args, _ := squirrel.
+		Insert("comments").
+		Columns("todo_id", "task_id", "author", "body", "created_at", "updated_at").
+		Values(comment.ToDoID, comment.TaskID, comment.Author, comment.Body, comment.CreatedAt, comment.UpdatedAt).
+		ToSql()
+
+	result, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return model.Comment{}, err
+	}
+
+	id, _ := result.LastInsertId()
+	comment.ID = id
+
+	return comment, nil
+}
+
+// FindComments returns all comments for the given ToDo ID and task ID ordered
+// by their auto-incremented ID, which equals their creation order.
+func (m *mariaDB) FindComments(toDoID, taskID int64) ([]model.Comment, error) {
+	sql, args, _ := squirrel.
+		Select("id", "todo_id", "task_id", "author", "body", "created_at", "updated_at").
+		From("comments").
+		Where(squirrel.Eq{"todo_id": toDoID, "task_id": taskID}).
+		OrderBy("id").
 		ToSql()
 
 	rows, err := m.db.Queryx(sql, args...)
@@ -329,18 +799,1090 @@ func (m *mariaDB) findTasksByToDoID(toDoID int64) ([]model.Task, error) {
 		return nil, err
 	}
 
-	tasks := make([]model.Task, 0)
+	comments := make([]model.Comment, 0)
 
 	for rows.Next() {
-		var task model.Task
-		if err := rows.StructScan(&task); err != nil {
+		var comment model.Comment
+		if err := rows.StructScan(&comment); err != nil {
 			return nil, err
 		}
 
-		tasks = append(tasks, task)
+		comments = append(comments, comment)
+	}
+
+	return comments, nil
+}
+
+// FindCommentsByToDoIDs returns all comments of the given ToDo items and their
+// tasks, ordered by their ID.
+func (m *mariaDB) FindCommentsByToDoIDs(toDoIDs []int64) ([]model.Comment, error) {
+	comments := make([]model.Comment, 0)
+
+	if len(toDoIDs) == 0 {
+		return comments, nil
+	}
+
+	sql, args, _ := squirrel.
+		Select("id", "todo_id", "task_id", "author", "body", "created_at", "updated_at").
+		From("comments").
+		Where(squirrel.Eq{"todo_id": toDoIDs}).
+		OrderBy("id").
+		ToSql()
+
+	rows, err := m.db.Queryx(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var comment model.Comment
+		if err := rows.StructScan(&comment); err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, comment)
+	}
+
+	return comments, nil
+}
+
+// FindCommentByID looks for a comment with the provided ID and returns that
+// comment if it was found. Otherwise, ErrCommentNotFound will be returned.
+func (m *mariaDB) FindCommentByID(id int64) (model.Comment, error) {
+	sql, args, _ := squirrel.
+		Select("id", "todo_id", "task_id", "author", "body", "created_at", "updated_at").
+		From("comments").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+
+	var comment model.Comment
+
+	err := m.db.QueryRowx(sql, args...).StructScan(&comment)
+	if err != nil {
+		return model.Comment{}, ErrCommentNotFound
+	}
+
+	return comment, nil
+}
+
+// UpdateComment overwrites a stored comment with the provided comment. If the
+// requested comment cannot be found, ErrCommentNotFound will be returned.
+func (m *mariaDB) UpdateComment(id int64, comment model.Comment) error {
+	if _, err := m.FindCommentByID(id); err != nil {
+		return err
+	}
+
+	sql, args, _ := squirrel.
+		Update("comments").
+		Set("author", comment.Author).
+		Set("body", comment.Body).
+		Set("updated_at", comment.UpdatedAt).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteComment deletes the comment with the given ID. If the comment cannot
+// be found, ErrCommentNotFound will be returned.
+func (m *mariaDB) DeleteComment(id int64) error {
+	if _, err := m.FindCommentByID(id); err != nil {
+		return err
+	}
+
+	sql, args, _ := squirrel.
+		Delete("comments").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateAttachment inserts the given attachment metadata, which is expected to
+// not have an ID.
+func (m *mariaDB) CreateAttachment(attachment model.Attachment) (model.Attachment, error) {
+	sql, args, _ := squirrel.
+		Insert("attachments").
+		Columns("todo_id", "task_id", "filename", "content_type", "size", "blob_key", "created_at").
+		Values(attachment.ToDoID, attachment.TaskID, attachment.Filename, attachment.ContentType,
+			attachment.Size, attachment.BlobKey, attachment.CreatedAt).
+		ToSql()
+
+	result, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return model.Attachment{}, err
+	}
+
+	id, _ := result.LastInsertId()
+	attachment.ID = id
+
+	return attachment, nil
+}
+
+// FindAttachments returns the metadata of all attachments of the given ToDo
+// item, ordered by their ID.
+func (m *mariaDB) FindAttachments(toDoID int64) ([]model.Attachment, error) {
+	sql, args, _ := squirrel.
+		Select("id", "todo_id", "task_id", "filename", "content_type", "size", "blob_key", "created_at").
+		From("attachments").
+		Where(squirrel.Eq{"todo_id": toDoID}).
+		OrderBy("id").
+		ToSql()
+
+	rows, err := m.db.Queryx(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := make([]model.Attachment, 0)
+
+	for rows.Next() {
+		var attachment model.Attachment
+		if err := rows.StructScan(&attachment); err != nil {
+			return nil, err
+		}
+
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, nil
+}
+
+// FindAttachmentsByToDoIDs returns the metadata of all attachments of the given
+// ToDo items and their tasks, ordered by their ID.
+func (m *mariaDB) FindAttachmentsByToDoIDs(toDoIDs []int64) ([]model.Attachment, error) {
+	attachments := make([]model.Attachment, 0)
+
+	if len(toDoIDs) == 0 {
+		return attachments, nil
+	}
+
+	sql, args, _ := squirrel.
+		Select("id", "todo_id", "task_id", "filename", "content_type", "size", "blob_key", "created_at").
+		From("attachments").
+		Where(squirrel.Eq{"todo_id": toDoIDs}).
+		OrderBy("id").
+		ToSql()
+
+	rows, err := m.db.Queryx(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var attachment model.Attachment
+		if err := rows.StructScan(&attachment); err != nil {
+			return nil, err
+		}
+
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, nil
+}
+
+// FindAttachmentByID looks for the metadata of the attachment with the given ID
+// and returns it if it was found. Otherwise, ErrAttachmentNotFound will be
+// returned.
+func (m *mariaDB) FindAttachmentByID(id int64) (model.Attachment, error) {
+	sql, args, _ := squirrel.
+		Select("id", "todo_id", "task_id", "filename", "content_type", "size", "blob_key", "created_at").
+		From("attachments").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+
+	var attachment model.Attachment
+
+	err := m.db.QueryRowx(sql, args...).StructScan(&attachment)
+	if err != nil {
+		return model.Attachment{}, ErrAttachmentNotFound
+	}
+
+	return attachment, nil
+}
+
+// DeleteAttachment deletes the metadata of the attachment with the given ID. If
+// the attachment cannot be found, ErrAttachmentNotFound will be returned.
+func (m *mariaDB) DeleteAttachment(id int64) error {
+	if _, err := m.FindAttachmentByID(id); err != nil {
+		return err
+	}
+
+	sql, args, _ := squirrel.
+		Delete("attachments").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateView inserts the given view, which is expected to not have an ID.
+func (m *mariaDB) CreateView(view model.View) (model.View, error) {
+	sql, args, _ := squirrel.
+		Insert("views").
+		Columns("owner", "name", "filter", "sort").
+		Values(view.Owner, view.Name, view.Filter, view.Sort).
+		ToSql()
+
+	result, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return model.View{}, err
+	}
+
+	id, _ := result.LastInsertId()
+	view.ID = id
+
+	return view, nil
+}
+
+// FindViews returns all views owned by the given user ordered by their ID.
+func (m *mariaDB) FindViews(owner string) ([]model.View, error) {
+	return m.findViews(squirrel.Eq{"owner": owner})
+}
+
+// FindAllViews returns the views of all users ordered by their ID.
+func (m *mariaDB) FindAllViews() ([]model.View, error) {
+	return m.findViews(nil)
+}
+
+// findViews returns the views matching the given condition ordered by their
+// ID. A nil condition matches all views.
+func (m *mariaDB) findViews(condition squirrel.Sqlizer) ([]model.View, error) {
+	query := squirrel.
+		Select("id", "owner", "name", "filter", "sort").
+		From("views").
+		OrderBy("id")
+
+	if condition != nil {
+		query = query.Where(condition)
+	}
+
+	sql, args, _ := query.ToSql()
+
+	rows, err := m.db.Queryx(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]model.View, 0)
+
+	for rows.Next() {
+		var view model.View
+		if err := rows.StructScan(&view); err != nil {
+			return nil, err
+		}
+
+		views = append(views, view)
+	}
+
+	return views, nil
+}
+
+// FindViewByID looks for a view with the provided ID and returns that view if
+// it was found. Otherwise, ErrViewNotFound will be returned.
+func (m *mariaDB) FindViewByID(id int64) (model.View, error) {
+	sql, args, _ := squirrel.
+		Select("id", "owner", "name", "filter", "sort").
+		From("views").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+
+	var view model.View
+
+	err := m.db.QueryRowx(sql, args...).StructScan(&view)
+	if err != nil {
+		return model.View{}, ErrViewNotFound
+	}
+
+	return view, nil
+}
+
+// UpdateView overwrites a stored view with the provided view. If the requested
+// view cannot be found, ErrViewNotFound will be returned.
+func (m *mariaDB) UpdateView(id int64, view model.View) error {
+	if _, err := m.FindViewByID(id); err != nil {
+		return err
+	}
+
+	sql, args, _ := squirrel.
+		Update("views").
+		Set("owner", view.Owner).
+		Set("name", view.Name).
+		Set("filter", view.Filter).
+		Set("sort", view.Sort).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteView deletes the view with the given ID. If the view cannot be found,
+// ErrViewNotFound will be returned.
+func (m *mariaDB) DeleteView(id int64) error {
+	if _, err := m.FindViewByID(id); err != nil {
+		return err
+	}
+
+	sql, args, _ := squirrel.
+		Delete("views").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateWebhook inserts the given webhook, which is expected to not have an ID.
+func (m *mariaDB) CreateWebhook(webhook model.Webhook) (model.Webhook, error) {
+	sql, args, _ := squirrel.
+		Insert("webhooks").
+		Columns("url", "secret", "events", "created_at").
+		Values(webhook.URL, webhook.Secret, joinEventTypes(webhook.Events), webhook.CreatedAt).
+		ToSql()
+
+	result, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+
+	id, _ := result.LastInsertId()
+	webhook.ID = id
+
+	return webhook, nil
+}
+
+// FindWebhooks returns all webhooks ordered by their ID.
+func (m *mariaDB) FindWebhooks() ([]model.Webhook, error) {
+	sql, args, _ := squirrel.
+		Select("id", "url", "secret", "events", "created_at").
+		From("webhooks").
+		OrderBy("id").
+		ToSql()
+
+	rows, err := m.db.Query(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]model.Webhook, 0)
+
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, nil
+}
+
+// FindWebhookByID looks for a webhook with the provided ID and returns that
+// webhook if it was found. Otherwise, ErrWebhookNotFound will be returned.
+func (m *mariaDB) FindWebhookByID(id int64) (model.Webhook, error) {
+	sql, args, _ := squirrel.
+		Select("id", "url", "secret", "events", "created_at").
+		From("webhooks").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+
+	webhook, err := scanWebhook(m.db.QueryRow(sql, args...))
+	if err != nil {
+		return model.Webhook{}, ErrWebhookNotFound
+	}
+
+	return webhook, nil
+}
+
+// DeleteWebhook deletes the webhook with the given ID and its deliveries. If
+// the webhook cannot be found, ErrWebhookNotFound will be returned.
+func (m *mariaDB) DeleteWebhook(id int64) error {
+	if _, err := m.FindWebhookByID(id); err != nil {
+		return err
+	}
+
+	sql, args, _ := squirrel.
+		Delete("deliveries").
+		Where(squirrel.Eq{"webhook_id": id}).
+		ToSql()
+
+	if _, err := m.db.Exec(sql, args...); err != nil {
+		return err
+	}
+
+	sql, args, _ = squirrel.
+		Delete("webhooks").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateDelivery inserts the given delivery, which is expected to not have an
+// ID.
+func (m *mariaDB) CreateDelivery(delivery model.Delivery) (model.Delivery, error) {
+	sql, args, _ := squirrel.
+		Insert("deliveries").
+		Columns("webhook_id", "event_type", "payload", "status", "attempts", "response_code",
+			"last_error", "next_attempt_at", "created_at", "updated_at").
+		Values(delivery.WebhookID, delivery.EventType, delivery.Payload, delivery.Status, delivery.Attempts,
+			delivery.ResponseCode, delivery.LastError, delivery.NextAttemptAt, delivery.CreatedAt, delivery.UpdatedAt).
+		ToSql()
+
+	result, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return model.Delivery{}, err
+	}
+
+	id, _ := result.LastInsertId()
+	delivery.ID = id
+
+	return delivery, nil
+}
+
+// FindDeliveries returns all deliveries of the given webhook ordered by their
+// ID.
+func (m *mariaDB) FindDeliveries(webhookID int64) ([]model.Delivery, error) {
+	return m.findDeliveries(squirrel.Eq{"webhook_id": webhookID}, "id", 0)
+}
+
+// FindDueDeliveries returns up to limit pending deliveries that are due at the
+// given time, ordered by their next attempt and ID.
+func (m *mariaDB) FindDueDeliveries(due time.Time, limit int) ([]model.Delivery, error) {
+	condition := squirrel.And{
+		squirrel.Eq{"status": model.DeliveryPending},
+		squirrel.LtOrEq{"next_attempt_at": due},
+	}
+
+	return m.findDeliveries(condition, "next_attempt_at, id", uint64(limit))
+}
+
+// findDeliveries returns all deliveries matching the given condition in the
+// given order. A limit of 0 returns all deliveries.
+func (m *mariaDB) findDeliveries(condition squirrel.Sqlizer, orderBy string, limit uint64) ([]model.Delivery, error) {
+	query := squirrel.
+		Select("id", "webhook_id", "event_type", "payload", "status", "attempts", "response_code",
+			"last_error", "next_attempt_at", "created_at", "updated_at").
+		From("deliveries").
+		Where(condition).
+		OrderBy(orderBy)
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	sql, args, _ := query.ToSql()
+
+	rows, err := m.db.Queryx(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]model.Delivery, 0)
+
+	for rows.Next() {
+		var delivery model.Delivery
+		if err := rows.StructScan(&delivery); err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+// UpdateDelivery overwrites a stored delivery with the provided delivery. If
+// the requested delivery cannot be found, ErrDeliveryNotFound will be returned.
+func (m *mariaDB) UpdateDelivery(id int64, delivery model.Delivery) error {
+	sql, args, _ := squirrel.
+		Select("COUNT(*)").
+		From("deliveries").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+
+	var count int
+
+	if err := m.db.QueryRow(sql, args...).Scan(&count); err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return ErrDeliveryNotFound
+	}
+
+	sql, args, _ = squirrel.
+		Update("deliveries").
+		Set("status", delivery.Status).
+		Set("attempts", delivery.Attempts).
+		Set("response_code", delivery.ResponseCode).
+		Set("last_error", delivery.LastError).
+		Set("next_attempt_at", delivery.NextAttemptAt).
+		Set("updated_at", delivery.UpdatedAt).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// scanWebhook scans a webhook row, whose event types are stored as a comma-
+// separated list.
+func scanWebhook(row interface{ Scan(dest ...interface{}) error }) (model.Webhook, error) {
+	var (
+		webhook model.Webhook
+		events  string
+	)
+
+	if err := row.Scan(&webhook.ID, &webhook.URL, &webhook.Secret, &events, &webhook.CreatedAt); err != nil {
+		return model.Webhook{}, err
+	}
+
+	if events != "" {
+		for _, eventType := range strings.Split(events, ",") {
+			webhook.Events = append(webhook.Events, model.EventType(eventType))
+		}
+	}
+
+	return webhook, nil
+}
+
+// joinEventTypes joins event types to a comma-separated list.
+func joinEventTypes(eventTypes []model.EventType) string {
+	values := make([]string, len(eventTypes))
+
+	for i, eventType := range eventTypes {
+		values[i] = string(eventType)
+	}
+
+	return strings.Join(values, ",")
+}
+
+// SaveCalendarFeed inserts the given calendar feed or replaces the existing
+// feed of the same owner.
+func (m *mariaDB) SaveCalendarFeed(feed model.CalendarFeed) error {
+	sql, args, _ := squirrel.
+		Replace("calendar_feeds").
+		Columns("owner", "token", "filter", "created_at").
+		Values(feed.Owner, feed.Token, feed.Filter, feed.CreatedAt).
+		ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindCalendarFeed returns the calendar feed of the given owner. If the owner
+// has no feed, ErrCalendarFeedNotFound will be returned.
+func (m *mariaDB) FindCalendarFeed(owner string) (model.CalendarFeed, error) {
+	return m.findCalendarFeed(squirrel.Eq{"owner": owner})
+}
+
+// FindCalendarFeedByToken looks for a calendar feed with the provided token.
+// If the feed cannot be found, ErrCalendarFeedNotFound will be returned.
+func (m *mariaDB) FindCalendarFeedByToken(token string) (model.CalendarFeed, error) {
+	return m.findCalendarFeed(squirrel.Eq{"token": token})
+}
+
+// findCalendarFeed returns the calendar feed matching the given condition.
+func (m *mariaDB) findCalendarFeed(condition squirrel.Eq) (model.CalendarFeed, error) {
+	sql, args, _ := squirrel.
+		Select("owner", "token", "filter", "created_at").
+		From("calendar_feeds").
+		Where(condition).
+		ToSql()
+
+	var feed model.CalendarFeed
+
+	err := m.db.QueryRowx(sql, args...).StructScan(&feed)
+	if err != nil {
+		return model.CalendarFeed{}, ErrCalendarFeedNotFound
+	}
+
+	return feed, nil
+}
+
+// DeleteCalendarFeed deletes the calendar feed of the given owner. If the owner
+// has no feed, ErrCalendarFeedNotFound will be returned.
+func (m *mariaDB) DeleteCalendarFeed(owner string) error {
+	if _, err := m.FindCalendarFeed(owner); err != nil {
+		return err
+	}
+
+	sql, args, _ := squirrel.
+		Delete("calendar_feeds").
+		Where(squirrel.Eq{"owner": owner}).
+		ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindCalendarFeeds returns the calendar feeds of all users ordered by their
+// owner.
+func (m *mariaDB) FindCalendarFeeds() ([]model.CalendarFeed, error) {
+	sql, args, _ := squirrel.
+		Select("owner", "token", "filter", "created_at").
+		From("calendar_feeds").
+		OrderBy("owner").
+		ToSql()
+
+	rows, err := m.db.Queryx(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	feeds := make([]model.CalendarFeed, 0)
+
+	for rows.Next() {
+		var feed model.CalendarFeed
+		if err := rows.StructScan(&feed); err != nil {
+			return nil, err
+		}
+
+		feeds = append(feeds, feed)
+	}
+
+	return feeds, nil
+}
+
+// idempotencyColumns are the columns of the idempotency_records table.
+var idempotencyColumns = []string{
+	"owner", "idempotency_key", "fingerprint", "status", "content_type", "body", "created_at", "expires_at",
+}
+
+// errDuplicateEntry is the number of the MySQL error raised when an insert
+// violates a primary key or unique index.
+const errDuplicateEntry = 1062
+
+// CreateIdempotencyRecord inserts the given record. If a record with the same
+// owner and key exists, ErrIdempotencyKeyExists will be returned.
+func (m *mariaDB) CreateIdempotencyRecord(record model.IdempotencyRecord) error {
+	sql, args, _ := squirrel.
+		Insert("idempotency_records").
+		Columns(idempotencyColumns...).
+		Values(record.Owner, record.Key, record.Fingerprint, record.Status, record.ContentType,
+			nonNilBytes(record.Body), record.CreatedAt, record.ExpiresAt).
+		ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+
+	var mysqlErr *mysql.MySQLError
+
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry {
+		return ErrIdempotencyKeyExists
+	}
+
+	return err
+}
+
+// FindIdempotencyRecord returns the record with the given owner and key. If the
+// record cannot be found, ErrIdempotencyRecordNotFound will be returned.
+func (m *mariaDB) FindIdempotencyRecord(owner, key string) (model.IdempotencyRecord, error) {
+	sql, args, _ := squirrel.
+		Select(idempotencyColumns...).
+		From("idempotency_records").
+		Where(squirrel.Eq{"owner": owner, "idempotency_key": key}).
+		ToSql()
+
+	var record model.IdempotencyRecord
+
+	err := m.db.QueryRowx(sql, args...).StructScan(&record)
+	if err != nil {
+		return model.IdempotencyRecord{}, ErrIdempotencyRecordNotFound
+	}
+
+	return record, nil
+}
+
+// UpdateIdempotencyRecord overwrites the record with the owner and key of the
+// given record. If the record cannot be found, ErrIdempotencyRecordNotFound
+// will be returned.
+func (m *mariaDB) UpdateIdempotencyRecord(record model.IdempotencyRecord) error {
+	if _, err := m.FindIdempotencyRecord(record.Owner, record.Key); err != nil {
+		return err
+	}
+
+	sql, args, _ := squirrel.
+		Update("idempotency_records").
+		Set("fingerprint", record.Fingerprint).
+		Set("status", record.Status).
+		Set("content_type", record.ContentType).
+		Set("body", nonNilBytes(record.Body)).
+		Set("created_at", record.CreatedAt).
+		Set("expires_at", record.ExpiresAt).
+		Where(squirrel.Eq{"owner": record.Owner, "idempotency_key": record.Key}).
+		ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+	return err
+}
+
+// DeleteIdempotencyRecord deletes the record with the given owner and key.
+func (m *mariaDB) DeleteIdempotencyRecord(owner, key string) error {
+	sql, args, _ := squirrel.
+		Delete("idempotency_records").
+		Where(squirrel.Eq{"owner": owner, "idempotency_key": key}).
+		ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+	return err
+}
+
+// DeleteExpiredIdempotencyRecords deletes all records expiring before the given
+// time.
+func (m *mariaDB) DeleteExpiredIdempotencyRecords(before time.Time) error {
+	sql, args, _ := squirrel.
+		Delete("idempotency_records").
+		Where(squirrel.Lt{"expires_at": before}).
+		ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+	return err
+}
+
+// nonNilBytes returns an empty slice for nil, since nil would be stored as NULL.
+func nonNilBytes(data []byte) []byte {
+	if data == nil {
+		return []byte{}
+	}
+
+	return data
+}
+
+// RestoreToDo inserts the given ToDo item along with its tags and tasks while
+// keeping the IDs and the version. MariaDB raises the AUTO_INCREMENT values of
+// the tables on its own, so that new items receive higher IDs.
+func (m *mariaDB) RestoreToDo(toDo model.ToDo) error {
+	sql, args, _ := squirrel.
+		Insert("todos").
+		Columns("id", "name", "description", "done", "due", "project", "priority", "recurrence", "uid", "version").
+		Values(toDo.ID, toDo.Name, toDo.Description, toDo.Done, toDo.Due, toDo.Project, toDo.Priority,
+			toDo.Recurrence, toDo.UID, toDo.Version).
+		ToSql()
+
+	if _, err := m.db.Exec(sql, args...); err != nil {
+		return err
+	}
+
+	if err := m.createTagsForToDo(toDo.ID, toDo.Tags); err != nil {
+		return err
+	}
+
+	for _, task := range toDo.Tasks {
+		sql, args, _ := squirrel.
+			Insert("tasks").
+			Columns("id", "name", "description", "done", "uid", "todo_id").
+			Values(task.ID, task.Name, task.Description, task.Done, task.UID, toDo.ID).
+			ToSql()
+
+		if _, err := m.db.Exec(sql, args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RestoreComment inserts the given comment while keeping its ID.
+func (m *mariaDB) RestoreComment(comment model.Comment) error {
+	sql, args, _ := squirrel.
+		Insert("comments").
+		Columns("id", "todo_id", "task_id", "author", "body", "created_at", "updated_at").
+		Values(comment.ID, comment.ToDoID, comment.TaskID, comment.Author, comment.Body,
+			comment.CreatedAt, comment.UpdatedAt).
+		ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RestoreAttachment inserts the metadata of the given attachment while keeping
+// its ID.
+func (m *mariaDB) RestoreAttachment(attachment model.Attachment) error {
+	sql, args, _ := squirrel.
+		Insert("attachments").
+		Columns("id", "todo_id", "task_id", "filename", "content_type", "size", "blob_key", "created_at").
+		Values(attachment.ID, attachment.ToDoID, attachment.TaskID, attachment.Filename,
+			attachment.ContentType, attachment.Size, attachment.BlobKey, attachment.CreatedAt).
+		ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RestoreView inserts the given view while keeping its ID.
+func (m *mariaDB) RestoreView(view model.View) error {
+	sql, args, _ := squirrel.
+		Insert("views").
+		Columns("id", "owner", "name", "filter", "sort").
+		Values(view.ID, view.Owner, view.Name, view.Filter, view.Sort).
+		ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RestoreWebhook inserts the given webhook while keeping its ID.
+func (m *mariaDB) RestoreWebhook(webhook model.Webhook) error {
+	sql, args, _ := squirrel.
+		Insert("webhooks").
+		Columns("id", "url", "secret", "events", "created_at").
+		Values(webhook.ID, webhook.URL, webhook.Secret, joinEventTypes(webhook.Events), webhook.CreatedAt).
+		ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RestoreDelivery inserts the given delivery while keeping its ID.
+func (m *mariaDB) RestoreDelivery(delivery model.Delivery) error {
+	sql, args, _ := squirrel.
+		Insert("deliveries").
+		Columns("id", "webhook_id", "event_type", "payload", "status", "attempts", "response_code",
+			"last_error", "next_attempt_at", "created_at", "updated_at").
+		Values(delivery.ID, delivery.WebhookID, delivery.EventType, delivery.Payload, delivery.Status,
+			delivery.Attempts, delivery.ResponseCode, delivery.LastError, delivery.NextAttemptAt,
+			delivery.CreatedAt, delivery.UpdatedAt).
+		ToSql()
+
+	_, err := m.db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Reindex is a no-op, because MariaDB keeps its FULLTEXT indexes up to date.
+func (m *mariaDB) Reindex(toDoID int64) error {
+	return nil
+}
+
+// searchQuery searches the FULLTEXT indexes of all tables in natural language
+// mode and merges the results. The query has to be bound to each placeholder.
+const searchQuery = `
+	SELECT 'todo' AS type, id AS todo_id, 0 AS task_id, 0 AS comment_id, name,
+		CONCAT_WS(' ', name, description) AS text,
+		MATCH (name, description) AGAINST (?) AS score
+	FROM todos
+	WHERE MATCH (name, description) AGAINST (?)
+	UNION ALL
+	SELECT 'task', todo_id, id, 0, name,
+		CONCAT_WS(' ', name, description),
+		MATCH (name, description) AGAINST (?)
+	FROM tasks
+	WHERE MATCH (name, description) AGAINST (?)
+	UNION ALL
+	SELECT 'comment', comments.todo_id, comments.task_id, comments.id, todos.name,
+		comments.body,
+		MATCH (comments.body) AGAINST (?)
+	FROM comments
+	JOIN todos ON todos.id = comments.todo_id
+	WHERE MATCH (comments.body) AGAINST (?)
+	ORDER BY score DESC
+	LIMIT ?`
+
+// Search runs a full-text search on ToDo items, tasks and comments using the
+// MariaDB FULLTEXT indexes. Note that MariaDB ignores stopwords and words that
+// are shorter than the minimum word length, which is 3 characters for InnoDB.
+func (m *mariaDB) Search(query string, limit int) ([]model.SearchResult, error) {
+	rows, err := m.db.Queryx(searchQuery, query, query, query, query, query, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]model.SearchResult, 0)
+
+	for rows.Next() {
+		var result model.SearchResult
+		if err := rows.StructScan(&result); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// filterCondition compiles a filter expression to a WHERE condition for the
+// todos table. The semantics equal those of filter.Match: Text comparisons are
+// case-insensitive regardless of the collation, and due date comparisons are
+// false for ToDo items without due date.
+func filterCondition(expression filter.Expression) squirrel.Sqlizer {
+	switch e := expression.(type) {
+	case filter.And:
+		return squirrel.And{filterCondition(e.Left), filterCondition(e.Right)}
+	case filter.Or:
+		return squirrel.Or{filterCondition(e.Left), filterCondition(e.Right)}
+	case filter.Not:
+		return notCondition{filterCondition(e.Operand)}
+	case filter.Condition:
+		return fieldCondition(e)
+	default:
+		return squirrel.Expr("FALSE")
+	}
+}
+
+// fieldCondition compiles a single filter condition.
+func fieldCondition(condition filter.Condition) squirrel.Sqlizer {
+	switch condition.Field {
+	case filter.FieldID:
+		return compareColumn("id", condition.Operator, condition.Value)
+
+	case filter.FieldName, filter.FieldDescription:
+		column := fmt.Sprintf("LOWER(COALESCE(%s, ''))", condition.Field)
+		value := strings.ToLower(condition.Value.(string))
+
+		switch condition.Operator {
+		case filter.OperatorMatch:
+			return squirrel.Expr(column+" LIKE ?", "%"+escapeLike(value)+"%")
+		case filter.OperatorNotEqual:
+			return squirrel.Expr(column+" <> ?", value)
+		default:
+			return squirrel.Expr(column+" = ?", value)
+		}
+
+	case filter.FieldDone:
+		return compareColumn("done", condition.Operator, condition.Value)
+
+	case filter.FieldTag:
+		hasTag := squirrel.Expr(
+			"EXISTS (SELECT 1 FROM tags WHERE tags.todo_id = todos.id AND LOWER(tags.name) = ?)",
+			strings.ToLower(condition.Value.(string)),
+		)
+		if condition.Operator == filter.OperatorNotEqual {
+			return notCondition{hasTag}
+		}
+		return hasTag
+
+	case filter.FieldDue:
+		hasDue := squirrel.Expr("due IS NOT NULL")
+
+		switch {
+		case condition.Operator == filter.OperatorExists:
+			return hasDue
+		case !condition.Day:
+			return squirrel.And{hasDue, compareColumn("due", condition.Operator, condition.Value)}
+		}
+
+		start, end := condition.DayRange()
+
+		switch condition.Operator {
+		case filter.OperatorLess:
+			return squirrel.And{hasDue, squirrel.Lt{"due": start}}
+		case filter.OperatorLessEqual:
+			return squirrel.And{hasDue, squirrel.Lt{"due": end}}
+		case filter.OperatorGreater:
+			return squirrel.And{hasDue, squirrel.GtOrEq{"due": end}}
+		case filter.OperatorGreaterEqual:
+			return squirrel.And{hasDue, squirrel.GtOrEq{"due": start}}
+		case filter.OperatorNotEqual:
+			return squirrel.And{hasDue, squirrel.Or{squirrel.Lt{"due": start}, squirrel.GtOrEq{"due": end}}}
+		default:
+			return squirrel.And{hasDue, squirrel.GtOrEq{"due": start}, squirrel.Lt{"due": end}}
+		}
+	}
+
+	return squirrel.Expr("FALSE")
+}
+
+// compareColumn compares a column with a value using the given operator.
+func compareColumn(column string, operator filter.Operator, value interface{}) squirrel.Sqlizer {
+	switch operator {
+	case filter.OperatorNotEqual:
+		return squirrel.NotEq{column: value}
+	case filter.OperatorLess:
+		return squirrel.Lt{column: value}
+	case filter.OperatorLessEqual:
+		return squirrel.LtOrEq{column: value}
+	case filter.OperatorGreater:
+		return squirrel.Gt{column: value}
+	case filter.OperatorGreaterEqual:
+		return squirrel.GtOrEq{column: value}
+	default:
+		return squirrel.Eq{column: value}
+	}
+}
+
+// escapeLike escapes the wildcard characters of a LIKE pattern.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// notCondition negates the wrapped condition. squirrel doesn't provide such a
+// type out of the box.
+type notCondition struct {
+	condition squirrel.Sqlizer
+}
+
+// ToSql implements squirrel.Sqlizer.
+func (n notCondition) ToSql() (string, []interface{}, error) {
+	sql, args, err := n.condition.ToSql()
+	if err != nil {
+		return "", nil, err
 	}
 
-	return tasks, nil
+	return "NOT (" + sql + ")", args, nil
 }
 
 // Remove drops the configured MariaDB database along with its tables.
@@ -356,5 +1898,5 @@ func (m *mariaDB) Remove() error {
 
 // Close attempts to close the database connection.
 func (m *mariaDB) Close() error {
-	return m.db.Close()
+	return m.pool.Close()
 }
@@ -2,30 +2,100 @@
 package storage
 
 import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dominikbraun/todo/filter"
 	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/search"
 )
 
 type memory struct {
-	internal map[int64]model.ToDo
-	toDoID   int64
-	taskID   int64
+	internal     map[int64]model.ToDo
+	comments     map[int64]model.Comment
+	attachments  map[int64]model.Attachment
+	views        map[int64]model.View
+	webhooks     map[int64]model.Webhook
+	deliveries   map[int64]model.Delivery
+	feeds        map[string]model.CalendarFeed
+	idempotency  map[idempotencyKey]model.IdempotencyRecord
+	index        *search.Index
+	documents    map[string]model.SearchResult
+	indexedKeys  map[int64][]string
+	toDoID       int64
+	taskID       int64
+	commentID    int64
+	attachmentID int64
+	viewID       int64
+	webhookID    int64
+	deliveryID   int64
 }
 
 // NewMemory creates an in-memory storage living as long as the server process.
 func NewMemory() *memory {
 	return &memory{
-		internal: make(map[int64]model.ToDo),
-		toDoID:   0,
-		taskID:   0,
+		internal:     make(map[int64]model.ToDo),
+		comments:     make(map[int64]model.Comment),
+		attachments:  make(map[int64]model.Attachment),
+		views:        make(map[int64]model.View),
+		webhooks:     make(map[int64]model.Webhook),
+		deliveries:   make(map[int64]model.Delivery),
+		feeds:        make(map[string]model.CalendarFeed),
+		idempotency:  make(map[idempotencyKey]model.IdempotencyRecord),
+		index:        search.NewIndex(),
+		documents:    make(map[string]model.SearchResult),
+		indexedKeys:  make(map[int64][]string),
+		toDoID:       0,
+		taskID:       0,
+		commentID:    0,
+		attachmentID: 0,
+		viewID:       0,
+		webhookID:    0,
+		deliveryID:   0,
 	}
 }
 
-// Initialize initializes the in-memory storage by creating a hash map.
+// Initialize initializes the in-memory storage by creating the hash maps.
 func (m *memory) Initialize() error {
 	if m.internal == nil {
 		m.internal = make(map[int64]model.ToDo)
 	}
 
+	if m.comments == nil {
+		m.comments = make(map[int64]model.Comment)
+	}
+
+	if m.attachments == nil {
+		m.attachments = make(map[int64]model.Attachment)
+	}
+
+	if m.views == nil {
+		m.views = make(map[int64]model.View)
+	}
+
+	if m.webhooks == nil {
+		m.webhooks = make(map[int64]model.Webhook)
+	}
+
+	if m.deliveries == nil {
+		m.deliveries = make(map[int64]model.Delivery)
+	}
+
+	if m.feeds == nil {
+		m.feeds = make(map[string]model.CalendarFeed)
+	}
+
+	if m.idempotency == nil {
+		m.idempotency = make(map[idempotencyKey]model.IdempotencyRecord)
+	}
+
+	if m.index == nil {
+		m.index = search.NewIndex()
+		m.documents = make(map[string]model.SearchResult)
+		m.indexedKeys = make(map[int64][]string)
+	}
+
 	return nil
 }
 
@@ -41,6 +111,7 @@ func (m *memory) CreateToDo(toDo model.ToDo) (model.ToDo, error) {
 
 	m.toDoID++
 	toDo.ID = m.toDoID
+	toDo.Version = 1
 
 	m.internal[toDo.ID] = toDo
 
@@ -60,6 +131,56 @@ func (m *memory) FindToDos() ([]model.ToDo, error) {
 	return toDos, nil
 }
 
+// FindToDosByFilter returns all ToDo items that match the filter expression,
+// which is evaluated using filter.Match.
+func (m *memory) FindToDosByFilter(expression filter.Expression) ([]model.ToDo, error) {
+	toDos := make([]model.ToDo, 0)
+
+	for _, toDo := range m.internal {
+		if filter.Match(expression, toDo) {
+			toDos = append(toDos, toDo)
+		}
+	}
+
+	sort.Slice(toDos, func(i, j int) bool {
+		return toDos[i].ID < toDos[j].ID
+	})
+
+	return toDos, nil
+}
+
+// FindToDoPage returns up to limit ToDo items matching the filter expression
+// with an ID greater than afterID, along with the number of all matching items.
+// A nil expression matches all ToDo items.
+func (m *memory) FindToDoPage(expression filter.Expression, afterID int64, limit int) ([]model.ToDo, int, error) {
+	var (
+		toDos = make([]model.ToDo, 0)
+		total = 0
+	)
+
+	for _, toDo := range m.internal {
+		if expression != nil && !filter.Match(expression, toDo) {
+			continue
+		}
+
+		total++
+
+		if toDo.ID > afterID {
+			toDos = append(toDos, toDo)
+		}
+	}
+
+	sort.Slice(toDos, func(i, j int) bool {
+		return toDos[i].ID < toDos[j].ID
+	})
+
+	if len(toDos) > limit {
+		toDos = toDos[:limit]
+	}
+
+	return toDos, total, nil
+}
+
 // FindToDoByID looks for a ToDo item with the provided ID and returns that item
 // if it was found. Otherwise, ErrToDoNotFound will be returned.
 func (m *memory) FindToDoByID(id int64) (model.ToDo, error) {
@@ -78,17 +199,34 @@ func (m *memory) FindToDoByID(id int64) (model.ToDo, error) {
 // new and will receive an ID. All other tasks, regardless whether they were
 // modified or removed, will be overridden with the tasks of the new ToDo item.
 func (m *memory) UpdateToDo(id int64, toDo model.ToDo) error {
-	if _, exists := m.internal[id]; !exists {
+	stored, exists := m.internal[id]
+	if !exists {
 		return ErrToDoNotFound
 	}
 
+	if toDo.Version != 0 && toDo.Version != stored.Version {
+		return ErrVersionConflict
+	}
+
+	taskIDs := make(map[int64]bool)
+
 	for i, task := range toDo.Tasks {
 		if task.ID == 0 {
 			m.taskID++
 			toDo.Tasks[i].ID = m.taskID
 		}
+		taskIDs[toDo.Tasks[i].ID] = true
+	}
+
+	// Delete the comments of all tasks that have been removed from the item.
+	for commentID, comment := range m.comments {
+		if comment.ToDoID == id && comment.TaskID != 0 && !taskIDs[comment.TaskID] {
+			delete(m.comments, commentID)
+		}
 	}
 
+	toDo.ID = id
+	toDo.Version = stored.Version + 1
 	m.internal[id] = toDo
 
 	return nil
@@ -101,14 +239,689 @@ func (m *memory) DeleteToDo(id int64) error {
 		return ErrToDoNotFound
 	}
 	delete(m.internal, id)
+
+	for commentID, comment := range m.comments {
+		if comment.ToDoID == id {
+			delete(m.comments, commentID)
+		}
+	}
+
+	for attachmentID, attachment := range m.attachments {
+		if attachment.ToDoID == id {
+			delete(m.attachments, attachmentID)
+		}
+	}
+
+	return nil
+}
+
+// Batch applies the operations in order. Atomic batches are rolled back by
+// restoring a snapshot taken before the first operation.
+func (m *memory) Batch(operations []BatchOperation, atomic bool) ([]BatchResult, error) {
+	var restore func()
+
+	if atomic {
+		restore = m.snapshot()
+	}
+
+	results := make([]BatchResult, 0, len(operations))
+
+	for _, operation := range operations {
+		result := applyBatchOperation(m, operation)
+		results = append(results, result)
+
+		if atomic && result.Err != nil {
+			restore()
+			return results, result.Err
+		}
+	}
+
+	return results, nil
+}
+
+// snapshot copies the data that can be changed by batch operations, i.e. the
+// ToDo items, comments, attachments and ID counters, and returns a function
+// restoring the copy.
+func (m *memory) snapshot() func() {
+	var (
+		toDos       = make(map[int64]model.ToDo, len(m.internal))
+		comments    = make(map[int64]model.Comment, len(m.comments))
+		attachments = make(map[int64]model.Attachment, len(m.attachments))
+		toDoID      = m.toDoID
+		taskID      = m.taskID
+	)
+
+	for id, toDo := range m.internal {
+		toDos[id] = toDo
+	}
+
+	for id, comment := range m.comments {
+		comments[id] = comment
+	}
+
+	for id, attachment := range m.attachments {
+		attachments[id] = attachment
+	}
+
+	return func() {
+		m.internal, m.comments, m.attachments = toDos, comments, attachments
+		m.toDoID, m.taskID = toDoID, taskID
+	}
+}
+
+// CreateComment inserts the given comment, which is expected to not have an ID.
+func (m *memory) CreateComment(comment model.Comment) (model.Comment, error) {
+	m.commentID++
+	comment.ID = m.commentID
+
+	m.comments[comment.ID] = comment
+
+	return comment, nil
+}
+
+// FindComments returns all comments for the given ToDo ID and task ID. Since
+// IDs are auto-incremented, sorting the comments by ID yields creation order.
+func (m *memory) FindComments(toDoID, taskID int64) ([]model.Comment, error) {
+	comments := make([]model.Comment, 0)
+
+	for _, comment := range m.comments {
+		if comment.ToDoID == toDoID && comment.TaskID == taskID {
+			comments = append(comments, comment)
+		}
+	}
+
+	sort.Slice(comments, func(i, j int) bool {
+		return comments[i].ID < comments[j].ID
+	})
+
+	return comments, nil
+}
+
+// FindCommentsByToDoIDs returns all comments of the given ToDo items and their
+// tasks, ordered by their ID.
+func (m *memory) FindCommentsByToDoIDs(toDoIDs []int64) ([]model.Comment, error) {
+	requested := idSet(toDoIDs)
+	comments := make([]model.Comment, 0)
+
+	for _, comment := range m.comments {
+		if requested[comment.ToDoID] {
+			comments = append(comments, comment)
+		}
+	}
+
+	sort.Slice(comments, func(i, j int) bool {
+		return comments[i].ID < comments[j].ID
+	})
+
+	return comments, nil
+}
+
+// FindCommentByID looks for a comment with the provided ID and returns that
+// comment if it was found. Otherwise, ErrCommentNotFound will be returned.
+func (m *memory) FindCommentByID(id int64) (model.Comment, error) {
+	if comment, exists := m.comments[id]; exists {
+		return comment, nil
+	}
+
+	return model.Comment{}, ErrCommentNotFound
+}
+
+// UpdateComment overwrites a stored comment with the provided comment. If the
+// requested comment cannot be found, ErrCommentNotFound will be returned.
+func (m *memory) UpdateComment(id int64, comment model.Comment) error {
+	if _, exists := m.comments[id]; !exists {
+		return ErrCommentNotFound
+	}
+
+	comment.ID = id
+	m.comments[id] = comment
+
+	return nil
+}
+
+// DeleteComment deletes the comment with the given ID. If the comment cannot
+// be found, ErrCommentNotFound will be returned.
+func (m *memory) DeleteComment(id int64) error {
+	if _, exists := m.comments[id]; !exists {
+		return ErrCommentNotFound
+	}
+	delete(m.comments, id)
 	return nil
 }
 
-// Remove removes the in-memory storage by setting its hash map to nil.
+// CreateAttachment inserts the given attachment metadata, which is expected to
+// not have an ID.
+func (m *memory) CreateAttachment(attachment model.Attachment) (model.Attachment, error) {
+	m.attachmentID++
+	attachment.ID = m.attachmentID
+
+	m.attachments[attachment.ID] = attachment
+
+	return attachment, nil
+}
+
+// FindAttachments returns the metadata of all attachments of the given ToDo
+// item, sorted by their ID.
+func (m *memory) FindAttachments(toDoID int64) ([]model.Attachment, error) {
+	attachments := make([]model.Attachment, 0)
+
+	for _, attachment := range m.attachments {
+		if attachment.ToDoID == toDoID {
+			attachments = append(attachments, attachment)
+		}
+	}
+
+	sort.Slice(attachments, func(i, j int) bool {
+		return attachments[i].ID < attachments[j].ID
+	})
+
+	return attachments, nil
+}
+
+// FindAttachmentsByToDoIDs returns the metadata of all attachments of the given
+// ToDo items and their tasks, ordered by their ID.
+func (m *memory) FindAttachmentsByToDoIDs(toDoIDs []int64) ([]model.Attachment, error) {
+	requested := idSet(toDoIDs)
+	attachments := make([]model.Attachment, 0)
+
+	for _, attachment := range m.attachments {
+		if requested[attachment.ToDoID] {
+			attachments = append(attachments, attachment)
+		}
+	}
+
+	sort.Slice(attachments, func(i, j int) bool {
+		return attachments[i].ID < attachments[j].ID
+	})
+
+	return attachments, nil
+}
+
+// FindAttachmentByID looks for the metadata of the attachment with the given ID
+// and returns it if it was found. Otherwise, ErrAttachmentNotFound will be
+// returned.
+func (m *memory) FindAttachmentByID(id int64) (model.Attachment, error) {
+	if attachment, exists := m.attachments[id]; exists {
+		return attachment, nil
+	}
+
+	return model.Attachment{}, ErrAttachmentNotFound
+}
+
+// DeleteAttachment deletes the metadata of the attachment with the given ID. If
+// the attachment cannot be found, ErrAttachmentNotFound will be returned.
+func (m *memory) DeleteAttachment(id int64) error {
+	if _, exists := m.attachments[id]; !exists {
+		return ErrAttachmentNotFound
+	}
+	delete(m.attachments, id)
+	return nil
+}
+
+// CreateView inserts the given view, which is expected to not have an ID.
+func (m *memory) CreateView(view model.View) (model.View, error) {
+	m.viewID++
+	view.ID = m.viewID
+
+	m.views[view.ID] = view
+
+	return view, nil
+}
+
+// FindViews returns all views owned by the given user, sorted by their ID.
+func (m *memory) FindViews(owner string) ([]model.View, error) {
+	views := make([]model.View, 0)
+
+	for _, view := range m.views {
+		if view.Owner == owner {
+			views = append(views, view)
+		}
+	}
+
+	sort.Slice(views, func(i, j int) bool {
+		return views[i].ID < views[j].ID
+	})
+
+	return views, nil
+}
+
+// FindViewByID looks for a view with the provided ID and returns that view if
+// it was found. Otherwise, ErrViewNotFound will be returned.
+func (m *memory) FindViewByID(id int64) (model.View, error) {
+	if view, exists := m.views[id]; exists {
+		return view, nil
+	}
+
+	return model.View{}, ErrViewNotFound
+}
+
+// UpdateView overwrites a stored view with the provided view. If the requested
+// view cannot be found, ErrViewNotFound will be returned.
+func (m *memory) UpdateView(id int64, view model.View) error {
+	if _, exists := m.views[id]; !exists {
+		return ErrViewNotFound
+	}
+
+	view.ID = id
+	m.views[id] = view
+
+	return nil
+}
+
+// DeleteView deletes the view with the given ID. If the view cannot be found,
+// ErrViewNotFound will be returned.
+func (m *memory) DeleteView(id int64) error {
+	if _, exists := m.views[id]; !exists {
+		return ErrViewNotFound
+	}
+	delete(m.views, id)
+	return nil
+}
+
+// CreateWebhook inserts the given webhook, which is expected to not have an ID.
+func (m *memory) CreateWebhook(webhook model.Webhook) (model.Webhook, error) {
+	m.webhookID++
+	webhook.ID = m.webhookID
+
+	m.webhooks[webhook.ID] = webhook
+
+	return webhook, nil
+}
+
+// FindWebhooks returns all webhooks, sorted by their ID.
+func (m *memory) FindWebhooks() ([]model.Webhook, error) {
+	webhooks := make([]model.Webhook, 0, len(m.webhooks))
+
+	for _, webhook := range m.webhooks {
+		webhooks = append(webhooks, webhook)
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+
+	return webhooks, nil
+}
+
+// FindWebhookByID looks for a webhook with the provided ID and returns that
+// webhook if it was found. Otherwise, ErrWebhookNotFound will be returned.
+func (m *memory) FindWebhookByID(id int64) (model.Webhook, error) {
+	if webhook, exists := m.webhooks[id]; exists {
+		return webhook, nil
+	}
+
+	return model.Webhook{}, ErrWebhookNotFound
+}
+
+// DeleteWebhook deletes the webhook with the given ID and its deliveries. If
+// the webhook cannot be found, ErrWebhookNotFound will be returned.
+func (m *memory) DeleteWebhook(id int64) error {
+	if _, exists := m.webhooks[id]; !exists {
+		return ErrWebhookNotFound
+	}
+	delete(m.webhooks, id)
+
+	for deliveryID, delivery := range m.deliveries {
+		if delivery.WebhookID == id {
+			delete(m.deliveries, deliveryID)
+		}
+	}
+
+	return nil
+}
+
+// CreateDelivery inserts the given delivery, which is expected to not have an
+// ID.
+func (m *memory) CreateDelivery(delivery model.Delivery) (model.Delivery, error) {
+	m.deliveryID++
+	delivery.ID = m.deliveryID
+
+	m.deliveries[delivery.ID] = delivery
+
+	return delivery, nil
+}
+
+// FindDeliveries returns all deliveries of the given webhook, sorted by their
+// ID.
+func (m *memory) FindDeliveries(webhookID int64) ([]model.Delivery, error) {
+	deliveries := make([]model.Delivery, 0)
+
+	for _, delivery := range m.deliveries {
+		if delivery.WebhookID == webhookID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].ID < deliveries[j].ID
+	})
+
+	return deliveries, nil
+}
+
+// FindDueDeliveries returns up to limit pending deliveries that are due at the
+// given time, sorted by their next attempt and ID.
+func (m *memory) FindDueDeliveries(due time.Time, limit int) ([]model.Delivery, error) {
+	deliveries := make([]model.Delivery, 0)
+
+	for _, delivery := range m.deliveries {
+		if delivery.Status == model.DeliveryPending && !delivery.NextAttemptAt.After(due) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].NextAttemptAt.Equal(deliveries[j].NextAttemptAt) {
+			return deliveries[i].NextAttemptAt.Before(deliveries[j].NextAttemptAt)
+		}
+		return deliveries[i].ID < deliveries[j].ID
+	})
+
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+// UpdateDelivery overwrites a stored delivery with the provided delivery. If
+// the requested delivery cannot be found, ErrDeliveryNotFound will be returned.
+func (m *memory) UpdateDelivery(id int64, delivery model.Delivery) error {
+	if _, exists := m.deliveries[id]; !exists {
+		return ErrDeliveryNotFound
+	}
+
+	delivery.ID = id
+	m.deliveries[id] = delivery
+
+	return nil
+}
+
+// Reindex replaces all documents of the given ToDo item in the inverted index.
+// Each ToDo item, task and comment is indexed as a separate document.
+func (m *memory) Reindex(toDoID int64) error {
+	for _, key := range m.indexedKeys[toDoID] {
+		m.index.Remove(key)
+		delete(m.documents, key)
+	}
+	delete(m.indexedKeys, toDoID)
+
+	toDo, exists := m.internal[toDoID]
+	if !exists {
+		return nil
+	}
+
+	m.indexDocument(fmt.Sprintf("todo:%d", toDo.ID), model.SearchResult{
+		Type:   model.SearchResultToDo,
+		ToDoID: toDo.ID,
+		Name:   toDo.Name,
+		Text:   toDo.Name + " " + toDo.Description,
+	})
+
+	for _, task := range toDo.Tasks {
+		m.indexDocument(fmt.Sprintf("task:%d", task.ID), model.SearchResult{
+			Type:   model.SearchResultTask,
+			ToDoID: toDo.ID,
+			TaskID: task.ID,
+			Name:   task.Name,
+			Text:   task.Name + " " + task.Description,
+		})
+	}
+
+	for _, comment := range m.comments {
+		if comment.ToDoID != toDo.ID {
+			continue
+		}
+
+		m.indexDocument(fmt.Sprintf("comment:%d", comment.ID), model.SearchResult{
+			Type:      model.SearchResultComment,
+			ToDoID:    toDo.ID,
+			TaskID:    comment.TaskID,
+			CommentID: comment.ID,
+			Name:      toDo.Name,
+			Text:      comment.Body,
+		})
+	}
+
+	return nil
+}
+
+// SaveCalendarFeed stores the given calendar feed under its owner, replacing
+// any existing feed of the owner.
+func (m *memory) SaveCalendarFeed(feed model.CalendarFeed) error {
+	m.feeds[feed.Owner] = feed
+	return nil
+}
+
+// FindCalendarFeed returns the calendar feed of the given owner. If the owner
+// has no feed, ErrCalendarFeedNotFound will be returned.
+func (m *memory) FindCalendarFeed(owner string) (model.CalendarFeed, error) {
+	if feed, exists := m.feeds[owner]; exists {
+		return feed, nil
+	}
+
+	return model.CalendarFeed{}, ErrCalendarFeedNotFound
+}
+
+// FindCalendarFeedByToken looks for a calendar feed with the provided token.
+// If the feed cannot be found, ErrCalendarFeedNotFound will be returned.
+func (m *memory) FindCalendarFeedByToken(token string) (model.CalendarFeed, error) {
+	for _, feed := range m.feeds {
+		if feed.Token == token {
+			return feed, nil
+		}
+	}
+
+	return model.CalendarFeed{}, ErrCalendarFeedNotFound
+}
+
+// DeleteCalendarFeed deletes the calendar feed of the given owner. If the owner
+// has no feed, ErrCalendarFeedNotFound will be returned.
+func (m *memory) DeleteCalendarFeed(owner string) error {
+	if _, exists := m.feeds[owner]; !exists {
+		return ErrCalendarFeedNotFound
+	}
+	delete(m.feeds, owner)
+	return nil
+}
+
+// FindAllViews returns the views of all users, sorted by their ID.
+func (m *memory) FindAllViews() ([]model.View, error) {
+	views := make([]model.View, 0, len(m.views))
+
+	for _, view := range m.views {
+		views = append(views, view)
+	}
+
+	sort.Slice(views, func(i, j int) bool {
+		return views[i].ID < views[j].ID
+	})
+
+	return views, nil
+}
+
+// FindCalendarFeeds returns the calendar feeds of all users, sorted by their
+// owner.
+func (m *memory) FindCalendarFeeds() ([]model.CalendarFeed, error) {
+	feeds := make([]model.CalendarFeed, 0, len(m.feeds))
+
+	for _, feed := range m.feeds {
+		feeds = append(feeds, feed)
+	}
+
+	sort.Slice(feeds, func(i, j int) bool {
+		return feeds[i].Owner < feeds[j].Owner
+	})
+
+	return feeds, nil
+}
+
+// idempotencyKey identifies an idempotency record by its owner and key.
+type idempotencyKey struct {
+	owner string
+	key   string
+}
+
+// CreateIdempotencyRecord stores the given record under its owner and key. If
+// a record with the same owner and key exists, ErrIdempotencyKeyExists will be
+// returned.
+func (m *memory) CreateIdempotencyRecord(record model.IdempotencyRecord) error {
+	key := idempotencyKey{owner: record.Owner, key: record.Key}
+
+	if _, exists := m.idempotency[key]; exists {
+		return ErrIdempotencyKeyExists
+	}
+	m.idempotency[key] = record
+
+	return nil
+}
+
+// FindIdempotencyRecord returns the record with the given owner and key. If the
+// record cannot be found, ErrIdempotencyRecordNotFound will be returned.
+func (m *memory) FindIdempotencyRecord(owner, key string) (model.IdempotencyRecord, error) {
+	if record, exists := m.idempotency[idempotencyKey{owner: owner, key: key}]; exists {
+		return record, nil
+	}
+
+	return model.IdempotencyRecord{}, ErrIdempotencyRecordNotFound
+}
+
+// UpdateIdempotencyRecord overwrites the record with the owner and key of the
+// given record. If the record cannot be found, ErrIdempotencyRecordNotFound
+// will be returned.
+func (m *memory) UpdateIdempotencyRecord(record model.IdempotencyRecord) error {
+	key := idempotencyKey{owner: record.Owner, key: record.Key}
+
+	if _, exists := m.idempotency[key]; !exists {
+		return ErrIdempotencyRecordNotFound
+	}
+	m.idempotency[key] = record
+
+	return nil
+}
+
+// DeleteIdempotencyRecord deletes the record with the given owner and key.
+func (m *memory) DeleteIdempotencyRecord(owner, key string) error {
+	delete(m.idempotency, idempotencyKey{owner: owner, key: key})
+	return nil
+}
+
+// DeleteExpiredIdempotencyRecords deletes all records expiring before the given
+// time.
+func (m *memory) DeleteExpiredIdempotencyRecords(before time.Time) error {
+	for key, record := range m.idempotency {
+		if record.ExpiresAt.Before(before) {
+			delete(m.idempotency, key)
+		}
+	}
+
+	return nil
+}
+
+// RestoreToDo inserts the given ToDo item with its ID, version and task IDs.
+// The ID counters are raised, so that new items receive higher IDs.
+func (m *memory) RestoreToDo(toDo model.ToDo) error {
+	for _, task := range toDo.Tasks {
+		m.taskID = maxID(m.taskID, task.ID)
+	}
+
+	m.toDoID = maxID(m.toDoID, toDo.ID)
+	m.internal[toDo.ID] = toDo
+
+	return nil
+}
+
+// RestoreComment inserts the given comment with its ID.
+func (m *memory) RestoreComment(comment model.Comment) error {
+	m.commentID = maxID(m.commentID, comment.ID)
+	m.comments[comment.ID] = comment
+	return nil
+}
+
+// RestoreAttachment inserts the metadata of the given attachment with its ID.
+func (m *memory) RestoreAttachment(attachment model.Attachment) error {
+	m.attachmentID = maxID(m.attachmentID, attachment.ID)
+	m.attachments[attachment.ID] = attachment
+	return nil
+}
+
+// RestoreView inserts the given view with its ID.
+func (m *memory) RestoreView(view model.View) error {
+	m.viewID = maxID(m.viewID, view.ID)
+	m.views[view.ID] = view
+	return nil
+}
+
+// RestoreWebhook inserts the given webhook with its ID.
+func (m *memory) RestoreWebhook(webhook model.Webhook) error {
+	m.webhookID = maxID(m.webhookID, webhook.ID)
+	m.webhooks[webhook.ID] = webhook
+	return nil
+}
+
+// RestoreDelivery inserts the given delivery with its ID.
+func (m *memory) RestoreDelivery(delivery model.Delivery) error {
+	m.deliveryID = maxID(m.deliveryID, delivery.ID)
+	m.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+// maxID returns the greater one of two IDs.
+func maxID(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+// Search looks up the query terms in the inverted index and returns the best
+// matching documents.
+func (m *memory) Search(query string, limit int) ([]model.SearchResult, error) {
+	hits := m.index.Search(query)
+
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	results := make([]model.SearchResult, len(hits))
+
+	for i, hit := range hits {
+		results[i] = m.documents[hit.Key]
+		results[i].Score = hit.Score
+	}
+
+	return results, nil
+}
+
+// indexDocument adds a document to the inverted index and remembers the search
+// result to return when the document matches a query.
+func (m *memory) indexDocument(key string, result model.SearchResult) {
+	m.index.Add(key, result.Text)
+	m.documents[key] = result
+	m.indexedKeys[result.ToDoID] = append(m.indexedKeys[result.ToDoID], key)
+}
+
+// Remove removes the in-memory storage by setting its hash maps to nil.
 func (m *memory) Remove() error {
 	m.internal = nil
+	m.comments = nil
+	m.attachments = nil
+	m.views = nil
+	m.webhooks = nil
+	m.deliveries = nil
+	m.feeds = nil
+	m.idempotency = nil
+	m.index = nil
+	m.documents = nil
+	m.indexedKeys = nil
 	m.toDoID = 0
 	m.taskID = 0
+	m.commentID = 0
+	m.attachmentID = 0
+	m.viewID = 0
+	m.webhookID = 0
+	m.deliveryID = 0
 
 	return nil
 }
@@ -117,3 +930,14 @@ func (m *memory) Remove() error {
 func (m *memory) Close() error {
 	return nil
 }
+
+// idSet converts a list of IDs to a set for fast lookups.
+func idSet(ids []int64) map[int64]bool {
+	set := make(map[int64]bool, len(ids))
+
+	for _, id := range ids {
+		set[id] = true
+	}
+
+	return set
+}
@@ -3,13 +3,50 @@ package storage
 
 import (
 	"errors"
+	"time"
 
+	"github.com/dominikbraun/todo/filter"
 	"github.com/dominikbraun/todo/model"
 )
 
 var (
 	// ErrToDoNotFound indicates that a requested ToDo item cannot be found.
 	ErrToDoNotFound = errors.New("requested ToDo item not found")
+
+	// ErrTaskNotFound indicates that a requested task cannot be found.
+	ErrTaskNotFound = errors.New("requested task not found")
+
+	// ErrCommentNotFound indicates that a requested comment cannot be found.
+	ErrCommentNotFound = errors.New("requested comment not found")
+
+	// ErrAttachmentNotFound indicates that a requested attachment cannot be
+	// found.
+	ErrAttachmentNotFound = errors.New("requested attachment not found")
+
+	// ErrWebhookNotFound indicates that a requested webhook cannot be found.
+	ErrWebhookNotFound = errors.New("requested webhook not found")
+
+	// ErrDeliveryNotFound indicates that a requested delivery cannot be found.
+	ErrDeliveryNotFound = errors.New("requested delivery not found")
+
+	// ErrVersionConflict indicates that an item has been modified in the meantime,
+	// i.e. the version of the update doesn't match the stored version.
+	ErrVersionConflict = errors.New("item has been modified in the meantime")
+
+	// ErrViewNotFound indicates that a requested view cannot be found.
+	ErrViewNotFound = errors.New("requested view not found")
+
+	// ErrCalendarFeedNotFound indicates that a requested calendar feed cannot
+	// be found.
+	ErrCalendarFeedNotFound = errors.New("requested calendar feed not found")
+
+	// ErrIdempotencyRecordNotFound indicates that a requested idempotency
+	// record cannot be found.
+	ErrIdempotencyRecordNotFound = errors.New("requested idempotency record not found")
+
+	// ErrIdempotencyKeyExists indicates that a record for an idempotency key
+	// already exists.
+	ErrIdempotencyKeyExists = errors.New("idempotency key already exists")
 )
 
 // Storage represents a storage backend.
@@ -22,24 +59,214 @@ type Storage interface {
 	// database and tables if they don't exist yet.
 	Initialize() error
 
-	// CreateToDo stores a new ToDo item and returns the inserted entity.
+	// CreateToDo stores a new ToDo item and returns the inserted entity, which
+	// has the version 1.
 	CreateToDo(toDo model.ToDo) (model.ToDo, error)
 
 	// FindToDos returns a list of all stored ToDo items.
 	FindToDos() ([]model.ToDo, error)
 
+	// FindToDosByFilter returns a list of all stored ToDo items matching the
+	// given filter expression, ordered by their ID.
+	FindToDosByFilter(expression filter.Expression) ([]model.ToDo, error)
+
+	// FindToDoPage returns up to limit ToDo items with an ID greater than
+	// afterID ordered by their ID, along with the total number of ToDo items.
+	// If the filter expression is not nil, only the items matching it are
+	// considered.
+	FindToDoPage(expression filter.Expression, afterID int64, limit int) ([]model.ToDo, int, error)
+
 	// FindToDoById returns the ToDo item with the given ID. In case the item
 	// cannot be found, an error will be returned.
 	FindToDoByID(id int64) (model.ToDo, error)
 
-	// UpdateToDo overwrites the ToDo item with the given ID. In case the item
-	// cannot be found, an error will be returned.
+	// UpdateToDo overwrites the ToDo item with the given ID and increments its
+	// version. In case the item cannot be found, an error will be returned.
+	//
+	// If the version of the provided item is not 0, it has to match the stored
+	// version. Otherwise, ErrVersionConflict will be returned.
 	UpdateToDo(id int64, toDo model.ToDo) error
 
-	// DeleteToDo deletes the ToDo item with the given ID. In case the item
-	// cannot be found, an error will be returned.
+	// DeleteToDo deletes the ToDo item with the given ID along with its tasks,
+	// comments and attachment metadata. In case the item cannot be found, an
+	// error will be returned.
 	DeleteToDo(id int64) error
 
+	// Batch applies the given operations in order and returns a result for
+	// each operation. Failing operations don't affect the other operations.
+	//
+	// If atomic is true, the batch stops at the first failing operation, all
+	// changes of the batch are rolled back and the error of the operation is
+	// returned. The results only cover the operations up to the failing one.
+	Batch(operations []BatchOperation, atomic bool) ([]BatchResult, error)
+
+	// CreateComment stores a new comment and returns the inserted entity.
+	CreateComment(comment model.Comment) (model.Comment, error)
+
+	// FindComments returns all comments for the given ToDo ID and task ID in
+	// the order they were created. A task ID of 0 refers to the ToDo itself.
+	FindComments(toDoID, taskID int64) ([]model.Comment, error)
+
+	// FindCommentsByToDoIDs returns all comments of the ToDo items with the
+	// given IDs, including the comments of their tasks, ordered by their ID.
+	FindCommentsByToDoIDs(toDoIDs []int64) ([]model.Comment, error)
+
+	// FindCommentByID returns the comment with the given ID. In case the
+	// comment cannot be found, an error will be returned.
+	FindCommentByID(id int64) (model.Comment, error)
+
+	// UpdateComment overwrites the comment with the given ID. In case the
+	// comment cannot be found, an error will be returned.
+	UpdateComment(id int64, comment model.Comment) error
+
+	// DeleteComment deletes the comment with the given ID. In case the comment
+	// cannot be found, an error will be returned.
+	DeleteComment(id int64) error
+
+	// CreateAttachment stores the metadata of a new attachment and returns the
+	// inserted entity. The attachment content is not stored in Storage.
+	CreateAttachment(attachment model.Attachment) (model.Attachment, error)
+
+	// FindAttachments returns the metadata of all attachments of the ToDo item
+	// with the given ID, including the attachments of its tasks.
+	FindAttachments(toDoID int64) ([]model.Attachment, error)
+
+	// FindAttachmentsByToDoIDs returns the metadata of all attachments of the
+	// ToDo items with the given IDs, including the attachments of their tasks,
+	// ordered by their ID.
+	FindAttachmentsByToDoIDs(toDoIDs []int64) ([]model.Attachment, error)
+
+	// FindAttachmentByID returns the metadata of the attachment with the given
+	// ID. In case the attachment cannot be found, an error will be returned.
+	FindAttachmentByID(id int64) (model.Attachment, error)
+
+	// DeleteAttachment deletes the metadata of the attachment with the given
+	// ID. In case the attachment cannot be found, an error will be returned.
+	DeleteAttachment(id int64) error
+
+	// CreateView stores a new view and returns the inserted entity.
+	CreateView(view model.View) (model.View, error)
+
+	// FindViews returns all views owned by the given user ordered by their ID.
+	FindViews(owner string) ([]model.View, error)
+
+	// FindViewByID returns the view with the given ID. In case the view cannot
+	// be found, an error will be returned.
+	FindViewByID(id int64) (model.View, error)
+
+	// UpdateView overwrites the view with the given ID. In case the view cannot
+	// be found, an error will be returned.
+	UpdateView(id int64, view model.View) error
+
+	// DeleteView deletes the view with the given ID. In case the view cannot be
+	// found, an error will be returned.
+	DeleteView(id int64) error
+
+	// CreateWebhook stores a new webhook and returns the inserted entity.
+	CreateWebhook(webhook model.Webhook) (model.Webhook, error)
+
+	// FindWebhooks returns all webhooks ordered by their ID.
+	FindWebhooks() ([]model.Webhook, error)
+
+	// FindWebhookByID returns the webhook with the given ID. In case the
+	// webhook cannot be found, an error will be returned.
+	FindWebhookByID(id int64) (model.Webhook, error)
+
+	// DeleteWebhook deletes the webhook with the given ID along with all of its
+	// deliveries. In case the webhook cannot be found, an error will be returned.
+	DeleteWebhook(id int64) error
+
+	// CreateDelivery stores a new delivery and returns the inserted entity.
+	CreateDelivery(delivery model.Delivery) (model.Delivery, error)
+
+	// FindDeliveries returns all deliveries of the given webhook ordered by
+	// their ID.
+	FindDeliveries(webhookID int64) ([]model.Delivery, error)
+
+	// FindDueDeliveries returns up to limit pending deliveries whose next
+	// attempt is not after the given time, ordered by their next attempt.
+	FindDueDeliveries(due time.Time, limit int) ([]model.Delivery, error)
+
+	// UpdateDelivery overwrites the delivery with the given ID. In case the
+	// delivery cannot be found, an error will be returned.
+	UpdateDelivery(id int64, delivery model.Delivery) error
+
+	// SaveCalendarFeed stores the given calendar feed, replacing the existing
+	// feed of the same owner.
+	SaveCalendarFeed(feed model.CalendarFeed) error
+
+	// FindCalendarFeed returns the calendar feed of the given owner. In case
+	// the owner has no feed, an error will be returned.
+	FindCalendarFeed(owner string) (model.CalendarFeed, error)
+
+	// FindCalendarFeedByToken returns the calendar feed with the given token.
+	// In case the feed cannot be found, an error will be returned.
+	FindCalendarFeedByToken(token string) (model.CalendarFeed, error)
+
+	// DeleteCalendarFeed deletes the calendar feed of the given owner. In case
+	// the owner has no feed, an error will be returned.
+	DeleteCalendarFeed(owner string) error
+
+	// FindAllViews returns the views of all users ordered by their ID.
+	FindAllViews() ([]model.View, error)
+
+	// FindCalendarFeeds returns the calendar feeds of all users ordered by
+	// their owner.
+	FindCalendarFeeds() ([]model.CalendarFeed, error)
+
+	// CreateIdempotencyRecord stores a new idempotency record. If a record with
+	// the same owner and key exists, ErrIdempotencyKeyExists will be returned,
+	// so that only one of several concurrent requests can claim a key.
+	CreateIdempotencyRecord(record model.IdempotencyRecord) error
+
+	// FindIdempotencyRecord returns the idempotency record with the given owner
+	// and key. In case the record cannot be found, an error will be returned.
+	FindIdempotencyRecord(owner, key string) (model.IdempotencyRecord, error)
+
+	// UpdateIdempotencyRecord overwrites the idempotency record with the owner
+	// and key of the given record. In case the record cannot be found, an error
+	// will be returned.
+	UpdateIdempotencyRecord(record model.IdempotencyRecord) error
+
+	// DeleteIdempotencyRecord deletes the idempotency record with the given
+	// owner and key. Deleting a record that doesn't exist is not an error.
+	DeleteIdempotencyRecord(owner, key string) error
+
+	// DeleteExpiredIdempotencyRecords deletes all idempotency records that
+	// expire before the given time.
+	DeleteExpiredIdempotencyRecords(before time.Time) error
+
+	// RestoreToDo stores a ToDo item from a backup along with its tasks. The
+	// IDs and the version are kept, and items created afterwards receive higher
+	// IDs. The IDs must not be in use. The same applies to all Restore methods.
+	RestoreToDo(toDo model.ToDo) error
+
+	// RestoreComment stores a comment from a backup, keeping its ID.
+	RestoreComment(comment model.Comment) error
+
+	// RestoreAttachment stores the metadata of an attachment from a backup,
+	// keeping its ID.
+	RestoreAttachment(attachment model.Attachment) error
+
+	// RestoreView stores a view from a backup, keeping its ID.
+	RestoreView(view model.View) error
+
+	// RestoreWebhook stores a webhook from a backup, keeping its ID.
+	RestoreWebhook(webhook model.Webhook) error
+
+	// RestoreDelivery stores a delivery from a backup, keeping its ID.
+	RestoreDelivery(delivery model.Delivery) error
+
+	// Reindex updates the full-text search index for the ToDo item with the
+	// given ID, its tasks and its comments. If the item doesn't exist anymore,
+	// it will be removed from the index. Implementations whose index is kept
+	// up to date by the database itself may implement Reindex as a no-op.
+	Reindex(toDoID int64) error
+
+	// Search returns up to limit ToDo items, tasks and comments matching the
+	// given full-text query, ordered by their relevance.
+	Search(query string, limit int) ([]model.SearchResult, error)
+
 	// Remove removes the storage. It is the inverse operation of Initialize.
 	// Must be called before Close when wiping a storage.
 	Remove() error
@@ -4,11 +4,15 @@ package storage
 import (
 	"errors"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/dominikbraun/todo/filter"
 	"github.com/dominikbraun/todo/model"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 const (
@@ -56,17 +60,197 @@ func loadAndInitializeStorages() (map[string]Storage, error) {
 // TestStorage it not a Unit Test but rather a stateful Integration Test that
 // creates a ToDo item and simulates its entire lifecycle.
 func TestStorage(t *testing.T) {
-	storages, err := loadAndInitializeStorages()
-	if err != nil {
-		t.Fatalf("failed to initialize storages: %s", err.Error())
-	}
-
-	tests := []func(*testing.T, Storage){
+	runLifecycleTests(t, []func(*testing.T, Storage){
 		testCreateToDo,
 		testFindToDos,
 		testFindToDoByID,
 		testUpdateToDo,
 		testDeleteToDo,
+		testFindToDosWithTagsAndTasks,
+	})
+}
+
+// TestLimitStorage tests that all supported storage implementations store
+// values of the maximum lengths accepted by core.App without truncating them.
+func TestLimitStorage(t *testing.T) {
+	runLifecycleTests(t, []func(*testing.T, Storage){
+		testCreateToDoWithMaximumLengths,
+	})
+}
+
+// TestMariaDBCharsetMigration tests that Initialize converts a database that
+// has been created by an earlier version with another character set, so that
+// it can store values of the maximum lengths afterwards.
+func TestMariaDBCharsetMigration(t *testing.T) {
+	if os.Getenv(envTestMariaDB) == "" {
+		t.Skip("MariaDB tests are disabled")
+	}
+
+	config := MariaDBConfig{
+		User:     os.Getenv(envTestMariaDBUser),
+		Password: os.Getenv(envTestMariaDBPassword),
+		Address:  os.Getenv(envTestMariaDBAddress),
+		DBName:   os.Getenv(envTestMariaDBDBName),
+	}
+
+	mariaDB, err := NewMariaDB(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		_ = mariaDB.Remove()
+		_ = mariaDB.Close()
+	}()
+
+	// Create the database and a table the way earlier versions did.
+	statements := []string{
+		`CREATE DATABASE ` + config.DBName + ` CHARACTER SET latin1`,
+		`CREATE TABLE ` + config.DBName + `.tags (
+			todo_id BIGINT UNSIGNED NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			PRIMARY KEY (todo_id, name)
+		) CHARACTER SET latin1`,
+	}
+
+	for _, statement := range statements {
+		if _, err := mariaDB.db.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := mariaDB.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+
+	var collations []string
+
+	err = mariaDB.pool.Select(&collations, `SELECT table_collation FROM information_schema.tables
+			WHERE table_schema = ?
+		UNION SELECT collation_name FROM information_schema.columns
+			WHERE table_schema = ? AND collation_name IS NOT NULL`, config.DBName, config.DBName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(collations) != 1 || collations[0] != "utf8mb4_unicode_ci" {
+		t.Errorf("expected all tables and columns to use utf8mb4_unicode_ci, got %v", collations)
+	}
+
+	testCreateToDoWithMaximumLengths(t, mariaDB)
+}
+
+// TestBatchStorage tests Batch for all supported storage implementations,
+// both with and without atomicity.
+func TestBatchStorage(t *testing.T) {
+	runLifecycleTests(t, []func(*testing.T, Storage){
+		testBatch,
+		testAtomicBatchRollback,
+	})
+}
+
+// TestCommentStorage tests all comment-related Storage functions for all
+// supported implementations by simulating the lifecycle of a comment thread.
+func TestCommentStorage(t *testing.T) {
+	runLifecycleTests(t, []func(*testing.T, Storage){
+		testCreateComment,
+		testFindComments,
+		testFindCommentsByToDoIDs,
+		testUpdateComment,
+		testDeleteComment,
+		testDeleteToDoWithComments,
+	})
+}
+
+// TestAttachmentStorage tests all attachment-related Storage functions for all
+// supported implementations by simulating the lifecycle of an attachment.
+func TestAttachmentStorage(t *testing.T) {
+	runLifecycleTests(t, []func(*testing.T, Storage){
+		testCreateAttachment,
+		testFindAttachments,
+		testFindAttachmentsByToDoIDs,
+		testDeleteAttachment,
+		testDeleteToDoWithAttachments,
+	})
+}
+
+// TestSearchStorage tests the full-text search functions for all supported
+// storage implementations.
+func TestSearchStorage(t *testing.T) {
+	runLifecycleTests(t, []func(*testing.T, Storage){
+		testSearch,
+		testSearchAfterDeleteToDo,
+	})
+}
+
+// TestFilterStorage tests FindToDosByFilter and FindToDoPage for all supported
+// storage implementations, ensuring that they evaluate filters the same way.
+func TestFilterStorage(t *testing.T) {
+	runLifecycleTests(t, []func(*testing.T, Storage){
+		testFindToDosByFilter,
+		testFindToDoPage,
+	})
+}
+
+// TestViewStorage tests the view-related functions for all supported storage
+// implementations.
+func TestViewStorage(t *testing.T) {
+	runLifecycleTests(t, []func(*testing.T, Storage){
+		testCreateView,
+		testFindViews,
+		testUpdateView,
+		testDeleteView,
+	})
+}
+
+// TestWebhookStorage tests the webhook and delivery functions for all supported
+// storage implementations.
+func TestWebhookStorage(t *testing.T) {
+	runLifecycleTests(t, []func(*testing.T, Storage){
+		testCreateWebhook,
+		testCreateDelivery,
+		testFindDueDeliveries,
+		testDeleteWebhook,
+	})
+}
+
+// TestCalendarFeedStorage tests the calendar feed functions for all supported
+// storage implementations.
+func TestCalendarFeedStorage(t *testing.T) {
+	runLifecycleTests(t, []func(*testing.T, Storage){
+		testSaveCalendarFeed,
+		testDeleteCalendarFeed,
+	})
+}
+
+// TestBackupStorage tests the functions used for backups and restores for all
+// supported storage implementations.
+func TestBackupStorage(t *testing.T) {
+	runLifecycleTests(t, []func(*testing.T, Storage){
+		testRestoreToDo,
+		testRestoreCommentAndAttachment,
+		testRestoreViews,
+		testRestoreWebhookAndDelivery,
+		testFindCalendarFeeds,
+	})
+}
+
+// TestIdempotencyStorage tests the idempotency record functions for all
+// supported storage implementations.
+func TestIdempotencyStorage(t *testing.T) {
+	runLifecycleTests(t, []func(*testing.T, Storage){
+		testCreateIdempotencyRecord,
+		testUpdateIdempotencyRecord,
+		testDeleteExpiredIdempotencyRecords,
+	})
+}
+
+// runLifecycleTests initializes all storage implementations, runs the given
+// tests against each of them in order and removes the storages afterwards.
+func runLifecycleTests(t *testing.T, tests []func(*testing.T, Storage)) {
+	storages, err := loadAndInitializeStorages()
+	if err != nil {
+		t.Fatalf("failed to initialize storages: %s", err.Error())
 	}
 
 	for name, storage := range storages {
@@ -87,7 +271,8 @@ func TestStorage(t *testing.T) {
 
 func testCreateToDo(t *testing.T, storage Storage) {
 	toDo := model.ToDo{
-		Name: "ToDo 1",
+		Name:    "ToDo 1",
+		Project: "website",
 		Tasks: []model.Task{
 			{
 				Name: "Task 1",
@@ -104,6 +289,7 @@ func testCreateToDo(t *testing.T, storage Storage) {
 	}
 
 	toDo.ID = createdToDo.ID
+	toDo.Version = 1
 
 	if !cmp.Equal(createdToDo, toDo) {
 		t.Fatalf("expected ToDo %v, got %v", toDo, createdToDo)
@@ -138,17 +324,27 @@ func testFindToDoByID(t *testing.T, storage Storage) {
 	if len(toDo.Tasks) != 2 {
 		t.Errorf("expected %d tasks, got %d", 2, len(toDo.Tasks))
 	}
+
+	if toDo.Project != "website" {
+		t.Errorf("expected project %s, got %s", "website", toDo.Project)
+	}
 }
 
 func testUpdateToDo(t *testing.T, storage Storage) {
 	toDo := model.ToDo{
-		Name: "ToDo 1",
+		Name:       "ToDo 1",
+		UID:        "todo-1@example.com",
+		Priority:   "B",
+		Recurrence: "FREQ=DAILY",
 		Tasks: []model.Task{
 			{
 				ID:   1,
+				UID:  "task-1@example.com",
 				Name: "Task 1",
+				Done: true,
 			},
 			{
+				UID:  "new-task@example.com",
 				Name: "New Task",
 			},
 		},
@@ -167,6 +363,40 @@ func testUpdateToDo(t *testing.T, storage Storage) {
 	if len(updatedToDo.Tasks) != len(toDo.Tasks) {
 		t.Fatalf("expected %d tasks, got %d", len(toDo.Tasks), len(updatedToDo.Tasks))
 	}
+
+	if updatedToDo.Version != 2 {
+		t.Fatalf("expected version %d, got %d", 2, updatedToDo.Version)
+	}
+
+	if !updatedToDo.Tasks[0].Done || updatedToDo.Tasks[1].Done {
+		t.Errorf("expected only the first task to be done, got %v", updatedToDo.Tasks)
+	}
+
+	if updatedToDo.Priority != toDo.Priority {
+		t.Errorf("expected priority %s, got %s", toDo.Priority, updatedToDo.Priority)
+	}
+
+	if updatedToDo.Recurrence != toDo.Recurrence {
+		t.Errorf("expected recurrence %s, got %s", toDo.Recurrence, updatedToDo.Recurrence)
+	}
+
+	if updatedToDo.UID != toDo.UID || updatedToDo.Tasks[0].UID != toDo.Tasks[0].UID || updatedToDo.Tasks[1].UID != toDo.Tasks[1].UID {
+		t.Errorf("expected UIDs to be stored, got %v", updatedToDo)
+	}
+
+	// Updating the outdated version must not change the stored item.
+	toDo.Name = "Outdated"
+	toDo.Version = 1
+
+	if err := storage.UpdateToDo(1, toDo); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected error %v, got %v", ErrVersionConflict, err)
+	}
+
+	updatedToDo, _ = storage.FindToDoByID(1)
+
+	if updatedToDo.Name != "ToDo 1" || updatedToDo.Version != 2 {
+		t.Errorf("expected unchanged ToDo with version %d, got %v", 2, updatedToDo)
+	}
 }
 
 func testDeleteToDo(t *testing.T, storage Storage) {
@@ -178,3 +408,1128 @@ func testDeleteToDo(t *testing.T, storage Storage) {
 		t.Fatalf("expected error %v, got %v", ErrToDoNotFound, err)
 	}
 }
+
+func testFindToDosWithTagsAndTasks(t *testing.T, storage Storage) {
+	toDos := []model.ToDo{
+		{
+			Name:  "ToDo 2",
+			Tags:  []string{"errands", "home"},
+			Tasks: []model.Task{{Name: "Task 1"}, {Name: "Task 2"}},
+		},
+		{
+			Name: "ToDo 3",
+		},
+		{
+			Name:  "ToDo 4",
+			Tags:  []string{"work"},
+			Tasks: []model.Task{{Name: "Task 3"}},
+		},
+	}
+
+	for _, toDo := range toDos {
+		if _, err := storage.CreateToDo(toDo); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	foundToDos, err := storage.FindToDos()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(foundToDos) != len(toDos) {
+		t.Fatalf("expected %d ToDos, got %d", len(toDos), len(foundToDos))
+	}
+
+	expectedTasks := map[string][]string{
+		"ToDo 2": {"Task 1", "Task 2"},
+		"ToDo 3": {},
+		"ToDo 4": {"Task 3"},
+	}
+
+	for _, toDo := range foundToDos {
+		for _, expected := range toDos {
+			if expected.Name == toDo.Name && !cmp.Equal(toDo.Tags, expected.Tags) {
+				t.Errorf("expected tags %v for %s, got %v", expected.Tags, toDo.Name, toDo.Tags)
+			}
+		}
+
+		taskNames := make([]string, len(toDo.Tasks))
+		for j, task := range toDo.Tasks {
+			taskNames[j] = task.Name
+		}
+
+		if !cmp.Equal(taskNames, expectedTasks[toDo.Name]) {
+			t.Errorf("expected tasks %v for %s, got %v", expectedTasks[toDo.Name], toDo.Name, taskNames)
+		}
+	}
+}
+
+func testCreateToDoWithMaximumLengths(t *testing.T, storage Storage) {
+	// The lengths are the maximum lengths like core.MaxNameLength, using
+	// characters that take up several bytes in UTF-8.
+	toDo := model.ToDo{
+		UID:         strings.Repeat("ü", 255),
+		Name:        strings.Repeat("ä", 100),
+		Description: strings.Repeat("€", 500),
+		Project:     strings.Repeat("ö", 100),
+		Recurrence:  "FREQ=DAILY;" + strings.Repeat("x", 244),
+		Tags:        []string{strings.Repeat("é", 100)},
+		Tasks: []model.Task{
+			{
+				UID:         strings.Repeat("ü", 255),
+				Name:        strings.Repeat("😀", 100),
+				Description: strings.Repeat("€", 500),
+			},
+		},
+	}
+
+	createdToDo, err := storage.CreateToDo(toDo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	foundToDo, err := storage.FindToDoByID(createdToDo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	toDo.ID = createdToDo.ID
+	toDo.Version = 1
+	toDo.Tasks[0].ID = createdToDo.Tasks[0].ID
+
+	if diff := cmp.Diff(toDo, foundToDo); diff != "" {
+		t.Errorf("unexpected ToDo (-expected +got):\n%s", diff)
+	}
+}
+
+func testBatch(t *testing.T, storage Storage) {
+	toDo, err := storage.CreateToDo(model.ToDo{Name: "ToDo 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := storage.Batch([]BatchOperation{
+		{Action: BatchCreate, ToDo: model.ToDo{Name: "ToDo 2", Tasks: []model.Task{{Name: "Task 1"}}}},
+		{Action: BatchDelete, ID: 1000},
+		{Action: BatchUpdate, ID: toDo.ID, ToDo: model.ToDo{Name: "ToDo 1", Done: true}},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("expected %d results, got %d", 3, len(results))
+	}
+
+	if results[0].Err != nil || results[2].Err != nil {
+		t.Fatalf("unexpected errors %v and %v", results[0].Err, results[2].Err)
+	}
+
+	if !errors.Is(results[1].Err, ErrToDoNotFound) {
+		t.Errorf("expected error %v, got %v", ErrToDoNotFound, results[1].Err)
+	}
+
+	createdToDo, err := storage.FindToDoByID(results[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if createdToDo.Name != "ToDo 2" || len(createdToDo.Tasks) != 1 {
+		t.Errorf("unexpected ToDo %v", createdToDo)
+	}
+
+	updatedToDo, err := storage.FindToDoByID(toDo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !updatedToDo.Done {
+		t.Errorf("expected ToDo %d to be done", toDo.ID)
+	}
+}
+
+func testAtomicBatchRollback(t *testing.T, storage Storage) {
+	toDos, err := storage.FindToDos()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := storage.Batch([]BatchOperation{
+		{Action: BatchCreate, ToDo: model.ToDo{Name: "ToDo 3"}},
+		{Action: BatchDelete, ID: toDos[0].ID},
+		{Action: BatchUpdate, ID: 1000, ToDo: model.ToDo{Name: "ToDo 4"}},
+		{Action: BatchCreate, ToDo: model.ToDo{Name: "ToDo 5"}},
+	}, true)
+	if !errors.Is(err, ErrToDoNotFound) {
+		t.Fatalf("expected error %v, got %v", ErrToDoNotFound, err)
+	}
+
+	if len(results) != 3 {
+		t.Errorf("expected %d results, got %d", 3, len(results))
+	}
+
+	rolledBack, err := storage.FindToDos()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The order of FindToDos is unspecified.
+	byID := cmpopts.SortSlices(func(a, b model.ToDo) bool { return a.ID < b.ID })
+
+	if diff := cmp.Diff(toDos, rolledBack, byID); diff != "" {
+		t.Errorf("unexpected ToDo items after rollback (-expected +got):\n%s", diff)
+	}
+
+	results, err = storage.Batch([]BatchOperation{
+		{Action: BatchCreate, ToDo: model.ToDo{Name: "ToDo 3"}},
+		{Action: BatchDelete, ID: toDos[0].ID},
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := storage.FindToDoByID(toDos[0].ID); !errors.Is(err, ErrToDoNotFound) {
+		t.Errorf("expected error %v, got %v", ErrToDoNotFound, err)
+	}
+
+	if _, err := storage.FindToDoByID(results[0].ID); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func testCreateComment(t *testing.T, storage Storage) {
+	toDo, err := storage.CreateToDo(model.ToDo{
+		Name: "ToDo 1",
+		Tasks: []model.Task{
+			{
+				Name: "Task 1",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timestamp := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	comments := []model.Comment{
+		{
+			ToDoID:    toDo.ID,
+			Author:    "Alice",
+			Body:      "A comment on the ToDo",
+			CreatedAt: timestamp,
+			UpdatedAt: timestamp,
+		},
+		{
+			ToDoID:    toDo.ID,
+			TaskID:    toDo.Tasks[0].ID,
+			Author:    "Bob",
+			Body:      "A comment on the task",
+			CreatedAt: timestamp,
+			UpdatedAt: timestamp,
+		},
+	}
+
+	for _, comment := range comments {
+		createdComment, err := storage.CreateComment(comment)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		comment.ID = createdComment.ID
+
+		if !cmp.Equal(createdComment, comment) {
+			t.Fatalf("expected comment %v, got %v", comment, createdComment)
+		}
+	}
+}
+
+func testFindComments(t *testing.T, storage Storage) {
+	comments, err := storage.FindComments(1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(comments) != 1 {
+		t.Fatalf("expected %d comments, got %d", 1, len(comments))
+	}
+
+	if comments[0].Author != "Alice" {
+		t.Errorf("expected author %s, got %s", "Alice", comments[0].Author)
+	}
+
+	comments, err = storage.FindComments(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(comments) != 1 {
+		t.Fatalf("expected %d comments, got %d", 1, len(comments))
+	}
+
+	if comments[0].Author != "Bob" {
+		t.Errorf("expected author %s, got %s", "Bob", comments[0].Author)
+	}
+}
+
+func testFindCommentsByToDoIDs(t *testing.T, storage Storage) {
+	comments, err := storage.FindCommentsByToDoIDs([]int64{1, 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(comments) != 2 {
+		t.Fatalf("expected %d comments, got %d", 2, len(comments))
+	}
+
+	if comments[0].Author != "Alice" || comments[1].Author != "Bob" {
+		t.Errorf("expected authors %s and %s, got %s and %s", "Alice", "Bob", comments[0].Author, comments[1].Author)
+	}
+}
+
+func testUpdateComment(t *testing.T, storage Storage) {
+	comment, err := storage.FindCommentByID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	comment.Body = "An edited comment"
+
+	if err := storage.UpdateComment(1, comment); err != nil {
+		t.Fatal(err)
+	}
+
+	updatedComment, err := storage.FindCommentByID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(updatedComment, comment) {
+		t.Fatalf("expected comment %v, got %v", comment, updatedComment)
+	}
+}
+
+func testDeleteComment(t *testing.T, storage Storage) {
+	if err := storage.DeleteComment(1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storage.DeleteComment(1); !errors.Is(err, ErrCommentNotFound) {
+		t.Fatalf("expected error %v, got %v", ErrCommentNotFound, err)
+	}
+}
+
+func testDeleteToDoWithComments(t *testing.T, storage Storage) {
+	if err := storage.DeleteToDo(1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := storage.FindCommentByID(2); !errors.Is(err, ErrCommentNotFound) {
+		t.Fatalf("expected error %v, got %v", ErrCommentNotFound, err)
+	}
+}
+
+func testCreateAttachment(t *testing.T, storage Storage) {
+	toDo, err := storage.CreateToDo(model.ToDo{
+		Name: "ToDo 1",
+		Tasks: []model.Task{
+			{
+				Name: "Task 1",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timestamp := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	attachments := []model.Attachment{
+		{
+			ToDoID:      toDo.ID,
+			Filename:    "notes.txt",
+			ContentType: "text/plain",
+			Size:        42,
+			BlobKey:     "blob1",
+			CreatedAt:   timestamp,
+		},
+		{
+			ToDoID:      toDo.ID,
+			TaskID:      toDo.Tasks[0].ID,
+			Filename:    "screenshot.png",
+			ContentType: "image/png",
+			Size:        1024,
+			BlobKey:     "blob2",
+			CreatedAt:   timestamp,
+		},
+	}
+
+	for _, attachment := range attachments {
+		createdAttachment, err := storage.CreateAttachment(attachment)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		attachment.ID = createdAttachment.ID
+
+		if !cmp.Equal(createdAttachment, attachment) {
+			t.Fatalf("expected attachment %v, got %v", attachment, createdAttachment)
+		}
+	}
+}
+
+func testFindAttachments(t *testing.T, storage Storage) {
+	attachments, err := storage.FindAttachments(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(attachments) != 2 {
+		t.Fatalf("expected %d attachments, got %d", 2, len(attachments))
+	}
+
+	if attachments[1].BlobKey != "blob2" {
+		t.Errorf("expected blob key %s, got %s", "blob2", attachments[1].BlobKey)
+	}
+}
+
+func testFindAttachmentsByToDoIDs(t *testing.T, storage Storage) {
+	attachments, err := storage.FindAttachmentsByToDoIDs([]int64{1, 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(attachments) != 2 {
+		t.Fatalf("expected %d attachments, got %d", 2, len(attachments))
+	}
+
+	attachments, err = storage.FindAttachmentsByToDoIDs(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(attachments) != 0 {
+		t.Errorf("expected %d attachments, got %d", 0, len(attachments))
+	}
+}
+
+func testDeleteAttachment(t *testing.T, storage Storage) {
+	if err := storage.DeleteAttachment(1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storage.DeleteAttachment(1); !errors.Is(err, ErrAttachmentNotFound) {
+		t.Fatalf("expected error %v, got %v", ErrAttachmentNotFound, err)
+	}
+}
+
+func testDeleteToDoWithAttachments(t *testing.T, storage Storage) {
+	if err := storage.DeleteToDo(1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := storage.FindAttachmentByID(2); !errors.Is(err, ErrAttachmentNotFound) {
+		t.Fatalf("expected error %v, got %v", ErrAttachmentNotFound, err)
+	}
+}
+
+func testSearch(t *testing.T, storage Storage) {
+	toDo, err := storage.CreateToDo(model.ToDo{
+		Name:        "Release preparation",
+		Description: "Prepare the upcoming release",
+		Tasks: []model.Task{
+			{
+				Name: "Write changelog",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = storage.CreateComment(model.Comment{
+		ToDoID:    toDo.ID,
+		Author:    "Alice",
+		Body:      "The changelog needs a review",
+		CreatedAt: time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storage.Reindex(toDo.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := storage.Search("changelog", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected %d results, got %d", 2, len(results))
+	}
+
+	for _, result := range results {
+		if result.ToDoID != toDo.ID {
+			t.Errorf("expected ToDo ID %d, got %d", toDo.ID, result.ToDoID)
+		}
+
+		if result.Type == model.SearchResultToDo {
+			t.Errorf("expected only tasks and comments to match")
+		}
+	}
+}
+
+func testSearchAfterDeleteToDo(t *testing.T, storage Storage) {
+	if err := storage.DeleteToDo(1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storage.Reindex(1); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := storage.Search("changelog", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 0 {
+		t.Fatalf("expected %d results, got %d", 0, len(results))
+	}
+}
+
+func testFindToDosByFilter(t *testing.T, storage Storage) {
+	due := time.Date(2026, 10, 31, 15, 0, 0, 0, time.UTC)
+
+	toDos := []model.ToDo{
+		{
+			Name: "Prepare release",
+			Due:  &due,
+			Tags: []string{"release"},
+		},
+		{
+			Name:        "Publish release",
+			Description: "Upload 100% of the artifacts",
+			Done:        true,
+			Tags:        []string{"release", "v2"},
+		},
+		{
+			Name: "Weekly sync",
+		},
+	}
+
+	for _, toDo := range toDos {
+		if _, err := storage.CreateToDo(toDo); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := map[string][]int64{
+		"tag:release AND due<2026-11-01 AND NOT done": {1},
+		"tag:release":                  {1, 2},
+		"tag!=v2":                      {1, 3},
+		"NOT due<2026-11-01":           {2, 3},
+		"due:2026-10-31":               {1},
+		"due":                          {1},
+		"done OR name=\"weekly sync\"": {2, 3},
+		"description:\"100%\"":         {2},
+		"description:\"_\"":            {},
+		"id>1 AND id<=2":               {2},
+	}
+
+	for query, expectedIDs := range tests {
+		expression, err := filter.Parse(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		foundToDos, err := storage.FindToDosByFilter(expression)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids := make([]int64, 0)
+
+		for _, toDo := range foundToDos {
+			ids = append(ids, toDo.ID)
+		}
+
+		if !cmp.Equal(ids, expectedIDs) {
+			t.Errorf("expected %s to return %v, got %v", query, expectedIDs, ids)
+		}
+	}
+
+	toDo, err := storage.FindToDoByID(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !toDo.Done || !cmp.Equal(toDo.Tags, []string{"release", "v2"}) {
+		t.Errorf("expected done ToDo with tags, got %v", toDo)
+	}
+}
+
+func testFindToDoPage(t *testing.T, storage Storage) {
+	// The ToDo items are the ones created by testFindToDosByFilter.
+	expression, err := filter.Parse("tag:release")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expression    filter.Expression
+		afterID       int64
+		limit         int
+		expectedIDs   []int64
+		expectedTotal int
+	}{
+		{expression: nil, afterID: 0, limit: 2, expectedIDs: []int64{1, 2}, expectedTotal: 3},
+		{expression: nil, afterID: 2, limit: 2, expectedIDs: []int64{3}, expectedTotal: 3},
+		{expression: expression, afterID: 0, limit: 1, expectedIDs: []int64{1}, expectedTotal: 2},
+		{expression: expression, afterID: 1, limit: 5, expectedIDs: []int64{2}, expectedTotal: 2},
+		{expression: expression, afterID: 2, limit: 5, expectedIDs: []int64{}, expectedTotal: 2},
+	}
+
+	for _, test := range tests {
+		toDos, total, err := storage.FindToDoPage(test.expression, test.afterID, test.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids := make([]int64, 0)
+
+		for _, toDo := range toDos {
+			ids = append(ids, toDo.ID)
+		}
+
+		if !cmp.Equal(ids, test.expectedIDs) || total != test.expectedTotal {
+			t.Errorf("expected %v of %d ToDos after %d, got %v of %d", test.expectedIDs, test.expectedTotal, test.afterID, ids, total)
+		}
+	}
+
+	toDos, _, err := storage.FindToDoPage(nil, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(toDos) != 1 || !cmp.Equal(toDos[0].Tags, []string{"release", "v2"}) {
+		t.Errorf("expected ToDo with tags, got %v", toDos)
+	}
+}
+
+func testCreateView(t *testing.T, storage Storage) {
+	views := []model.View{
+		{Owner: "alice", Name: "Release", Filter: "tag:release", Sort: "due"},
+		{Owner: "bob", Name: "Open", Filter: "NOT done", Sort: "-id"},
+		{Owner: "alice", Name: "All", Filter: "", Sort: "name"},
+	}
+
+	for _, view := range views {
+		createdView, err := storage.CreateView(view)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		view.ID = createdView.ID
+
+		if !cmp.Equal(createdView, view) {
+			t.Fatalf("expected view %v, got %v", view, createdView)
+		}
+	}
+}
+
+func testFindViews(t *testing.T, storage Storage) {
+	views, err := storage.FindViews("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(views) != 2 {
+		t.Fatalf("expected %d views, got %d", 2, len(views))
+	}
+
+	if views[0].Name != "Release" || views[1].Name != "All" {
+		t.Errorf("unexpected views %v", views)
+	}
+}
+
+func testUpdateView(t *testing.T, storage Storage) {
+	view := model.View{Owner: "alice", Name: "Next release", Filter: "tag:v2", Sort: "-due"}
+
+	if err := storage.UpdateView(1, view); err != nil {
+		t.Fatal(err)
+	}
+
+	updatedView, err := storage.FindViewByID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	view.ID = 1
+
+	if !cmp.Equal(updatedView, view) {
+		t.Fatalf("expected view %v, got %v", view, updatedView)
+	}
+
+	if err := storage.UpdateView(42, view); !errors.Is(err, ErrViewNotFound) {
+		t.Fatalf("expected error %v, got %v", ErrViewNotFound, err)
+	}
+}
+
+func testDeleteView(t *testing.T, storage Storage) {
+	if err := storage.DeleteView(1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := storage.FindViewByID(1); !errors.Is(err, ErrViewNotFound) {
+		t.Fatalf("expected error %v, got %v", ErrViewNotFound, err)
+	}
+
+	if err := storage.DeleteView(1); !errors.Is(err, ErrViewNotFound) {
+		t.Fatalf("expected error %v, got %v", ErrViewNotFound, err)
+	}
+}
+
+func testCreateWebhook(t *testing.T, storage Storage) {
+	webhook := model.Webhook{
+		URL:       "https://example.com/hook",
+		Secret:    "secret",
+		Events:    []model.EventType{model.EventCreated, model.EventCompleted},
+		CreatedAt: time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	createdWebhook, err := storage.CreateWebhook(webhook)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	webhook.ID = createdWebhook.ID
+
+	foundWebhook, err := storage.FindWebhookByID(webhook.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(foundWebhook, webhook) {
+		t.Fatalf("expected webhook %v, got %v", webhook, foundWebhook)
+	}
+}
+
+func testCreateDelivery(t *testing.T, storage Storage) {
+	timestamp := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		delivery := model.Delivery{
+			WebhookID:     1,
+			EventType:     model.EventCreated,
+			Payload:       `{"id":1}`,
+			Status:        model.DeliveryPending,
+			NextAttemptAt: timestamp.Add(time.Duration(i) * time.Hour),
+			CreatedAt:     timestamp,
+			UpdatedAt:     timestamp,
+		}
+
+		createdDelivery, err := storage.CreateDelivery(delivery)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		delivery.ID = createdDelivery.ID
+
+		if !cmp.Equal(createdDelivery, delivery) {
+			t.Fatalf("expected delivery %v, got %v", delivery, createdDelivery)
+		}
+	}
+
+	deliveries, err := storage.FindDeliveries(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deliveries) != 3 || deliveries[0].Payload != `{"id":1}` {
+		t.Fatalf("unexpected deliveries %v", deliveries)
+	}
+}
+
+func testFindDueDeliveries(t *testing.T, storage Storage) {
+	delivery, _ := storage.FindDeliveries(1)
+
+	delivery[0].Status = model.DeliverySucceeded
+	delivery[0].Attempts = 1
+	delivery[0].ResponseCode = 200
+
+	if err := storage.UpdateDelivery(delivery[0].ID, delivery[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storage.UpdateDelivery(42, delivery[0]); !errors.Is(err, ErrDeliveryNotFound) {
+		t.Fatalf("expected error %v, got %v", ErrDeliveryNotFound, err)
+	}
+
+	due := time.Date(2021, 3, 1, 13, 30, 0, 0, time.UTC)
+
+	deliveries, err := storage.FindDueDeliveries(due, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deliveries) != 1 || deliveries[0].ID != delivery[1].ID {
+		t.Fatalf("expected only delivery %d to be due, got %v", delivery[1].ID, deliveries)
+	}
+}
+
+func testDeleteWebhook(t *testing.T, storage Storage) {
+	if err := storage.DeleteWebhook(1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := storage.FindWebhookByID(1); !errors.Is(err, ErrWebhookNotFound) {
+		t.Fatalf("expected error %v, got %v", ErrWebhookNotFound, err)
+	}
+
+	deliveries, _ := storage.FindDeliveries(1)
+
+	if len(deliveries) != 0 {
+		t.Errorf("expected deliveries to be deleted, got %v", deliveries)
+	}
+}
+
+func testSaveCalendarFeed(t *testing.T, storage Storage) {
+	feed := model.CalendarFeed{
+		Owner:     "alice",
+		Token:     strings.Repeat("a", 64),
+		Filter:    "NOT done",
+		CreatedAt: time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	if err := storage.SaveCalendarFeed(feed); err != nil {
+		t.Fatal(err)
+	}
+
+	// Saving a feed for the same owner replaces the old feed and its token.
+	feed.Token = strings.Repeat("b", 64)
+	feed.Filter = ""
+
+	if err := storage.SaveCalendarFeed(feed); err != nil {
+		t.Fatal(err)
+	}
+
+	foundFeed, err := storage.FindCalendarFeed("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(foundFeed, feed) {
+		t.Fatalf("expected calendar feed %v, got %v", feed, foundFeed)
+	}
+
+	if foundFeed, err = storage.FindCalendarFeedByToken(feed.Token); err != nil || foundFeed.Owner != "alice" {
+		t.Fatalf("expected calendar feed of %s, got %v (%v)", "alice", foundFeed, err)
+	}
+
+	if _, err := storage.FindCalendarFeedByToken(strings.Repeat("a", 64)); !errors.Is(err, ErrCalendarFeedNotFound) {
+		t.Fatalf("expected error %v, got %v", ErrCalendarFeedNotFound, err)
+	}
+
+	if _, err := storage.FindCalendarFeed("bob"); !errors.Is(err, ErrCalendarFeedNotFound) {
+		t.Fatalf("expected error %v, got %v", ErrCalendarFeedNotFound, err)
+	}
+}
+
+func testDeleteCalendarFeed(t *testing.T, storage Storage) {
+	if err := storage.DeleteCalendarFeed("alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := storage.FindCalendarFeed("alice"); !errors.Is(err, ErrCalendarFeedNotFound) {
+		t.Fatalf("expected error %v, got %v", ErrCalendarFeedNotFound, err)
+	}
+
+	if err := storage.DeleteCalendarFeed("alice"); !errors.Is(err, ErrCalendarFeedNotFound) {
+		t.Fatalf("expected error %v, got %v", ErrCalendarFeedNotFound, err)
+	}
+}
+
+func testRestoreToDo(t *testing.T, storage Storage) {
+	toDo := model.ToDo{
+		ID:      5,
+		Name:    "Restored",
+		Tags:    []string{"release"},
+		Tasks:   []model.Task{{ID: 7, Name: "Task 1"}, {ID: 8, Name: "Task 2", Done: true}},
+		Version: 3,
+	}
+
+	if err := storage.RestoreToDo(toDo); err != nil {
+		t.Fatal(err)
+	}
+
+	restoredToDo, err := storage.FindToDoByID(toDo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(restoredToDo, toDo) {
+		t.Fatalf("expected ToDo %v, got %v", toDo, restoredToDo)
+	}
+
+	// New items must get IDs greater than the restored IDs.
+	createdToDo, err := storage.CreateToDo(model.ToDo{Name: "Created", Tasks: []model.Task{{Name: "Task 3"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if createdToDo.ID <= toDo.ID || createdToDo.Tasks[0].ID <= 8 {
+		t.Errorf("expected IDs greater than the restored IDs, got %v", createdToDo)
+	}
+}
+
+func testRestoreCommentAndAttachment(t *testing.T, storage Storage) {
+	timestamp := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	comment := model.Comment{
+		ID:        10,
+		ToDoID:    5,
+		TaskID:    7,
+		Author:    "alice",
+		Body:      "Restored",
+		CreatedAt: timestamp,
+		UpdatedAt: timestamp,
+	}
+
+	if err := storage.RestoreComment(comment); err != nil {
+		t.Fatal(err)
+	}
+
+	if restoredComment, _ := storage.FindCommentByID(comment.ID); !cmp.Equal(restoredComment, comment) {
+		t.Errorf("expected comment %v, got %v", comment, restoredComment)
+	}
+
+	attachment := model.Attachment{
+		ID:          10,
+		ToDoID:      5,
+		Filename:    "notes.txt",
+		ContentType: "text/plain",
+		Size:        8,
+		BlobKey:     "key",
+		CreatedAt:   timestamp,
+	}
+
+	if err := storage.RestoreAttachment(attachment); err != nil {
+		t.Fatal(err)
+	}
+
+	if restoredAttachment, _ := storage.FindAttachmentByID(attachment.ID); !cmp.Equal(restoredAttachment, attachment) {
+		t.Errorf("expected attachment %v, got %v", attachment, restoredAttachment)
+	}
+
+	createdComment, _ := storage.CreateComment(model.Comment{ToDoID: 5, Author: "bob", Body: "Created", CreatedAt: timestamp, UpdatedAt: timestamp})
+	if createdComment.ID <= comment.ID {
+		t.Errorf("expected comment ID greater than %d, got %d", comment.ID, createdComment.ID)
+	}
+
+	attachment.BlobKey = "other"
+	createdAttachment, _ := storage.CreateAttachment(attachment)
+
+	if createdAttachment.ID <= 10 {
+		t.Errorf("expected attachment ID greater than %d, got %d", 10, createdAttachment.ID)
+	}
+}
+
+func testRestoreViews(t *testing.T, storage Storage) {
+	views := []model.View{
+		{ID: 4, Owner: "bob", Name: "Open", Filter: "NOT done", Sort: "-id"},
+		{ID: 2, Owner: "alice", Name: "Release", Filter: "tag:release", Sort: "due"},
+	}
+
+	for _, view := range views {
+		if err := storage.RestoreView(view); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	allViews, err := storage.FindAllViews()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []model.View{views[1], views[0]}
+
+	if !cmp.Equal(allViews, expected) {
+		t.Errorf("expected views %v, got %v", expected, allViews)
+	}
+
+	createdView, _ := storage.CreateView(model.View{Owner: "alice", Name: "All"})
+	if createdView.ID <= 4 {
+		t.Errorf("expected view ID greater than %d, got %d", 4, createdView.ID)
+	}
+}
+
+func testRestoreWebhookAndDelivery(t *testing.T, storage Storage) {
+	timestamp := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	webhook := model.Webhook{
+		ID:        3,
+		URL:       "https://example.com/hook",
+		Secret:    "secret",
+		Events:    []model.EventType{model.EventCreated},
+		CreatedAt: timestamp,
+	}
+
+	if err := storage.RestoreWebhook(webhook); err != nil {
+		t.Fatal(err)
+	}
+
+	if restoredWebhook, _ := storage.FindWebhookByID(webhook.ID); !cmp.Equal(restoredWebhook, webhook) {
+		t.Errorf("expected webhook %v, got %v", webhook, restoredWebhook)
+	}
+
+	delivery := model.Delivery{
+		ID:            6,
+		WebhookID:     webhook.ID,
+		EventType:     model.EventCreated,
+		Payload:       `{"type":"created"}`,
+		Status:        model.DeliverySucceeded,
+		Attempts:      1,
+		ResponseCode:  200,
+		NextAttemptAt: timestamp,
+		CreatedAt:     timestamp,
+		UpdatedAt:     timestamp,
+	}
+
+	if err := storage.RestoreDelivery(delivery); err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries, err := storage.FindDeliveries(webhook.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deliveries) != 1 || !cmp.Equal(deliveries[0], delivery) {
+		t.Errorf("expected delivery %v, got %v", delivery, deliveries)
+	}
+
+	delivery.Status = model.DeliveryPending
+	createdDelivery, _ := storage.CreateDelivery(delivery)
+
+	if createdDelivery.ID <= 6 {
+		t.Errorf("expected delivery ID greater than %d, got %d", 6, createdDelivery.ID)
+	}
+}
+
+func testFindCalendarFeeds(t *testing.T, storage Storage) {
+	timestamp := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	feeds := []model.CalendarFeed{
+		{Owner: "bob", Token: strings.Repeat("b", 64), CreatedAt: timestamp},
+		{Owner: "alice", Token: strings.Repeat("a", 64), Filter: "NOT done", CreatedAt: timestamp},
+	}
+
+	for _, feed := range feeds {
+		if err := storage.SaveCalendarFeed(feed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	allFeeds, err := storage.FindCalendarFeeds()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []model.CalendarFeed{feeds[1], feeds[0]}
+
+	if !cmp.Equal(allFeeds, expected) {
+		t.Errorf("expected calendar feeds %v, got %v", expected, allFeeds)
+	}
+}
+
+func testCreateIdempotencyRecord(t *testing.T, storage Storage) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	record := model.IdempotencyRecord{
+		Owner:     "alice",
+		Key:       "key-1",
+		Body:      []byte{},
+		CreatedAt: now,
+		ExpiresAt: now.Add(24 * time.Hour),
+	}
+
+	if err := storage.CreateIdempotencyRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storage.CreateIdempotencyRecord(record); !errors.Is(err, ErrIdempotencyKeyExists) {
+		t.Errorf("expected error %v, got %v", ErrIdempotencyKeyExists, err)
+	}
+
+	// Keys are scoped to their owner.
+	record.Owner = "bob"
+
+	if err := storage.CreateIdempotencyRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := storage.FindIdempotencyRecord("carol", "key-1"); !errors.Is(err, ErrIdempotencyRecordNotFound) {
+		t.Errorf("expected error %v, got %v", ErrIdempotencyRecordNotFound, err)
+	}
+}
+
+func testUpdateIdempotencyRecord(t *testing.T, storage Storage) {
+	record, err := storage.FindIdempotencyRecord("alice", "key-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record.Fingerprint = strings.Repeat("a", 64)
+	record.Status = 200
+	record.ContentType = "application/json; charset=utf-8"
+	record.Body = []byte(`{"id":1}` + "\n")
+
+	if err := storage.UpdateIdempotencyRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := storage.FindIdempotencyRecord("alice", "key-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(record, updated); diff != "" {
+		t.Errorf("unexpected record (-expected +got):\n%s", diff)
+	}
+
+	if err := storage.DeleteIdempotencyRecord("alice", "key-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storage.UpdateIdempotencyRecord(record); !errors.Is(err, ErrIdempotencyRecordNotFound) {
+		t.Errorf("expected error %v, got %v", ErrIdempotencyRecordNotFound, err)
+	}
+}
+
+func testDeleteExpiredIdempotencyRecords(t *testing.T, storage Storage) {
+	record, err := storage.FindIdempotencyRecord("bob", "key-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storage.DeleteExpiredIdempotencyRecords(record.ExpiresAt); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := storage.FindIdempotencyRecord("bob", "key-1"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if err := storage.DeleteExpiredIdempotencyRecords(record.ExpiresAt.Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := storage.FindIdempotencyRecord("bob", "key-1"); !errors.Is(err, ErrIdempotencyRecordNotFound) {
+		t.Errorf("expected error %v, got %v", ErrIdempotencyRecordNotFound, err)
+	}
+}
@@ -0,0 +1,545 @@
+// Package todocsv converts ToDo items from and to CSV files as exported and
+// imported by spreadsheet applications. Each row is a task along with the
+// fields of its ToDo item, and ToDo items without tasks take up a single row
+// with empty task columns. Rows with the same ToDo ID, or the same name if
+// there is no ID column, belong to the same ToDo item.
+//
+// Written files start with a UTF-8 byte order mark and use CRLF line breaks,
+// so that Excel detects the encoding. Values that spreadsheet applications
+// would evaluate as formulas are prefixed with a single quote.
+package todocsv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+// The fields of ToDo items and tasks. They are the column names written by
+// Encode.
+const (
+	FieldToDoID          = "todo_id"
+	FieldName            = "name"
+	FieldDescription     = "description"
+	FieldDone            = "done"
+	FieldDue             = "due"
+	FieldProject         = "project"
+	FieldPriority        = "priority"
+	FieldTags            = "tags"
+	FieldRecurrence      = "recurrence"
+	FieldTaskID          = "task_id"
+	FieldTask            = "task"
+	FieldTaskDescription = "task_description"
+	FieldTaskDone        = "task_done"
+)
+
+// Fields lists all fields in the order of the columns written by Encode. Files
+// without header are expected to have their columns in this order, unless a
+// mapping is given.
+var Fields = []string{
+	FieldToDoID, FieldName, FieldDescription, FieldDone, FieldDue, FieldProject, FieldPriority,
+	FieldTags, FieldRecurrence, FieldTaskID, FieldTask, FieldTaskDescription, FieldTaskDone,
+}
+
+// aliases maps alternative column names commonly found in spreadsheets to
+// their fields.
+var aliases = map[string]string{
+	"id":        FieldToDoID,
+	"todo":      FieldName,
+	"todo_name": FieldName,
+	"title":     FieldName,
+	"notes":     FieldDescription,
+	"completed": FieldDone,
+	"due_date":  FieldDue,
+	"tag":       FieldTags,
+	"labels":    FieldTags,
+	"subtask":   FieldTask,
+	"task_name": FieldTask,
+}
+
+// dateLayout is the layout of due dates without time.
+const dateLayout = "2006-01-02"
+
+// byteOrderMark is the UTF-8 byte order mark.
+const byteOrderMark = "\ufeff"
+
+var (
+	// ErrInvalidCSV indicates that a file is not a valid CSV file. It is
+	// wrapped by an error describing the problem.
+	ErrInvalidCSV = errors.New("invalid CSV file")
+
+	// ErrUnknownField indicates that a column mapping refers to a field that
+	// doesn't exist.
+	ErrUnknownField = errors.New("unknown field")
+
+	// ErrColumnNotFound indicates that a column mapping refers to a column that
+	// doesn't exist in the file.
+	ErrColumnNotFound = errors.New("mapped column not found")
+
+	// ErrNoNameColumn indicates that no column has been mapped to the name
+	// field, which is required for all ToDo items.
+	ErrNoNameColumn = errors.New("no column contains the ToDo names")
+)
+
+// Header determines whether the first row of a file is a header.
+type Header int
+
+const (
+	// HeaderAuto treats the first row as header if one of its values is a
+	// known column name or a column name of the mapping.
+	HeaderAuto Header = iota
+
+	// HeaderPresent treats the first row as header.
+	HeaderPresent
+
+	// HeaderAbsent treats the first row as data.
+	HeaderAbsent
+)
+
+// Mapping maps fields to columns, which are identified by their header or by
+// their 1-based position.
+type Mapping map[string]string
+
+// Options configures how a CSV file is read.
+type Options struct {
+	// Mapping maps fields to columns. Columns with a known name are mapped to
+	// their field automatically, so only other columns have to be mapped.
+	Mapping Mapping
+
+	// Header determines whether the first row is a header.
+	Header Header
+
+	// Delimiter separates the values of a row. If 0, the delimiter is detected
+	// from the first line, which may be separated by commas, semicolons or tabs.
+	Delimiter rune
+}
+
+// Entry is a ToDo item read from one or more rows. Row is the number of the
+// row the item has been read from and TaskRows the rows of its tasks, in the
+// order of the tasks. Errors lists the values that couldn't be parsed.
+type Entry struct {
+	ToDo     model.ToDo
+	Row      int
+	TaskRows []int
+	Errors   []model.RowError
+}
+
+// ParseMapping parses a mapping like `name:Title,task:Subtask,due:4`, where each
+// field is mapped to a column header or a 1-based column position.
+func ParseMapping(value string) (Mapping, error) {
+	mapping := make(Mapping)
+
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("%w: %q is not like field:column", ErrUnknownField, pair)
+		}
+
+		field, isKnown := fieldForName(parts[0])
+		if !isKnown {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownField, strings.TrimSpace(parts[0]))
+		}
+
+		mapping[field] = strings.TrimSpace(parts[1])
+	}
+
+	return mapping, nil
+}
+
+// Decode reads a CSV file and returns its ToDo items. Empty rows are skipped,
+// and values that can't be parsed are reported as errors of their entry, so
+// that the caller can decide whether to skip the entry or the whole file.
+//
+// Done values may be true, yes, x or 1, and due dates may be dates like
+// 2026-11-01 or RFC 3339 timestamps. Tags are separated by commas.
+func Decode(reader io.Reader, options Options) ([]Entry, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimPrefix(data, []byte(byteOrderMark))
+
+	csvReader := csv.NewReader(bytes.NewReader(data))
+	csvReader.FieldsPerRecord = -1
+	csvReader.Comma = options.Delimiter
+
+	if csvReader.Comma == 0 {
+		csvReader.Comma = detectDelimiter(data)
+	}
+
+	rows, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCSV, err.Error())
+	}
+
+	entries := make([]Entry, 0)
+
+	if len(rows) == 0 {
+		return entries, nil
+	}
+
+	hasHeader := options.Header == HeaderPresent ||
+		(options.Header == HeaderAuto && isHeader(rows[0], options.Mapping))
+
+	var header []string
+	if hasHeader {
+		header = rows[0]
+	}
+
+	columns, err := resolveColumns(header, options.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]int)
+
+	for i, row := range rows {
+		if hasHeader && i == 0 || isEmpty(row) {
+			continue
+		}
+
+		values := rowValues{row: row, columns: columns, number: i + 1}
+
+		key := values.get(FieldName)
+		if id := values.get(FieldToDoID); id != "" {
+			key = "id:" + id
+		}
+
+		index, exists := keys[key]
+		if !exists {
+			index = len(entries)
+			keys[key] = index
+			entries = append(entries, values.entry())
+		}
+
+		entry := &entries[index]
+
+		if task, isTask := values.task(); isTask {
+			entry.ToDo.Tasks = append(entry.ToDo.Tasks, task)
+			entry.TaskRows = append(entry.TaskRows, values.number)
+		}
+
+		entry.Errors = append(entry.Errors, values.errors...)
+	}
+
+	return entries, nil
+}
+
+// Encode writes the ToDo items and their tasks as CSV file with a header.
+func Encode(writer io.Writer, toDos []model.ToDo) error {
+	if _, err := io.WriteString(writer, byteOrderMark); err != nil {
+		return err
+	}
+
+	csvWriter := csv.NewWriter(writer)
+	csvWriter.UseCRLF = true
+
+	if err := csvWriter.Write(Fields); err != nil {
+		return err
+	}
+
+	for _, toDo := range toDos {
+		toDoValues := []string{
+			strconv.FormatInt(toDo.ID, 10),
+			escapeFormula(toDo.Name),
+			escapeFormula(toDo.Description),
+			strconv.FormatBool(toDo.Done),
+			formatDue(toDo.Due),
+			escapeFormula(toDo.Project),
+			toDo.Priority,
+			escapeFormula(strings.Join(toDo.Tags, ", ")),
+			toDo.Recurrence,
+		}
+
+		if len(toDo.Tasks) == 0 {
+			if err := csvWriter.Write(append(toDoValues, "", "", "", "")); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, task := range toDo.Tasks {
+			row := append(append([]string(nil), toDoValues...),
+				strconv.FormatInt(task.ID, 10),
+				escapeFormula(task.Name),
+				escapeFormula(task.Description),
+				strconv.FormatBool(task.Done),
+			)
+
+			if err := csvWriter.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+// rowValues provides the values of a row by their field and collects the
+// errors of values that can't be parsed.
+type rowValues struct {
+	row     []string
+	columns map[string]int
+	number  int
+	errors  []model.RowError
+}
+
+// get returns the trimmed value of the given field, or an empty string if the
+// field has no column or the row is too short.
+func (r *rowValues) get(field string) string {
+	column, exists := r.columns[field]
+	if !exists || column >= len(r.row) {
+		return ""
+	}
+
+	return unescapeFormula(strings.TrimSpace(r.row[column]))
+}
+
+// fail records an error for the given field.
+func (r *rowValues) fail(field, message string) {
+	r.errors = append(r.errors, model.RowError{
+		Row:     r.number,
+		Column:  field,
+		Message: message,
+	})
+}
+
+// entry returns an entry for the ToDo item of the row.
+func (r *rowValues) entry() Entry {
+	toDo := model.ToDo{
+		Name:        r.get(FieldName),
+		Description: r.get(FieldDescription),
+		Done:        r.bool(FieldDone),
+		Project:     r.get(FieldProject),
+		Priority:    strings.ToUpper(r.get(FieldPriority)),
+		Recurrence:  r.get(FieldRecurrence),
+	}
+
+	for _, tag := range strings.Split(r.get(FieldTags), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			toDo.Tags = append(toDo.Tags, tag)
+		}
+	}
+
+	if due := r.get(FieldDue); due != "" {
+		parsed, err := parseDue(due)
+		if err != nil {
+			r.fail(FieldDue, fmt.Sprintf("%q is not a date like 2026-11-01 or an RFC 3339 timestamp", due))
+		} else {
+			toDo.Due = &parsed
+		}
+	}
+
+	return Entry{
+		ToDo: toDo,
+		Row:  r.number,
+	}
+}
+
+// task returns the task of the row. The returned bool is false if the row has
+// no task.
+func (r *rowValues) task() (model.Task, bool) {
+	task := model.Task{
+		Name:        r.get(FieldTask),
+		Description: r.get(FieldTaskDescription),
+	}
+
+	if task.Name == "" && task.Description == "" {
+		return model.Task{}, false
+	}
+
+	task.Done = r.bool(FieldTaskDone)
+
+	return task, true
+}
+
+// bool parses the boolean value of the given field. Empty values are false.
+func (r *rowValues) bool(field string) bool {
+	switch value := strings.ToLower(r.get(field)); value {
+	case "", "false", "no", "0":
+		return false
+	case "true", "yes", "x", "1":
+		return true
+	default:
+		r.fail(field, fmt.Sprintf("%q is not true or false", value))
+		return false
+	}
+}
+
+// resolveColumns determines the column of each field. Columns with a known
+// header are mapped automatically, and the mapping takes precedence. Without
+// header and mapping, the columns are expected in the order of Fields.
+func resolveColumns(header []string, mapping Mapping) (map[string]int, error) {
+	columns := make(map[string]int)
+
+	if header == nil && len(mapping) == 0 {
+		for i, field := range Fields {
+			columns[field] = i
+		}
+		return columns, nil
+	}
+
+	for i, name := range header {
+		if field, isKnown := fieldForName(name); isKnown {
+			if _, exists := columns[field]; !exists {
+				columns[field] = i
+			}
+		}
+	}
+
+	for field, column := range mapping {
+		index, err := findColumn(header, column)
+		if err != nil {
+			return nil, err
+		}
+		columns[field] = index
+	}
+
+	if _, exists := columns[FieldName]; !exists {
+		return nil, ErrNoNameColumn
+	}
+
+	return columns, nil
+}
+
+// findColumn returns the index of a column identified by its header or by its
+// 1-based position.
+func findColumn(header []string, column string) (int, error) {
+	if position, err := strconv.Atoi(column); err == nil && position > 0 {
+		return position - 1, nil
+	}
+
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), column) {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s", ErrColumnNotFound, column)
+}
+
+// fieldForName returns the field of a column name, ignoring the case and
+// treating spaces and dashes like underscores.
+func fieldForName(name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+
+	if field, isAlias := aliases[name]; isAlias {
+		return field, true
+	}
+
+	for _, field := range Fields {
+		if field == name {
+			return field, true
+		}
+	}
+
+	return "", false
+}
+
+// isHeader reports whether a row looks like a header, i.e. it contains a known
+// column name or a column name used by the mapping.
+func isHeader(row []string, mapping Mapping) bool {
+	for _, value := range row {
+		if _, isKnown := fieldForName(value); isKnown {
+			return true
+		}
+
+		for _, column := range mapping {
+			if strings.EqualFold(strings.TrimSpace(value), column) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isEmpty reports whether all values of a row are empty.
+func isEmpty(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// detectDelimiter returns the most frequent of the supported delimiters in the
+// first line, preferring commas.
+func detectDelimiter(data []byte) rune {
+	firstLine := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		firstLine = data[:i]
+	}
+
+	delimiter, count := ',', bytes.Count(firstLine, []byte{','})
+
+	for _, candidate := range []rune{';', '\t'} {
+		if n := bytes.Count(firstLine, []byte{byte(candidate)}); n > count {
+			delimiter, count = candidate, n
+		}
+	}
+
+	return delimiter
+}
+
+// parseDue parses a due date, which is either a date or an RFC 3339 timestamp.
+func parseDue(value string) (time.Time, error) {
+	if due, err := time.Parse(dateLayout, value); err == nil {
+		return due, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+// formatDue formats a due date as date if it is at midnight UTC, and as RFC 3339
+// timestamp otherwise.
+func formatDue(due *time.Time) string {
+	if due == nil {
+		return ""
+	}
+
+	utc := due.UTC()
+
+	if utc.Equal(utc.Truncate(24 * time.Hour)) {
+		return utc.Format(dateLayout)
+	}
+
+	return utc.Format(time.RFC3339)
+}
+
+// escapeFormula prefixes values that spreadsheet applications would evaluate as
+// formulas with a single quote.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+// unescapeFormula removes the single quote added by escapeFormula.
+func unescapeFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@", rune(value[1])) {
+		return value[1:]
+	}
+
+	return value
+}
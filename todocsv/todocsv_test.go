@@ -0,0 +1,227 @@
+// Package todocsv converts ToDo items from and to CSV files as exported and
+// imported by spreadsheet applications. Each row is a task along with the
+// fields of its ToDo item, and ToDo items without tasks take up a single row
+// with empty task columns. Rows with the same ToDo ID, or the same name if
+// there is no ID column, belong to the same ToDo item.
+//
+// Written files start with a UTF-8 byte order mark and use CRLF line breaks,
+// so that Excel detects the encoding. Values that spreadsheet applications
+// would evaluate as formulas are prefixed with a single quote.
+package todocsv
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecode(t *testing.T) {
+	due := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		input    string
+		options  Options
+		expected []Entry
+	}{
+		"header with aliases and grouped tasks": {
+			input: "\ufeffTitle,Due Date,Tags,Subtask,Task Done\r\n" +
+				"Release 1.0,2026-11-01,\"release, team\",Write notes,\r\n" +
+				"Release 1.0,,,Tag commit,yes\r\n" +
+				"Call mom,,,,\r\n",
+			expected: []Entry{
+				{
+					ToDo: model.ToDo{
+						Name:  "Release 1.0",
+						Due:   &due,
+						Tags:  []string{"release", "team"},
+						Tasks: []model.Task{{Name: "Write notes"}, {Name: "Tag commit", Done: true}},
+					},
+					Row:      2,
+					TaskRows: []int{2, 3},
+				},
+				{ToDo: model.ToDo{Name: "Call mom"}, Row: 4},
+			},
+		},
+		"semicolons and mapped columns": {
+			input:   "Was;Wann;Erledigt\nSteuern;2026-11-01;x\n",
+			options: Options{Mapping: Mapping{FieldName: "Was", FieldDue: "wann", FieldDone: "3"}},
+			expected: []Entry{
+				{ToDo: model.ToDo{Name: "Steuern", Due: &due, Done: true}, Row: 2},
+			},
+		},
+		"no header in export order": {
+			input: "1,Release 1.0,,false,,website,a,,,,'=SUM(A1),,\n",
+			expected: []Entry{
+				{
+					ToDo:     model.ToDo{Name: "Release 1.0", Project: "website", Priority: "A", Tasks: []model.Task{{Name: "=SUM(A1)"}}},
+					Row:      1,
+					TaskRows: []int{1},
+				},
+			},
+		},
+		"rows grouped by ToDo ID": {
+			input: "todo_id\tname\ttask\n1\tPlants\tWater\n2\tPlants\t\n1\tPlants\tFertilize\n",
+			expected: []Entry{
+				{
+					ToDo:     model.ToDo{Name: "Plants", Tasks: []model.Task{{Name: "Water"}, {Name: "Fertilize"}}},
+					Row:      2,
+					TaskRows: []int{2, 4},
+				},
+				{ToDo: model.ToDo{Name: "Plants"}, Row: 3},
+			},
+		},
+		"invalid values": {
+			input:   "name,due,done\nToDo 1,tomorrow,maybe\n",
+			options: Options{Header: HeaderPresent},
+			expected: []Entry{
+				{
+					ToDo: model.ToDo{Name: "ToDo 1"},
+					Row:  2,
+					Errors: []model.RowError{
+						{Row: 2, Column: FieldDone, Message: `"maybe" is not true or false`},
+						{Row: 2, Column: FieldDue, Message: `"tomorrow" is not a date like 2026-11-01 or an RFC 3339 timestamp`},
+					},
+				},
+			},
+		},
+		"empty file": {
+			input:    "",
+			expected: []Entry{},
+		},
+	}
+
+	for name, test := range tests {
+		entries, err := Decode(strings.NewReader(test.input), test.options)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", name, err.Error())
+			continue
+		}
+
+		if diff := cmp.Diff(test.expected, entries); diff != "" {
+			t.Errorf("%s: unexpected entries (-expected +got):\n%s", name, diff)
+		}
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		options  Options
+		expected error
+	}{
+		"unterminated quote":   {"name\n\"ToDo 1\n", Options{}, ErrInvalidCSV},
+		"no name column":       {"title x,due\nToDo 1,\n", Options{Header: HeaderPresent}, ErrNoNameColumn},
+		"mapped column absent": {"name,due\nToDo 1,\n", Options{Mapping: Mapping{FieldDue: "Deadline"}}, ErrColumnNotFound},
+	}
+
+	for name, test := range tests {
+		if _, err := Decode(strings.NewReader(test.input), test.options); !errors.Is(err, test.expected) {
+			t.Errorf("%s: expected error %v, got %v", name, test.expected, err)
+		}
+	}
+}
+
+func TestParseMapping(t *testing.T) {
+	mapping, err := ParseMapping("Title:Was, due date:4,")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(Mapping{FieldName: "Was", FieldDue: "4"}, mapping); diff != "" {
+		t.Errorf("unexpected mapping (-expected +got):\n%s", diff)
+	}
+
+	for _, value := range []string{"color:3", "name", "name:"} {
+		if _, err := ParseMapping(value); !errors.Is(err, ErrUnknownField) {
+			t.Errorf("%s: expected error %v, got %v", value, ErrUnknownField, err)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	due := time.Date(2026, 11, 1, 12, 30, 0, 0, time.UTC)
+
+	toDos := []model.ToDo{
+		{
+			ID:       4,
+			Name:     "Release 1.0",
+			Priority: "A",
+			Tags:     []string{"release", "team"},
+			Due:      &due,
+			Tasks:    []model.Task{{ID: 1, Name: "Write notes"}, {ID: 2, Name: "-1 bugs", Done: true}},
+		},
+		{ID: 7, Name: "=cmd", Description: "Line 1\nLine 2", Done: true},
+	}
+
+	expected := "\ufefftodo_id,name,description,done,due,project,priority,tags,recurrence,task_id,task,task_description,task_done\r\n" +
+		"4,Release 1.0,,false,2026-11-01T12:30:00Z,,A,\"release, team\",,1,Write notes,,false\r\n" +
+		"4,Release 1.0,,false,2026-11-01T12:30:00Z,,A,\"release, team\",,2,'-1 bugs,,true\r\n" +
+		"7,'=cmd,\"Line 1\r\nLine 2\",true,,,,,,,,,\r\n"
+
+	var buffer bytes.Buffer
+
+	if err := Encode(&buffer, toDos); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expected, buffer.String()); diff != "" {
+		t.Errorf("unexpected output (-expected +got):\n%s", diff)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	due := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	toDos := []model.ToDo{
+		{
+			ID:          1,
+			Name:        "Release 1.0",
+			Description: "Ship it",
+			Due:         &due,
+			Project:     "website",
+			Priority:    "B",
+			Tags:        []string{"release"},
+			Recurrence:  "weekly",
+			Tasks:       []model.Task{{ID: 1, Name: "Write notes", Description: "+changelog"}, {ID: 2, Name: "Tag commit", Done: true}},
+		},
+		{ID: 2, Name: "Release 1.0", Done: true},
+	}
+
+	var buffer bytes.Buffer
+
+	if err := Encode(&buffer, toDos); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Decode(&buffer, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// IDs are only used for grouping rows, since imported ToDo items and
+	// tasks get new IDs.
+	decoded := make([]model.ToDo, 0, len(entries))
+
+	for _, entry := range entries {
+		if len(entry.Errors) > 0 {
+			t.Errorf("unexpected errors %v", entry.Errors)
+		}
+		decoded = append(decoded, entry.ToDo)
+	}
+
+	for i := range toDos {
+		toDos[i].ID = 0
+		for j := range toDos[i].Tasks {
+			toDos[i].Tasks[j].ID = 0
+		}
+	}
+
+	if diff := cmp.Diff(toDos, decoded); diff != "" {
+		t.Errorf("unexpected ToDo items (-expected +got):\n%s", diff)
+	}
+}
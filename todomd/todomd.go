@@ -0,0 +1,261 @@
+// Package todomd converts ToDo items from and to GitHub-flavoured Markdown. A
+// ToDo item is written as a heading with its name, followed by its description
+// as paragraphs and its tasks as a task list:
+//
+//	# Release 1.0
+//
+//	Ship the first stable release.
+//
+//	- [ ] Write notes
+//	  Mention the API changes.
+//	- [x] Tag commit
+//
+// Text indented below a task item is the description of the task. Since tasks
+// can't have tasks of their own, task items of nested lists are read as tasks
+// following their parent item, and other nested list items are added to the
+// description of their parent task.
+package todomd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+// tabWidth is the number of spaces a tab is expanded to.
+const tabWidth = 4
+
+var (
+	// headingPattern matches ATX headings like `# Release 1.0`, whose optional
+	// closing sequence of `#` is not part of the name.
+	headingPattern = regexp.MustCompile(`^ {0,3}#{1,6}(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+
+	// listItemPattern matches bullet and ordered list items, capturing the
+	// indentation, the optional checkbox and the text.
+	listItemPattern = regexp.MustCompile(`^( *)(?:[-*+]|[0-9]{1,9}[.)])(?:[ \t]+(?:\[([ xX])\](?:[ \t]+|$))?(.*))?$`)
+)
+
+// SyntaxError indicates that a Markdown document doesn't describe a ToDo item.
+type SyntaxError struct {
+	Line    int
+	Message string
+}
+
+// Error returns the error message along with the line number.
+func (s *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", s.Line, s.Message)
+}
+
+// decoder holds the state of a document that is being read.
+type decoder struct {
+	toDo       model.ToDo
+	hasHeading bool
+	paragraphs []string
+	paragraph  []string
+	taskLines  [][]string
+	open       []openTask
+	afterBlank bool
+}
+
+// openTask is a task item that following lines may belong to. Its index refers
+// to the tasks of the ToDo item.
+type openTask struct {
+	index  int
+	indent int
+}
+
+// Decode reads a Markdown document describing a single ToDo item. The document
+// has to start with a heading, which may only be preceded by blank lines.
+func Decode(reader io.Reader) (model.ToDo, error) {
+	var (
+		d       decoder
+		scanner = bufio.NewScanner(reader)
+		number  = 0
+	)
+
+	for scanner.Scan() {
+		number++
+
+		if err := d.readLine(number, expandTabs(scanner.Text())); err != nil {
+			return model.ToDo{}, err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return model.ToDo{}, err
+	}
+
+	if !d.hasHeading {
+		return model.ToDo{}, &SyntaxError{Line: number, Message: "the document has no heading"}
+	}
+
+	d.endParagraph()
+
+	d.toDo.Description = strings.Join(d.paragraphs, "\n\n")
+
+	for i, lines := range d.taskLines {
+		d.toDo.Tasks[i].Description = strings.Join(lines, "\n")
+	}
+
+	return d.toDo, nil
+}
+
+// readLine processes a single line of the document.
+func (d *decoder) readLine(number int, line string) error {
+	if strings.TrimSpace(line) == "" {
+		d.endParagraph()
+		d.afterBlank = true
+		return nil
+	}
+
+	defer func() {
+		d.afterBlank = false
+	}()
+
+	if matches := headingPattern.FindStringSubmatch(line); matches != nil {
+		if d.hasHeading {
+			return &SyntaxError{Line: number, Message: "a document may only have one heading"}
+		}
+
+		d.toDo.Name = strings.TrimSpace(matches[1])
+		d.hasHeading = true
+
+		return nil
+	}
+
+	if !d.hasHeading {
+		return &SyntaxError{Line: number, Message: "the document has to start with a heading"}
+	}
+
+	indent := len(line) - len(strings.TrimLeft(line, " "))
+	matches := listItemPattern.FindStringSubmatch(line)
+	text := unescape(strings.TrimSpace(line))
+
+	// Lazy continuation lines belong to the innermost task regardless of
+	// their indentation.
+	if matches == nil && !d.afterBlank && len(d.open) > 0 {
+		d.addTaskLine(d.open[len(d.open)-1].index, text)
+		return nil
+	}
+
+	// Close the task items the line isn't nested in.
+	for len(d.open) > 0 && d.open[len(d.open)-1].indent >= indent {
+		d.open = d.open[:len(d.open)-1]
+	}
+
+	switch {
+	case matches != nil && matches[2] != "":
+		d.endParagraph()
+		d.toDo.Tasks = append(d.toDo.Tasks, model.Task{
+			Name: strings.TrimSpace(matches[3]),
+			Done: matches[2] != " ",
+		})
+		d.taskLines = append(d.taskLines, nil)
+		d.open = append(d.open, openTask{index: len(d.toDo.Tasks) - 1, indent: indent})
+	case len(d.open) > 0:
+		d.addTaskLine(d.open[len(d.open)-1].index, text)
+	default:
+		d.paragraph = append(d.paragraph, text)
+	}
+
+	return nil
+}
+
+// addTaskLine adds a line to the description of the task with the given index.
+func (d *decoder) addTaskLine(index int, line string) {
+	d.taskLines[index] = append(d.taskLines[index], line)
+}
+
+// endParagraph adds the current paragraph to the description of the ToDo item.
+func (d *decoder) endParagraph() {
+	if len(d.paragraph) == 0 {
+		return
+	}
+
+	d.paragraphs = append(d.paragraphs, strings.Join(d.paragraph, "\n"))
+	d.paragraph = nil
+}
+
+// Encode writes a ToDo item as Markdown document. Lines of descriptions that
+// would be read as heading or list item are escaped with a backslash.
+func Encode(writer io.Writer, toDo model.ToDo) error {
+	var builder strings.Builder
+
+	builder.WriteString("# " + singleLine(toDo.Name) + "\n")
+
+	if toDo.Description != "" {
+		builder.WriteString("\n")
+
+		for _, line := range strings.Split(toDo.Description, "\n") {
+			builder.WriteString(escape(strings.TrimSpace(line)) + "\n")
+		}
+	}
+
+	if len(toDo.Tasks) > 0 {
+		builder.WriteString("\n")
+	}
+
+	for _, task := range toDo.Tasks {
+		checkbox := "[ ]"
+		if task.Done {
+			checkbox = "[x]"
+		}
+
+		builder.WriteString(fmt.Sprintf("- %s %s\n", checkbox, singleLine(task.Name)))
+
+		if task.Description == "" {
+			continue
+		}
+
+		for _, line := range strings.Split(task.Description, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				builder.WriteString("  " + escape(line) + "\n")
+			}
+		}
+	}
+
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
+
+// escape prefixes lines that would be read as heading or list item with a
+// backslash.
+func escape(line string) string {
+	if isStructure(line) {
+		return `\` + line
+	}
+
+	return line
+}
+
+// unescape removes the backslash added by escape.
+func unescape(line string) string {
+	if strings.HasPrefix(line, `\`) && isStructure(line[1:]) {
+		return line[1:]
+	}
+
+	return line
+}
+
+// isStructure reports whether a line is a heading or a bullet list item, which
+// can be escaped with a leading backslash.
+func isStructure(line string) bool {
+	return headingPattern.MatchString(line) || listItemPattern.MatchString(line) && strings.ContainsRune("-*+", rune(line[0]))
+}
+
+// singleLine replaces line breaks with spaces.
+func singleLine(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// expandTabs replaces the tabs of the indentation with spaces.
+func expandTabs(line string) string {
+	trimmed := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(trimmed)]
+
+	return strings.ReplaceAll(indent, "\t", strings.Repeat(" ", tabWidth)) + trimmed
+}
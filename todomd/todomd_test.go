@@ -0,0 +1,156 @@
+// Package todomd converts ToDo items from and to GitHub-flavoured Markdown. A
+// ToDo item is written as a heading with its name, followed by its description
+// as paragraphs and its tasks as a task list:
+//
+//	# Release 1.0
+//
+//	Ship the first stable release.
+//
+//	- [ ] Write notes
+//	  Mention the API changes.
+//	- [x] Tag commit
+//
+// Text indented below a task item is the description of the task. Since tasks
+// can't have tasks of their own, task items of nested lists are read as tasks
+// following their parent item, and other nested list items are added to the
+// description of their parent task.
+package todomd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecode(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		expected model.ToDo
+	}{
+		"heading, paragraphs and task list": {
+			input: "\n## Release 1.0 ##\n\nShip the first\nstable release.\n\nSee the *milestone*.\n\n" +
+				"- [ ] Write notes\n  Mention the API changes.\n* [X] Tag commit\n",
+			expected: model.ToDo{
+				Name:        "Release 1.0",
+				Description: "Ship the first\nstable release.\n\nSee the *milestone*.",
+				Tasks: []model.Task{
+					{Name: "Write notes", Description: "Mention the API changes."},
+					{Name: "Tag commit", Done: true},
+				},
+			},
+		},
+		"nested lists": {
+			input: "# Meeting\n\n1. [ ] Prepare slides\n\t- [x] Outline\n\t- Ask Bob\n\t  for numbers\n2. [ ] Book room\nlazy continuation\n\nNotes at the end\n- plain item\n",
+			expected: model.ToDo{
+				Name:        "Meeting",
+				Description: "Notes at the end\n- plain item",
+				Tasks: []model.Task{
+					{Name: "Prepare slides", Description: "- Ask Bob\nfor numbers"},
+					{Name: "Outline", Done: true},
+					{Name: "Book room", Description: "lazy continuation"},
+				},
+			},
+		},
+		"escaped lines": {
+			input:    "# Escapes\n\n\\# Not a heading\n\\- [ ] Not a task\n\\*emphasis*\n",
+			expected: model.ToDo{Name: "Escapes", Description: "# Not a heading\n- [ ] Not a task\n\\*emphasis*"},
+		},
+	}
+
+	for name, test := range tests {
+		toDo, err := Decode(strings.NewReader(test.input))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", name, err.Error())
+			continue
+		}
+
+		if diff := cmp.Diff(test.expected, toDo); diff != "" {
+			t.Errorf("%s: unexpected ToDo item (-expected +got):\n%s", name, diff)
+		}
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		line  int
+	}{
+		"empty document":        {"\n\n", 2},
+		"text before heading":   {"\nSome text\n# ToDo", 2},
+		"task before heading":   {"- [ ] Task\n# ToDo", 1},
+		"more than one heading": {"# ToDo 1\n\n- [ ] Task\n\n# ToDo 2", 5},
+	}
+
+	for name, test := range tests {
+		_, err := Decode(strings.NewReader(test.input))
+
+		var syntaxError *SyntaxError
+
+		if !errors.As(err, &syntaxError) || syntaxError.Line != test.line {
+			t.Errorf("%s: expected syntax error at line %d, got %v", name, test.line, err)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	toDo := model.ToDo{
+		ID:          4,
+		Name:        "Release\n1.0",
+		Description: "Ship it.\n\n# Not a heading\n**Bold**",
+		Tasks: []model.Task{
+			{ID: 1, Name: "Write notes", Description: "Line 1\n\n- [ ] Not a task"},
+			{ID: 2, Name: "Tag commit", Done: true},
+		},
+	}
+
+	expected := "# Release 1.0\n\n" +
+		"Ship it.\n\n\\# Not a heading\n**Bold**\n\n" +
+		"- [ ] Write notes\n  Line 1\n  \\- [ ] Not a task\n" +
+		"- [x] Tag commit\n"
+
+	var buffer bytes.Buffer
+
+	if err := Encode(&buffer, toDo); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expected, buffer.String()); diff != "" {
+		t.Errorf("unexpected output (-expected +got):\n%s", diff)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	// A document in canonical form must be written exactly as it has been read.
+	input := "# Release 1.0\n\n" +
+		"Ship the first\nstable release.\n\n\\- Not a list\n\n" +
+		"- [ ] Write notes\n  Mention the API changes.\n  \\# Not a heading\n" +
+		"- [x] Tag commit\n"
+
+	toDo, err := Decode(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buffer bytes.Buffer
+
+	if err := Encode(&buffer, toDo); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(input, buffer.String()); diff != "" {
+		t.Errorf("unexpected output (-expected +got):\n%s", diff)
+	}
+
+	decoded, err := Decode(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(toDo, decoded); diff != "" {
+		t.Errorf("unexpected ToDo item (-expected +got):\n%s", diff)
+	}
+}
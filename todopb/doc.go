@@ -0,0 +1,8 @@
+// Package todopb provides the protocol buffer messages and the gRPC service
+// definition of the ToDo app. The Go code is generated from todo.proto.
+//
+// Unlike building the app, generating the code requires Go 1.22 or newer,
+// since buf is run in the version given below.
+package todopb
+
+//go:generate go run github.com/bufbuild/buf/cmd/buf@v1.50.0 generate
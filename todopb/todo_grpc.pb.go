@@ -0,0 +1,314 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package todopb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ToDoServiceClient is the client API for ToDoService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ToDoServiceClient interface {
+	CreateToDo(ctx context.Context, in *CreateToDoRequest, opts ...grpc.CallOption) (*ToDo, error)
+	ListToDos(ctx context.Context, in *ListToDosRequest, opts ...grpc.CallOption) (*ListToDosResponse, error)
+	GetToDo(ctx context.Context, in *GetToDoRequest, opts ...grpc.CallOption) (*ToDo, error)
+	UpdateToDo(ctx context.Context, in *UpdateToDoRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	DeleteToDo(ctx context.Context, in *DeleteToDoRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// WatchToDos streams the changes of ToDo items until the client cancels the
+	// call. It corresponds to the GET /events endpoint.
+	WatchToDos(ctx context.Context, in *WatchToDosRequest, opts ...grpc.CallOption) (ToDoService_WatchToDosClient, error)
+}
+
+type toDoServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewToDoServiceClient(cc grpc.ClientConnInterface) ToDoServiceClient {
+	return &toDoServiceClient{cc}
+}
+
+func (c *toDoServiceClient) CreateToDo(ctx context.Context, in *CreateToDoRequest, opts ...grpc.CallOption) (*ToDo, error) {
+	out := new(ToDo)
+	err := c.cc.Invoke(ctx, "/todo.v1.ToDoService/CreateToDo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) ListToDos(ctx context.Context, in *ListToDosRequest, opts ...grpc.CallOption) (*ListToDosResponse, error) {
+	out := new(ListToDosResponse)
+	err := c.cc.Invoke(ctx, "/todo.v1.ToDoService/ListToDos", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) GetToDo(ctx context.Context, in *GetToDoRequest, opts ...grpc.CallOption) (*ToDo, error) {
+	out := new(ToDo)
+	err := c.cc.Invoke(ctx, "/todo.v1.ToDoService/GetToDo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) UpdateToDo(ctx context.Context, in *UpdateToDoRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/todo.v1.ToDoService/UpdateToDo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) DeleteToDo(ctx context.Context, in *DeleteToDoRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/todo.v1.ToDoService/DeleteToDo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) WatchToDos(ctx context.Context, in *WatchToDosRequest, opts ...grpc.CallOption) (ToDoService_WatchToDosClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ToDoService_ServiceDesc.Streams[0], "/todo.v1.ToDoService/WatchToDos", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &toDoServiceWatchToDosClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ToDoService_WatchToDosClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type toDoServiceWatchToDosClient struct {
+	grpc.ClientStream
+}
+
+func (x *toDoServiceWatchToDosClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ToDoServiceServer is the server API for ToDoService service.
+// All implementations must embed UnimplementedToDoServiceServer
+// for forward compatibility
+type ToDoServiceServer interface {
+	CreateToDo(context.Context, *CreateToDoRequest) (*ToDo, error)
+	ListToDos(context.Context, *ListToDosRequest) (*ListToDosResponse, error)
+	GetToDo(context.Context, *GetToDoRequest) (*ToDo, error)
+	UpdateToDo(context.Context, *UpdateToDoRequest) (*emptypb.Empty, error)
+	DeleteToDo(context.Context, *DeleteToDoRequest) (*emptypb.Empty, error)
+	// WatchToDos streams the changes of ToDo items until the client cancels the
+	// call. It corresponds to the GET /events endpoint.
+	WatchToDos(*WatchToDosRequest, ToDoService_WatchToDosServer) error
+	mustEmbedUnimplementedToDoServiceServer()
+}
+
+// UnimplementedToDoServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedToDoServiceServer struct {
+}
+
+func (UnimplementedToDoServiceServer) CreateToDo(context.Context, *CreateToDoRequest) (*ToDo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateToDo not implemented")
+}
+func (UnimplementedToDoServiceServer) ListToDos(context.Context, *ListToDosRequest) (*ListToDosResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListToDos not implemented")
+}
+func (UnimplementedToDoServiceServer) GetToDo(context.Context, *GetToDoRequest) (*ToDo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetToDo not implemented")
+}
+func (UnimplementedToDoServiceServer) UpdateToDo(context.Context, *UpdateToDoRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateToDo not implemented")
+}
+func (UnimplementedToDoServiceServer) DeleteToDo(context.Context, *DeleteToDoRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteToDo not implemented")
+}
+func (UnimplementedToDoServiceServer) WatchToDos(*WatchToDosRequest, ToDoService_WatchToDosServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchToDos not implemented")
+}
+func (UnimplementedToDoServiceServer) mustEmbedUnimplementedToDoServiceServer() {}
+
+// UnsafeToDoServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ToDoServiceServer will
+// result in compilation errors.
+type UnsafeToDoServiceServer interface {
+	mustEmbedUnimplementedToDoServiceServer()
+}
+
+func RegisterToDoServiceServer(s grpc.ServiceRegistrar, srv ToDoServiceServer) {
+	s.RegisterService(&ToDoService_ServiceDesc, srv)
+}
+
+func _ToDoService_CreateToDo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateToDoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).CreateToDo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.ToDoService/CreateToDo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).CreateToDo(ctx, req.(*CreateToDoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_ListToDos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListToDosRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).ListToDos(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.ToDoService/ListToDos",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).ListToDos(ctx, req.(*ListToDosRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_GetToDo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetToDoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).GetToDo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.ToDoService/GetToDo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).GetToDo(ctx, req.(*GetToDoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_UpdateToDo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateToDoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).UpdateToDo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.ToDoService/UpdateToDo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).UpdateToDo(ctx, req.(*UpdateToDoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_DeleteToDo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteToDoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).DeleteToDo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.ToDoService/DeleteToDo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).DeleteToDo(ctx, req.(*DeleteToDoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_WatchToDos_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchToDosRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ToDoServiceServer).WatchToDos(m, &toDoServiceWatchToDosServer{stream})
+}
+
+type ToDoService_WatchToDosServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type toDoServiceWatchToDosServer struct {
+	grpc.ServerStream
+}
+
+func (x *toDoServiceWatchToDosServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// ToDoService_ServiceDesc is the grpc.ServiceDesc for ToDoService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ToDoService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "todo.v1.ToDoService",
+	HandlerType: (*ToDoServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateToDo",
+			Handler:    _ToDoService_CreateToDo_Handler,
+		},
+		{
+			MethodName: "ListToDos",
+			Handler:    _ToDoService_ListToDos_Handler,
+		},
+		{
+			MethodName: "GetToDo",
+			Handler:    _ToDoService_GetToDo_Handler,
+		},
+		{
+			MethodName: "UpdateToDo",
+			Handler:    _ToDoService_UpdateToDo_Handler,
+		},
+		{
+			MethodName: "DeleteToDo",
+			Handler:    _ToDoService_DeleteToDo_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchToDos",
+			Handler:       _ToDoService_WatchToDos_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "todo.proto",
+}
@@ -0,0 +1,12 @@
+//go:build tools
+// +build tools
+
+// Package todopb provides the protocol buffer messages and the gRPC service
+// definition of the ToDo app. The Go code is generated from todo.proto.
+package todopb
+
+// The code generators are imported so that their versions are pinned by go.mod.
+import (
+	_ "google.golang.org/grpc/cmd/protoc-gen-go-grpc"
+	_ "google.golang.org/protobuf/cmd/protoc-gen-go"
+)
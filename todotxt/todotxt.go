@@ -0,0 +1,294 @@
+// Package todotxt converts ToDo items from and to the todo.txt format described
+// at https://github.com/todotxt/todo.txt. A ToDo item is written as a line like
+// `(A) Release 1.0 +website @release due:2026-11-01`, where the priority, the
+// project, the contexts and the due date are mapped to the priority, project,
+// tags and due date of the item. Completed items start with an `x`.
+//
+// Since todo.txt has no notion of sub-tasks, tasks are written as lines of
+// their own that refer to their ToDo item with a `p:` key matching the `id:`
+// key of the item's line, which is the convention used by tools like topydo.
+package todotxt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dominikbraun/todo/model"
+)
+
+// dateLayout is the layout of all dates in todo.txt files.
+const dateLayout = "2006-01-02"
+
+// SyntaxError indicates that a line of a todo.txt file is invalid. Line is the
+// 1-based number of the offending line.
+type SyntaxError struct {
+	Line    int
+	Message string
+}
+
+// Error returns a description of the syntax error pointing at the line.
+func (s *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid todo.txt at line %d: %s", s.Line, s.Message)
+}
+
+// line is a parsed line, which is either a ToDo item or a task. id is the value
+// of the `id:` key, parent the value of the `p:` key that only tasks have.
+type line struct {
+	number int
+	toDo   model.ToDo
+	id     string
+	parent string
+}
+
+// Decode reads a todo.txt file and returns its ToDo items along with their
+// tasks. Empty lines are skipped. Creation and completion dates are ignored
+// since ToDo items have no such fields.
+//
+// If a line is invalid, a *SyntaxError will be returned.
+func Decode(reader io.Reader) ([]model.ToDo, error) {
+	var (
+		scanner = bufio.NewScanner(reader)
+		toDos   = make([]model.ToDo, 0)
+		parents = make(map[string]int)
+		tasks   []line
+	)
+
+	for number := 1; scanner.Scan(); number++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		parsed, err := parseLine(text)
+		if err != nil {
+			return nil, &SyntaxError{Line: number, Message: err.Error()}
+		}
+
+		parsed.number = number
+
+		if parsed.parent != "" {
+			tasks = append(tasks, parsed)
+			continue
+		}
+
+		if parsed.id != "" {
+			if _, exists := parents[parsed.id]; exists {
+				return nil, &SyntaxError{Line: number, Message: fmt.Sprintf("id:%s is used more than once", parsed.id)}
+			}
+			parents[parsed.id] = len(toDos)
+		}
+
+		toDos = append(toDos, parsed.toDo)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Tasks are assigned after all lines have been read, so that a task may
+	// precede its ToDo item.
+	for _, task := range tasks {
+		i, exists := parents[task.parent]
+		if !exists {
+			return nil, &SyntaxError{Line: task.number, Message: fmt.Sprintf("p:%s refers to no line with id:%s", task.parent, task.parent)}
+		}
+
+		toDos[i].Tasks = append(toDos[i].Tasks, model.Task{
+			Name: task.toDo.Name,
+			Done: task.toDo.Done,
+		})
+	}
+
+	return toDos, nil
+}
+
+// parseLine parses a single, non-empty line.
+func parseLine(text string) (line, error) {
+	var (
+		parsed line
+		words  = strings.Fields(text)
+		name   []string
+	)
+
+	// Completed lines start with an `x`, followed by the optional completion
+	// and creation dates. Other lines may start with a priority and the
+	// creation date.
+	if words[0] == "x" {
+		parsed.toDo.Done = true
+		words = words[1:]
+
+		for i := 0; i < 2 && len(words) > 0 && isDate(words[0]); i++ {
+			words = words[1:]
+		}
+	} else {
+		if len(words[0]) == 3 && words[0][0] == '(' && words[0][2] == ')' && isPriority(words[0][1:2]) {
+			parsed.toDo.Priority = words[0][1:2]
+			words = words[1:]
+		}
+
+		if len(words) > 0 && isDate(words[0]) {
+			words = words[1:]
+		}
+	}
+
+	for _, word := range words {
+		switch {
+		case len(word) > 1 && word[0] == '+':
+			if parsed.toDo.Project == "" {
+				parsed.toDo.Project = word[1:]
+			} else {
+				parsed.toDo.Tags = append(parsed.toDo.Tags, word[1:])
+			}
+			continue
+		case len(word) > 1 && word[0] == '@':
+			parsed.toDo.Tags = append(parsed.toDo.Tags, word[1:])
+			continue
+		}
+
+		key, value, isKeyValue := splitKeyValue(word)
+
+		switch {
+		case !isKeyValue:
+			name = append(name, word)
+		case key == "due":
+			due, err := time.Parse(dateLayout, value)
+			if err != nil {
+				return line{}, fmt.Errorf("due:%s is not a date like 2026-11-01", value)
+			}
+			parsed.toDo.Due = &due
+		case key == "pri":
+			if !isPriority(value) {
+				return line{}, fmt.Errorf("pri:%s is not a letter from A to Z", value)
+			}
+			parsed.toDo.Priority = value
+		case key == "id":
+			parsed.id = value
+		case key == "p":
+			parsed.parent = value
+		default:
+			name = append(name, word)
+		}
+	}
+
+	if len(name) == 0 {
+		return line{}, fmt.Errorf("the line has no description")
+	}
+
+	parsed.toDo.Name = strings.Join(name, " ")
+
+	return parsed, nil
+}
+
+// Encode writes the ToDo items and their tasks in todo.txt format. Descriptions
+// are omitted since todo.txt has no such field, and due dates are written as
+// dates in UTC.
+//
+// ToDo items with tasks get an `id:` key with their ID, or with their position
+// in the list if they haven't been stored yet.
+func Encode(writer io.Writer, toDos []model.ToDo) error {
+	buffered := bufio.NewWriter(writer)
+
+	for i, toDo := range toDos {
+		var words []string
+
+		if toDo.Done {
+			words = append(words, "x")
+		} else if toDo.Priority != "" {
+			words = append(words, "("+toDo.Priority+")")
+		}
+
+		words = append(words, toDo.Name)
+
+		if toDo.Project != "" {
+			words = append(words, "+"+joinWords(toDo.Project))
+		}
+
+		for _, tag := range toDo.Tags {
+			words = append(words, "@"+joinWords(tag))
+		}
+
+		if toDo.Due != nil {
+			words = append(words, "due:"+toDo.Due.UTC().Format(dateLayout))
+		}
+
+		// The priority of completed items is kept as `pri:` key, which is
+		// the convention of the todo.txt CLI.
+		if toDo.Done && toDo.Priority != "" {
+			words = append(words, "pri:"+toDo.Priority)
+		}
+
+		id := toDo.ID
+		if id == 0 {
+			id = int64(i + 1)
+		}
+
+		if len(toDo.Tasks) > 0 {
+			words = append(words, "id:"+strconv.FormatInt(id, 10))
+		}
+
+		if err := writeLine(buffered, words); err != nil {
+			return err
+		}
+
+		for _, task := range toDo.Tasks {
+			words = words[:0]
+
+			if task.Done {
+				words = append(words, "x")
+			}
+
+			words = append(words, task.Name, "p:"+strconv.FormatInt(id, 10))
+
+			if err := writeLine(buffered, words); err != nil {
+				return err
+			}
+		}
+	}
+
+	return buffered.Flush()
+}
+
+// writeLine writes the words as a single line. Line breaks within the words are
+// replaced with spaces.
+func writeLine(writer io.Writer, words []string) error {
+	_, err := fmt.Fprintln(writer, strings.Join(strings.Fields(strings.Join(words, " ")), " "))
+	return err
+}
+
+// splitKeyValue splits a word like `due:2026-11-01` into key and value. URLs
+// like `https://example.com` are not considered key-value pairs.
+func splitKeyValue(word string) (string, string, bool) {
+	i := strings.IndexByte(word, ':')
+	if i <= 0 || i == len(word)-1 {
+		return "", "", false
+	}
+
+	key, value := word[:i], word[i+1:]
+
+	if strings.ContainsRune(value, ':') || strings.HasPrefix(value, "//") {
+		return "", "", false
+	}
+
+	return key, value, true
+}
+
+// joinWords joins the words of a project or tag with dashes, since spaces would
+// end the project or context in todo.txt.
+func joinWords(value string) string {
+	return strings.Join(strings.Fields(value), "-")
+}
+
+// isDate reports whether the word is a date like 2026-11-01.
+func isDate(word string) bool {
+	_, err := time.Parse(dateLayout, word)
+	return err == nil
+}
+
+// isPriority reports whether the value is a priority from A to Z.
+func isPriority(value string) bool {
+	return len(value) == 1 && value[0] >= 'A' && value[0] <= 'Z'
+}
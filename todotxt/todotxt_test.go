@@ -0,0 +1,172 @@
+// Package todotxt converts ToDo items from and to the todo.txt format described
+// at https://github.com/todotxt/todo.txt. A ToDo item is written as a line like
+// `(A) Release 1.0 +website @release due:2026-11-01`, where the priority, the
+// project, the contexts and the due date are mapped to the priority, project,
+// tags and due date of the item. Completed items start with an `x`.
+//
+// Since todo.txt has no notion of sub-tasks, tasks are written as lines of
+// their own that refer to their ToDo item with a `p:` key matching the `id:`
+// key of the item's line, which is the convention used by tools like topydo.
+package todotxt
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dominikbraun/todo/model"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecode(t *testing.T) {
+	due := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		input    string
+		expected []model.ToDo
+	}{
+		"priority, project, contexts and due date": {
+			input: "(A) 2026-10-01 Release 1.0 +website @release @team due:2026-11-01\n",
+			expected: []model.ToDo{{
+				Name:     "Release 1.0",
+				Priority: "A",
+				Project:  "website",
+				Tags:     []string{"release", "team"},
+				Due:      &due,
+			}},
+		},
+		"completed with dates and priority key": {
+			input:    "x 2026-10-18 2026-10-01 Call mom pri:B",
+			expected: []model.ToDo{{Name: "Call mom", Done: true, Priority: "B"}},
+		},
+		"additional projects and unknown keys": {
+			input: "Read https://example.com/docs at 10:30 +docs +website",
+			expected: []model.ToDo{{
+				Name:    "Read https://example.com/docs at 10:30",
+				Project: "docs",
+				Tags:    []string{"website"},
+			}},
+		},
+		"tasks before and after their ToDo item": {
+			input: "\nWrite notes p:r1\nRelease 1.0 id:r1\n\nx Tag commit p:r1\nWater plants\n",
+			expected: []model.ToDo{
+				{
+					Name:  "Release 1.0",
+					Tasks: []model.Task{{Name: "Write notes"}, {Name: "Tag commit", Done: true}},
+				},
+				{Name: "Water plants"},
+			},
+		},
+		"empty file": {
+			input:    "\n\n",
+			expected: []model.ToDo{},
+		},
+	}
+
+	for name, test := range tests {
+		toDos, err := Decode(strings.NewReader(test.input))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", name, err.Error())
+			continue
+		}
+
+		if diff := cmp.Diff(test.expected, toDos); diff != "" {
+			t.Errorf("%s: unexpected ToDo items (-expected +got):\n%s", name, diff)
+		}
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		line  int
+	}{
+		"invalid due date":    {"ToDo 1\nToDo 2 due:tomorrow", 2},
+		"invalid priority":    {"x ToDo 1 pri:a", 1},
+		"no description":      {"ToDo 1\n\nx 2026-10-18 +website", 3},
+		"unknown parent":      {"ToDo 1 id:1\nTask 1 p:2", 2},
+		"duplicate parent id": {"ToDo 1 id:1\nToDo 2 id:1", 2},
+	}
+
+	for name, test := range tests {
+		_, err := Decode(strings.NewReader(test.input))
+
+		var syntaxError *SyntaxError
+
+		if !errors.As(err, &syntaxError) || syntaxError.Line != test.line {
+			t.Errorf("%s: expected syntax error at line %d, got %v", name, test.line, err)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	due := time.Date(2026, 11, 1, 12, 30, 0, 0, time.UTC)
+
+	toDos := []model.ToDo{
+		{
+			ID:          4,
+			Name:        "Release 1.0",
+			Description: "Not part of todo.txt",
+			Priority:    "A",
+			Project:     "web site",
+			Tags:        []string{"release"},
+			Due:         &due,
+			Tasks:       []model.Task{{ID: 1, Name: "Write\nnotes"}, {ID: 2, Name: "Tag commit", Done: true}},
+		},
+		{ID: 7, Name: "Call mom", Done: true, Priority: "B"},
+	}
+
+	expected := "(A) Release 1.0 +web-site @release due:2026-11-01 id:4\n" +
+		"Write notes p:4\n" +
+		"x Tag commit p:4\n" +
+		"x Call mom pri:B\n"
+
+	var buffer bytes.Buffer
+
+	if err := Encode(&buffer, toDos); err != nil {
+		t.Fatal(err)
+	}
+
+	if buffer.String() != expected {
+		t.Errorf("expected output %q, got %q", expected, buffer.String())
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	// A file in canonical form must be written exactly as it has been read.
+	input := "(A) Release 1.0 +website @release @team due:2026-11-01 id:1\n" +
+		"Write notes p:1\n" +
+		"x Tag commit p:1\n" +
+		"x Call mom @phone pri:B\n" +
+		"Water plants\n" +
+		"(C) Fix bug id:4\n" +
+		"Reproduce p:4\n"
+
+	toDos, err := Decode(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buffer bytes.Buffer
+
+	if err := Encode(&buffer, toDos); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(input, buffer.String()); diff != "" {
+		t.Errorf("unexpected output (-expected +got):\n%s", diff)
+	}
+
+	// ToDo items must be read exactly as they have been written, except for
+	// the fields todo.txt doesn't support.
+	decoded, err := Decode(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(toDos, decoded); diff != "" {
+		t.Errorf("unexpected ToDo items (-expected +got):\n%s", diff)
+	}
+}
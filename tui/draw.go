@@ -0,0 +1,181 @@
+// Package tui provides an interactive terminal UI for managing ToDo items. All
+// changes are synced through the REST API, and changes made by other clients
+// are applied live as long as the server's change feed is available.
+package tui
+
+import (
+	"fmt"
+
+	"github.com/dominikbraun/todo/model"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// help is shown in the status line if there's no status message.
+const help = "j/k move  enter expand  space done  e name  d description  r reload  q quit"
+
+var (
+	styleDefault     = tcell.StyleDefault
+	styleHeader      = tcell.StyleDefault.Reverse(true).Bold(true)
+	styleSelected    = tcell.StyleDefault.Reverse(true)
+	styleDescription = tcell.StyleDefault.Dim(true)
+	styleDone        = tcell.StyleDefault.Foreground(tcell.ColorGreen)
+)
+
+// draw renders the header, the visible part of the list and the status line,
+// which is replaced with an input line while a field is being edited.
+func (u *UI) draw() {
+	u.screen.Clear()
+
+	width, height := u.screen.Size()
+
+	feed := "offline"
+	if u.live {
+		feed = "live"
+	}
+
+	u.fill(0, width, styleHeader)
+	u.drawText(1, 0, styleHeader, fmt.Sprintf("%d ToDo items", len(u.toDos)))
+	u.drawText(width-runewidth.StringWidth(feed)-1, 0, styleHeader, feed)
+
+	rows := u.rows()
+	listHeight := height - 2
+
+	// Scroll the list so that the cursor stays visible.
+	if u.cursor < u.offset {
+		u.offset = u.cursor
+	}
+	if listHeight > 0 && u.cursor >= u.offset+listHeight {
+		u.offset = u.cursor - listHeight + 1
+	}
+
+	if len(rows) == 0 {
+		u.drawText(1, 1, styleDescription, "No ToDo items.")
+	}
+
+	for y := 0; y < listHeight && u.offset+y < len(rows); y++ {
+		i := u.offset + y
+		u.drawRow(1+y, width, rows[i], i == u.cursor)
+	}
+
+	u.screen.HideCursor()
+
+	switch {
+	case u.editing != nil:
+		label := "Name: "
+		if u.editing.field == fieldDescription {
+			label = "Description: "
+		}
+		x := u.drawText(0, height-1, styleDefault, label)
+		x = u.drawText(x, height-1, styleDefault, string(u.editing.value))
+		u.screen.ShowCursor(x, height-1)
+	case u.status != "":
+		u.drawText(0, height-1, styleDefault, u.status)
+	default:
+		u.drawText(0, height-1, styleDescription, help)
+	}
+
+	u.screen.Show()
+}
+
+// drawRow renders a ToDo item or task in the given line. ToDo items are marked
+// with + if they have hidden tasks and with - if their tasks are shown.
+func (u *UI) drawRow(y, width int, r row, selected bool) {
+	toDo := u.toDos[r.toDo]
+
+	style := styleDefault
+	if selected {
+		style = styleSelected
+		u.fill(y, width, style)
+	}
+
+	var (
+		x           int
+		done        bool
+		name        string
+		description string
+	)
+
+	if r.task < 0 {
+		marker := " "
+		if len(toDo.Tasks) > 0 {
+			marker = "+"
+			if u.expanded[toDo.ID] {
+				marker = "-"
+			}
+		}
+		x = u.drawText(1, y, style, marker+" ")
+		done, name, description = toDo.Done, toDo.Name, toDo.Description
+	} else {
+		task := toDo.Tasks[r.task]
+		x = u.drawText(1, y, style, "    ")
+		done, name, description = task.Done, task.Name, task.Description
+	}
+
+	checkboxStyle := style
+	if done && !selected {
+		checkboxStyle = styleDone
+	}
+
+	x = u.drawText(x, y, checkboxStyle, checkbox(done))
+	x = u.drawText(x, y, style, " "+name)
+
+	if r.task < 0 && len(toDo.Tasks) > 0 {
+		x = u.drawText(x, y, style, " "+taskProgress(toDo))
+	}
+
+	if description != "" {
+		descriptionStyle := styleDescription
+		if selected {
+			descriptionStyle = style
+		}
+		u.drawText(x, y, descriptionStyle, "  "+description)
+	}
+}
+
+// drawText draws a single line of text and returns the column after it. Text
+// exceeding the width of the screen is cut off.
+func (u *UI) drawText(x, y int, style tcell.Style, text string) int {
+	width, _ := u.screen.Size()
+
+	for _, r := range text {
+		if x >= width {
+			break
+		}
+		u.screen.SetContent(x, y, r, nil, style)
+		x += runewidth.RuneWidth(r)
+	}
+
+	return x
+}
+
+// fill fills a line with the given style.
+func (u *UI) fill(y, width int, style tcell.Style) {
+	for x := 0; x < width; x++ {
+		u.screen.SetContent(x, y, ' ', nil, style)
+	}
+}
+
+// checkbox renders the completion state of a ToDo item or task.
+func checkbox(done bool) string {
+	if done {
+		return "[x]"
+	}
+
+	return "[ ]"
+}
+
+// taskProgress renders the number of finished tasks and the total number of
+// tasks of a ToDo item, e.g. `(1/3)`.
+func taskProgress(toDo model.ToDo) string {
+	finished := 0
+
+	for _, task := range toDo.Tasks {
+		if task.Done {
+			finished++
+		}
+	}
+
+	return fmt.Sprintf("(%d/%d)", finished, len(toDo.Tasks))
+}
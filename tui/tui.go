@@ -0,0 +1,626 @@
+// Package tui provides an interactive terminal UI for managing ToDo items. All
+// changes are synced through the REST API, and changes made by other clients
+// are applied live as long as the server's change feed is available.
+package tui
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/dominikbraun/todo/client"
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/storage"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	// requestTimeout is the maximum duration of a single request to the server.
+	requestTimeout = 10 * time.Second
+
+	// reconnectWait is the delay before subscribing to the change feed again
+	// after the connection has been lost.
+	reconnectWait = 2 * time.Second
+)
+
+var (
+	// errNoChangeFeed indicates that the server doesn't expose a change feed,
+	// so that changes of other clients are only visible after reloading.
+	errNoChangeFeed = errors.New("the server has no change feed, press r to reload")
+)
+
+// field is a field of a ToDo item or task that can be edited inline.
+type field int
+
+const (
+	fieldName field = iota
+	fieldDescription
+)
+
+// row is a line of the list, which is either a ToDo item or one of its tasks.
+// Both are indices into the list of ToDo items and its tasks, where task is -1
+// for the ToDo item itself.
+type row struct {
+	toDo int
+	task int
+}
+
+// edit is an ongoing inline edit of a ToDo item or task. taskID is 0 when the
+// ToDo item itself is edited.
+type edit struct {
+	field  field
+	toDoID int64
+	taskID int64
+	value  []rune
+}
+
+// changeEvent is posted to the event queue of the screen for each event
+// received from the change feed, so that it is applied by the event loop.
+type changeEvent struct {
+	tcell.EventTime
+	event model.Event
+}
+
+// feedEvent is posted to the event queue of the screen when the change feed has
+// been connected, in which case err is nil, or when the connection failed.
+type feedEvent struct {
+	tcell.EventTime
+	err error
+}
+
+// UI is an interactive terminal UI listing all ToDo items. It is not safe for
+// concurrent use.
+type UI struct {
+	client *client.Client
+	screen tcell.Screen
+
+	toDos    []model.ToDo
+	expanded map[int64]bool
+	cursor   int
+	offset   int
+	editing  *edit
+	status   string
+	live     bool
+}
+
+// New creates a new UI which uses the given client for talking to the server
+// and draws on the given screen. The caller is responsible for initializing the
+// screen before calling Run and for finalizing it afterwards.
+func New(c *client.Client, screen tcell.Screen) *UI {
+	return &UI{
+		client:   c,
+		screen:   screen,
+		expanded: make(map[int64]bool),
+	}
+}
+
+// Run loads all ToDo items and handles key presses and live updates until the
+// user quits or the context is cancelled.
+func (u *UI) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Subscribing before loading the ToDo items ensures that no change gets
+	// lost in between. Events that are older than the loaded items are
+	// ignored by apply.
+	go u.watch(ctx)
+
+	go func() {
+		<-ctx.Done()
+		_ = u.screen.PostEvent(tcell.NewEventInterrupt(nil))
+	}()
+
+	if err := u.reload(); err != nil {
+		return err
+	}
+
+	for {
+		u.draw()
+
+		switch event := u.screen.PollEvent().(type) {
+		case nil:
+			return nil
+		case *tcell.EventInterrupt:
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		case *tcell.EventResize:
+			u.screen.Sync()
+		case *tcell.EventKey:
+			if quit := u.handleKey(event); quit {
+				return nil
+			}
+		case *changeEvent:
+			u.apply(event.event)
+		case *feedEvent:
+			u.live = event.err == nil
+			if event.err != nil {
+				u.status = event.err.Error()
+			}
+		}
+	}
+}
+
+// watch subscribes to the change feed and posts all received events to the
+// event queue of the screen. If the connection breaks, it resubscribes with
+// the ID of the last received event, so that no events are missed.
+func (u *UI) watch(ctx context.Context) {
+	var lastEventID int64
+
+	for {
+		stream, err := u.client.WatchEvents(ctx, client.WatchOptions{LastEventID: lastEventID})
+
+		var apiError *client.Error
+
+		if errors.As(err, &apiError) && apiError.StatusCode == http.StatusNotFound {
+			u.post(ctx, &feedEvent{err: errNoChangeFeed})
+			return
+		}
+
+		if err == nil {
+			u.post(ctx, &feedEvent{})
+
+			for {
+				event, err := stream.Next()
+				if err != nil {
+					break
+				}
+				u.post(ctx, &changeEvent{event: event})
+			}
+
+			lastEventID = stream.LastEventID()
+			_ = stream.Close()
+			err = errors.New("lost connection to the change feed, reconnecting")
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		u.post(ctx, &feedEvent{err: err})
+
+		timer := time.NewTimer(reconnectWait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// post posts an event to the event queue of the screen. Since the queue has a
+// limited size, it waits until there's space or the context is cancelled.
+func (u *UI) post(ctx context.Context, event tcell.Event) {
+	if timed, ok := event.(interface{ SetEventNow() }); ok {
+		timed.SetEventNow()
+	}
+
+	for u.screen.PostEvent(event) != nil {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// handleKey handles a key press and reports whether the user wants to quit.
+func (u *UI) handleKey(key *tcell.EventKey) bool {
+	u.status = ""
+
+	if u.editing != nil {
+		u.handleEditKey(key)
+		return false
+	}
+
+	switch key.Key() {
+	case tcell.KeyCtrlC:
+		return true
+	case tcell.KeyUp:
+		u.move(-1)
+	case tcell.KeyDown:
+		u.move(1)
+	case tcell.KeyHome:
+		u.cursor = 0
+	case tcell.KeyEnd:
+		u.cursor = len(u.rows()) - 1
+	case tcell.KeyEnter:
+		u.toggleExpanded()
+	case tcell.KeyRight:
+		u.setExpanded(true)
+	case tcell.KeyLeft:
+		u.setExpanded(false)
+	case tcell.KeyRune:
+		switch key.Rune() {
+		case 'q':
+			return true
+		case 'k':
+			u.move(-1)
+		case 'j':
+			u.move(1)
+		case 'l':
+			u.setExpanded(true)
+		case 'h':
+			u.setExpanded(false)
+		case ' ', 'x':
+			u.toggleDone()
+		case 'e':
+			u.startEdit(fieldName)
+		case 'd':
+			u.startEdit(fieldDescription)
+		case 'r':
+			if err := u.reload(); err != nil {
+				u.status = err.Error()
+			}
+		}
+	}
+
+	return false
+}
+
+// handleEditKey handles a key press while a field is being edited. Enter saves
+// the value, Escape discards it.
+func (u *UI) handleEditKey(key *tcell.EventKey) {
+	switch key.Key() {
+	case tcell.KeyEnter:
+		u.saveEdit()
+	case tcell.KeyEscape:
+		u.editing = nil
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if n := len(u.editing.value); n > 0 {
+			u.editing.value = u.editing.value[:n-1]
+		}
+	case tcell.KeyCtrlU:
+		u.editing.value = nil
+	case tcell.KeyRune:
+		u.editing.value = append(u.editing.value, key.Rune())
+	}
+}
+
+// rows returns the visible lines of the list: all ToDo items, each followed by
+// its tasks if it is expanded.
+func (u *UI) rows() []row {
+	rows := make([]row, 0, len(u.toDos))
+
+	for i, toDo := range u.toDos {
+		rows = append(rows, row{toDo: i, task: -1})
+
+		if !u.expanded[toDo.ID] {
+			continue
+		}
+
+		for j := range toDo.Tasks {
+			rows = append(rows, row{toDo: i, task: j})
+		}
+	}
+
+	return rows
+}
+
+// selected returns the row under the cursor. It returns false if the list is
+// empty.
+func (u *UI) selected() (row, bool) {
+	rows := u.rows()
+
+	if u.cursor < 0 || u.cursor >= len(rows) {
+		return row{}, false
+	}
+
+	return rows[u.cursor], true
+}
+
+// selectedIDs returns the IDs of the ToDo item and task under the cursor. The
+// task ID is 0 if the cursor is on a ToDo item.
+func (u *UI) selectedIDs() (int64, int64) {
+	r, ok := u.selected()
+	if !ok {
+		return 0, 0
+	}
+
+	toDo := u.toDos[r.toDo]
+
+	if r.task < 0 {
+		return toDo.ID, 0
+	}
+
+	return toDo.ID, toDo.Tasks[r.task].ID
+}
+
+// move moves the cursor by the given number of rows.
+func (u *UI) move(delta int) {
+	u.cursor += delta
+	u.clampCursor()
+}
+
+// clampCursor moves the cursor back into the list if it is out of bounds.
+func (u *UI) clampCursor() {
+	if n := len(u.rows()); u.cursor >= n {
+		u.cursor = n - 1
+	}
+
+	if u.cursor < 0 {
+		u.cursor = 0
+	}
+}
+
+// keepSelection runs a change of the list and moves the cursor to the ToDo item
+// or task that has been selected before, as long as it still exists.
+func (u *UI) keepSelection(change func()) {
+	toDoID, taskID := u.selectedIDs()
+
+	change()
+
+	for i, r := range u.rows() {
+		toDo := u.toDos[r.toDo]
+
+		if toDo.ID != toDoID {
+			continue
+		}
+
+		if r.task < 0 && taskID == 0 || r.task >= 0 && toDo.Tasks[r.task].ID == taskID {
+			u.cursor = i
+			return
+		}
+	}
+
+	u.clampCursor()
+}
+
+// toggleExpanded shows or hides the tasks of the selected ToDo item.
+func (u *UI) toggleExpanded() {
+	toDoID, _ := u.selectedIDs()
+	u.setExpanded(!u.expanded[toDoID])
+}
+
+// setExpanded shows or hides the tasks of the selected ToDo item. When they're
+// hidden, the cursor moves from a task to its ToDo item.
+func (u *UI) setExpanded(expanded bool) {
+	toDoID, _ := u.selectedIDs()
+	if toDoID == 0 {
+		return
+	}
+
+	u.keepSelection(func() {
+		u.expanded[toDoID] = expanded
+	})
+
+	if !expanded {
+		for i, r := range u.rows() {
+			if r.task < 0 && u.toDos[r.toDo].ID == toDoID {
+				u.cursor = i
+			}
+		}
+	}
+}
+
+// toggleDone marks the selected ToDo item or task as done or not done.
+func (u *UI) toggleDone() {
+	r, ok := u.selected()
+	if !ok {
+		return
+	}
+
+	toDo := u.toDos[r.toDo]
+
+	if r.task < 0 {
+		toDo.Done = !toDo.Done
+		u.updateToDo(toDo)
+		return
+	}
+
+	task := toDo.Tasks[r.task]
+	task.Done = !task.Done
+
+	u.updateTask(toDo, task)
+}
+
+// startEdit starts editing a field of the selected ToDo item or task, using its
+// current value as initial value.
+func (u *UI) startEdit(f field) {
+	r, ok := u.selected()
+	if !ok {
+		return
+	}
+
+	toDo := u.toDos[r.toDo]
+	name, description := toDo.Name, toDo.Description
+
+	u.editing = &edit{
+		field:  f,
+		toDoID: toDo.ID,
+	}
+
+	if r.task >= 0 {
+		task := toDo.Tasks[r.task]
+		u.editing.taskID = task.ID
+		name, description = task.Name, task.Description
+	}
+
+	if f == fieldName {
+		u.editing.value = []rune(name)
+	} else {
+		u.editing.value = []rune(description)
+	}
+}
+
+// saveEdit stores the edited value of the ToDo item or task.
+func (u *UI) saveEdit() {
+	e := u.editing
+	u.editing = nil
+
+	i := u.indexOf(e.toDoID)
+	if i < 0 {
+		u.status = storage.ErrToDoNotFound.Error()
+		return
+	}
+
+	toDo := u.toDos[i]
+	value := string(e.value)
+
+	if e.taskID == 0 {
+		if e.field == fieldName {
+			toDo.Name = value
+		} else {
+			toDo.Description = value
+		}
+		u.updateToDo(toDo)
+		return
+	}
+
+	for _, task := range toDo.Tasks {
+		if task.ID != e.taskID {
+			continue
+		}
+		if e.field == fieldName {
+			task.Name = value
+		} else {
+			task.Description = value
+		}
+		u.updateTask(toDo, task)
+		return
+	}
+
+	u.status = storage.ErrTaskNotFound.Error()
+}
+
+// reload loads all ToDo items from the server.
+func (u *UI) reload() error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	toDos, err := u.client.GetToDos(ctx)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(toDos, func(i, j int) bool {
+		return toDos[i].ID < toDos[j].ID
+	})
+
+	u.keepSelection(func() {
+		u.toDos = toDos
+	})
+
+	return nil
+}
+
+// updateToDo stores a changed ToDo item on the server. Its version has to match
+// the version on the server.
+func (u *UI) updateToDo(toDo model.ToDo) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	if err := u.client.UpdateToDo(ctx, toDo.ID, toDo); err != nil {
+		u.fail(toDo.ID, err)
+		return
+	}
+
+	updatedToDo, err := u.client.GetToDo(ctx, toDo.ID)
+	if err != nil {
+		u.fail(toDo.ID, err)
+		return
+	}
+
+	u.upsert(updatedToDo)
+}
+
+// updateTask stores a changed task of a ToDo item on the server. The version of
+// the ToDo item has to match the version on the server.
+func (u *UI) updateTask(toDo model.ToDo, task model.Task) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	updatedToDo, err := u.client.UpdateTask(ctx, toDo.ID, toDo.Version, task)
+	if err != nil {
+		u.fail(toDo.ID, err)
+		return
+	}
+
+	u.upsert(updatedToDo)
+}
+
+// fail reports a failed change of a ToDo item in the status line. If the item
+// has been changed or deleted by someone else, the local copy is updated.
+func (u *UI) fail(toDoID int64, err error) {
+	u.status = err.Error()
+
+	switch {
+	case errors.Is(err, storage.ErrToDoNotFound):
+		u.remove(toDoID)
+		u.status = "the ToDo item has been deleted in the meantime"
+	case errors.Is(err, storage.ErrVersionConflict):
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		defer cancel()
+
+		if toDo, err := u.client.GetToDo(ctx, toDoID); err == nil {
+			u.upsert(toDo)
+		}
+		u.status = "the ToDo item has been changed in the meantime, please try again"
+	}
+}
+
+// apply applies an event from the change feed to the list. Events describing an
+// older version than the local one are ignored.
+func (u *UI) apply(event model.Event) {
+	if event.Type == model.EventDeleted {
+		u.remove(event.ToDoID)
+		return
+	}
+
+	if i := u.indexOf(event.ToDoID); i >= 0 && u.toDos[i].Version > event.ToDo.Version {
+		return
+	}
+
+	u.upsert(event.ToDo)
+}
+
+// upsert replaces the ToDo item with the same ID or inserts it in order.
+func (u *UI) upsert(toDo model.ToDo) {
+	u.keepSelection(func() {
+		if i := u.indexOf(toDo.ID); i >= 0 {
+			u.toDos[i] = toDo
+			return
+		}
+
+		i := sort.Search(len(u.toDos), func(i int) bool {
+			return u.toDos[i].ID > toDo.ID
+		})
+
+		u.toDos = append(u.toDos, model.ToDo{})
+		copy(u.toDos[i+1:], u.toDos[i:])
+		u.toDos[i] = toDo
+	})
+}
+
+// remove removes the ToDo item with the given ID from the list.
+func (u *UI) remove(toDoID int64) {
+	i := u.indexOf(toDoID)
+	if i < 0 {
+		return
+	}
+
+	if u.editing != nil && u.editing.toDoID == toDoID {
+		u.editing = nil
+	}
+
+	u.keepSelection(func() {
+		u.toDos = append(u.toDos[:i], u.toDos[i+1:]...)
+		delete(u.expanded, toDoID)
+	})
+}
+
+// indexOf returns the index of the ToDo item with the given ID or -1.
+func (u *UI) indexOf(toDoID int64) int {
+	for i, toDo := range u.toDos {
+		if toDo.ID == toDoID {
+			return i
+		}
+	}
+
+	return -1
+}
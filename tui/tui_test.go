@@ -0,0 +1,303 @@
+// Package tui provides an interactive terminal UI for managing ToDo items. All
+// changes are synced through the REST API, and changes made by other clients
+// are applied live as long as the server's change feed is available.
+package tui
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dominikbraun/todo/client"
+	"github.com/dominikbraun/todo/core"
+	"github.com/dominikbraun/todo/model"
+	"github.com/dominikbraun/todo/server"
+	"github.com/dominikbraun/todo/storage"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// waitTimeout is the maximum duration to wait for the screen to show a change.
+const waitTimeout = 5 * time.Second
+
+// newTestClient starts an httptest.Server serving the real router backed by an
+// in-memory storage and returns a client for it. The router is wrapped by the
+// given function, which allows tests to change the server's behavior.
+func newTestClient(t *testing.T, wrap func(http.Handler) http.Handler) *client.Client {
+	fileSystem, err := storage.NewFileSystem(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create blob store: %s", err.Error())
+	}
+
+	app := core.NewApp(storage.NewMemory(), fileSystem)
+	testServer := httptest.NewServer(wrap(server.New(0, 0, app, "").Handler()))
+	t.Cleanup(testServer.Close)
+
+	c, err := client.New(client.Config{URL: testServer.URL, RetryWait: time.Millisecond})
+	if err != nil {
+		t.Fatalf("failed to create client: %s", err.Error())
+	}
+
+	return c
+}
+
+// testScreen is a simulated screen that records its content each time it is
+// shown. The content of a simulated screen must not be read while the UI is
+// drawing on it, so tests read the recorded content instead.
+type testScreen struct {
+	tcell.SimulationScreen
+	mutex sync.Mutex
+	text  string
+}
+
+// Show shows the screen and records its content, one line per row.
+func (s *testScreen) Show() {
+	s.SimulationScreen.Show()
+
+	cells, width, height := s.GetContents()
+
+	var builder strings.Builder
+
+	for y := 0; y < height; y++ {
+		var line []rune
+		for x := 0; x < width; x++ {
+			line = append(line, cells[y*width+x].Runes...)
+		}
+		builder.WriteString(strings.TrimRight(string(line), " "))
+		builder.WriteByte('\n')
+	}
+
+	s.mutex.Lock()
+	s.text = builder.String()
+	s.mutex.Unlock()
+}
+
+// Text returns the content of the screen when it has been shown the last time.
+func (s *testScreen) Text() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.text
+}
+
+// withoutChangeFeed wraps a handler so that it responds to requests for the
+// change feed with 404 Not Found.
+func withoutChangeFeed(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/events" {
+			http.NotFound(writer, request)
+			return
+		}
+		handler.ServeHTTP(writer, request)
+	})
+}
+
+// startUI runs a UI on a simulated screen until the test has finished.
+func startUI(t *testing.T, c *client.Client) *testScreen {
+	screen := &testScreen{SimulationScreen: tcell.NewSimulationScreen("UTF-8")}
+	if err := screen.Init(); err != nil {
+		t.Fatalf("failed to initialize screen: %s", err.Error())
+	}
+
+	ui := New(c, screen)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- ui.Run(ctx)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		<-done
+		screen.Fini()
+	})
+
+	return screen
+}
+
+// waitForScreen waits until the screen content satisfies the condition.
+func waitForScreen(t *testing.T, screen *testScreen, description string, condition func(text string) bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(waitTimeout)
+
+	for {
+		text := screen.Text()
+		if condition(text) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected screen to show %s, got:\n%s", description, text)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// waitForText waits until the screen shows the given text.
+func waitForText(t *testing.T, screen *testScreen, text string) {
+	t.Helper()
+
+	waitForScreen(t, screen, text, func(screenText string) bool {
+		return strings.Contains(screenText, text)
+	})
+}
+
+// pressKey simulates a key press. Unlike InjectKey, it waits for space in the
+// event queue instead of dropping the key press if the queue is full.
+func pressKey(screen *testScreen, key tcell.Key) {
+	screen.PostEventWait(tcell.NewEventKey(key, 0, tcell.ModNone))
+}
+
+// typeText simulates a key press for each rune of the text.
+func typeText(screen *testScreen, text string) {
+	for _, r := range text {
+		screen.PostEventWait(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+}
+
+func TestUI(t *testing.T) {
+	c := newTestClient(t, func(handler http.Handler) http.Handler { return handler })
+	ctx := context.Background()
+
+	release, _ := c.CreateToDo(ctx, model.ToDo{
+		Name:  "Release 1.0",
+		Tasks: []model.Task{{Name: "Write notes"}, {Name: "Tag commit"}},
+	})
+	other, _ := c.CreateToDo(ctx, model.ToDo{Name: "Other ToDo"})
+
+	screen := startUI(t, c)
+
+	waitForText(t, screen, "+ [ ] Release 1.0 (0/2)")
+	waitForText(t, screen, "  [ ] Other ToDo")
+	waitForText(t, screen, "live")
+
+	// Expand the tasks and mark the first one as done.
+	pressKey(screen, tcell.KeyEnter)
+	waitForText(t, screen, "- [ ] Release 1.0")
+
+	typeText(screen, "j ")
+	waitForText(t, screen, "[x] Write notes")
+	waitForText(t, screen, "Release 1.0 (1/2)")
+
+	toDo, _ := c.GetToDo(ctx, release.ID)
+	if !toDo.Tasks[0].Done {
+		t.Errorf("expected task %d to be done on the server", toDo.Tasks[0].ID)
+	}
+
+	// Rename the task inline.
+	typeText(screen, "e")
+	waitForText(t, screen, "Name: Write notes")
+
+	pressKey(screen, tcell.KeyCtrlU)
+	typeText(screen, "Write changelog")
+	pressKey(screen, tcell.KeyEnter)
+	waitForText(t, screen, "[x] Write changelog")
+
+	// Describe the ToDo item inline.
+	typeText(screen, "kd")
+	waitForText(t, screen, "Description:")
+	typeText(screen, "Due Friday")
+	pressKey(screen, tcell.KeyEnter)
+	waitForText(t, screen, "Release 1.0 (1/2)  Due Friday")
+
+	toDo, _ = c.GetToDo(ctx, release.ID)
+	if toDo.Description != "Due Friday" || toDo.Tasks[0].Name != "Write changelog" {
+		t.Errorf("expected edits to be stored on the server, got %v", toDo)
+	}
+
+	// Cancelled edits must not be stored.
+	typeText(screen, "eDiscarded")
+	pressKey(screen, tcell.KeyEscape)
+	waitForText(t, screen, help)
+
+	if toDo, _ = c.GetToDo(ctx, release.ID); toDo.Name != "Release 1.0" {
+		t.Errorf("expected name %s, got %s", "Release 1.0", toDo.Name)
+	}
+
+	// Changes made by other clients are applied live.
+	_, _ = c.CreateToDo(ctx, model.ToDo{Name: "Created elsewhere"})
+	waitForText(t, screen, "Created elsewhere")
+
+	other.Done = true
+	_ = c.UpdateToDo(ctx, other.ID, other)
+	waitForText(t, screen, "[x] Other ToDo")
+
+	_ = c.DeleteToDo(ctx, release.ID)
+	waitForScreen(t, screen, "no Release 1.0", func(text string) bool {
+		return !strings.Contains(text, "Release 1.0") && !strings.Contains(text, "Write changelog")
+	})
+}
+
+func TestUI_VersionConflict(t *testing.T) {
+	// Without change feed, the UI doesn't know about changes of other clients
+	// and sends outdated versions.
+	c := newTestClient(t, withoutChangeFeed)
+	ctx := context.Background()
+
+	toDo, _ := c.CreateToDo(ctx, model.ToDo{Name: "ToDo 1"})
+
+	screen := startUI(t, c)
+	waitForText(t, screen, "[ ] ToDo 1")
+
+	toDo.Name = "Renamed elsewhere"
+	_ = c.UpdateToDo(ctx, toDo.ID, toDo)
+
+	typeText(screen, "x")
+	waitForText(t, screen, "changed in the meantime")
+	waitForText(t, screen, "[ ] Renamed elsewhere")
+
+	typeText(screen, "x")
+	waitForText(t, screen, "[x] Renamed elsewhere")
+}
+
+func TestUI_NoChangeFeed(t *testing.T) {
+	c := newTestClient(t, withoutChangeFeed)
+
+	_, _ = c.CreateToDo(context.Background(), model.ToDo{Name: "ToDo 1"})
+
+	screen := startUI(t, c)
+
+	waitForText(t, screen, errNoChangeFeed.Error())
+	waitForText(t, screen, "offline")
+
+	_, _ = c.CreateToDo(context.Background(), model.ToDo{Name: "ToDo 2"})
+
+	typeText(screen, "r")
+	waitForText(t, screen, "ToDo 2")
+}
+
+func TestUI_Apply(t *testing.T) {
+	ui := New(nil, nil)
+
+	ui.upsert(model.ToDo{ID: 3, Name: "ToDo 3", Version: 1})
+	ui.upsert(model.ToDo{ID: 1, Name: "ToDo 1", Version: 1})
+	ui.cursor = 1
+
+	ui.apply(model.Event{Type: model.EventCreated, ToDoID: 2, ToDo: model.ToDo{ID: 2, Version: 1}})
+
+	if len(ui.toDos) != 3 || ui.toDos[1].ID != 2 {
+		t.Fatalf("expected ToDo 2 to be inserted in order, got %v", ui.toDos)
+	}
+
+	if toDoID, _ := ui.selectedIDs(); toDoID != 3 {
+		t.Errorf("expected cursor to stay on ToDo %d, got %d", 3, toDoID)
+	}
+
+	ui.apply(model.Event{Type: model.EventUpdated, ToDoID: 3, ToDo: model.ToDo{ID: 3, Name: "Outdated", Version: 0}})
+
+	if ui.toDos[2].Name != "ToDo 3" {
+		t.Errorf("expected outdated event to be ignored, got %s", ui.toDos[2].Name)
+	}
+
+	ui.apply(model.Event{Type: model.EventDeleted, ToDoID: 3})
+
+	if toDoID, _ := ui.selectedIDs(); len(ui.toDos) != 2 || toDoID != 2 {
+		t.Errorf("expected cursor on ToDo %d after deletion, got %d", 2, toDoID)
+	}
+}